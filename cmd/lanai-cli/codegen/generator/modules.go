@@ -73,7 +73,7 @@ var (
 	LanaiSwagger       = &LanaiModule{Name: "swagger", InitPackage: "swagger"}
 	LanaiTracing       = &LanaiModule{Name: "tracing", InitPackage: "tracing/init"}
 	LanaiData          = &LanaiModule{Name: "data", InitPackage: "data/init"}
-	LanaiCockroach     = &LanaiModule{Name: "cockroach", InitPackage: "cockroach"}
+	LanaiCockroach     = &LanaiModule{Name: "cockroach", InitPackage: "data/postgresql/cockroach"}
 	LanaiKafka         = &LanaiModule{Name: "kafka", InitPackage: "kafka"}
 	LanaiHttpClient    = &LanaiModule{Name: "httpclient", InitPackage: "integrate/httpclient"}
 	LanaiSecurityScope = &LanaiModule{Name: "scope", InitPackage: "integrate/security/scope"}
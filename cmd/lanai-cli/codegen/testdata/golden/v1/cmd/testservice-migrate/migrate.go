@@ -1 +1,44 @@
 package main
+
+import (
+	appconfig "github.com/cisco-open/go-lanai/pkg/appconfig/init"
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	consul "github.com/cisco-open/go-lanai/pkg/consul/init"
+	data "github.com/cisco-open/go-lanai/pkg/data/init"
+	"github.com/cisco-open/go-lanai/pkg/data/postgresql/cockroach"
+	"github.com/cisco-open/go-lanai/pkg/migration"
+	"github.com/cisco-open/go-lanai/pkg/redis"
+	tracing "github.com/cisco-open/go-lanai/pkg/tracing/init"
+	vault "github.com/cisco-open/go-lanai/pkg/vault/init"
+	"go.uber.org/fx"
+	"time"
+)
+
+func init() {
+	// basic modules
+	appconfig.Use()
+	consul.Use()
+	vault.Use()
+	redis.Use()
+	tracing.Use()
+
+	// data related, always required by migration
+	data.Use()
+	cockroach.Use()
+
+	// migration, with flags "-filter", "-allow_out_of_order", "-target", "-repair" and "-dry_run".
+	// e.g. use "-target 1.0.0.1" to rollback any applied steps newer than 1.0.0.1
+	migration.Use()
+}
+
+func main() {
+	// bootstrapping
+	bootstrap.NewAppCmd(
+		"testservice-migrate",
+		nil,
+		[]fx.Option{
+			fx.StartTimeout(60 * time.Second),
+		},
+	)
+	bootstrap.Execute()
+}
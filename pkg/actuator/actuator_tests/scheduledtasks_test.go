@@ -0,0 +1,189 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package actuator_tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/actuator"
+	"github.com/cisco-open/go-lanai/pkg/actuator/actuator_tests/testdata"
+	"github.com/cisco-open/go-lanai/pkg/actuator/scheduledtasks"
+	"github.com/cisco-open/go-lanai/pkg/scheduler"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/actuatortest"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/sectest"
+	. "github.com/cisco-open/go-lanai/test/utils/gomega"
+	"github.com/cisco-open/go-lanai/test/webtest"
+	. "github.com/onsi/gomega"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const TestScheduledTaskName = "test-actuator-task"
+
+/*************************
+	Test Setup
+ *************************/
+
+func SetupScheduledTask() test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		_, e := scheduler.Repeat(func(ctx context.Context) error {
+			return nil
+		}, scheduler.StartAfter(time.Hour), scheduler.AtRate(time.Hour), scheduler.Name(TestScheduledTaskName))
+		return ctx, e
+	}
+}
+
+func TeardownScheduledTask() test.TeardownFunc {
+	return func(ctx context.Context, t *testing.T) error {
+		if task := scheduler.FindTask(TestScheduledTaskName); task != nil {
+			task.Cancel()
+		}
+		return nil
+	}
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestScheduledTasksEndpoint(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		webtest.WithMockedServer(webtest.AddDefaultRequestOptions(v3RequestOptions())),
+		sectest.WithMockedMiddleware(),
+		actuatortest.WithEndpoints(actuatortest.DisableAllEndpoints()),
+		apptest.WithModules(scheduledtasks.Module),
+		apptest.WithConfigFS(testdata.TestConfigFS),
+		apptest.WithProperties("management.endpoint.scheduledtasks.enabled: true"),
+		test.SubTestSetup(SetupScheduledTask()),
+		test.SubTestTeardown(TeardownScheduledTask()),
+		test.GomegaSubTest(SubTestScheduledTasksWithAccess(mockedSecurityAdmin()), "TestScheduledTasksWithAccess"),
+		test.GomegaSubTest(SubTestScheduledTasksWithoutAccess(mockedSecurityNonAdmin()), "TestScheduledTasksWithoutAccess"),
+		test.GomegaSubTest(SubTestScheduledTasksWithoutAuth(), "TestScheduledTasksWithoutAuth"),
+		test.GomegaSubTest(SubTestScheduledTasksActions(mockedSecurityAdmin()), "TestScheduledTasksActions"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestScheduledTasksWithAccess(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		// GET all
+		req := webtest.NewRequest(ctx, http.MethodGet, "/admin/scheduledtasks", nil)
+		resp := webtest.MustExec(ctx, req)
+		assertResponse(t, g, resp.Response, http.StatusOK, "Content-Type", actuator.ContentTypeSpringBootV3)
+		body := readBody(g, resp.Response)
+		g.Expect(body).To(HaveJsonPathWithValue(fmt.Sprintf("$.tasks[?(@.name=='%s')].mode", TestScheduledTaskName), "fixed-rate"),
+			"response should contain task's mode")
+		g.Expect(body).To(HaveJsonPathWithValue(fmt.Sprintf("$.tasks[?(@.name=='%s')].interval", TestScheduledTaskName), "1h0m0s"),
+			"response should contain task's interval")
+
+		// GET by name
+		req = webtest.NewRequest(ctx, http.MethodGet, "/admin/scheduledtasks/"+TestScheduledTaskName, nil)
+		resp = webtest.MustExec(ctx, req)
+		assertResponse(t, g, resp.Response, http.StatusOK, "Content-Type", actuator.ContentTypeSpringBootV3)
+		body = readBody(g, resp.Response)
+		g.Expect(body).To(HaveJsonPathWithValue("$.name", TestScheduledTaskName), "response should contain task's name")
+		g.Expect(body).To(HaveJsonPathWithValue("$.runCount", float64(0)), "response should contain task's run count")
+
+		// GET non-existing
+		req = webtest.NewRequest(ctx, http.MethodGet, "/admin/scheduledtasks/non-existing", nil)
+		resp = webtest.MustExec(ctx, req)
+		assertResponse(t, g, resp.Response, http.StatusNotFound)
+	}
+}
+
+func SubTestScheduledTasksWithoutAccess(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		req := webtest.NewRequest(ctx, http.MethodGet, "/admin/scheduledtasks", nil)
+		resp := webtest.MustExec(ctx, req)
+		assertResponse(t, g, resp.Response, http.StatusForbidden)
+
+		req = newActionRequest(ctx, TestScheduledTaskName, scheduledtasks.ActionTrigger)
+		resp = webtest.MustExec(ctx, req)
+		assertResponse(t, g, resp.Response, http.StatusForbidden)
+	}
+}
+
+func SubTestScheduledTasksWithoutAuth() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		req := webtest.NewRequest(ctx, http.MethodGet, "/admin/scheduledtasks", nil)
+		resp := webtest.MustExec(ctx, req)
+		assertResponse(t, g, resp.Response, http.StatusUnauthorized)
+
+		req = newActionRequest(ctx, TestScheduledTaskName, scheduledtasks.ActionPause)
+		resp = webtest.MustExec(ctx, req)
+		assertResponse(t, g, resp.Response, http.StatusUnauthorized)
+	}
+}
+
+func SubTestScheduledTasksActions(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		task := scheduler.FindTask(TestScheduledTaskName)
+		g.Expect(task).ToNot(BeNil(), "task should be registered")
+
+		// pause
+		resp := webtest.MustExec(ctx, newActionRequest(ctx, TestScheduledTaskName, scheduledtasks.ActionPause))
+		assertResponse(t, g, resp.Response, http.StatusNoContent)
+		g.Expect(task.Info().Paused).To(BeTrue(), "task should be paused")
+
+		// resume
+		resp = webtest.MustExec(ctx, newActionRequest(ctx, TestScheduledTaskName, scheduledtasks.ActionResume))
+		assertResponse(t, g, resp.Response, http.StatusNoContent)
+		g.Expect(task.Info().Paused).To(BeFalse(), "task should be resumed")
+
+		// trigger
+		resp = webtest.MustExec(ctx, newActionRequest(ctx, TestScheduledTaskName, scheduledtasks.ActionTrigger))
+		assertResponse(t, g, resp.Response, http.StatusNoContent)
+		g.Eventually(func() int { return task.Info().RunCount }, time.Second).
+			Should(Equal(1), "task should be triggered")
+
+		// unsupported
+		resp = webtest.MustExec(ctx, newActionRequest(ctx, TestScheduledTaskName, "unknown"))
+		assertResponse(t, g, resp.Response, http.StatusBadRequest)
+
+		// non-existing
+		resp = webtest.MustExec(ctx, newActionRequest(ctx, "non-existing", scheduledtasks.ActionTrigger))
+		assertResponse(t, g, resp.Response, http.StatusNotFound)
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func newActionRequest(ctx context.Context, name, action string) *http.Request {
+	body := fmt.Sprintf(`{"action":"%s"}`, action)
+	return webtest.NewRequest(ctx, http.MethodPost, "/admin/scheduledtasks/"+name, strings.NewReader(body),
+		webtest.ContentType("application/json"))
+}
+
+func readBody(g *WithT, resp *http.Response) []byte {
+	body, e := io.ReadAll(resp.Body)
+	g.Expect(e).To(Succeed(), "response body should be readable")
+	return body
+}
@@ -27,7 +27,7 @@ import (
 
 var (
 	DefaultStatusOrders = []Status{
-		StatusDown, StatusOutOfService, StatusUp, StatusDown,
+		StatusDown, StatusOutOfService, StatusDegraded, StatusUp, StatusDown,
 	}
 )
 
@@ -29,6 +29,7 @@ var DefaultStaticStatusCodeMapper = StaticStatusCodeMapper{
 	StatusUp:           http.StatusOK,
 	StatusDown:         http.StatusServiceUnavailable,
 	StatusOutOfService: http.StatusServiceUnavailable,
+	StatusDegraded:     http.StatusOK,
 	StatusUnknown:      http.StatusInternalServerError,
 }
 
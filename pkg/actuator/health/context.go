@@ -26,6 +26,8 @@ const (
 	StatusUp
 	StatusOutOfService
 	StatusDown
+	// StatusDegraded the component is functioning with reduced capability, e.g. falling behind
+	StatusDegraded
 )
 
 type Status int
@@ -39,6 +41,8 @@ func (s Status) String() string {
 		return "DOWN"
 	case StatusOutOfService:
 		return "OUT_OF_SERVICE"
+	case StatusDegraded:
+		return "DEGRADED"
 	default:
 		return "UNKNOWN"
 	}
@@ -59,6 +63,8 @@ func (s *Status) UnmarshalText(data []byte) error {
 		*s = StatusDown
 	case "OUT_OF_SERVICE":
 		*s = StatusOutOfService
+	case "DEGRADED":
+		*s = StatusDegraded
 	default:
 		*s = StatusUnknown
 	}
@@ -72,7 +72,7 @@ type StatusProperties struct {
 func NewHealthProperties() *HealthProperties {
 	return &HealthProperties{
 		Status: StatusProperties{
-			Orders: StatusOrders{StatusDown, StatusOutOfService, StatusUp, StatusUnknown},
+			Orders: StatusOrders{StatusDown, StatusOutOfService, StatusDegraded, StatusUp, StatusUnknown},
 			ScMapping: map[Status]int{},
 		},
 		Permissions: []string{},
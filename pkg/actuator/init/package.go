@@ -25,6 +25,7 @@ import (
     health "github.com/cisco-open/go-lanai/pkg/actuator/health/endpoint"
     "github.com/cisco-open/go-lanai/pkg/actuator/info"
     "github.com/cisco-open/go-lanai/pkg/actuator/loggers"
+    "github.com/cisco-open/go-lanai/pkg/actuator/scheduledtasks"
     appconfig "github.com/cisco-open/go-lanai/pkg/appconfig/init"
     "github.com/cisco-open/go-lanai/pkg/bootstrap"
     "go.uber.org/fx"
@@ -50,6 +51,7 @@ func Use() {
 	alive.Register()
 	apilist.Register()
 	loggers.Register()
+	scheduledtasks.Register()
 }
 
 /**************************
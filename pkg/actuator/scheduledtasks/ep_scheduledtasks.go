@@ -0,0 +1,199 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduledtasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/actuator"
+	"github.com/cisco-open/go-lanai/pkg/scheduler"
+	"github.com/cisco-open/go-lanai/pkg/web"
+	"net/http"
+	"time"
+)
+
+const (
+	ID              = "scheduledtasks"
+	EnableByDefault = false
+)
+
+const (
+	ActionPause   = "pause"
+	ActionResume  = "resume"
+	ActionTrigger = "trigger"
+)
+
+type ReadInput struct {
+	Name string `uri:"name"`
+}
+
+type WriteInput struct {
+	Name   string `uri:"name" binding:"required"`
+	Action string `json:"action" binding:"required"`
+}
+
+type ReadOutput struct {
+	Tasks []Task `json:"tasks"`
+}
+
+type Task struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name,omitempty"`
+	Mode           string     `json:"mode"`
+	Interval       string     `json:"interval,omitempty"`
+	Cron           string     `json:"cron,omitempty"`
+	Paused         bool       `json:"paused"`
+	NextFireTime   *time.Time `json:"nextFireTime,omitempty"`
+	RunCount       int        `json:"runCount"`
+	SkipCount      int        `json:"skipCount"`
+	LastStartedAt  *time.Time `json:"lastStartedAt,omitempty"`
+	LastFinishedAt *time.Time `json:"lastFinishedAt,omitempty"`
+	LastOutcome    string     `json:"lastOutcome,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+}
+
+// ScheduledTasksEndpoint implements actuator.Endpoint, actuator.WebEndpoint
+//
+//goland:noinspection GoNameStartsWithPackageName
+type ScheduledTasksEndpoint struct {
+	actuator.WebEndpointBase
+	pathSuffix map[actuator.Operation]string
+}
+
+func newEndpoint(di regDI) *ScheduledTasksEndpoint {
+	ep := ScheduledTasksEndpoint{}
+	ep.pathSuffix = map[actuator.Operation]string{
+		actuator.NewReadOperation(ep.ReadAll):    "",
+		actuator.NewReadOperation(ep.ReadAll):    "/",
+		actuator.NewReadOperation(ep.ReadByName): "/:name",
+		actuator.NewWriteOperation(ep.Write):     "/:name",
+	}
+	ops := make([]actuator.Operation, 0, len(ep.pathSuffix))
+	for k := range ep.pathSuffix {
+		ops = append(ops, k)
+	}
+	ep.WebEndpointBase = actuator.MakeWebEndpointBase(func(opt *actuator.EndpointOption) {
+		opt.Id = ID
+		opt.Ops = ops
+		opt.Properties = &di.MgtProperties.Endpoints
+		opt.EnabledByDefault = EnableByDefault
+	})
+	return &ep
+}
+
+// Mappings implements WebEndpoint
+func (ep *ScheduledTasksEndpoint) Mappings(op actuator.Operation, group string) ([]web.Mapping, error) {
+	builder, e := ep.RestMappingBuilder(op, group, ep.MappingPath, ep.MappingName)
+	if e != nil {
+		return nil, e
+	}
+	if op.Mode() == actuator.OperationWrite {
+		builder.EncodeResponseFunc(ep.WriteEncodeResponse)
+	}
+	return []web.Mapping{builder.Build()}, nil
+}
+
+func (ep *ScheduledTasksEndpoint) MappingPath(op actuator.Operation, props *actuator.WebEndpointsProperties) string {
+	path := ep.WebEndpointBase.MappingPath(op, props)
+	suffix, _ := ep.pathSuffix[op]
+	return path + suffix
+}
+
+// ReadAll returns all active scheduled tasks
+func (ep *ScheduledTasksEndpoint) ReadAll(_ context.Context, _ *struct{}) (interface{}, error) {
+	tasks := scheduler.Tasks()
+	out := ReadOutput{
+		Tasks: make([]Task, len(tasks)),
+	}
+	for i := range tasks {
+		out.Tasks[i] = toTask(tasks[i].Info())
+	}
+	return out, nil
+}
+
+// ReadByName find one task by ID or name
+func (ep *ScheduledTasksEndpoint) ReadByName(_ context.Context, in *ReadInput) (interface{}, error) {
+	task, e := findTask(in.Name)
+	if e != nil {
+		return nil, e
+	}
+	out := toTask(task.Info())
+	return &out, nil
+}
+
+// Write pause, resume or trigger a task
+func (ep *ScheduledTasksEndpoint) Write(_ context.Context, in *WriteInput) (interface{}, error) {
+	task, e := findTask(in.Name)
+	if e != nil {
+		return nil, e
+	}
+	switch in.Action {
+	case ActionPause:
+		task.Pause()
+	case ActionResume:
+		task.Resume()
+	case ActionTrigger:
+		if e := task.Trigger(); e != nil {
+			return nil, web.NewHttpError(http.StatusConflict, e)
+		}
+	default:
+		return nil, web.NewHttpError(http.StatusBadRequest, fmt.Errorf("unsupported action [%s]", in.Action))
+	}
+	return nil, nil
+}
+
+func (ep *ScheduledTasksEndpoint) WriteEncodeResponse(_ context.Context, rw http.ResponseWriter, _ interface{}) error {
+	rw.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+/*******************
+	Helpers
+ *******************/
+
+func findTask(idOrName string) (scheduler.ManagedTask, error) {
+	task := scheduler.FindTask(idOrName)
+	if task == nil {
+		return nil, web.NewHttpError(http.StatusNotFound, fmt.Errorf("scheduled task with name %s not found", idOrName))
+	}
+	return task, nil
+}
+
+func toTask(info scheduler.TaskInfo) Task {
+	return Task{
+		ID:             info.ID,
+		Name:           info.Name,
+		Mode:           info.Mode,
+		Interval:       info.Interval,
+		Cron:           info.Cron,
+		Paused:         info.Paused,
+		NextFireTime:   timePtr(info.NextFireTime),
+		RunCount:       info.RunCount,
+		SkipCount:      info.SkipCount,
+		LastStartedAt:  timePtr(info.LastStartedAt),
+		LastFinishedAt: timePtr(info.LastFinishedAt),
+		LastOutcome:    string(info.LastOutcome),
+		LastError:      info.LastError,
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
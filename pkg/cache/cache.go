@@ -0,0 +1,67 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrCacheMiss = errors.New("cache miss")
+)
+
+// Cache is a generic key-value cache. Implementations are goroutine-safe.
+// See MemCache for in-process cache, and package "cache/redis" for shared cache and near-cache backed by Redis
+type Cache[K comparable, V any] interface {
+	// Get returns the cached value of given key, or ErrCacheMiss if the entry doesn't exist or is expired
+	Get(ctx context.Context, key K) (V, error)
+	// Set caches the value of given key. Entry's TTL can be set via WithTTL, otherwise the cache's default TTL applies
+	Set(ctx context.Context, key K, value V, opts ...EntryOptions) error
+	// Delete removes entries of given keys. Deleting non-existing keys is not an error
+	Delete(ctx context.Context, keys ...K) error
+	// GetOrLoad returns the cached value of given key. If the entry doesn't exist, the loader is invoked and its result is cached.
+	// Concurrent invocations of same key share a single load. Errors returned by the loader are not cached.
+	GetOrLoad(ctx context.Context, key K, loader LoadFunc[K, V], opts ...EntryOptions) (V, error)
+}
+
+// LoadFunc loads the value of given key when it's not found in Cache
+type LoadFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+type EntryOptions func(opt *EntryOption)
+type EntryOption struct {
+	// TTL of the entry. Zero means the entry doesn't expire
+	TTL time.Duration
+}
+
+// WithTTL overrides the cache's default TTL of the entry
+func WithTTL(ttl time.Duration) EntryOptions {
+	return func(opt *EntryOption) {
+		opt.TTL = ttl
+	}
+}
+
+// ResolveEntryOption applies given EntryOptions on top of the default TTL.
+// This function is intended for Cache implementations
+func ResolveEntryOption(defaultTTL time.Duration, opts ...EntryOptions) EntryOption {
+	opt := EntryOption{TTL: defaultTTL}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	return opt
+}
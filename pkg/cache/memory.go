@@ -0,0 +1,119 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/utils/cacheutils"
+	"time"
+)
+
+type MemCacheOptions func(opt *MemCacheOption)
+type MemCacheOption struct {
+	// DefaultTTL applies to entries without TTL set. Zero means entries don't expire
+	DefaultTTL time.Duration
+	// Heartbeat is the interval of evicting expired entries
+	Heartbeat time.Duration
+}
+
+// MemCache implements Cache in-process, backed by cacheutils.MemCache
+type MemCache[K comparable, V any] struct {
+	MemCacheOption
+	cache cacheutils.MemCache
+}
+
+func NewMemCache[K comparable, V any](opts ...MemCacheOptions) *MemCache[K, V] {
+	opt := MemCacheOption{
+		Heartbeat: 10 * time.Minute,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	return &MemCache[K, V]{
+		MemCacheOption: opt,
+		cache: cacheutils.NewMemCache(func(cOpt *cacheutils.CacheOption) {
+			cOpt.Heartbeat = opt.Heartbeat
+		}),
+	}
+}
+
+func (c *MemCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	// entries created by this loader expire immediately, so misses are never cached
+	return c.get(ctx, key, func(_ context.Context, _ cacheutils.Key) (interface{}, time.Time, error) {
+		return nil, time.Now(), ErrCacheMiss
+	}, nil)
+}
+
+func (c *MemCache[K, V]) Set(ctx context.Context, key K, value V, opts ...EntryOptions) error {
+	opt := ResolveEntryOption(c.DefaultTTL, opts...)
+	// existing entry is always invalidated and replaced by new entry
+	_, e := c.get(ctx, key, func(_ context.Context, _ cacheutils.Key) (interface{}, time.Time, error) {
+		return value, expireTime(opt.TTL), nil
+	}, func(_ context.Context, _ interface{}) bool {
+		return false
+	})
+	return e
+}
+
+func (c *MemCache[K, V]) Delete(_ context.Context, keys ...K) error {
+	for _, k := range keys {
+		c.cache.Delete(memKey[K]{key: k})
+	}
+	return nil
+}
+
+// Reset removes all entries, regardless if they are valid
+func (c *MemCache[K, V]) Reset(_ context.Context) {
+	c.cache.Reset()
+}
+
+func (c *MemCache[K, V]) GetOrLoad(ctx context.Context, key K, loader LoadFunc[K, V], opts ...EntryOptions) (V, error) {
+	opt := ResolveEntryOption(c.DefaultTTL, opts...)
+	return c.get(ctx, key, func(ctx context.Context, _ cacheutils.Key) (interface{}, time.Time, error) {
+		v, e := loader(ctx, key)
+		if e != nil {
+			// don't cache errors
+			return nil, time.Now(), e
+		}
+		return v, expireTime(opt.TTL), nil
+	}, nil)
+}
+
+func (c *MemCache[K, V]) get(ctx context.Context, key K, loader cacheutils.LoadFunc, validator cacheutils.ValidateFunc) (ret V, err error) {
+	v, e := c.cache.GetOrLoad(ctx, memKey[K]{key: key}, loader, validator)
+	if e != nil {
+		return ret, e
+	}
+	ret, _ = v.(V)
+	return ret, nil
+}
+
+// memKey implements cacheutils.Key
+type memKey[K comparable] struct {
+	key K
+}
+
+func (k memKey[K]) Hash() interface{} {
+	return k.key
+}
+
+func expireTime(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
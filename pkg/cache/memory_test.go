@@ -0,0 +1,148 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/cache"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*************************
+	Tests
+ *************************/
+
+func TestMemCache(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestGetSetDelete(), "TestGetSetDelete"),
+		test.GomegaSubTest(SubTestEntryTTL(), "TestEntryTTL"),
+		test.GomegaSubTest(SubTestGetOrLoad(), "TestGetOrLoad"),
+		test.GomegaSubTest(SubTestGetOrLoadError(), "TestGetOrLoadError"),
+		test.GomegaSubTest(SubTestReset(), "TestReset"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestGetSetDelete() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		var c cache.Cache[string, int] = cache.NewMemCache[string, int]()
+		_, e := c.Get(ctx, "key")
+		g.Expect(e).To(MatchError(cache.ErrCacheMiss), "Get of non-existing key should return ErrCacheMiss")
+
+		g.Expect(c.Set(ctx, "key", 1)).To(Succeed(), "Set should not fail")
+		v, e := c.Get(ctx, "key")
+		g.Expect(e).To(Succeed(), "Get should not fail")
+		g.Expect(v).To(Equal(1), "Get should return value of Set")
+
+		g.Expect(c.Set(ctx, "key", 2)).To(Succeed(), "Set on existing key should not fail")
+		v, e = c.Get(ctx, "key")
+		g.Expect(e).To(Succeed(), "Get should not fail")
+		g.Expect(v).To(Equal(2), "Get should return overwritten value")
+
+		g.Expect(c.Delete(ctx, "key", "non-existing")).To(Succeed(), "Delete should not fail")
+		_, e = c.Get(ctx, "key")
+		g.Expect(e).To(MatchError(cache.ErrCacheMiss), "Get of deleted key should return ErrCacheMiss")
+	}
+}
+
+func SubTestEntryTTL() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := cache.NewMemCache[string, string](func(opt *cache.MemCacheOption) {
+			opt.DefaultTTL = time.Hour
+		})
+		g.Expect(c.Set(ctx, "short", "v", cache.WithTTL(50*time.Millisecond))).To(Succeed(), "Set with TTL should not fail")
+		g.Expect(c.Set(ctx, "long", "v")).To(Succeed(), "Set with default TTL should not fail")
+
+		time.Sleep(100 * time.Millisecond)
+		_, e := c.Get(ctx, "short")
+		g.Expect(e).To(MatchError(cache.ErrCacheMiss), "entry should expire after its own TTL")
+		v, e := c.Get(ctx, "long")
+		g.Expect(e).To(Succeed(), "entry with default TTL should not expire yet")
+		g.Expect(v).To(Equal("v"), "entry with default TTL should have correct value")
+	}
+}
+
+func SubTestGetOrLoad() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := cache.NewMemCache[string, string]()
+		var count int64
+		loader := func(ctx context.Context, key string) (string, error) {
+			atomic.AddInt64(&count, 1)
+			time.Sleep(50 * time.Millisecond)
+			return "loaded-" + key, nil
+		}
+
+		var wg sync.WaitGroup
+		results := make([]string, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = c.GetOrLoad(ctx, "key", loader)
+			}(i)
+		}
+		wg.Wait()
+		g.Expect(atomic.LoadInt64(&count)).To(BeEquivalentTo(1), "concurrent GetOrLoad should load only once")
+		for _, v := range results {
+			g.Expect(v).To(Equal("loaded-key"), "all GetOrLoad should return loaded value")
+		}
+
+		v, e := c.Get(ctx, "key")
+		g.Expect(e).To(Succeed(), "loaded entry should be cached")
+		g.Expect(v).To(Equal("loaded-key"), "cached entry should have loaded value")
+	}
+}
+
+func SubTestGetOrLoadError() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := cache.NewMemCache[string, string]()
+		expectedErr := errors.New("oops")
+		_, e := c.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (string, error) {
+			return "", expectedErr
+		})
+		g.Expect(e).To(MatchError(expectedErr), "GetOrLoad should return loader's error")
+
+		v, e := c.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (string, error) {
+			return "recovered", nil
+		})
+		g.Expect(e).To(Succeed(), "loader's error should not be cached")
+		g.Expect(v).To(Equal("recovered"), "GetOrLoad should return loaded value after error")
+	}
+}
+
+func SubTestReset() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := cache.NewMemCache[string, int]()
+		g.Expect(c.Set(ctx, "key1", 1)).To(Succeed(), "Set should not fail")
+		g.Expect(c.Set(ctx, "key2", 2)).To(Succeed(), "Set should not fail")
+		c.Reset(ctx)
+		for _, k := range []string{"key1", "key2"} {
+			_, e := c.Get(ctx, k)
+			g.Expect(e).To(MatchError(cache.ErrCacheMiss), "Get after Reset should return ErrCacheMiss")
+		}
+	}
+}
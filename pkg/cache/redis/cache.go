@@ -0,0 +1,196 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/cache"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/log"
+	"github.com/cisco-open/go-lanai/pkg/redis"
+	goredis "github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+	"time"
+)
+
+var logger = log.New("Cache.Redis")
+
+const (
+	lockKeyPrefix = "cache-load/"
+)
+
+// loadFlights coordinates GetOrLoad within the process. It's shared by all RedisCache instances, because instances of
+// same name would share same distributed lock, which should not be used by multiple goroutines at the same time.
+// Instances of same name are expected to have same value type. Otherwise, GetOrLoad fails with an error.
+var loadFlights singleflight.Group
+
+type RedisCacheOptions func(opt *RedisCacheOption)
+type RedisCacheOption struct {
+	// DefaultTTL applies to entries without TTL set. Zero means entries don't expire
+	DefaultTTL time.Duration
+	// Serializer converts values from/to bytes. cache.JsonSerializer by default
+	Serializer cache.Serializer
+	// KeyFormatter converts cache keys to string. fmt.Sprint by default
+	KeyFormatter func(key interface{}) string
+	// SyncManager enables single-flight GetOrLoad across replicas. When set, loading of each key is guarded by a
+	// distributed lock. Otherwise, GetOrLoad is only single-flight within the process
+	SyncManager dsync.SyncManager
+	// LoadTimeout bounds the shared load of GetOrLoad, including waiting for the distributed lock and the loader.
+	// One minute by default. Zero or negative value means no timeout
+	LoadTimeout time.Duration
+}
+
+// RedisCache implements cache.Cache backed by Redis. Entries are stored as "<name>:<key>".
+// Redis commands are traced via the tracing hook of redis.ClientFactory, as long as the client is created by the factory
+type RedisCache[K comparable, V any] struct {
+	RedisCacheOption
+	name   string
+	client redis.Client
+}
+
+// NewRedisCache creates a RedisCache with given name, which is used as namespace of Redis keys.
+// RedisCache instances of same name are interchangeable across replicas
+func NewRedisCache[K comparable, V any](client redis.Client, name string, opts ...RedisCacheOptions) *RedisCache[K, V] {
+	opt := RedisCacheOption{
+		Serializer:   cache.JsonSerializer{},
+		KeyFormatter: func(key interface{}) string { return fmt.Sprint(key) },
+		LoadTimeout:  time.Minute,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	return &RedisCache[K, V]{
+		RedisCacheOption: opt,
+		name:             name,
+		client:           client,
+	}
+}
+
+func (c *RedisCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	return c.get(ctx, c.redisKey(key))
+}
+
+func (c *RedisCache[K, V]) Set(ctx context.Context, key K, value V, opts ...cache.EntryOptions) error {
+	return c.set(ctx, c.redisKey(key), value, cache.ResolveEntryOption(c.DefaultTTL, opts...))
+}
+
+func (c *RedisCache[K, V]) Delete(ctx context.Context, keys ...K) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, c.redisKeys(keys...)...).Err()
+}
+
+func (c *RedisCache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.LoadFunc[K, V], opts ...cache.EntryOptions) (V, error) {
+	rKey := c.redisKey(key)
+	v, e := c.get(ctx, rKey)
+	if e == nil || !errors.Is(e, cache.ErrCacheMiss) {
+		return v, e
+	}
+	// The shared load is detached from the caller's cancellation, so one caller giving up doesn't fail the others.
+	// It's bounded by LoadTimeout instead, so a stuck load doesn't block later callers of the same key forever.
+	// Each caller still stops waiting when its own context is done.
+	ch := loadFlights.DoChan(rKey, func() (ret interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("loading cache entry [%s] panicked: %v", rKey, r)
+			}
+		}()
+		loadCtx := context.WithoutCancel(ctx)
+		if c.LoadTimeout > 0 {
+			var cancelFn context.CancelFunc
+			loadCtx, cancelFn = context.WithTimeout(loadCtx, c.LoadTimeout)
+			defer cancelFn()
+		}
+		return c.load(loadCtx, key, rKey, loader, cache.ResolveEntryOption(c.DefaultTTL, opts...))
+	})
+	select {
+	case <-ctx.Done():
+		return v, context.Cause(ctx)
+	case r := <-ch:
+		if r.Err != nil {
+			return v, r.Err
+		}
+		loaded, ok := r.Val.(V)
+		if !ok {
+			return v, fmt.Errorf("cache entry [%s] is loaded as %T by another cache of same name, but %T is expected", rKey, r.Val, v)
+		}
+		return loaded, nil
+	}
+}
+
+// load invokes the loader and caches the result. If SyncManager is available, the loading is guarded by distributed lock,
+// and the entry is double-checked after the lock is acquired, because it might be loaded by other replicas meanwhile.
+func (c *RedisCache[K, V]) load(ctx context.Context, key K, rKey string, loader cache.LoadFunc[K, V], opt cache.EntryOption) (v V, err error) {
+	if c.SyncManager != nil {
+		lock, e := c.SyncManager.Lock(lockKeyPrefix + rKey)
+		if e != nil {
+			return v, e
+		}
+		defer func() { _ = lock.Release() }()
+		if e := lock.Lock(ctx); e != nil {
+			return v, fmt.Errorf("unable to acquire loading lock of cache entry [%s]: %w", rKey, e)
+		}
+		if v, e = c.get(ctx, rKey); e == nil || !errors.Is(e, cache.ErrCacheMiss) {
+			return v, e
+		}
+	}
+
+	if v, err = loader(ctx, key); err != nil {
+		return
+	}
+	if e := c.set(ctx, rKey, v, opt); e != nil {
+		logger.WithContext(ctx).Warnf("unable to cache loaded entry [%s]: %v", rKey, e)
+	}
+	return v, nil
+}
+
+func (c *RedisCache[K, V]) get(ctx context.Context, rKey string) (v V, err error) {
+	data, e := c.client.Get(ctx, rKey).Bytes()
+	switch {
+	case errors.Is(e, goredis.Nil):
+		return v, cache.ErrCacheMiss
+	case e != nil:
+		return v, e
+	}
+	if e := c.Serializer.Deserialize(data, &v); e != nil {
+		return v, fmt.Errorf("unable to deserialize cache entry [%s]: %w", rKey, e)
+	}
+	return v, nil
+}
+
+func (c *RedisCache[K, V]) set(ctx context.Context, rKey string, value V, opt cache.EntryOption) error {
+	data, e := c.Serializer.Serialize(value)
+	if e != nil {
+		return fmt.Errorf("unable to serialize cache entry [%s]: %w", rKey, e)
+	}
+	return c.client.Set(ctx, rKey, data, opt.TTL).Err()
+}
+
+func (c *RedisCache[K, V]) redisKey(key K) string {
+	return c.name + ":" + c.KeyFormatter(key)
+}
+
+func (c *RedisCache[K, V]) redisKeys(keys ...K) []string {
+	rKeys := make([]string, len(keys))
+	for i, k := range keys {
+		rKeys[i] = c.redisKey(k)
+	}
+	return rKeys
+}
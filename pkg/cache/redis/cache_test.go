@@ -0,0 +1,334 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rediscache_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/cache"
+	rediscache "github.com/cisco-open/go-lanai/pkg/cache/redis"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	redisdsync "github.com/cisco-open/go-lanai/pkg/dsync/redis"
+	"github.com/cisco-open/go-lanai/pkg/redis"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/embedded"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*************************
+	Test Setup
+ *************************/
+
+type TestValue struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type TestDI struct {
+	fx.In
+	Client      redis.Client
+	SyncManager dsync.SyncManager
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestRedisCache(t *testing.T) {
+	di := &TestDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		embedded.WithRedis(),
+		apptest.WithModules(redis.Module, redisdsync.Module),
+		apptest.WithDI(di),
+		test.GomegaSubTest(SubTestGetSetDelete(di), "TestGetSetDelete"),
+		test.GomegaSubTest(SubTestEntryTTL(di), "TestEntryTTL"),
+		test.GomegaSubTest(SubTestGetOrLoadWithLock(di), "TestGetOrLoadWithLock"),
+		test.GomegaSubTest(SubTestGetOrLoadCancelled(di), "TestGetOrLoadCancelled"),
+		test.GomegaSubTest(SubTestGetOrLoadTimeout(di), "TestGetOrLoadTimeout"),
+		test.GomegaSubTest(SubTestGetOrLoadPanic(di), "TestGetOrLoadPanic"),
+		test.GomegaSubTest(SubTestGetOrLoadTypeMismatch(di), "TestGetOrLoadTypeMismatch"),
+		test.GomegaSubTest(SubTestNearCacheInvalidation(di), "TestNearCacheInvalidation"),
+		// Note: this test restarts the embedded Redis server, keep it last
+		test.GomegaSubTest(SubTestNearCacheResubscribe(di), "TestNearCacheResubscribe"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestGetSetDelete(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		var c cache.Cache[int, TestValue] = rediscache.NewRedisCache[int, TestValue](di.Client, "test-get-set")
+		_, e := c.Get(ctx, 1)
+		g.Expect(e).To(MatchError(cache.ErrCacheMiss), "Get of non-existing key should return ErrCacheMiss")
+
+		expected := TestValue{Name: "one", Count: 1}
+		g.Expect(c.Set(ctx, 1, expected)).To(Succeed(), "Set should not fail")
+		v, e := c.Get(ctx, 1)
+		g.Expect(e).To(Succeed(), "Get should not fail")
+		g.Expect(v).To(Equal(expected), "Get should return value of Set")
+
+		raw, e := di.Client.Get(ctx, "test-get-set:1").Result()
+		g.Expect(e).To(Succeed(), "entry should be stored with namespaced key")
+		g.Expect(raw).To(MatchJSON(`{"name":"one","count":1}`), "entry should be serialized as JSON by default")
+
+		g.Expect(c.Delete(ctx, 1, 2)).To(Succeed(), "Delete should not fail")
+		_, e = c.Get(ctx, 1)
+		g.Expect(e).To(MatchError(cache.ErrCacheMiss), "Get of deleted key should return ErrCacheMiss")
+	}
+}
+
+func SubTestEntryTTL(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := rediscache.NewRedisCache[string, TestValue](di.Client, "test-ttl", func(opt *rediscache.RedisCacheOption) {
+			opt.DefaultTTL = time.Hour
+			opt.Serializer = cache.GobSerializer{}
+		})
+		g.Expect(c.Set(ctx, "short", TestValue{Name: "short"}, cache.WithTTL(time.Minute))).To(Succeed(), "Set with TTL should not fail")
+		g.Expect(c.Set(ctx, "long", TestValue{Name: "long"})).To(Succeed(), "Set with default TTL should not fail")
+		g.Expect(di.Client.TTL(ctx, "test-ttl:short").Val()).To(Equal(time.Minute), "entry should have its own TTL")
+		g.Expect(di.Client.TTL(ctx, "test-ttl:long").Val()).To(Equal(time.Hour), "entry should have default TTL")
+
+		embedded.CurrentRedisServer(ctx).FastForward(2 * time.Minute)
+		_, e := c.Get(ctx, "short")
+		g.Expect(e).To(MatchError(cache.ErrCacheMiss), "entry should expire after its own TTL")
+		v, e := c.Get(ctx, "long")
+		g.Expect(e).To(Succeed(), "entry with default TTL should not expire yet")
+		g.Expect(v.Name).To(Equal("long"), "entry with default TTL should have correct value")
+	}
+}
+
+func SubTestGetOrLoadWithLock(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		// two caches of same name simulate two replicas
+		replicas := make([]*rediscache.RedisCache[string, TestValue], 2)
+		for i := range replicas {
+			replicas[i] = rediscache.NewRedisCache[string, TestValue](di.Client, "test-load", func(opt *rediscache.RedisCacheOption) {
+				opt.SyncManager = di.SyncManager
+			})
+		}
+		var count int64
+		loader := func(ctx context.Context, key string) (TestValue, error) {
+			n := atomic.AddInt64(&count, 1)
+			time.Sleep(50 * time.Millisecond)
+			return TestValue{Name: key, Count: int(n)}, nil
+		}
+
+		var wg sync.WaitGroup
+		results := make([]TestValue, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = replicas[i%len(replicas)].GetOrLoad(ctx, "key", loader)
+			}(i)
+		}
+		wg.Wait()
+		g.Expect(atomic.LoadInt64(&count)).To(BeEquivalentTo(1), "concurrent GetOrLoad across replicas should load only once")
+		for _, v := range results {
+			g.Expect(v).To(Equal(TestValue{Name: "key", Count: 1}), "all GetOrLoad should return loaded value")
+		}
+	}
+}
+
+func SubTestGetOrLoadCancelled(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := rediscache.NewRedisCache[string, TestValue](di.Client, "test-load-cancel")
+		var count int64
+		started := make(chan struct{})
+		release := make(chan struct{})
+		loader := func(ctx context.Context, key string) (TestValue, error) {
+			if atomic.AddInt64(&count, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return TestValue{Name: key}, ctx.Err()
+		}
+
+		// first caller gives up while loading
+		cancelCtx, cancelFn := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			_, e := c.GetOrLoad(cancelCtx, "key", loader)
+			done <- e
+		}()
+		<-started
+		waiter := make(chan TestValue, 1)
+		go func() {
+			v, _ := c.GetOrLoad(ctx, "key", loader)
+			waiter <- v
+		}()
+		cancelFn()
+		g.Expect(<-done).To(MatchError(context.Canceled), "cancelled caller should stop waiting")
+
+		// other callers are not affected
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		var v TestValue
+		g.Eventually(waiter).WithTimeout(time.Second).Should(Receive(&v), "other caller should get loaded value")
+		g.Expect(v.Name).To(Equal("key"), "loading should not be cancelled by the first caller")
+		g.Expect(atomic.LoadInt64(&count)).To(BeEquivalentTo(1), "other caller should share the in-progress loading")
+		v, e := c.Get(ctx, "key")
+		g.Expect(e).To(Succeed(), "loaded value should be cached")
+		g.Expect(v.Name).To(Equal("key"), "cached value should be correct")
+	}
+}
+
+func SubTestGetOrLoadTimeout(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := rediscache.NewRedisCache[string, TestValue](di.Client, "test-load-timeout", func(opt *rediscache.RedisCacheOption) {
+			opt.SyncManager = di.SyncManager
+			opt.LoadTimeout = 100 * time.Millisecond
+		})
+		// stuck loader is bounded by LoadTimeout, even if the caller has no deadline
+		_, e := c.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (TestValue, error) {
+			<-ctx.Done()
+			return TestValue{}, ctx.Err()
+		})
+		g.Expect(e).To(MatchError(context.DeadlineExceeded), "stuck loading should time out")
+
+		v, e := c.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (TestValue, error) {
+			return TestValue{Name: key}, nil
+		})
+		g.Expect(e).To(Succeed(), "GetOrLoad after timeout should not fail")
+		g.Expect(v.Name).To(Equal("key"), "GetOrLoad after timeout should return loaded value")
+	}
+}
+
+func SubTestGetOrLoadPanic(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		c := rediscache.NewRedisCache[string, TestValue](di.Client, "test-load-panic")
+		_, e := c.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (TestValue, error) {
+			panic("oops")
+		})
+		g.Expect(e).To(HaveOccurred(), "panicking loader should result in error")
+		g.Expect(e.Error()).To(ContainSubstring("oops"), "error should contain panic value")
+
+		v, e := c.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (TestValue, error) {
+			return TestValue{Name: key}, nil
+		})
+		g.Expect(e).To(Succeed(), "GetOrLoad after panic should not fail")
+		g.Expect(v.Name).To(Equal("key"), "GetOrLoad after panic should return loaded value")
+	}
+}
+
+func SubTestGetOrLoadTypeMismatch(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		// misconfigured caches: same name with different value types
+		c1 := rediscache.NewRedisCache[string, TestValue](di.Client, "test-load-mismatch")
+		c2 := rediscache.NewRedisCache[string, string](di.Client, "test-load-mismatch")
+		started := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			_, e := c1.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (TestValue, error) {
+				close(started)
+				<-release
+				return TestValue{Name: key}, nil
+			})
+			done <- e
+		}()
+		<-started
+		mismatched := make(chan error, 1)
+		go func() {
+			_, e := c2.GetOrLoad(ctx, "key", func(ctx context.Context, key string) (string, error) {
+				return "", errors.New("loader of second cache should not be invoked")
+			})
+			mismatched <- e
+		}()
+		// give the second caller a chance to join the in-progress load
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		g.Expect(<-done).To(Succeed(), "GetOrLoad should not fail")
+		var e error
+		g.Eventually(mismatched).WithTimeout(time.Second).Should(Receive(&e), "second GetOrLoad should return")
+		g.Expect(e).To(HaveOccurred(), "GetOrLoad of mismatched value type should fail")
+		g.Expect(e.Error()).To(ContainSubstring("is expected"), "error should report type mismatch")
+	}
+}
+
+func SubTestNearCacheInvalidation(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		// two near-caches of same name simulate two replicas
+		replicas := make([]*rediscache.NearCache[string, TestValue], 2)
+		for i := range replicas {
+			remote := rediscache.NewRedisCache[string, TestValue](di.Client, "test-near")
+			var e error
+			replicas[i], e = rediscache.NewNearCache(ctx, remote)
+			g.Expect(e).To(Succeed(), "creating near-cache should not fail")
+			defer func(c *rediscache.NearCache[string, TestValue]) { _ = c.Close() }(replicas[i])
+		}
+
+		g.Expect(replicas[0].Set(ctx, "key", TestValue{Name: "v1"})).To(Succeed(), "Set should not fail")
+		v, e := replicas[1].Get(ctx, "key")
+		g.Expect(e).To(Succeed(), "Get from other replica should not fail")
+		g.Expect(v.Name).To(Equal("v1"), "Get from other replica should return value of Set")
+
+		// changes bypassing near-cache are not visible while local entry is valid
+		g.Expect(di.Client.Set(ctx, "test-near:key", `{"name":"bypassed"}`, 0).Err()).To(Succeed(), "direct Set should not fail")
+		v, _ = replicas[1].Get(ctx, "key")
+		g.Expect(v.Name).To(Equal("v1"), "Get should return local entry")
+
+		// changes through near-cache invalidates local entries of all replicas
+		g.Expect(replicas[0].Set(ctx, "key", TestValue{Name: "v2"})).To(Succeed(), "Set should not fail")
+		g.Eventually(func() string {
+			v, _ := replicas[1].Get(ctx, "key")
+			return v.Name
+		}).WithTimeout(time.Second).Should(Equal("v2"), "local entry of other replica should be invalidated")
+
+		g.Expect(replicas[0].Delete(ctx, "key")).To(Succeed(), "Delete should not fail")
+		g.Eventually(func() error {
+			_, e := replicas[1].Get(ctx, "key")
+			return e
+		}).WithTimeout(time.Second).Should(MatchError(cache.ErrCacheMiss), "deleted entry should be invalidated in other replica")
+	}
+}
+
+func SubTestNearCacheResubscribe(di *TestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		remote := rediscache.NewRedisCache[string, TestValue](di.Client, "test-near-resubscribe")
+		c, e := rediscache.NewNearCache(ctx, remote)
+		g.Expect(e).To(Succeed(), "creating near-cache should not fail")
+		defer func() { _ = c.Close() }()
+
+		g.Expect(c.Set(ctx, "key", TestValue{Name: "v1"})).To(Succeed(), "Set should not fail")
+		v, _ := c.Get(ctx, "key")
+		g.Expect(v.Name).To(Equal("v1"), "Get should return value of Set")
+
+		// changes during connection failures are missed, local entries should be flushed after re-subscribed
+		srv := embedded.CurrentRedisServer(ctx)
+		srv.Close()
+		g.Eventually(srv.Restart).WithTimeout(5*time.Second).Should(Succeed(), "restarting Redis should not fail")
+		g.Eventually(func() error {
+			// pooled connections are broken by the restart
+			return di.Client.Set(ctx, "test-near-resubscribe:key", `{"name":"missed"}`, 0).Err()
+		}).WithTimeout(5*time.Second).Should(Succeed(), "direct Set should not fail")
+		g.Eventually(func() string {
+			v, _ := c.Get(ctx, "key")
+			return v.Name
+		}).WithTimeout(10*time.Second).WithPolling(100*time.Millisecond).Should(Equal("missed"), "local entries should be flushed after re-subscribed")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cisco-open/go-lanai/pkg/cache"
+	goredis "github.com/go-redis/redis/v8"
+	"time"
+)
+
+type NearCacheOptions func(opt *NearCacheOption)
+type NearCacheOption struct {
+	// LocalTTL is the max TTL of local entries. It bounds staleness of local entries in case invalidation messages are missed.
+	// Entries with shorter TTL keep their own TTL locally.
+	LocalTTL time.Duration
+	// Channel is the Redis pub/sub channel of invalidation messages. "<name>:invalidation" by default
+	Channel string
+	// Heartbeat is the interval of evicting expired local entries
+	Heartbeat time.Duration
+}
+
+// NearCache implements cache.Cache with a local cache.MemCache in front of RedisCache.
+// Local entries are kept coherent across replicas by invalidation messages via Redis pub/sub:
+// whenever an entry is changed or deleted through any NearCache of the same channel, all local copies of the entry are removed.
+// Invalidation messages published while the subscription is reconnecting are lost, so all local entries are removed
+// once the subscription is re-established.
+//
+// Note: NearCache keeps a subscription to Redis until Close is called.
+type NearCache[K comparable, V any] struct {
+	NearCacheOption
+	remote *RedisCache[K, V]
+	local  *cache.MemCache[string, V]
+	pubsub *goredis.PubSub
+}
+
+// NewNearCache creates a NearCache in front of given RedisCache and subscribes invalidation messages.
+// Given context is used for the subscription and for logging of the listener
+func NewNearCache[K comparable, V any](ctx context.Context, remote *RedisCache[K, V], opts ...NearCacheOptions) (*NearCache[K, V], error) {
+	opt := NearCacheOption{
+		LocalTTL:  time.Minute,
+		Channel:   remote.name + ":invalidation",
+		Heartbeat: 10 * time.Minute,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+
+	c := &NearCache[K, V]{
+		NearCacheOption: opt,
+		remote:          remote,
+		local: cache.NewMemCache[string, V](func(mOpt *cache.MemCacheOption) {
+			mOpt.Heartbeat = opt.Heartbeat
+		}),
+	}
+	c.pubsub = remote.client.Subscribe(ctx, opt.Channel)
+	// wait for subscription confirmation, so invalidation messages are not missed after this function returns
+	if _, e := c.pubsub.Receive(ctx); e != nil {
+		_ = c.pubsub.Close()
+		return nil, e
+	}
+	go c.listen(ctx, c.pubsub.ChannelWithSubscriptions(ctx, 100))
+	return c, nil
+}
+
+func (c *NearCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	return c.local.GetOrLoad(ctx, c.remote.redisKey(key), func(ctx context.Context, _ string) (V, error) {
+		return c.remote.Get(ctx, key)
+	}, c.localTTL())
+}
+
+func (c *NearCache[K, V]) Set(ctx context.Context, key K, value V, opts ...cache.EntryOptions) error {
+	if e := c.remote.Set(ctx, key, value, opts...); e != nil {
+		return e
+	}
+	return c.invalidate(ctx, key)
+}
+
+func (c *NearCache[K, V]) Delete(ctx context.Context, keys ...K) error {
+	if e := c.remote.Delete(ctx, keys...); e != nil {
+		return e
+	}
+	return c.invalidate(ctx, keys...)
+}
+
+func (c *NearCache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.LoadFunc[K, V], opts ...cache.EntryOptions) (V, error) {
+	return c.local.GetOrLoad(ctx, c.remote.redisKey(key), func(ctx context.Context, _ string) (V, error) {
+		return c.remote.GetOrLoad(ctx, key, loader, opts...)
+	}, c.localTTL(opts...))
+}
+
+// Close stops listening to invalidation messages. The NearCache should not be used after closed.
+func (c *NearCache[K, V]) Close() error {
+	return c.pubsub.Close()
+}
+
+// invalidate removes local entries of given keys and publishes invalidation message to other replicas
+func (c *NearCache[K, V]) invalidate(ctx context.Context, keys ...K) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	rKeys := c.remote.redisKeys(keys...)
+	_ = c.local.Delete(ctx, rKeys...)
+	msg, e := json.Marshal(rKeys)
+	if e != nil {
+		return e
+	}
+	return c.remote.client.Publish(ctx, c.Channel, msg).Err()
+}
+
+// listen removes local entries according to invalidation messages. Any subscription confirmation received here
+// means the subscription is re-established after connection failures, in which case all local entries are removed.
+func (c *NearCache[K, V]) listen(ctx context.Context, ch <-chan interface{}) {
+	for msg := range ch {
+		switch m := msg.(type) {
+		case *goredis.Subscription:
+			if m.Kind != "subscribe" {
+				continue
+			}
+			logger.WithContext(ctx).Infof("re-subscribed to cache invalidation channel [%s], local entries are flushed", m.Channel)
+			c.local.Reset(ctx)
+		case *goredis.Message:
+			var rKeys []string
+			if e := json.Unmarshal([]byte(m.Payload), &rKeys); e != nil {
+				logger.WithContext(ctx).Warnf("ignored invalid cache invalidation message on channel [%s]: %v", m.Channel, e)
+				continue
+			}
+			_ = c.local.Delete(ctx, rKeys...)
+		}
+	}
+}
+
+// localTTL returns the entry TTL of local cache, capped by LocalTTL
+func (c *NearCache[K, V]) localTTL(opts ...cache.EntryOptions) cache.EntryOptions {
+	ttl := cache.ResolveEntryOption(c.remote.DefaultTTL, opts...).TTL
+	if c.LocalTTL > 0 && (ttl <= 0 || ttl > c.LocalTTL) {
+		ttl = c.LocalTTL
+	}
+	return cache.WithTTL(ttl)
+}
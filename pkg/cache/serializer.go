@@ -0,0 +1,56 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Serializer converts cached values from/to bytes, used by caches that store values out of process
+type Serializer interface {
+	Serialize(v interface{}) ([]byte, error)
+	// Deserialize populates given pointer "v" with data
+	Deserialize(data []byte, v interface{}) error
+}
+
+// JsonSerializer implements Serializer using JSON encoding. This is the default Serializer
+type JsonSerializer struct{}
+
+func (s JsonSerializer) Serialize(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (s JsonSerializer) Deserialize(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobSerializer implements Serializer using encoding/gob. Interface typed values need to be registered via gob.Register
+type GobSerializer struct{}
+
+func (s GobSerializer) Serialize(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if e := gob.NewEncoder(&buf).Encode(v); e != nil {
+		return nil, e
+	}
+	return buf.Bytes(), nil
+}
+
+func (s GobSerializer) Deserialize(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,304 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package data
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"net"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultDatasource is the name of the datasource configured by "data.db"
+	DefaultDatasource = "default"
+
+	gormPluginDatasource   = gormCallbackPrefix + "datasource"
+	gormPluginReplicas     = gormCallbackPrefix + "replicas"
+	gormSettingPrimary     = gormCallbackPrefix + "use_primary"
+	gormSettingReplica     = gormCallbackPrefix + "use_replica"
+	gormSettingRows        = "rows"
+	gormInstanceKeyReplica = gormCallbackPrefix + "replica"
+
+	// ReplicaRetryInterval is how long an unavailable replica is excluded from routing
+	ReplicaRetryInterval = 30 * time.Second
+)
+
+// DialectorFactory creates gorm.Dialector of given DatabaseProperties.
+// It's used for read replicas and named datasources, and is typically provided by database specific packages
+// such as "postgresql".
+type DialectorFactory func(props DatabaseProperties) gorm.Dialector
+
+type ckUsePrimary struct{}
+
+// WithPrimary returns a context that pins any reads executed with it to the primary database, bypassing read replicas.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ckUsePrimary{}, true)
+}
+
+// UsePrimary is used as a scope for gorm.DB to pin reads to the primary database, bypassing read replicas.
+// e.g. db.WithContext(ctx).Scopes(UsePrimary()).Find(...)
+func UsePrimary() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(gormSettingPrimary, true)
+	}
+}
+
+// UseReplica is used as a scope for gorm.DB to allow reads being routed to read replicas.
+// Reads without this scope always go to the primary database. repo.CrudRepository applies this scope to reads
+// executed outside of transactions.
+// e.g. db.WithContext(ctx).Scopes(UseReplica()).Find(...)
+func UseReplica() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(gormSettingReplica, true)
+	}
+}
+
+// DatasourceName returns name of the datasource the given *gorm.DB belongs to.
+// DefaultDatasource is returned if the *gorm.DB is not created by NewGorm with a name.
+func DatasourceName(db *gorm.DB) string {
+	if db == nil || db.Config == nil {
+		return DefaultDatasource
+	}
+	if p, ok := db.Config.Plugins[gormPluginDatasource].(*datasourcePlugin); ok {
+		return p.name
+	}
+	return DefaultDatasource
+}
+
+// Replicas returns read replicas of the given *gorm.DB, if any.
+func Replicas(db *gorm.DB) []*gorm.DB {
+	if db == nil || db.Config == nil {
+		return nil
+	}
+	if p, ok := db.Config.Plugins[gormPluginReplicas].(*replicaPlugin); ok {
+		return p.replicas
+	}
+	return nil
+}
+
+/**************************
+	Datasources
+ **************************/
+
+// Datasources holds all configured datasources, including the default one
+type Datasources struct {
+	datasources map[string]*gorm.DB
+}
+
+func NewDatasources(defaultDB *gorm.DB, named map[string]*gorm.DB) *Datasources {
+	ds := Datasources{
+		datasources: map[string]*gorm.DB{},
+	}
+	for k, v := range named {
+		ds.datasources[k] = v
+	}
+	if defaultDB != nil {
+		ds.datasources[DefaultDatasource] = defaultDB
+	}
+	return &ds
+}
+
+// DB returns *gorm.DB of the named datasource. Empty name is the same as DefaultDatasource
+func (ds Datasources) DB(name string) (*gorm.DB, error) {
+	if name == "" {
+		name = DefaultDatasource
+	}
+	db, ok := ds.datasources[name]
+	if !ok {
+		return nil, NewDataError(ErrorCodeInvalidApiUsage, fmt.Sprintf("datasource [%s] is not configured", name))
+	}
+	return db, nil
+}
+
+// MustDB is same as DB, but panic if the datasource is not configured
+func (ds Datasources) MustDB(name string) *gorm.DB {
+	db, e := ds.DB(name)
+	if e != nil {
+		panic(e)
+	}
+	return db
+}
+
+// Names returns sorted names of all datasources
+func (ds Datasources) Names() []string {
+	names := make([]string, 0, len(ds.datasources))
+	for k := range ds.datasources {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**************************
+	Plugins
+ **************************/
+
+// datasourcePlugin is a marker plugin carrying the datasource name
+type datasourcePlugin struct {
+	name string
+}
+
+func (p datasourcePlugin) Name() string {
+	return gormPluginDatasource
+}
+
+func (p datasourcePlugin) Initialize(_ *gorm.DB) error {
+	return nil
+}
+
+// replicaPlugin routes queries opted in via UseReplica to read replicas in round-robin fashion.
+// Queries are not routed when
+// - executed within a transaction
+// - pinned to primary via WithPrimary or UsePrimary
+// - with locking clause, e.g. "SELECT ... FOR UPDATE"
+// - all replicas are unavailable
+// When a routed query fails because the replica is unavailable, the query is re-executed on the primary,
+// and the replica is excluded from routing for ReplicaRetryInterval.
+type replicaPlugin struct {
+	replicas []*gorm.DB
+	counter  uint64
+	// downUntil are unix nano timestamps until which each replica is considered unavailable
+	downUntil []int64
+}
+
+func newReplicaPlugin(replicas []*gorm.DB) *replicaPlugin {
+	return &replicaPlugin{
+		replicas:  replicas,
+		downUntil: make([]int64, len(replicas)),
+	}
+}
+
+// routedStatement is stored in gorm.DB instance when the statement is routed to a replica
+type routedStatement struct {
+	index   int
+	primary gorm.ConnPool
+}
+
+func (p *replicaPlugin) Name() string {
+	return gormPluginReplicas
+}
+
+func (p *replicaPlugin) Initialize(db *gorm.DB) error {
+	if e := db.Callback().Query().Before("gorm:query").Register(gormPluginReplicas+":query", p.route); e != nil {
+		return e
+	}
+	if e := db.Callback().Query().After("gorm:query").Register(gormPluginReplicas+":query_fallback", p.fallbackQuery); e != nil {
+		return e
+	}
+	if e := db.Callback().Row().Before("gorm:row").Register(gormPluginReplicas+":row", p.route); e != nil {
+		return e
+	}
+	return db.Callback().Row().After("gorm:row").Register(gormPluginReplicas+":row_fallback", p.fallbackRow)
+}
+
+func (p *replicaPlugin) route(db *gorm.DB) {
+	if db.Error != nil || len(p.replicas) == 0 || !p.isRoutable(db) {
+		return
+	}
+	now := time.Now().UnixNano()
+	for range p.replicas {
+		i := int(atomic.AddUint64(&p.counter, 1) % uint64(len(p.replicas)))
+		if atomic.LoadInt64(&p.downUntil[i]) > now {
+			continue
+		}
+		db.InstanceSet(gormInstanceKeyReplica, &routedStatement{index: i, primary: db.Statement.ConnPool})
+		db.Statement.ConnPool = p.replicas[i].ConnPool
+		return
+	}
+}
+
+func (p *replicaPlugin) fallbackQuery(db *gorm.DB) {
+	if p.fallback(db) {
+		callbacks.Query(db)
+	}
+}
+
+// fallbackRow only re-execute "Rows()", because errors of "Row()" are deferred to sql.Row.Scan
+func (p *replicaPlugin) fallbackRow(db *gorm.DB) {
+	if p.fallback(db) {
+		db.Statement.Settings.Store(gormSettingRows, true)
+		callbacks.RowQuery(db)
+	}
+}
+
+// fallback switches the statement back to primary if the replica it's routed to is unavailable.
+// Returns true if the statement should be re-executed
+func (p *replicaPlugin) fallback(db *gorm.DB) bool {
+	routed, ok := routedStatementOf(db)
+	if !ok || !isConnectionError(db.Error) {
+		return false
+	}
+	logger.WithContext(db.Statement.Context).Warnf("read replica [%d] is unavailable, fallback to primary: %v", routed.index, db.Error)
+	atomic.StoreInt64(&p.downUntil[routed.index], time.Now().Add(ReplicaRetryInterval).UnixNano())
+	db.InstanceSet(gormInstanceKeyReplica, (*routedStatement)(nil))
+	db.Statement.ConnPool = routed.primary
+	db.Error = nil
+	return true
+}
+
+func (p *replicaPlugin) isRoutable(db *gorm.DB) bool {
+	if v, ok := db.Get(gormSettingReplica); !ok || v != true {
+		return false
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return false
+	}
+	if v, ok := db.Get(gormSettingPrimary); ok && v == true {
+		return false
+	}
+	if ctx := db.Statement.Context; ctx != nil && ctx.Value(ckUsePrimary{}) == true {
+		return false
+	}
+	if _, ok := db.Statement.Clauses[clause.Locking{}.Name()]; ok {
+		return false
+	}
+	return true
+}
+
+func routedStatementOf(db *gorm.DB) (*routedStatement, bool) {
+	v, ok := db.InstanceGet(gormInstanceKeyReplica)
+	if !ok {
+		return nil, false
+	}
+	routed, ok := v.(*routedStatement)
+	return routed, ok && routed != nil
+}
+
+// replicaIndex returns index of replica the statement is routed to
+func replicaIndex(db *gorm.DB) (int, bool) {
+	if routed, ok := routedStatementOf(db); ok {
+		return routed.index, true
+	}
+	return 0, false
+}
+
+// isConnectionError returns true if the error indicates the database is not reachable
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr)
+}
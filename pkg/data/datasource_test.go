@@ -0,0 +1,233 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package data_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+var errNoConnection = errors.New("no connection")
+
+// recordingConnPool implements gorm.ConnPool and gorm.ConnPoolBeginner, it records executed SQL without actual connection
+type recordingConnPool struct {
+	gorm.ConnPool
+	// err is returned by any executed SQL, errNoConnection if not set
+	err     error
+	queries []string
+	// deadlines are deadlines of the contexts of executed SQL, zero time if no deadline
+	deadlines []time.Time
+}
+
+func (p *recordingConnPool) record(ctx context.Context, query string) error {
+	p.queries = append(p.queries, query)
+	deadline, _ := ctx.Deadline()
+	p.deadlines = append(p.deadlines, deadline)
+	if p.err != nil {
+		return p.err
+	}
+	return errNoConnection
+}
+
+func (p *recordingConnPool) QueryContext(ctx context.Context, query string, _ ...interface{}) (*sql.Rows, error) {
+	return nil, p.record(ctx, query)
+}
+
+func (p *recordingConnPool) ExecContext(ctx context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	return nil, p.record(ctx, query)
+}
+
+func (p *recordingConnPool) QueryRowContext(ctx context.Context, query string, _ ...interface{}) *sql.Row {
+	_ = p.record(ctx, query)
+	return &sql.Row{}
+}
+
+func (p *recordingConnPool) BeginTx(_ context.Context, _ *sql.TxOptions) (gorm.ConnPool, error) {
+	return &recordingTx{recordingConnPool: p}, nil
+}
+
+// recordingTx implements gorm.TxCommitter
+type recordingTx struct {
+	*recordingConnPool
+}
+
+func (tx *recordingTx) Commit() error {
+	return nil
+}
+
+func (tx *recordingTx) Rollback() error {
+	return nil
+}
+
+type ReplicaModel struct {
+	ID    int
+	Value string
+}
+
+func newRoutingTestDB(name string, primary *recordingConnPool, replicas ...*recordingConnPool) *gorm.DB {
+	return data.NewGorm(func(cfg *data.GormConfig) {
+		cfg.Name = name
+		cfg.Dialector = postgres.New(postgres.Config{Conn: primary})
+		for _, r := range replicas {
+			cfg.ReplicaDialectors = append(cfg.ReplicaDialectors, postgres.New(postgres.Config{Conn: r}))
+		}
+	})
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestDatasources(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestReplicaRouting(), "TestReplicaRouting"),
+		test.GomegaSubTest(SubTestPinToPrimary(), "TestPinToPrimary"),
+		test.GomegaSubTest(SubTestReplicaFallback(), "TestReplicaFallback"),
+		test.GomegaSubTest(SubTestNamedDatasources(), "TestNamedDatasources"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestReplicaRouting() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		primary := &recordingConnPool{}
+		replicas := []*recordingConnPool{{}, {}}
+		db := newRoutingTestDB("", primary, replicas...)
+		g.Expect(data.Replicas(db)).To(HaveLen(2), "replicas should be available")
+
+		// routing is opt-in
+		var models []ReplicaModel
+		_ = db.WithContext(ctx).Find(&models)
+		g.Expect(primary.queries).To(HaveLen(1), "queries without UseReplica should go to primary")
+
+		for i := 0; i < 4; i++ {
+			_ = db.WithContext(ctx).Scopes(data.UseReplica()).Find(&models)
+		}
+		g.Expect(primary.queries).To(HaveLen(1), "queries with UseReplica should not go to primary")
+		g.Expect(replicas[0].queries).To(HaveLen(2), "queries should be routed to replicas in round-robin fashion")
+		g.Expect(replicas[1].queries).To(HaveLen(2), "queries should be routed to replicas in round-robin fashion")
+
+		// writes
+		primary.queries = nil
+		_ = db.WithContext(ctx).Scopes(data.UseReplica()).Exec(`UPDATE "replica_models" SET "value" = ?`, "test")
+		g.Expect(primary.queries).To(HaveLen(1), "writes should go to primary")
+		g.Expect(replicas[0].queries).To(HaveLen(2), "writes should not be routed to replicas")
+		g.Expect(replicas[1].queries).To(HaveLen(2), "writes should not be routed to replicas")
+	}
+}
+
+func SubTestPinToPrimary() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		primary := &recordingConnPool{}
+		replica := &recordingConnPool{}
+		db := newRoutingTestDB("", primary, replica)
+		var models []ReplicaModel
+
+		_ = db.WithContext(data.WithPrimary(ctx)).Scopes(data.UseReplica()).Find(&models)
+		g.Expect(primary.queries).To(HaveLen(1), "query with pinned context should go to primary")
+
+		_ = db.WithContext(ctx).Scopes(data.UseReplica(), data.UsePrimary()).Find(&models)
+		g.Expect(primary.queries).To(HaveLen(2), "query with UsePrimary scope should go to primary")
+
+		_ = db.WithContext(ctx).Scopes(data.UseReplica()).Clauses(clause.Locking{Strength: "UPDATE"}).Find(&models)
+		g.Expect(primary.queries).To(HaveLen(3), "query with locking clause should go to primary")
+
+		_ = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.Scopes(data.UseReplica()).Find(&models).Error
+		})
+		g.Expect(primary.queries).To(HaveLen(4), "query within transaction should go to primary")
+		g.Expect(replica.queries).To(BeEmpty(), "no query should be routed to replica")
+	}
+}
+
+func SubTestReplicaFallback() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		primary := &recordingConnPool{}
+		replicas := []*recordingConnPool{{err: driver.ErrBadConn}, {}}
+		db := newRoutingTestDB("", primary, replicas...)
+		var models []ReplicaModel
+
+		// round-robin starts from the second replica
+		_ = db.WithContext(ctx).Scopes(data.UseReplica()).Find(&models)
+		g.Expect(replicas[1].queries).To(HaveLen(1), "query should be routed to available replica")
+		g.Expect(primary.queries).To(BeEmpty(), "query should not go to primary")
+
+		e := db.WithContext(ctx).Scopes(data.UseReplica()).Find(&models).Error
+		g.Expect(replicas[0].queries).To(HaveLen(1), "query should be routed to unavailable replica")
+		g.Expect(primary.queries).To(HaveLen(1), "query should be re-executed on primary when replica is unavailable")
+		g.Expect(errors.Is(e, driver.ErrBadConn)).To(BeFalse(), "error of unavailable replica should not be returned")
+
+		rows, _ := db.WithContext(ctx).Scopes(data.UseReplica()).Model(&ReplicaModel{}).Rows()
+		g.Expect(rows).To(BeNil(), "rows should not be available without connection")
+		g.Expect(replicas[0].queries).To(HaveLen(1), "unavailable replica should be excluded from routing")
+		g.Expect(replicas[1].queries).To(HaveLen(2), "query should be routed to available replica")
+
+		_ = db.WithContext(ctx).Scopes(data.UseReplica()).Find(&models)
+		g.Expect(replicas[0].queries).To(HaveLen(1), "unavailable replica should be excluded from routing")
+		g.Expect(replicas[1].queries).To(HaveLen(3), "query should be routed to available replica")
+
+		// all replicas unavailable
+		replicas[1].err = driver.ErrBadConn
+		_, _ = db.WithContext(ctx).Scopes(data.UseReplica()).Model(&ReplicaModel{}).Rows()
+		g.Expect(replicas[1].queries).To(HaveLen(4), "query should be routed to available replica")
+		g.Expect(primary.queries).To(HaveLen(2), "rows query should be re-executed on primary when replica is unavailable")
+		_ = db.WithContext(ctx).Scopes(data.UseReplica()).Find(&models)
+		g.Expect(primary.queries).To(HaveLen(3), "query should go to primary when all replicas are unavailable")
+		g.Expect(replicas[0].queries).To(HaveLen(1), "unavailable replica should be excluded from routing")
+		g.Expect(replicas[1].queries).To(HaveLen(4), "unavailable replica should be excluded from routing")
+	}
+}
+
+func SubTestNamedDatasources() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		defaultDB := newRoutingTestDB("", &recordingConnPool{})
+		reportingDB := newRoutingTestDB("reporting", &recordingConnPool{})
+		g.Expect(data.DatasourceName(defaultDB)).To(Equal(data.DefaultDatasource), "default datasource name should be correct")
+		g.Expect(data.DatasourceName(reportingDB)).To(Equal("reporting"), "datasource name should be correct")
+		g.Expect(data.DatasourceName(reportingDB.Session(&gorm.Session{SkipHooks: true}))).
+			To(Equal("reporting"), "datasource name should be available in sessions")
+
+		ds := data.NewDatasources(defaultDB, map[string]*gorm.DB{"reporting": reportingDB})
+		g.Expect(ds.Names()).To(Equal([]string{data.DefaultDatasource, "reporting"}), "names should be correct")
+		db, e := ds.DB("")
+		g.Expect(e).To(Succeed(), "default datasource should be available")
+		g.Expect(db).To(BeIdenticalTo(defaultDB), "default datasource should be correct")
+		db, e = ds.DB("reporting")
+		g.Expect(e).To(Succeed(), "named datasource should be available")
+		g.Expect(db).To(BeIdenticalTo(reportingDB), "named datasource should be correct")
+		_, e = ds.DB("unknown")
+		g.Expect(e).To(HaveOccurred(), "unknown datasource should not be available")
+	}
+}
@@ -46,7 +46,8 @@ func (t WebDataErrorTranslator) Translate(ctx context.Context, err error) error
 	switch {
 	case errors.Is(err, ErrorRecordNotFound), errors.Is(err, ErrorIncorrectRecordCount):
 		return t.errorWithStatusCode(ctx, err, http.StatusNotFound)
-	case errors.Is(err, ErrorSubTypeDataIntegrity):
+	case errors.Is(err, ErrorSubTypeDataIntegrity), errors.Is(err, ErrorStaleFencingToken),
+		errors.Is(err, ErrorOptimisticLockFailure):
 		return t.errorWithStatusCode(ctx, err, http.StatusConflict)
 	case errors.Is(err, ErrorSubTypeQuery):
 		return t.errorWithStatusCode(ctx, err, http.StatusBadRequest)
@@ -36,6 +36,7 @@ func SubTestDataErrorTranslation() test.GomegaSubTestFunc {
 			"ErrorCodeInvalidSQL":          http.StatusBadRequest,
 			"ErrorCodeQueryTimeout":        http.StatusRequestTimeout,
 			"ErrorCodePessimisticLocking":  http.StatusServiceUnavailable,
+			"ErrorCodeOptimisticLocking":   http.StatusConflict,
 		}
 		for code, status := range expect {
 			req := webtest.NewRequest(ctx, http.MethodGet, "/translate", nil,
@@ -68,6 +69,7 @@ func NewTestTranslateDateErrorController() *TestTranslateDateErrorController {
 			"ErrorCodeInvalidSQL":          NewDataError(ErrorCodeInvalidSQL, "invalid sql"),
 			"ErrorCodeQueryTimeout":        NewDataError(ErrorCodeQueryTimeout, "query timeout"),
 			"ErrorCodePessimisticLocking":  NewDataError(ErrorCodePessimisticLocking, "pessimistic locking"),
+			"ErrorCodeOptimisticLocking":   NewDataError(ErrorCodeOptimisticLocking, "version mismatch"),
 		},
 	}
 }
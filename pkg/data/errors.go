@@ -122,6 +122,7 @@ const (
 	_                           = iota
 	ErrorCodePessimisticLocking = ErrorSubTypeCodeConcurrency + iota
 	ErrorCodeOptimisticLocking
+	ErrorCodeStaleFencingToken
 )
 
 // ErrorSubTypeCodeTimeout
@@ -165,6 +166,8 @@ var (
 	ErrorIncorrectRecordCount  = NewDataError(ErrorCodeIncorrectRecordCount, "incorrect record count")
 	ErrorDuplicateKey          = NewDataError(ErrorCodeDuplicateKey, "duplicate key")
 	ErrorInsufficientPrivilege = NewDataError(ErrorCodeInsufficientPrivilege, "insufficient privilege")
+	ErrorStaleFencingToken     = NewDataError(ErrorCodeStaleFencingToken, "stale fencing token")
+	ErrorOptimisticLockFailure = NewDataError(ErrorCodeOptimisticLocking, "optimistic lock failure")
 )
 
 func init() {
@@ -53,10 +53,18 @@ type GormConfigurer interface {
 
 type GormOptions func(cfg *GormConfig)
 type GormConfig struct {
-	Dialector             gorm.Dialector
+	// Name of the datasource, DefaultDatasource if not set
+	Name      string
+	Dialector gorm.Dialector
+	// ReplicaDialectors are dialectors of read replicas, queries are routed to replicas when available
+	ReplicaDialectors     []gorm.Dialector
 	LogLevel              log.LoggingLevel
 	LogSlowQueryThreshold time.Duration
 	Configurers           []GormConfigurer
+	// Pool is applied to connection pools of the database and its read replicas
+	Pool PoolProperties
+	// StatementTimeout is the default timeout of each SQL statement. No timeout if not set.
+	StatementTimeout time.Duration
 }
 
 func NewGorm(opts ...GormOptions) *gorm.DB {
@@ -88,9 +96,44 @@ func NewGorm(opts ...GormOptions) *gorm.DB {
 		c.Configure(&config)
 	}
 
+	// datasource and replicas
+	if config.Plugins == nil {
+		config.Plugins = map[string]gorm.Plugin{}
+	}
+	if len(cfg.Name) != 0 {
+		config.Plugins[gormPluginDatasource] = &datasourcePlugin{name: cfg.Name}
+	}
+	if len(cfg.ReplicaDialectors) != 0 {
+		config.Plugins[gormPluginReplicas] = newReplicaPlugin(openReplicas(&cfg, config.Logger))
+	}
+	config.Plugins[gormPluginStatementTimeout] = &statementTimeoutPlugin{timeout: cfg.StatementTimeout}
+
 	db, e := gorm.Open(cfg.Dialector, &config)
 	if e != nil {
 		panic(e)
 	}
+	if e := ConfigurePool(db, cfg.Pool); e != nil {
+		logger.Warnf("unable to configure connection pool: %v", e)
+	}
 	return db
 }
+
+// openReplicas opens read replicas. Replicas are only used as connection pools, so no plugins are installed.
+// Note: automatic ping is disabled, so unavailable replicas don't prevent the application from starting.
+func openReplicas(cfg *GormConfig, gormLogger gormlogger.Interface) []*gorm.DB {
+	replicas := make([]*gorm.DB, len(cfg.ReplicaDialectors))
+	for i, dialector := range cfg.ReplicaDialectors {
+		db, e := gorm.Open(dialector, &gorm.Config{
+			Logger:               gormLogger,
+			DisableAutomaticPing: true,
+		})
+		if e != nil {
+			panic(e)
+		}
+		if e := ConfigurePool(db, cfg.Pool); e != nil {
+			logger.Warnf("unable to configure connection pool of replica: %v", e)
+		}
+		replicas[i] = db
+	}
+	return replicas
+}
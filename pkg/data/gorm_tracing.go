@@ -111,6 +111,7 @@ func (p gormPlugin) makeBeforeCallback(opName string) gormCallbackFunc {
 		opts := []tracing.SpanOption{
 			tracing.SpanKind(ext.SpanKindRPCClientEnum),
 			tracing.SpanTag("table", table),
+			tracing.SpanTag("datasource", DatasourceName(db)),
 		}
 
 		db.Statement.Context = tracing.WithTracer(p.tracer).
@@ -129,6 +130,9 @@ func (p gormPlugin) makeAfterCallback(_ string) gormCallbackFunc {
 		} else {
 			op = op.WithOptions(tracing.SpanTag("rows", db.RowsAffected))
 		}
+		if i, ok := replicaIndex(db); ok {
+			op = op.WithOptions(tracing.SpanTag("replica", i))
+		}
 		db.Statement.Context = op.FinishAndRewind(ctx)
 	}
 }
@@ -18,30 +18,62 @@ package data
 
 import (
 	"context"
+	"fmt"
 	"github.com/cisco-open/go-lanai/pkg/actuator/health"
 	"gorm.io/gorm"
 )
 
-// DbHealthIndicator
-// Note: we currently only support one database
+// DbHealthIndicator reports health of a datasource. When the datasource has read replicas,
+// each replica is reported as a component, and the datasource is DEGRADED if any replica is down.
+// Connection pool statistics are reported as details.
 type DbHealthIndicator struct {
 	db *gorm.DB
 }
 
+func NewDbHealthIndicator(db *gorm.DB) *DbHealthIndicator {
+	return &DbHealthIndicator{db: db}
+}
+
 func (i *DbHealthIndicator) Name() string {
+	if name := DatasourceName(i.db); name != DefaultDatasource {
+		return "database-" + name
+	}
 	return "database"
 }
 
 func (i *DbHealthIndicator) Health(c context.Context, options health.Options) health.Health {
-	if sqldb, e := i.db.DB(); e != nil {
+	primary := ping(c, i.db, options)
+	replicas := Replicas(i.db)
+	if len(replicas) == 0 {
+		return primary
+	}
+
+	components := map[string]health.Health{"primary": primary}
+	status := primary.Status()
+	for idx, replica := range replicas {
+		h := ping(c, replica, options)
+		components[fmt.Sprintf("replica-%d", idx)] = h
+		if status == health.StatusUp && h.Status() != health.StatusUp {
+			status = health.StatusDegraded
+		}
+	}
+	return health.NewCompositeHealth(status, "", components)
+}
+
+func ping(ctx context.Context, db *gorm.DB, options health.Options) health.Health {
+	if sqldb, e := db.DB(); e != nil {
 		return health.NewDetailedHealth(health.StatusUnknown, "database ping is not available", nil)
 	} else {
-		if e := sqldb.Ping(); e != nil {
-			return health.NewDetailedHealth(health.StatusDown, "database ping failed", nil)
+		var details map[string]interface{}
+		if options.ShowDetails {
+			details = map[string]interface{}{
+				"pool": poolDetails(sqldb.Stats()),
+			}
+		}
+		if e := sqldb.PingContext(ctx); e != nil {
+			return health.NewDetailedHealth(health.StatusDown, "database ping failed", details)
 		} else {
-			return health.NewDetailedHealth(health.StatusUp, "database ping succeeded", nil)
+			return health.NewDetailedHealth(health.StatusUp, "database ping succeeded", details)
 		}
 	}
 }
-
-
@@ -21,6 +21,7 @@ import (
 	"github.com/cisco-open/go-lanai/pkg/data"
 	"github.com/cisco-open/go-lanai/pkg/data/repo"
 	"github.com/cisco-open/go-lanai/pkg/data/tx"
+	"github.com/cisco-open/go-lanai/pkg/data/types"
 	"github.com/cisco-open/go-lanai/pkg/web"
 	"go.uber.org/fx"
 	"reflect"
@@ -34,6 +35,10 @@ var Module = &bootstrap.Module{
 	Options: []fx.Option{
 		fx.Provide(
 			transactionMaxRetry(),
+			transactionRetryOptimisticLock(),
+			auditGormConfigurer(),
+			historyGormConfigurer(),
+			versionGormConfigurer(),
 		),
 		web.FxErrorTranslatorProviders(
 			webErrTranslatorProvider(data.NewWebDataErrorTranslator),
@@ -69,6 +74,45 @@ func transactionMaxRetry() fx.Annotated {
 	}
 }
 
+func transactionRetryOptimisticLock() fx.Annotated {
+	return fx.Annotated{
+		Group: tx.FxTransactionExecuterOption,
+		Target: func(properties data.DataProperties) tx.TransactionExecuterOption {
+			return tx.RetryOnOptimisticLockFailure(properties.Transaction.RetryOptimisticLock)
+		},
+	}
+}
+
+type auditDI struct {
+	fx.In
+	Resolver types.AuditorResolver `optional:"true"`
+}
+
+func auditGormConfigurer() fx.Annotated {
+	return fx.Annotated{
+		Group: data.GormConfigurerGroup,
+		Target: func(di auditDI) data.GormConfigurer {
+			return types.NewAuditGormConfigurer(di.Resolver)
+		},
+	}
+}
+
+func historyGormConfigurer() fx.Annotated {
+	return fx.Annotated{
+		Group: data.GormConfigurerGroup,
+		Target: func(di auditDI) data.GormConfigurer {
+			return types.NewHistoryGormConfigurer(di.Resolver)
+		},
+	}
+}
+
+func versionGormConfigurer() fx.Annotated {
+	return fx.Annotated{
+		Group:  data.GormConfigurerGroup,
+		Target: types.NewVersionGormConfigurer,
+	}
+}
+
 /**************************
 	Initialize
 ***************************/
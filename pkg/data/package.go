@@ -17,6 +17,7 @@
 package data
 
 import (
+	"fmt"
 	"github.com/cisco-open/go-lanai/pkg/actuator/health"
 	"github.com/cisco-open/go-lanai/pkg/bootstrap"
 	"github.com/cisco-open/go-lanai/pkg/log"
@@ -35,6 +36,7 @@ var Module = &bootstrap.Module{
 		fx.Provide(
 			BindDataProperties,
 			provideGorm,
+			provideDatasources,
 			gormErrTranslatorProvider(),
 		),
 		fx.Invoke(registerHealth),
@@ -47,16 +49,64 @@ var Module = &bootstrap.Module{
 
 type gormInitDI struct {
 	fx.In
-	Dialector   gorm.Dialector
-	Properties  DataProperties
-	Configurers []GormConfigurer   `group:"gorm_config"`
-	Translators []ErrorTranslator  `group:"gorm_config"`
-	Tracer      opentracing.Tracer `optional:"true"`
+	Dialector        gorm.Dialector
+	DialectorFactory DialectorFactory `optional:"true"`
+	Properties       DataProperties
+	Configurers      []GormConfigurer   `group:"gorm_config"`
+	Translators      []ErrorTranslator  `group:"gorm_config"`
+	Tracer           opentracing.Tracer `optional:"true"`
 }
 
 func provideGorm(di gormInitDI) *gorm.DB {
-	return NewGorm(func(cfg *GormConfig) {
-		cfg.Dialector = di.Dialector
+	return NewGorm(gormOptions(di, di.Dialector, di.Properties.DB))
+}
+
+type datasourcesDI struct {
+	fx.In
+	DefaultDB        *gorm.DB
+	DialectorFactory DialectorFactory `optional:"true"`
+	Properties       DataProperties
+	Configurers      []GormConfigurer   `group:"gorm_config"`
+	Translators      []ErrorTranslator  `group:"gorm_config"`
+	Tracer           opentracing.Tracer `optional:"true"`
+}
+
+func provideDatasources(di datasourcesDI) *Datasources {
+	initDI := gormInitDI{
+		DialectorFactory: di.DialectorFactory,
+		Properties:       di.Properties,
+		Configurers:      di.Configurers,
+		Translators:      di.Translators,
+		Tracer:           di.Tracer,
+	}
+	named := map[string]*gorm.DB{}
+	for name, props := range di.Properties.Datasources {
+		if name == DefaultDatasource {
+			panic(fmt.Errorf(`datasource name "%s" is reserved`, DefaultDatasource))
+		}
+		if di.DialectorFactory == nil {
+			panic(fmt.Errorf("named datasource [%s] requires a DialectorFactory", name))
+		}
+		props = props.Inherit(di.Properties.DB)
+		named[name] = NewGorm(gormOptions(initDI, di.DialectorFactory(props), props), func(cfg *GormConfig) {
+			cfg.Name = name
+		})
+	}
+	return NewDatasources(di.DefaultDB, named)
+}
+
+func gormOptions(di gormInitDI, dialector gorm.Dialector, props DatabaseProperties) GormOptions {
+	return func(cfg *GormConfig) {
+		cfg.Dialector = dialector
+		for _, replica := range props.Replicas {
+			if di.DialectorFactory == nil {
+				logger.Warnf("read replicas are ignored, because DialectorFactory is not available")
+				break
+			}
+			cfg.ReplicaDialectors = append(cfg.ReplicaDialectors, di.DialectorFactory(replica.Inherit(props)))
+		}
+		cfg.Pool = props.Pool
+		cfg.StatementTimeout = time.Duration(props.StatementTimeout)
 		cfg.LogLevel = di.Properties.Logging.Level
 		cfg.Configurers = append(cfg.Configurers, NewGormErrorHandlingConfigurer(di.Translators...))
 		if di.Tracer != nil {
@@ -66,7 +116,7 @@ func provideGorm(di gormInitDI) *gorm.DB {
 		if di.Properties.Logging.SlowThreshold > 0 {
 			cfg.LogSlowQueryThreshold = time.Duration(di.Properties.Logging.SlowThreshold)
 		}
-	})
+	}
 }
 
 func gormErrTranslatorProvider() fx.Annotated {
@@ -85,14 +135,14 @@ func gormErrTranslatorProvider() fx.Annotated {
 type regDI struct {
 	fx.In
 	HealthRegistrar health.Registrar `optional:"true"`
-	GormDB          *gorm.DB         `optional:"true"`
+	Datasources     *Datasources     `optional:"true"`
 }
 
 func registerHealth(di regDI) {
-	if di.HealthRegistrar == nil || di.GormDB == nil {
+	if di.HealthRegistrar == nil || di.Datasources == nil {
 		return
 	}
-	di.HealthRegistrar.MustRegister(&DbHealthIndicator{
-		db: di.GormDB,
-	})
+	for _, name := range di.Datasources.Names() {
+		di.HealthRegistrar.MustRegister(NewDbHealthIndicator(di.Datasources.MustDB(name)))
+	}
 }
@@ -0,0 +1,60 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package data
+
+import (
+	"database/sql"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ConfigurePool applies given PoolProperties to the sql.DB of given *gorm.DB.
+// Unset (zero) values are not applied.
+func ConfigurePool(db *gorm.DB, props PoolProperties) error {
+	if props == (PoolProperties{}) {
+		return nil
+	}
+	sqldb, e := db.DB()
+	if e != nil {
+		return e
+	}
+	if props.MaxOpen > 0 {
+		sqldb.SetMaxOpenConns(props.MaxOpen)
+	}
+	if props.MaxIdle > 0 {
+		sqldb.SetMaxIdleConns(props.MaxIdle)
+	}
+	if props.MaxLifetime > 0 {
+		sqldb.SetConnMaxLifetime(time.Duration(props.MaxLifetime))
+	}
+	if props.MaxIdleTime > 0 {
+		sqldb.SetConnMaxIdleTime(time.Duration(props.MaxIdleTime))
+	}
+	return nil
+}
+
+// poolDetails converts sql.DBStats to health details
+func poolDetails(stats sql.DBStats) map[string]interface{} {
+	return map[string]interface{}{
+		"maxOpen":      stats.MaxOpenConnections,
+		"open":         stats.OpenConnections,
+		"inUse":        stats.InUse,
+		"idle":         stats.Idle,
+		"waitCount":    stats.WaitCount,
+		"waitDuration": stats.WaitDuration.String(),
+	}
+}
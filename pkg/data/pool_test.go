@@ -0,0 +1,120 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package data_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+func newTimeoutTestDB(timeout time.Duration, pool *recordingConnPool) *gorm.DB {
+	return data.NewGorm(func(cfg *data.GormConfig) {
+		cfg.Dialector = postgres.New(postgres.Config{Conn: pool})
+		cfg.StatementTimeout = timeout
+	})
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestConnectionPool(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestPoolConfiguration(), "TestPoolConfiguration"),
+		test.GomegaSubTest(SubTestDefaultStatementTimeout(), "TestDefaultStatementTimeout"),
+		test.GomegaSubTest(SubTestOverrideStatementTimeout(), "TestOverrideStatementTimeout"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestPoolConfiguration() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		db, e := gorm.Open(postgres.Open("host=localhost user=root dbname=test sslmode=disable"), &gorm.Config{
+			DisableAutomaticPing: true,
+		})
+		g.Expect(e).To(Succeed(), "DB should be created")
+		e = data.ConfigurePool(db, data.PoolProperties{
+			MaxOpen:     10,
+			MaxIdle:     5,
+			MaxLifetime: utils.Duration(time.Hour),
+		})
+		g.Expect(e).To(Succeed(), "pool should be configured")
+		sqldb, e := db.DB()
+		g.Expect(e).To(Succeed(), "sql.DB should be available")
+		g.Expect(sqldb.Stats().MaxOpenConnections).To(Equal(10), "max open connections should be configured")
+
+		// not applicable
+		db = newTimeoutTestDB(0, &recordingConnPool{})
+		g.Expect(data.ConfigurePool(db, data.PoolProperties{})).To(Succeed(), "empty pool properties should be ignored")
+		g.Expect(data.ConfigurePool(db, data.PoolProperties{MaxOpen: 10})).ToNot(Succeed(), "non sql.DB should fail")
+	}
+}
+
+func SubTestDefaultStatementTimeout() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		pool := &recordingConnPool{}
+		db := newTimeoutTestDB(time.Minute, pool)
+		var models []ReplicaModel
+		_ = db.WithContext(ctx).Find(&models)
+		_ = db.WithContext(ctx).Exec(`UPDATE "replica_models" SET "value" = ?`, "test")
+		g.Expect(pool.deadlines).To(HaveLen(2), "statements should be executed")
+		for _, deadline := range pool.deadlines {
+			g.Expect(time.Until(deadline)).To(BeNumerically("~", time.Minute, time.Second), "default timeout should be used")
+		}
+
+		pool = &recordingConnPool{}
+		db = newTimeoutTestDB(0, pool)
+		_ = db.WithContext(ctx).Find(&models)
+		g.Expect(pool.deadlines).To(HaveLen(1), "statements should be executed")
+		g.Expect(pool.deadlines[0].IsZero()).To(BeTrue(), "statement should not have deadline by default")
+	}
+}
+
+func SubTestOverrideStatementTimeout() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		pool := &recordingConnPool{}
+		db := newTimeoutTestDB(time.Minute, pool)
+		var models []ReplicaModel
+
+		_ = db.WithContext(ctx).Scopes(data.StatementTimeout(10 * time.Second)).Find(&models)
+		g.Expect(pool.deadlines).To(HaveLen(1), "statements should be executed")
+		g.Expect(time.Until(pool.deadlines[0])).To(BeNumerically("~", 10*time.Second, time.Second), "scope should override timeout")
+
+		_ = db.WithContext(data.WithStatementTimeout(ctx, 20*time.Second)).Find(&models)
+		g.Expect(pool.deadlines).To(HaveLen(2), "statements should be executed")
+		g.Expect(time.Until(pool.deadlines[1])).To(BeNumerically("~", 20*time.Second, time.Second), "context should override timeout")
+
+		_ = db.WithContext(data.WithStatementTimeout(ctx, 20*time.Second)).Scopes(data.StatementTimeout(-1)).Find(&models)
+		g.Expect(pool.deadlines).To(HaveLen(3), "statements should be executed")
+		g.Expect(pool.deadlines[2].IsZero()).To(BeTrue(), "timeout should be disabled")
+	}
+}
@@ -48,16 +48,27 @@ type initDI struct {
 }
 
 func NewGormDialetor(di initDI) gorm.Dialector {
+	return newGormDialector(di, di.Properties.DB)
+}
+
+// NewGormDialectorFactory returns a data.DialectorFactory, which is used for read replicas and named datasources
+func NewGormDialectorFactory(di initDI) data.DialectorFactory {
+	return func(props data.DatabaseProperties) gorm.Dialector {
+		return newGormDialector(di, props)
+	}
+}
+
+func newGormDialector(di initDI, props data.DatabaseProperties) gorm.Dialector {
 	//"host=localhost user=root password=root dbname=idm port=26257 sslmode=disable"
 	options := map[string]interface{}{
-		dsKeyHost:    di.Properties.DB.Host,
-		dsKeyPort:    di.Properties.DB.Port,
-		dsKeyDB:      di.Properties.DB.Database,
-		dsKeySslMode: di.Properties.DB.SslMode,
+		dsKeyHost:    props.Host,
+		dsKeyPort:    props.Port,
+		dsKeyDB:      props.Database,
+		dsKeySslMode: props.SslMode,
 	}
 	// Setup TLS properties
-	if di.Properties.DB.Tls.Enable && di.CertsManager != nil {
-		source, e := di.CertsManager.Source(di.AppContext, certs.WithSourceProperties(&di.Properties.DB.Tls.Certs))
+	if props.Tls.Enable && di.CertsManager != nil {
+		source, e := di.CertsManager.Source(di.AppContext, certs.WithSourceProperties(&props.Tls.Certs))
 		if e == nil {
 			certFiles, e := source.Files(di.AppContext)
 			if e == nil {
@@ -73,9 +84,9 @@ func NewGormDialetor(di initDI) gorm.Dialector {
 		}
 	}
 
-	if di.Properties.DB.Username != "" {
-		options[dsKeyUsername] = di.Properties.DB.Username
-		options[dsKeyPassword] = di.Properties.DB.Password
+	if props.Username != "" {
+		options[dsKeyUsername] = props.Username
+		options[dsKeyPassword] = props.Password
 	}
 
 	config := postgres.Config{
@@ -31,6 +31,7 @@ var Module = &bootstrap.Module{
 	Precedence: bootstrap.DatabasePrecedence,
 	Options: []fx.Option{
 		fx.Provide(NewGormDialetor,
+			NewGormDialectorFactory,
 			pqErrorTranslatorProvider(),
 			newAnnotatedGormDbCreator(),
 		),
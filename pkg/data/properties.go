@@ -33,10 +33,22 @@ type DataProperties struct {
 	Logging     LoggingProperties     `json:"logging"`
 	Transaction TransactionProperties `json:"transaction"`
 	DB          DatabaseProperties    `json:"db"`
+	Pagination  PaginationProperties  `json:"pagination"`
+	// Datasources are additional named datasources, e.g. "data.datasources.reporting".
+	// Unset properties of a named datasource are inherited from DB.
+	Datasources map[string]DatabaseProperties `json:"datasources"`
 }
 
 type TransactionProperties struct {
 	MaxRetry int `json:"max-retry"`
+	// RetryOptimisticLock enables retrying transactions failed with ErrorOptimisticLockFailure, limited by MaxRetry
+	RetryOptimisticLock bool `json:"retry-optimistic-lock"`
+}
+
+type PaginationProperties struct {
+	// CursorSecret is used to sign continuation tokens of keyset pagination. It should be same on all instances.
+	// When not set, a random secret is used, tokens are not valid across instances or restarts, and a warning is logged.
+	CursorSecret string `json:"cursor-secret"`
 }
 
 type LoggingProperties struct {
@@ -45,13 +57,57 @@ type LoggingProperties struct {
 }
 
 type DatabaseProperties struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Database string `json:"database"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	SslMode  string `json:"sslmode"`
-	Tls      TLS    `json:"tls"`
+	Host     string         `json:"host"`
+	Port     int            `json:"port"`
+	Database string         `json:"database"`
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	SslMode  string         `json:"sslmode"`
+	Tls      TLS            `json:"tls"`
+	Pool     PoolProperties `json:"pool"`
+	// StatementTimeout is the default timeout of each SQL statement. No timeout if not set.
+	StatementTimeout utils.Duration `json:"statement-timeout"`
+	// Replicas are read-only replicas of the database. Unset properties of a replica are inherited from its primary.
+	Replicas []DatabaseProperties `json:"replicas"`
+}
+
+// Inherit returns a copy of the properties, with unset values populated from given parent.
+// Replicas are not inherited.
+func (p DatabaseProperties) Inherit(parent DatabaseProperties) DatabaseProperties {
+	if p.Host == "" {
+		p.Host = parent.Host
+	}
+	if p.Port == 0 {
+		p.Port = parent.Port
+	}
+	if p.Database == "" {
+		p.Database = parent.Database
+	}
+	if p.Username == "" {
+		p.Username = parent.Username
+		p.Password = parent.Password
+	}
+	if p.SslMode == "" {
+		p.SslMode = parent.SslMode
+	}
+	if !p.Tls.Enable {
+		p.Tls = parent.Tls
+	}
+	if p.Pool == (PoolProperties{}) {
+		p.Pool = parent.Pool
+	}
+	if p.StatementTimeout == 0 {
+		p.StatementTimeout = parent.StatementTimeout
+	}
+	return p
+}
+
+// PoolProperties configures the connection pool of sql.DB. Unset (zero) values are left as sql.DB defaults.
+type PoolProperties struct {
+	MaxOpen     int            `json:"max-open"`
+	MaxIdle     int            `json:"max-idle"`
+	MaxLifetime utils.Duration `json:"max-lifetime"`
+	MaxIdleTime utils.Duration `json:"max-idle-time"`
 }
 
 type TLS struct {
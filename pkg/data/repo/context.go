@@ -19,6 +19,7 @@ package repo
 import (
 	"context"
 	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/data/types"
 	"reflect"
 )
 
@@ -36,7 +37,7 @@ type Factory interface {
 	// NewCRUD create an implementation specific CrudRepository.
 	// "model" represent the model this repository works on. It could be Struct or *Struct
 	// It panic if model is not a valid model definition
-	// accepted options depends on implementation. for gorm, *gorm.Session can be supplied
+	// accepted options depends on implementation. for gorm, *gorm.Session and Datasource can be supplied
 	NewCRUD(model interface{}, options...interface{}) CrudRepository
 }
 
@@ -179,6 +180,15 @@ type CrudRepository interface {
 	Truncate(ctx context.Context) error
 }
 
+// HistoryRepository is implemented by CrudRepository of models with types.History embedded.
+// It provides access to change history of entities, written by the history GORM plugin
+type HistoryRepository interface {
+	// FindHistory fetch change history of the entity with given primary key, ordered by creation time.
+	// Composite primary keys should be given as comma-separated string, in the order of primary fields.
+	// Supported options are Where, Page, Limit and func(*gorm.DB) *gorm.DB
+	FindHistory(ctx context.Context, id interface{}, options ...Option) ([]*types.HistoryRecord, error)
+}
+
 // Utility is a collection of repository related patterns that are useful for common service layer implementation
 type Utility interface{
 
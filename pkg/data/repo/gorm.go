@@ -19,6 +19,7 @@ package repo
 import (
     "context"
     "database/sql"
+    "fmt"
     "github.com/cisco-open/go-lanai/pkg/data"
     "github.com/cisco-open/go-lanai/pkg/data/tx"
     "gorm.io/gorm"
@@ -53,12 +54,19 @@ func (g gormApi) WithSession(config *gorm.Session) GormApi {
 }
 
 func (g gormApi) DB(ctx context.Context) *gorm.DB {
-	// tx support
+	// tx support, only if the transaction is of the same datasource
 	if t := tx.GormTxWithContext(ctx); t != nil {
+		if txDS, ds := data.DatasourceName(t), data.DatasourceName(g.db); txDS != ds {
+			db := g.db.WithContext(ctx)
+			_ = db.AddError(data.NewDataError(data.ErrorCodeInvalidTransaction, fmt.Sprintf(
+				"transaction of datasource [%s] cannot be used by repository of datasource [%s]", txDS, ds)))
+			return db
+		}
 		return t
 	}
 
-	return g.db.WithContext(ctx)
+	// reads outside of transaction can be routed to read replicas
+	return g.db.WithContext(ctx).Scopes(data.UseReplica())
 }
 
 func (g gormApi) Transaction(ctx context.Context, txFunc TxWithGormFunc, opts ...*sql.TxOptions) error {
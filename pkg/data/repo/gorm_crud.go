@@ -19,6 +19,8 @@ package repo
 import (
     "context"
     "fmt"
+    "github.com/cisco-open/go-lanai/pkg/data"
+    "github.com/cisco-open/go-lanai/pkg/data/types"
     "github.com/cisco-open/go-lanai/pkg/utils"
     "github.com/cisco-open/go-lanai/pkg/utils/order"
     "github.com/google/uuid"
@@ -169,7 +171,11 @@ func (g GormCrud) Save(ctx context.Context, v interface{}, options ...Option) er
 		return ErrorInvalidCrudParam.WithMessage(errTmplInvalidCrudValue, v, "Save", "*Struct or []*Struct or []Struct")
 	}
 
-	return execute(ctx, g.GormApi.DB(ctx), nil, options, nil, func(db *gorm.DB) *gorm.DB {
+	return execute(ctx, g.GormApi.DB(ctx), nil, []Option{options, optimisticLockCheck()}, nil, func(db *gorm.DB) *gorm.DB {
+		if g.isVersioned(ctx, v) {
+			// Note: without explicit Select, GORM fallbacks to upsert when no row is updated, which bypasses version check
+			db = db.Select("*")
+		}
 		return db.Save(v)
 	})
 }
@@ -190,7 +196,7 @@ func (g GormCrud) Update(ctx context.Context, model interface{}, v interface{},
 			WithMessage(errTmplInvalidCrudModel, v, "Update", "*Struct or Struct")
 	}
 
-	return execute(ctx, g.GormApi.DB(ctx), nil, options, modelFunc(model), func(db *gorm.DB) *gorm.DB {
+	return execute(ctx, g.GormApi.DB(ctx), nil, []Option{options, optimisticLockCheck()}, modelFunc(model), func(db *gorm.DB) *gorm.DB {
 		// note we use the actual model instead of template g.model
 		return db.Updates(v)
 	})
@@ -201,7 +207,7 @@ func (g GormCrud) Delete(ctx context.Context, v interface{}, options ...Option)
 		return ErrorInvalidCrudParam.WithMessage(errTmplInvalidCrudValue, v, "Delete", "*Struct, []Struct or []*Struct")
 	}
 
-	return execute(ctx, g.GormApi.DB(ctx), nil, options, modelFunc(g.model), func(db *gorm.DB) *gorm.DB {
+	return execute(ctx, g.GormApi.DB(ctx), nil, []Option{options, optimisticLockCheck()}, modelFunc(g.model), func(db *gorm.DB) *gorm.DB {
 		return db.Delete(v)
 	})
 }
@@ -236,6 +242,18 @@ func modelFunc(m interface{}) func(*gorm.DB) *gorm.DB {
 	}
 }
 
+// optimisticLockCheck returns data.ErrorOptimisticLockFailure if version check of types.Version is applied
+// but fewer rows than expected are affected
+func optimisticLockCheck() Option {
+	return postExecOptions(func(db *gorm.DB) *gorm.DB {
+		if expected, ok := types.ExpectedRowsWithVersionCheck(db); ok && db.Error == nil && db.RowsAffected < expected {
+			db.Error = data.ErrorOptimisticLockFailure.
+				WithMessage("%d of %d %s records are modified by others or not found", expected-db.RowsAffected, expected, db.Statement.Schema.Name)
+		}
+		return db
+	})
+}
+
 func execute(_ context.Context, db *gorm.DB, condition Condition, opts []Option, preOptsFn, fn func(*gorm.DB) *gorm.DB) error {
 	// make a copy of option array
 	options := make([]Option, len(opts), len(opts)+1)
@@ -405,6 +423,19 @@ func postExecOptsToDBFuncs(opts []Option) ([]func(*gorm.DB) *gorm.DB, error) {
 			switch opt := v.(type) {
 			case postExecOptions:
 				scopes = append(scopes, opt)
+			case priorityOption:
+				sub, e := postExecOptsToDBFuncs([]Option{opt.wrapped})
+				if e != nil {
+					return nil, e
+				}
+				scopes = append(scopes, sub...)
+			case delayedOption:
+				//SuppressWarnings go:S1871 we can use "opt.wrapped" here, but SONAR doesn't understand type switching
+				sub, e := postExecOptsToDBFuncs([]Option{opt.wrapped})
+				if e != nil {
+					return nil, e
+				}
+				scopes = append(scopes, sub...)
 			}
 		}
 	}
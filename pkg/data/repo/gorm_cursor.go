@@ -0,0 +1,385 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/utils/order"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+)
+
+const (
+	cursorSettingsKey = "lanai:repo:cursor"
+	cursorTokenSep    = "."
+)
+
+var cursorSigningKey = randomCursorSigningKey()
+
+// SetCursorSigningKey set the secret used to sign and verify continuation tokens of Cursor option.
+// By default, a random key is generated on startup, in which case tokens are not valid across instances or restarts.
+// This function is typically called during initialization with value of "data.pagination.cursor-secret"
+func SetCursorSigningKey(secret []byte) {
+	if len(secret) == 0 {
+		return
+	}
+	cursorSigningKey = secret
+}
+
+// Cursor is an Option specifying keyset (a.k.a. cursor) pagination when retrieve records from database.
+// Comparing to Page, rows are located with seek predicate "WHERE (sort keys) > (values of last row)" instead of OFFSET,
+// so the performance doesn't degrade with deeper pages and the result is stable under concurrent inserts.
+// token: 	opaque continuation token returned by previous page, empty string for the first page
+// size: 	page size (# of records per page)
+// next:	receives the continuation token of the next page, or empty string if there are no more records. Can be nil.
+//
+// The order is determined by Sort (with clause.OrderByColumn) or SortBy options, and primary key is appended
+// as tie-breaker if not already sorted by. Sort keys are expected to be non-nullable.
+// The token is signed (see SetCursorSigningKey) and only valid with the same table and sort keys.
+// e.g.
+//
+//	var next string
+//	CrudRepository.FindAll(ctx, &user, SortBy("Username", false), Cursor("", 10, &next))
+//	CrudRepository.FindAllBy(ctx, &user, Where(...), SortBy("Username", false), Cursor(next, 10, &next))
+func Cursor(token string, size int, next *string) Option {
+	opt := gormOptions(func(db *gorm.DB) *gorm.DB {
+		if size <= 0 || size >= maxUInt32 {
+			_ = db.AddError(ErrorInvalidPagination.WithMessage("invalid cursor page size %d", size))
+			return db
+		}
+		keys, e := resolveCursorKeys(db, true)
+		if e != nil {
+			_ = db.AddError(e)
+			return db
+		}
+		if len(token) != 0 {
+			values, e := decodeCursorToken(token, db.Statement.Table, keys)
+			if e != nil {
+				_ = db.AddError(e)
+				return db
+			}
+			db = db.Where(seekPredicate(keys, values))
+		}
+		// fetch one more row to tell if there are more records
+		return db.Limit(size+1).InstanceSet(cursorSettingsKey, keys)
+	})
+	post := postExecOptions(func(db *gorm.DB) *gorm.DB {
+		if db.Error != nil {
+			return db
+		}
+		v, ok := db.InstanceGet(cursorSettingsKey)
+		if !ok {
+			return db
+		}
+		rv := reflect.Indirect(db.Statement.ReflectValue)
+		if rv.Kind() != reflect.Slice || rv.Len() <= size {
+			setCursorToken(next, "")
+			return db
+		}
+		rv.SetLen(size)
+		token, e := encodeCursorToken(db, v.([]*cursorKey), rv.Index(size-1))
+		if e != nil {
+			_ = db.AddError(e)
+			return db
+		}
+		setCursorToken(next, token)
+		return db
+	})
+	// we want to run this option AFTER any Sort or SortBy
+	return delayedOption{
+		order:   order.Lowest,
+		wrapped: []Option{opt, post},
+	}
+}
+
+// SeekAfter is an Option that only returns records after given values of sort keys, in the order determined by
+// Sort (with clause.OrderByColumn) or SortBy options. Number of values should match the number of sort keys.
+// This is a lower level alternative of Cursor, typically used together with Limit.
+// e.g.
+//
+//	CrudRepository.FindAll(ctx, &user, SortBy("LastName", false), SortBy("ID", false), SeekAfter("Smith", lastID), Limit(10))
+func SeekAfter(values ...interface{}) Option {
+	opt := gormOptions(func(db *gorm.DB) *gorm.DB {
+		keys, e := resolveCursorKeys(db, false)
+		switch {
+		case e != nil:
+			_ = db.AddError(e)
+			return db
+		case len(keys) == 0:
+			_ = db.AddError(ErrorInvalidPagination.WithMessage("SeekAfter requires Sort or SortBy"))
+			return db
+		case len(keys) != len(values):
+			_ = db.AddError(ErrorInvalidPagination.WithMessage("SeekAfter requires %d values but got %d", len(keys), len(values)))
+			return db
+		}
+		return db.Where(seekPredicate(keys, values))
+	})
+	// we want to run this option AFTER any Sort or SortBy
+	return delayedOption{
+		order:   order.Lowest,
+		wrapped: opt,
+	}
+}
+
+/***********************
+	Helpers
+ ***********************/
+
+// cursorKey is a sort key of keyset pagination
+type cursorKey struct {
+	column clause.Column
+	desc   bool
+	// fieldPath is the path of fields from the model to the sort key's field.
+	// It contains more than one fields when sorted by a joined "ToOne" relationship's field
+	fieldPath []*schema.Field
+}
+
+func (k cursorKey) String() string {
+	var prefix string
+	if k.desc {
+		prefix = "-"
+	}
+	if k.column.Table == "" || k.column.Table == clause.CurrentTable {
+		return prefix + k.column.Name
+	}
+	return prefix + k.column.Table + "." + k.column.Name
+}
+
+// resolveCursorKeys resolve sort keys from ORDER BY clause of current statement.
+// When "withPK" is true, primary key is appended as tie-breaker, and ORDER BY clause is updated accordingly.
+func resolveCursorKeys(db *gorm.DB, withPK bool) ([]*cursorKey, error) {
+	if e := requireSchema(db); e != nil {
+		return nil, ErrorUnsupportedOptions.WithMessage("keyset pagination is not supported in this usage: %v", e)
+	}
+	s := db.Statement.Schema
+	var columns []clause.OrderByColumn
+	if c, ok := db.Statement.Clauses[clause.OrderBy{}.Name()]; ok {
+		if orderBy, ok := c.Expression.(clause.OrderBy); ok {
+			columns = orderBy.Columns
+		}
+	}
+
+	keys := make([]*cursorKey, 0, len(columns)+len(s.PrimaryFields))
+	for _, col := range columns {
+		if col.Column.Raw {
+			return nil, ErrorUnsupportedOptions.WithMessage("keyset pagination requires sorting by column or field name, but got raw expression [%s]", col.Column.Name)
+		}
+		path, e := resolveCursorFieldPath(db, s, col.Column)
+		if e != nil {
+			return nil, e
+		}
+		keys = append(keys, &cursorKey{column: col.Column, desc: col.Desc, fieldPath: path})
+	}
+	if !withPK {
+		return keys, nil
+	}
+
+	var desc bool
+	if len(keys) != 0 {
+		desc = keys[len(keys)-1].desc
+	}
+	pkColumns := make([]clause.OrderByColumn, 0, len(s.PrimaryFields))
+	for _, f := range s.PrimaryFields {
+		if isCursorKey(keys, f) {
+			continue
+		}
+		col := clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Desc: desc}
+		pkColumns = append(pkColumns, col)
+		keys = append(keys, &cursorKey{column: col.Column, desc: desc, fieldPath: []*schema.Field{f}})
+	}
+	if len(pkColumns) != 0 {
+		db.Statement.AddClause(clause.OrderBy{Columns: pkColumns})
+	}
+	return keys, nil
+}
+
+func resolveCursorFieldPath(db *gorm.DB, s *schema.Schema, col clause.Column) ([]*schema.Field, error) {
+	var path []*schema.Field
+	if col.Table != "" && col.Table != clause.CurrentTable && col.Table != db.Statement.Table {
+		for _, name := range strings.Split(col.Table, ".") {
+			rel, ok := s.Relationships.Relations[name]
+			if !ok || rel.FieldSchema == nil {
+				return nil, ErrorUnsupportedOptions.WithMessage("keyset pagination cannot resolve sort key [%s.%s] on model %s", col.Table, col.Name, s.Name)
+			}
+			path = append(path, rel.Field)
+			s = rel.FieldSchema
+		}
+	}
+	f := s.LookUpField(col.Name)
+	if f == nil {
+		return nil, ErrorUnsupportedOptions.WithMessage("keyset pagination cannot resolve sort key [%s] on model %s", col.Name, s.Name)
+	}
+	return append(path, f), nil
+}
+
+func isCursorKey(keys []*cursorKey, f *schema.Field) bool {
+	for _, k := range keys {
+		if len(k.fieldPath) == 1 && k.fieldPath[0] == f {
+			return true
+		}
+	}
+	return false
+}
+
+// seekPredicate build seek predicate of given keys and values.
+// If all keys are sorted in same direction, row value comparison is used. e.g. (col1, col2) > (val1, val2)
+// Otherwise, the comparison is expanded. e.g. col1 > val1 OR (col1 = val1 AND col2 < val2)
+func seekPredicate(keys []*cursorKey, values []interface{}) clause.Expression {
+	sameDirection := true
+	for _, k := range keys[1:] {
+		sameDirection = sameDirection && k.desc == keys[0].desc
+	}
+
+	if sameDirection {
+		op := ">"
+		if keys[0].desc {
+			op = "<"
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",")
+		vars := make([]interface{}, 0, len(keys)*2)
+		for _, k := range keys {
+			vars = append(vars, k.column)
+		}
+		vars = append(vars, values...)
+		return clause.Expr{
+			SQL:  fmt.Sprintf("(%s) %s (%s)", placeholders, op, placeholders),
+			Vars: vars,
+		}
+	}
+
+	exprs := make([]clause.Expression, len(keys))
+	for i, k := range keys {
+		conds := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, clause.Eq{Column: keys[j].column, Value: values[j]})
+		}
+		if k.desc {
+			conds = append(conds, clause.Lt{Column: k.column, Value: values[i]})
+		} else {
+			conds = append(conds, clause.Gt{Column: k.column, Value: values[i]})
+		}
+		exprs[i] = clause.And(conds...)
+	}
+	return clause.Or(exprs...)
+}
+
+type cursorPayload struct {
+	Table  string            `json:"t"`
+	Keys   []string          `json:"k"`
+	Values []json.RawMessage `json:"v"`
+}
+
+func encodeCursorToken(db *gorm.DB, keys []*cursorKey, row reflect.Value) (string, error) {
+	payload := cursorPayload{
+		Table:  db.Statement.Table,
+		Keys:   make([]string, len(keys)),
+		Values: make([]json.RawMessage, len(keys)),
+	}
+	for i, k := range keys {
+		payload.Keys[i] = k.String()
+		v := row
+		for _, f := range k.fieldPath {
+			if v = reflect.Indirect(v); !v.IsValid() {
+				break
+			}
+			v = f.ReflectValueOf(db.Statement.Context, v)
+		}
+		var value interface{}
+		if v = reflect.Indirect(v); v.IsValid() {
+			value = v.Interface()
+		}
+		raw, e := json.Marshal(value)
+		if e != nil {
+			return "", ErrorInvalidPagination.WithMessage("unable to encode value of sort key [%s]: %v", k, e)
+		}
+		payload.Values[i] = raw
+	}
+
+	data, e := json.Marshal(payload)
+	if e != nil {
+		return "", ErrorInvalidPagination.WithMessage("unable to encode continuation token: %v", e)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + cursorTokenSep + base64.RawURLEncoding.EncodeToString(signCursor(encoded)), nil
+}
+
+func decodeCursorToken(token string, table string, keys []*cursorKey) ([]interface{}, error) {
+	split := strings.Split(token, cursorTokenSep)
+	if len(split) != 2 {
+		return nil, ErrorInvalidPagination.WithMessage("malformed continuation token")
+	}
+	sig, e := base64.RawURLEncoding.DecodeString(split[1])
+	if e != nil || !hmac.Equal(sig, signCursor(split[0])) {
+		return nil, ErrorInvalidPagination.WithMessage("invalid continuation token signature")
+	}
+	data, e := base64.RawURLEncoding.DecodeString(split[0])
+	if e != nil {
+		return nil, ErrorInvalidPagination.WithMessage("malformed continuation token: %v", e)
+	}
+	var payload cursorPayload
+	if e := json.Unmarshal(data, &payload); e != nil {
+		return nil, ErrorInvalidPagination.WithMessage("malformed continuation token: %v", e)
+	}
+
+	if payload.Table != table {
+		return nil, ErrorInvalidPagination.WithMessage("continuation token doesn't match table")
+	}
+	if len(payload.Keys) != len(keys) || len(payload.Values) != len(keys) {
+		return nil, ErrorInvalidPagination.WithMessage("continuation token doesn't match sort keys")
+	}
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		if payload.Keys[i] != k.String() {
+			return nil, ErrorInvalidPagination.WithMessage("continuation token doesn't match sort keys")
+		}
+		f := k.fieldPath[len(k.fieldPath)-1]
+		ptr := reflect.New(f.FieldType)
+		if e := json.Unmarshal(payload.Values[i], ptr.Interface()); e != nil {
+			return nil, ErrorInvalidPagination.WithMessage("invalid value of sort key [%s]: %v", k, e)
+		}
+		values[i] = ptr.Elem().Interface()
+	}
+	return values, nil
+}
+
+func signCursor(payload string) []byte {
+	mac := hmac.New(sha256.New, cursorSigningKey)
+	_, _ = mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func setCursorToken(next *string, token string) {
+	if next != nil {
+		*next = token
+	}
+}
+
+func randomCursorSigningKey() []byte {
+	key := make([]byte, 32)
+	if _, e := rand.Read(key); e != nil {
+		panic(e)
+	}
+	return key
+}
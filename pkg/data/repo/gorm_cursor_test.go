@@ -0,0 +1,330 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data/types/pqx"
+	"github.com/cisco-open/go-lanai/pkg/security"
+	"github.com/cisco-open/go-lanai/pkg/tenancy"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/cisco-open/go-lanai/test/mocks"
+	"github.com/cisco-open/go-lanai/test/sectest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const tableSQLCursor = `
+CREATE TABLE IF NOT EXISTS public.test_cursor_models (
+	id UUID NOT NULL,
+	"name" STRING NOT NULL,
+	"value" INT NOT NULL,
+	tenant_id UUID NOT NULL,
+	tenant_path UUID[] NOT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC),
+	FAMILY "primary" (id, "name", "value", tenant_id, tenant_path)
+);`
+
+var (
+	cursorRootTenantID = uuid.MustParse("3b2c1d0e-7a41-4c53-9b8e-0f6a2d4c5e71")
+	cursorTenantIDA    = uuid.MustParse("8f0e5b1a-2c3d-4e5f-8a9b-1c2d3e4f5a6b")
+	cursorTenantIDB    = uuid.MustParse("d4c3b2a1-6f5e-4d3c-9b2a-7f6e5d4c3b2a")
+	cursorTestRows     = []*CursorModel{
+		newCursorModel("0a4c6c4e-3d0b-4a8e-9f63-1d1f6a7b8c01", cursorTenantIDA, "alpha", 3),
+		newCursorModel("1b5d7d5f-4e1c-4b9f-8a74-2e2a7b8c9d02", cursorTenantIDA, "bravo", 1),
+		newCursorModel("2c6e8e6a-5f2d-4c0a-9b85-3f3b8c9dae03", cursorTenantIDA, "charlie", 3),
+		newCursorModel("3d7f9f7b-6a3e-4d1b-8c96-4a4c9dae0f04", cursorTenantIDA, "delta", 2),
+		newCursorModel("4e8a0a8c-7b4f-4e2c-9da7-5b5daebf1a05", cursorTenantIDB, "echo", 1),
+		newCursorModel("5f9b1b9d-8c5a-4f3d-8eb8-6c6ebfca2b06", cursorTenantIDB, "foxtrot", 3),
+		newCursorModel("6a0c2c0e-9d6b-4a4e-9fc9-7d7fcadb3c07", cursorTenantIDB, "golf", 2),
+	}
+)
+
+// CursorModel enables tenancy filtering on both read and write
+type CursorModel struct {
+	ID          uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Name        string
+	Value       int
+	pqx.Tenancy `filter:"rw"`
+}
+
+func (CursorModel) TableName() string {
+	return "test_cursor_models"
+}
+
+// CursorAltModel has same sort keys as CursorModel but different table
+type CursorAltModel struct {
+	CursorModel
+}
+
+func (CursorAltModel) TableName() string {
+	return "test_cursor_alt_models"
+}
+
+type cursorTestDI struct {
+	fx.In
+	DB      *gorm.DB
+	Factory Factory
+}
+
+func provideCursorTenancyAccessor() tenancy.Accessor {
+	return mocks.NewMockTenancyAccessor([]mocks.TenancyRelation{
+		{Parent: cursorRootTenantID, Child: cursorTenantIDA},
+		{Parent: cursorRootTenantID, Child: cursorTenantIDB},
+	}, cursorRootTenantID)
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestCursorPagination(t *testing.T) {
+	di := &cursorTestDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithModules(Module, tenancy.Module),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithFxOptions(
+			fx.Provide(provideCursorTenancyAccessor),
+		),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupTestPrepareCursorTable(di)),
+		test.GomegaSubTest(SubTestCursorAllPages(di), "TestCursorAllPages"),
+		test.GomegaSubTest(SubTestCursorWithConditions(di), "TestCursorWithConditions"),
+		test.GomegaSubTest(SubTestCursorMixedDirections(di), "TestCursorMixedDirections"),
+		test.GomegaSubTest(SubTestCursorWithTenancy(di), "TestCursorWithTenancy"),
+		test.GomegaSubTest(SubTestCursorInvalidToken(di), "TestCursorInvalidToken"),
+		test.GomegaSubTest(SubTestSeekAfter(di), "TestSeekAfter"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupTestPrepareCursorTable(di *cursorTestDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		rs := di.DB.Exec(tableSQLCursor)
+		g.Expect(rs.Error).To(gomega.Succeed(), "create table if not exists shouldn't fail")
+		rs = di.DB.Exec("TRUNCATE TABLE test_cursor_models")
+		g.Expect(rs.Error).To(gomega.Succeed(), "truncate table shouldn't fail")
+		rows := make([]*CursorModel, len(cursorTestRows))
+		for i := range cursorTestRows {
+			row := *cursorTestRows[i]
+			rows[i] = &row
+		}
+		rs = di.DB.WithContext(cursorSecurityContext(ctx, cursorRootTenantID)).Create(rows)
+		g.Expect(rs.Error).To(gomega.Succeed(), "create test data shouldn't fail")
+		return ctx, nil
+	}
+}
+
+func SubTestCursorAllPages(di *cursorTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = cursorSecurityContext(ctx, cursorRootTenantID)
+		repo := di.Factory.NewCRUD(&CursorModel{})
+		names, pages := fetchAllCursorPages(ctx, g, 3, func(token string, next *string) ([]CursorModel, error) {
+			var models []CursorModel
+			e := repo.FindAll(ctx, &models, Cursor(token, 3, next), SortBy("Name", false))
+			return models, e
+		})
+		g.Expect(pages).To(gomega.Equal(3), "number of pages should be correct")
+		g.Expect(names).To(gomega.Equal([]string{
+			"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf",
+		}), "all records should be fetched in correct order")
+	}
+}
+
+func SubTestCursorWithConditions(di *cursorTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = cursorSecurityContext(ctx, cursorRootTenantID)
+		repo := di.Factory.NewCRUD(&CursorModel{})
+		names, pages := fetchAllCursorPages(ctx, g, 2, func(token string, next *string) ([]CursorModel, error) {
+			var models []CursorModel
+			e := repo.FindAllBy(ctx, &models, Where("value > ?", 1), SortBy("Name", true), Cursor(token, 2, next))
+			return models, e
+		})
+		g.Expect(pages).To(gomega.Equal(3), "number of pages should be correct")
+		g.Expect(names).To(gomega.Equal([]string{
+			"golf", "foxtrot", "delta", "charlie", "alpha",
+		}), "matching records should be fetched in correct order")
+	}
+}
+
+func SubTestCursorMixedDirections(di *cursorTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = cursorSecurityContext(ctx, cursorRootTenantID)
+		repo := di.Factory.NewCRUD(&CursorModel{})
+		opts := []Option{SortBy("Value", true), SortBy("Name", false)}
+		names, pages := fetchAllCursorPages(ctx, g, 2, func(token string, next *string) ([]CursorModel, error) {
+			var models []CursorModel
+			e := repo.FindAll(ctx, &models, opts, Cursor(token, 2, next))
+			return models, e
+		})
+		g.Expect(pages).To(gomega.Equal(4), "number of pages should be correct")
+		g.Expect(names).To(gomega.Equal([]string{
+			"alpha", "charlie", "foxtrot", "delta", "golf", "bravo", "echo",
+		}), "all records should be fetched in correct order")
+	}
+}
+
+func SubTestCursorWithTenancy(di *cursorTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&CursorModel{})
+		ctxA := cursorSecurityContext(ctx, cursorTenantIDA)
+		names, pages := fetchAllCursorPages(ctxA, g, 2, func(token string, next *string) ([]CursorModel, error) {
+			var models []CursorModel
+			e := repo.FindAll(ctxA, &models, SortBy("Value", false), SortBy("Name", false), Cursor(token, 2, next))
+			return models, e
+		})
+		g.Expect(pages).To(gomega.Equal(2), "number of pages should be correct")
+		g.Expect(names).To(gomega.Equal([]string{
+			"bravo", "delta", "alpha", "charlie",
+		}), "only records of accessible tenant should be fetched")
+
+		// token is not bound to tenant, but tenancy filter still applies
+		var models []CursorModel
+		var next string
+		e := repo.FindAll(ctxA, &models, SortBy("Name", false), Cursor("", 2, &next))
+		g.Expect(e).To(gomega.Succeed(), "FindAll should not fail")
+		g.Expect(next).ToNot(gomega.BeEmpty(), "next token should be available")
+		ctxB := cursorSecurityContext(ctx, cursorTenantIDB)
+		e = repo.FindAll(ctxB, &models, SortBy("Name", false), Cursor(next, 5, &next))
+		g.Expect(e).To(gomega.Succeed(), "FindAll with token should not fail")
+		g.Expect(cursorModelNames(models)).To(gomega.Equal([]string{
+			"echo", "foxtrot", "golf",
+		}), "only records of accessible tenant should be fetched")
+		g.Expect(next).To(gomega.BeEmpty(), "next token should be empty on last page")
+	}
+}
+
+func SubTestCursorInvalidToken(di *cursorTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = cursorSecurityContext(ctx, cursorRootTenantID)
+		repo := di.Factory.NewCRUD(&CursorModel{})
+		var models []CursorModel
+		var next string
+		e := repo.FindAll(ctx, &models, SortBy("Name", false), Cursor("", 2, &next))
+		g.Expect(e).To(gomega.Succeed(), "FindAll should not fail")
+		g.Expect(next).ToNot(gomega.BeEmpty(), "next token should be available")
+
+		// tampered
+		split := strings.Split(next, ".")
+		tampered := split[0] + "x." + split[1]
+		e = repo.FindAll(ctx, &models, SortBy("Name", false), Cursor(tampered, 2, &next))
+		g.Expect(errors.Is(e, ErrorInvalidPagination)).To(gomega.BeTrue(), "tampered token should be rejected")
+
+		// different sort keys
+		e = repo.FindAll(ctx, &models, SortBy("Value", false), Cursor(next, 2, &next))
+		g.Expect(errors.Is(e, ErrorInvalidPagination)).To(gomega.BeTrue(), "token with different sort keys should be rejected")
+
+		// different table
+		altRepo := di.Factory.NewCRUD(&CursorAltModel{})
+		var altModels []CursorAltModel
+		e = altRepo.FindAll(ctx, &altModels, SortBy("Name", false), Cursor(next, 2, &next))
+		g.Expect(errors.Is(e, ErrorInvalidPagination)).To(gomega.BeTrue(), "token of different table should be rejected")
+
+		// invalid size
+		e = repo.FindAll(ctx, &models, Cursor("", 0, &next))
+		g.Expect(errors.Is(e, ErrorInvalidPagination)).To(gomega.BeTrue(), "invalid page size should be rejected")
+
+		// raw sort
+		e = repo.FindAll(ctx, &models, Sort("name DESC"), Cursor("", 2, &next))
+		g.Expect(errors.Is(e, ErrorUnsupportedOptions)).To(gomega.BeTrue(), "raw sort should be rejected")
+	}
+}
+
+func SubTestSeekAfter(di *cursorTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = cursorSecurityContext(ctx, cursorRootTenantID)
+		repo := di.Factory.NewCRUD(&CursorModel{})
+		var models []CursorModel
+		e := repo.FindAll(ctx, &models, SeekAfter(2, "delta"), Limit(3), SortBy("Value", false), SortBy("Name", false))
+		g.Expect(e).To(gomega.Succeed(), "FindAll should not fail")
+		g.Expect(cursorModelNames(models)).To(gomega.Equal([]string{
+			"golf", "alpha", "charlie",
+		}), "records after given values should be fetched")
+
+		e = repo.FindAll(ctx, &models, SortBy("Name", false), SeekAfter("n", 10))
+		g.Expect(errors.Is(e, ErrorInvalidPagination)).To(gomega.BeTrue(), "mismatched values should be rejected")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func newCursorModel(id string, tenantID uuid.UUID, name string, value int) *CursorModel {
+	return &CursorModel{
+		ID:      uuid.MustParse(id),
+		Name:    name,
+		Value:   value,
+		Tenancy: pqx.Tenancy{TenantID: tenantID},
+	}
+}
+
+func cursorSecurityContext(parent context.Context, tenantID uuid.UUID) context.Context {
+	return sectest.WithMockedSecurity(parent, func(d *sectest.SecurityDetailsMock) {
+		d.Username = "any-username"
+		d.UserId = "any-user-id"
+		d.TenantId = tenantID.String()
+		d.Tenants = utils.NewStringSet(tenantID.String())
+		d.Permissions = utils.NewStringSet(security.SpecialPermissionSwitchTenant)
+	})
+}
+
+// fetchAllCursorPages keep fetching pages until no more "next" token is returned. Returns names of all fetched records
+// and number of pages
+func fetchAllCursorPages(_ context.Context, g *gomega.WithT, size int,
+	fetchFn func(token string, next *string) ([]CursorModel, error)) (names []string, pages int) {
+	var token string
+	for {
+		var next string
+		models, e := fetchFn(token, &next)
+		g.Expect(e).To(gomega.Succeed(), "fetching page %d should not fail", pages+1)
+		g.Expect(len(models)).To(gomega.BeNumerically("<=", size), "page %d should not exceed page size", pages+1)
+		names = append(names, cursorModelNames(models)...)
+		pages++
+		if next == "" || pages > len(cursorTestRows) {
+			return
+		}
+		token = next
+	}
+}
+
+func cursorModelNames(models []CursorModel) []string {
+	names := make([]string, len(models))
+	for i := range models {
+		names[i] = models[i].Name
+	}
+	return names
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/data/tx"
+	errorutils "github.com/cisco-open/go-lanai/pkg/utils/error"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+var errNoConnection = errors.New("no connection")
+
+// recordingConnPool implements gorm.ConnPool, it records executed SQL without actual connection
+type recordingConnPool struct {
+	gorm.ConnPool
+	queries []string
+}
+
+func (p *recordingConnPool) QueryContext(_ context.Context, query string, _ ...interface{}) (*sql.Rows, error) {
+	p.queries = append(p.queries, query)
+	return nil, errNoConnection
+}
+
+func (p *recordingConnPool) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	p.queries = append(p.queries, query)
+	return nil, errNoConnection
+}
+
+type DatasourceModel struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value string
+}
+
+func newDatasourceTestCrud(g *gomega.WithT, db *gorm.DB) *GormCrud {
+	crud, e := newGormCrud(gormApi{db: db}, &DatasourceModel{})
+	g.Expect(e).To(gomega.Succeed(), "creating repository shouldn't fail")
+	return crud
+}
+
+func newDatasourceTestDB(name string, primary *recordingConnPool, replicas ...*recordingConnPool) *gorm.DB {
+	return data.NewGorm(func(cfg *data.GormConfig) {
+		cfg.Name = name
+		cfg.Dialector = postgres.New(postgres.Config{Conn: primary})
+		for _, r := range replicas {
+			cfg.ReplicaDialectors = append(cfg.ReplicaDialectors, postgres.New(postgres.Config{Conn: r}))
+		}
+	})
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestRepositoryDatasource(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestRepositoryReadRouting(), "TestRepositoryReadRouting"),
+		test.GomegaSubTest(SubTestCrossDatasourceTransaction(), "TestCrossDatasourceTransaction"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestRepositoryReadRouting() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		primary, replica := &recordingConnPool{}, &recordingConnPool{}
+		db := newDatasourceTestDB("", primary, replica)
+		crud := newDatasourceTestCrud(g, db)
+		id := uuid.New()
+
+		_ = crud.FindById(ctx, &DatasourceModel{}, id)
+		g.Expect(replica.queries).To(gomega.HaveLen(1), "repository read outside of transaction should be routed to replica")
+		g.Expect(primary.queries).To(gomega.BeEmpty(), "repository read outside of transaction should not go to primary")
+
+		_ = db.WithContext(ctx).Take(&DatasourceModel{}, id)
+		g.Expect(primary.queries).To(gomega.HaveLen(1), "raw gorm read should go to primary")
+		g.Expect(replica.queries).To(gomega.HaveLen(1), "raw gorm read should not be routed to replica")
+
+		_ = crud.FindById(tx.NewGormTxContext(ctx, db.WithContext(ctx)), &DatasourceModel{}, id)
+		g.Expect(primary.queries).To(gomega.HaveLen(2), "repository read within transaction should go to primary")
+		g.Expect(replica.queries).To(gomega.HaveLen(1), "repository read within transaction should not be routed to replica")
+	}
+}
+
+func SubTestCrossDatasourceTransaction() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		defaultPrimary, reportingPrimary := &recordingConnPool{}, &recordingConnPool{}
+		crud := newDatasourceTestCrud(g, newDatasourceTestDB("", defaultPrimary))
+		reporting := newDatasourceTestDB("reporting", reportingPrimary)
+
+		txCtx := tx.NewGormTxContext(ctx, reporting.WithContext(ctx))
+		e := crud.FindById(txCtx, &DatasourceModel{}, uuid.New())
+		g.Expect(e).To(gomega.HaveOccurred(), "repository read within transaction of another datasource should fail")
+		var coder errorutils.ErrorCoder
+		g.Expect(errors.As(e, &coder)).To(gomega.BeTrue(), "error should be a coded error")
+		g.Expect(coder.Code()).To(gomega.BeEquivalentTo(data.ErrorCodeInvalidTransaction), "error should have correct code")
+		g.Expect(defaultPrimary.queries).To(gomega.BeEmpty(), "no SQL should be executed on repository's datasource")
+		g.Expect(reportingPrimary.queries).To(gomega.BeEmpty(), "no SQL should be executed on transaction's datasource")
+	}
+}
@@ -17,24 +17,40 @@
 package repo
 
 import (
+	"github.com/cisco-open/go-lanai/pkg/data"
 	"github.com/cisco-open/go-lanai/pkg/data/tx"
+	"go.uber.org/fx"
 	"gorm.io/gorm"
 )
 
+// Datasource is an option of Factory.NewCRUD, which binds the repository to the named datasource.
+// See data.Datasources
+type Datasource string
+
 type GormFactory struct {
 	db *gorm.DB
 	txManager tx.GormTxManager
 	api GormApi
+	datasources *data.Datasources
+}
+
+type factoryDI struct {
+	fx.In
+	DB          *gorm.DB
+	TxManager   tx.GormTxManager
+	Datasources *data.Datasources `optional:"true"`
 }
 
-func newGormFactory(db *gorm.DB, txManager tx.GormTxManager) Factory {
+func newGormFactory(di factoryDI) Factory {
 	return &GormFactory{
-		db: db,
-		txManager: txManager,
-		api: newGormApi(db, txManager),
+		db: di.DB,
+		txManager: di.TxManager,
+		api: newGormApi(di.DB, di.TxManager),
+		datasources: di.Datasources,
 	}
 }
 
+// NewCRUD implements Factory. Supported options are gorm.Session, *gorm.Session and Datasource
 func (f GormFactory) NewCRUD(model interface{}, options...interface{}) CrudRepository {
 	api := f.NewGormApi(options...)
 	crud, e := newGormCrud(api, model)
@@ -47,6 +63,11 @@ func (f GormFactory) NewCRUD(model interface{}, options...interface{}) CrudRepos
 
 func (f GormFactory) NewGormApi(options...interface{}) GormApi {
 	api := f.api
+	for _, v := range options {
+		if name, ok := v.(Datasource); ok {
+			api = newGormApi(f.mustDatasource(string(name)), f.txManager)
+		}
+	}
 	for _, v := range options {
 		switch opt := v.(type) {
 		case gorm.Session:
@@ -56,4 +77,14 @@ func (f GormFactory) NewGormApi(options...interface{}) GormApi {
 		}
 	}
 	return api
-}
\ No newline at end of file
+}
+
+func (f GormFactory) mustDatasource(name string) *gorm.DB {
+	if f.datasources == nil {
+		if name == "" || name == data.DefaultDatasource {
+			return f.db
+		}
+		panic(ErrorInvalidCrudModel.WithMessage("datasource [%s] is not available", name))
+	}
+	return f.datasources.MustDB(name)
+}
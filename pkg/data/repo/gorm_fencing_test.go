@@ -0,0 +1,182 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const tableSQLFenced = `
+CREATE TABLE IF NOT EXISTS public.test_fenced_models (
+	id UUID NOT NULL,
+	"value" STRING,
+	fencing_token INT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC),
+	FAMILY "primary" (id, "value", fencing_token)
+);`
+
+var fencedModelIDs = []uuid.UUID{
+	uuid.MustParse("5b7e9c63-0ab2-4b0e-9b0e-56a1b5d8e8a1"),
+	uuid.MustParse("c1d0f7a4-3c84-4b5e-a4f2-0c6b7a8e9d12"),
+}
+
+type FencedModel struct {
+	ID           uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value        string
+	FencingToken *uint64 `gorm:"column:fencing_token;"`
+}
+
+func (FencedModel) TableName() string {
+	return "test_fenced_models"
+}
+
+type fencingTestDI struct {
+	fx.In
+	DB      *gorm.DB
+	Factory Factory
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestFencingToken(t *testing.T) {
+	di := &fencingTestDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithModules(Module),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupTestPrepareFencedTable(di)),
+		test.GomegaSubTest(SubTestFencedUpdate(di), "TestFencedUpdate"),
+		test.GomegaSubTest(SubTestFencedDelete(di), "TestFencedDelete"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupTestPrepareFencedTable(di *fencingTestDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		rs := di.DB.Exec(tableSQLFenced)
+		g.Expect(rs.Error).To(gomega.Succeed(), "create table if not exists shouldn't fail")
+		rs = di.DB.Exec("TRUNCATE TABLE test_fenced_models")
+		g.Expect(rs.Error).To(gomega.Succeed(), "truncate table shouldn't fail")
+		rs = di.DB.Create([]*FencedModel{
+			{ID: fencedModelIDs[0], Value: "never fenced"},
+			{ID: fencedModelIDs[1], Value: "fenced", FencingToken: fencingToken(10)},
+		})
+		g.Expect(rs.Error).To(gomega.Succeed(), "create test data shouldn't fail")
+		return ctx, nil
+	}
+}
+
+func SubTestFencedUpdate(di *fencingTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&FencedModel{})
+		var e error
+
+		// record without token
+		e = repo.Update(ctx, &FencedModel{ID: fencedModelIDs[0]},
+			map[string]interface{}{"value": "updated", "fencing_token": 5}, FencingToken("fencing_token", 5))
+		g.Expect(e).To(gomega.Succeed(), "Update of record without token shouldn't fail")
+		assertFencedModel(ctx, g, repo, fencedModelIDs[0], "updated", 5)
+
+		// newer token
+		e = repo.Update(ctx, &FencedModel{ID: fencedModelIDs[1]},
+			map[string]interface{}{"value": "updated", "fencing_token": 11}, FencingToken("fencing_token", 11))
+		g.Expect(e).To(gomega.Succeed(), "Update with newer token shouldn't fail")
+		assertFencedModel(ctx, g, repo, fencedModelIDs[1], "updated", 11)
+
+		// same token
+		e = repo.Update(ctx, &FencedModel{ID: fencedModelIDs[1]},
+			map[string]interface{}{"value": "updated again"}, FencingToken("fencing_token", 11))
+		g.Expect(e).To(gomega.Succeed(), "Update with same token shouldn't fail")
+		assertFencedModel(ctx, g, repo, fencedModelIDs[1], "updated again", 11)
+
+		// stale token
+		e = repo.Update(ctx, &FencedModel{ID: fencedModelIDs[1]},
+			map[string]interface{}{"value": "stale", "fencing_token": 10}, FencingToken("fencing_token", 10))
+		g.Expect(errors.Is(e, data.ErrorStaleFencingToken)).To(gomega.BeTrue(), "Update with stale token should fail with ErrorStaleFencingToken")
+		assertFencedModel(ctx, g, repo, fencedModelIDs[1], "updated again", 11)
+	}
+}
+
+func SubTestFencedDelete(di *fencingTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&FencedModel{})
+		var e error
+
+		// stale token
+		e = repo.Delete(ctx, &FencedModel{ID: fencedModelIDs[1]}, FencingToken("fencing_token", 9))
+		g.Expect(errors.Is(e, data.ErrorStaleFencingToken)).To(gomega.BeTrue(), "Delete with stale token should fail with ErrorStaleFencingToken")
+		assertFencedModel(ctx, g, repo, fencedModelIDs[1], "fenced", 10)
+
+		// stale token with DeleteBy
+		e = repo.DeleteBy(ctx, Where("id = ?", fencedModelIDs[1]), FencingToken("fencing_token", 9))
+		g.Expect(errors.Is(e, data.ErrorStaleFencingToken)).To(gomega.BeTrue(), "DeleteBy with stale token should fail with ErrorStaleFencingToken")
+		assertFencedModel(ctx, g, repo, fencedModelIDs[1], "fenced", 10)
+
+		// current token
+		e = repo.Delete(ctx, &FencedModel{ID: fencedModelIDs[1]}, FencingToken("fencing_token", 10))
+		g.Expect(e).To(gomega.Succeed(), "Delete with current token shouldn't fail")
+		var model FencedModel
+		e = repo.FindById(ctx, &model, fencedModelIDs[1], ErrorOnZeroRows())
+		g.Expect(errors.Is(e, data.ErrorRecordNotFound)).To(gomega.BeTrue(), "re-fetch after Delete should yield RecordNotFound")
+
+		// record without token
+		e = repo.DeleteBy(ctx, Where("id = ?", fencedModelIDs[0]), FencingToken("fencing_token", 1))
+		g.Expect(e).To(gomega.Succeed(), "DeleteBy of record without token shouldn't fail")
+		e = repo.FindById(ctx, &model, fencedModelIDs[0], ErrorOnZeroRows())
+		g.Expect(errors.Is(e, data.ErrorRecordNotFound)).To(gomega.BeTrue(), "re-fetch after DeleteBy should yield RecordNotFound")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func fencingToken(v uint64) *uint64 {
+	return &v
+}
+
+func assertFencedModel(ctx context.Context, g *gomega.WithT, repo CrudRepository, id uuid.UUID, expectedValue string, expectedToken uint64) {
+	var model FencedModel
+	e := repo.FindById(ctx, &model, id)
+	g.Expect(e).To(gomega.Succeed(), "FindById shouldn't return error")
+	g.Expect(model.Value).To(gomega.Equal(expectedValue), "value should be correct")
+	g.Expect(model.FencingToken).ToNot(gomega.BeNil(), "fencing token should be set")
+	g.Expect(*model.FencingToken).To(gomega.Equal(expectedToken), "fencing token should be correct")
+}
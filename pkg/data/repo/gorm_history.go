@@ -0,0 +1,59 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/data/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	colHistoryEntityID  = "entity_id"
+	colHistoryCreatedAt = "created_at"
+)
+
+// FindHistory implements HistoryRepository.
+// ErrorInvalidCrudModel is returned if the model doesn't have types.History embedded
+func (g GormCrud) FindHistory(ctx context.Context, id interface{}, options ...Option) ([]*types.HistoryRecord, error) {
+	if !types.IsHistoryEnabled(g.schema) {
+		return nil, ErrorInvalidCrudModel.WithMessage("model %s doesn't have change history enabled", g.ModelName())
+	}
+	table, e := types.HistoryTableName(g.GormApi.DB(ctx), g.model)
+	if e != nil {
+		return nil, ErrorInvalidCrudModel.WithMessage("unable to resolve history table name for model %T", g.model)
+	}
+
+	switch v := id.(type) {
+	case *string:
+		id = *v
+	case fmt.Stringer:
+		id = v.String()
+	}
+
+	var records []*types.HistoryRecord
+	e = execute(ctx, g.GormApi.DB(ctx), nil, options, func(db *gorm.DB) *gorm.DB {
+		return db.Table(table)
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Name: colHistoryEntityID}, Value: fmt.Sprint(id)}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: colHistoryCreatedAt}}).
+			Find(&records)
+	})
+	return records, e
+}
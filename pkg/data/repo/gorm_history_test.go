@@ -0,0 +1,162 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/data/types"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const tableSQLHistory = `
+CREATE TABLE IF NOT EXISTS public.history_models (
+	id UUID NOT NULL,
+	"value" STRING,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC),
+	FAMILY "primary" (id, "value")
+);`
+
+var historyModelIDs = []uuid.UUID{
+	uuid.MustParse("1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e51"),
+	uuid.MustParse("2d3e4f5a-6b7c-4d8e-9f0a-1b2c3d4e5f62"),
+}
+
+type HistoryModel struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value string
+	types.History
+}
+
+func (HistoryModel) TableName() string {
+	return "history_models"
+}
+
+type historyTestDI struct {
+	fx.In
+	DB      *gorm.DB
+	Factory Factory
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestFindHistory(t *testing.T) {
+	di := &historyTestDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithModules(Module),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithFxOptions(
+			fx.Provide(fx.Annotated{
+				Group: data.GormConfigurerGroup,
+				Target: func() data.GormConfigurer {
+					return types.NewHistoryGormConfigurer(nil)
+				},
+			}),
+		),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupTestPrepareHistoryTables(di)),
+		test.GomegaSubTest(SubTestFindHistory(di), "TestFindHistory"),
+		test.GomegaSubTest(SubTestFindHistoryWithOptions(di), "TestFindHistoryWithOptions"),
+		test.GomegaSubTest(SubTestFindHistoryWithoutHistory(di), "TestFindHistoryWithoutHistory"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupTestPrepareHistoryTables(di *historyTestDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		rs := di.DB.Exec(tableSQLHistory)
+		g.Expect(rs.Error).To(gomega.Succeed(), "create table if not exists shouldn't fail")
+		e := types.CreateHistoryTableIfNotExist(ctx, di.DB, &HistoryModel{})
+		g.Expect(e).To(gomega.Succeed(), "create history table shouldn't fail")
+		for _, table := range []string{"history_models", "history_models_history"} {
+			rs = di.DB.Exec("TRUNCATE TABLE " + table)
+			g.Expect(rs.Error).To(gomega.Succeed(), "truncate table shouldn't fail")
+		}
+
+		repo := di.Factory.NewCRUD(&HistoryModel{})
+		for _, id := range historyModelIDs {
+			model := HistoryModel{ID: id, Value: "created"}
+			g.Expect(repo.Create(ctx, &model)).To(gomega.Succeed(), "create test data shouldn't fail")
+			g.Expect(repo.Update(ctx, &model, map[string]interface{}{"Value": "updated"})).
+				To(gomega.Succeed(), "update test data shouldn't fail")
+			g.Expect(repo.Update(ctx, &model, map[string]interface{}{"Value": "updated again"})).
+				To(gomega.Succeed(), "update test data shouldn't fail")
+		}
+		return ctx, nil
+	}
+}
+
+func SubTestFindHistory(di *historyTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&HistoryModel{}).(HistoryRepository)
+		records, e := repo.FindHistory(ctx, historyModelIDs[0])
+		g.Expect(e).To(gomega.Succeed(), "FindHistory shouldn't fail")
+		g.Expect(records).To(gomega.HaveLen(3), "FindHistory should return all records of the entity")
+		expected := []string{"created", "updated", "updated again"}
+		for i, record := range records {
+			g.Expect(record.EntityID).To(gomega.Equal(historyModelIDs[0].String()), "EntityID should be correct")
+			g.Expect(record.Changes).To(gomega.HaveKey("value"), "changes should be correct")
+			g.Expect(record.Changes["value"].New).To(gomega.Equal(expected[i]), "records should be ordered by creation time")
+		}
+		g.Expect(records[0].Operation).To(gomega.Equal(types.HistoryOperationCreate), "Operation should be correct")
+
+		// string ID
+		records, e = repo.FindHistory(ctx, historyModelIDs[1].String())
+		g.Expect(e).To(gomega.Succeed(), "FindHistory with string ID shouldn't fail")
+		g.Expect(records).To(gomega.HaveLen(3), "FindHistory should return all records of the entity")
+	}
+}
+
+func SubTestFindHistoryWithOptions(di *historyTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&HistoryModel{}).(HistoryRepository)
+		records, e := repo.FindHistory(ctx, historyModelIDs[0], Where("operation = ?", types.HistoryOperationUpdate), Limit(1))
+		g.Expect(e).To(gomega.Succeed(), "FindHistory with options shouldn't fail")
+		g.Expect(records).To(gomega.HaveLen(1), "FindHistory should apply options")
+		g.Expect(records[0].Operation).To(gomega.Equal(types.HistoryOperationUpdate), "FindHistory should apply conditions")
+		g.Expect(records[0].Changes["value"].New).To(gomega.Equal("updated"), "FindHistory should apply limit after ordering")
+	}
+}
+
+func SubTestFindHistoryWithoutHistory(di *historyTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&CursorModel{}).(HistoryRepository)
+		_, e := repo.FindHistory(ctx, uuid.New())
+		g.Expect(errors.Is(e, ErrorInvalidCrudModel)).To(gomega.BeTrue(), "FindHistory should fail on models without history")
+	}
+}
@@ -22,6 +22,7 @@ import (
     "github.com/cisco-open/go-lanai/pkg/utils/order"
     "gorm.io/gorm"
     "gorm.io/gorm/clause"
+    "time"
 )
 
 const (
@@ -207,6 +208,26 @@ func Page(page, size int) Option {
 	}
 }
 
+// Limit is an Option that directly bridge parameters to (*gorm.DB).Limit()
+// This Option is typically used together with SeekAfter option
+func Limit(limit int) Option {
+	return gormOptions(func(db *gorm.DB) *gorm.DB {
+		return db.Limit(limit)
+	})
+}
+
+// UsePrimary is an Option for read operations, which pins the query to the primary database, bypassing read replicas.
+// Note: reads within a transaction always go to the primary database
+func UsePrimary() Option {
+	return gormOptions(data.UsePrimary())
+}
+
+// StatementTimeout is an Option that overrides the default statement timeout of the operation.
+// Zero or negative value disables the timeout.
+func StatementTimeout(timeout time.Duration) Option {
+	return gormOptions(data.StatementTimeout(timeout))
+}
+
 // Sort is an Option specifying order when retrieve records from database by using column.
 // This Option is typically used together with Page option
 // When supported by gorm.io, this Option is a direct bridge to (*gorm.DB).Order()
@@ -261,6 +282,30 @@ func ErrorOnZeroRows() Option {
 	})
 }
 
+// FencingToken is an Option for Update, Delete and DeleteBy operations, which rejects writes carrying a stale fencing
+// token (see dsync.Lock). "column" is the column storing the fencing token of the last write.
+// Rows with stored token greater than given "token" are not affected. If no row is affected, data.ErrorStaleFencingToken
+// is returned.
+// Note: it's caller's responsibility to also update the column with the given token.
+// e.g.
+//		token, _ := dsync.FencingTokenFromContext(ctx)
+//		CrudRepository.Update(ctx, &job, map[string]interface{}{"status": "done", "fencing_token": token}, FencingToken("fencing_token", token))
+func FencingToken(column string, token uint64) Option {
+	col := clause.Column{Name: column}
+	return []Option{
+		gormOptions(func(db *gorm.DB) *gorm.DB {
+			return db.Where(clause.Or(clause.Lte{Column: col, Value: token}, clause.Eq{Column: col, Value: nil}))
+		}),
+		postExecOptions(func(db *gorm.DB) *gorm.DB {
+			if db.Error == nil && db.RowsAffected == 0 {
+				db.Error = data.ErrorStaleFencingToken.
+					WithMessage("fencing token %d is stale or record not found", token)
+			}
+			return db
+		}),
+	}
+}
+
 /***********************
 	Helpers
  ***********************/
@@ -17,7 +17,9 @@
 package repo
 
 import (
+    "context"
     "fmt"
+    "github.com/cisco-open/go-lanai/pkg/data/types"
     "github.com/cisco-open/go-lanai/pkg/utils"
     "gorm.io/gorm"
     "gorm.io/gorm/clause"
@@ -26,6 +28,8 @@ import (
     "strings"
 )
 
+var typeLockVersion = reflect.TypeOf(types.LockVersion(0))
+
 // GormSchemaResolver extends SchemaResolver to expose more schema related functions
 type GormSchemaResolver interface {
 	SchemaResolver
@@ -91,6 +95,21 @@ func (g GormMetadata) isSupportedValue(value interface{}, types utils.Set) bool
 	return ok && types.Has(typ)
 }
 
+// isVersioned returns true if the model embeds types.Version and given value is a single model with known version
+func (g GormMetadata) isVersioned(ctx context.Context, value interface{}) bool {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Struct {
+		return false
+	}
+	for _, f := range g.schema.Fields {
+		if f.FieldType == typeLockVersion {
+			_, zero := f.ValueOf(ctx, rv)
+			return !zero
+		}
+	}
+	return false
+}
+
 // gormSchemaResolver implements GormSchemaResolver
 type gormSchemaResolver struct {
 	schema *schema.Schema
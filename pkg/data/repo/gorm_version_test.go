@@ -0,0 +1,202 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/data/types"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const tableSQLVersioned = `
+CREATE TABLE IF NOT EXISTS public.versioned_models (
+	id UUID NOT NULL,
+	"value" STRING,
+	version INT8 NOT NULL DEFAULT 1,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC),
+	FAMILY "primary" (id, "value", version)
+);`
+
+var versionedModelIDs = []uuid.UUID{
+	uuid.MustParse("3f8a1c2e-9b4d-4e6f-a1b2-c3d4e5f60718"),
+	uuid.MustParse("4a9b2d3f-0c5e-4f70-b2c3-d4e5f6071829"),
+}
+
+type VersionedModel struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value string
+	types.Version
+}
+
+func (VersionedModel) TableName() string {
+	return "versioned_models"
+}
+
+type versionTestDI struct {
+	fx.In
+	DB      *gorm.DB
+	Factory Factory
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestOptimisticLockFailure(t *testing.T) {
+	di := &versionTestDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithModules(Module),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithFxOptions(
+			fx.Provide(fx.Annotated{
+				Group:  data.GormConfigurerGroup,
+				Target: types.NewVersionGormConfigurer,
+			}),
+		),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupTestPrepareVersionedTable(di)),
+		test.GomegaSubTest(SubTestVersionedUpdate(di), "TestVersionedUpdate"),
+		test.GomegaSubTest(SubTestVersionedSave(di), "TestVersionedSave"),
+		test.GomegaSubTest(SubTestOptimisticLockFailure(di), "TestOptimisticLockFailure"),
+		test.GomegaSubTest(SubTestWithoutVersionCheck(di), "TestWithoutVersionCheck"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupTestPrepareVersionedTable(di *versionTestDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		rs := di.DB.Exec(tableSQLVersioned)
+		g.Expect(rs.Error).To(gomega.Succeed(), "create table if not exists shouldn't fail")
+		rs = di.DB.Exec("TRUNCATE TABLE versioned_models")
+		g.Expect(rs.Error).To(gomega.Succeed(), "truncate table shouldn't fail")
+		rs = di.DB.Create([]*VersionedModel{
+			{ID: versionedModelIDs[0], Value: "first", Version: types.Version{Version: 1}},
+			{ID: versionedModelIDs[1], Value: "second", Version: types.Version{Version: 5}},
+		})
+		g.Expect(rs.Error).To(gomega.Succeed(), "create test data shouldn't fail")
+		return ctx, nil
+	}
+}
+
+func SubTestVersionedUpdate(di *versionTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&VersionedModel{})
+		model := loadVersionedModel(ctx, g, repo, versionedModelIDs[0])
+		e := repo.Update(ctx, model, map[string]interface{}{"Value": "updated"})
+		g.Expect(e).To(gomega.Succeed(), "Update with current version shouldn't fail")
+		g.Expect(model.Version.Version).To(gomega.BeEquivalentTo(2), "in-memory version should be incremented")
+		assertVersionedModel(ctx, g, repo, versionedModelIDs[0], "updated", 2)
+	}
+}
+
+func SubTestVersionedSave(di *versionTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&VersionedModel{})
+		model := loadVersionedModel(ctx, g, repo, versionedModelIDs[1])
+		model.Value = "saved"
+		e := repo.Save(ctx, model)
+		g.Expect(e).To(gomega.Succeed(), "Save with current version shouldn't fail")
+		g.Expect(model.Version.Version).To(gomega.BeEquivalentTo(6), "in-memory version should be incremented")
+		assertVersionedModel(ctx, g, repo, versionedModelIDs[1], "saved", 6)
+
+		// saving the same model again should use the incremented version
+		model.Value = "saved again"
+		e = repo.Save(ctx, model)
+		g.Expect(e).To(gomega.Succeed(), "Save with incremented version shouldn't fail")
+		g.Expect(model.Version.Version).To(gomega.BeEquivalentTo(7), "in-memory version should be incremented")
+		assertVersionedModel(ctx, g, repo, versionedModelIDs[1], "saved again", 7)
+	}
+}
+
+func SubTestOptimisticLockFailure(di *versionTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&VersionedModel{})
+		stale := loadVersionedModel(ctx, g, repo, versionedModelIDs[0])
+		current := loadVersionedModel(ctx, g, repo, versionedModelIDs[0])
+		e := repo.Update(ctx, current, map[string]interface{}{"Value": "modified by others"})
+		g.Expect(e).To(gomega.Succeed(), "Update with current version shouldn't fail")
+
+		stale.Value = "stale"
+		e = repo.Save(ctx, stale)
+		g.Expect(errors.Is(e, data.ErrorOptimisticLockFailure)).To(gomega.BeTrue(), "Save should fail on version mismatch")
+		g.Expect(stale.Version.Version).To(gomega.BeEquivalentTo(1), "in-memory version shouldn't be incremented")
+
+		e = repo.Update(ctx, stale, map[string]interface{}{"Value": "stale"})
+		g.Expect(errors.Is(e, data.ErrorOptimisticLockFailure)).To(gomega.BeTrue(), "Update should fail on version mismatch")
+		g.Expect(stale.Version.Version).To(gomega.BeEquivalentTo(1), "in-memory version shouldn't be incremented")
+
+		e = repo.Delete(ctx, stale)
+		g.Expect(errors.Is(e, data.ErrorOptimisticLockFailure)).To(gomega.BeTrue(), "Delete should fail on version mismatch")
+		assertVersionedModel(ctx, g, repo, versionedModelIDs[0], "modified by others", 2)
+
+		// not found
+		e = repo.Delete(ctx, &VersionedModel{ID: uuid.MustParse("00000000-0000-4000-8000-000000000000"), Version: types.Version{Version: 1}})
+		g.Expect(errors.Is(e, data.ErrorOptimisticLockFailure)).To(gomega.BeTrue(), "Delete should fail on missing record")
+	}
+}
+
+func SubTestWithoutVersionCheck(di *versionTestDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		repo := di.Factory.NewCRUD(&VersionedModel{})
+		e := repo.Update(ctx, &VersionedModel{ID: versionedModelIDs[0]}, map[string]interface{}{"Value": "updated"})
+		g.Expect(e).To(gomega.Succeed(), "Update without known version shouldn't fail")
+		assertVersionedModel(ctx, g, repo, versionedModelIDs[0], "updated", 1)
+
+		e = repo.DeleteBy(ctx, Where("value = ?", "second"))
+		g.Expect(e).To(gomega.Succeed(), "DeleteBy shouldn't fail")
+		count, e := repo.CountAll(ctx)
+		g.Expect(e).To(gomega.Succeed(), "CountAll shouldn't fail")
+		g.Expect(count).To(gomega.Equal(1), "DeleteBy should be applied")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func loadVersionedModel(ctx context.Context, g *gomega.WithT, repo CrudRepository, id uuid.UUID) *VersionedModel {
+	var model VersionedModel
+	e := repo.FindById(ctx, &model, id)
+	g.Expect(e).To(gomega.Succeed(), "FindById shouldn't fail")
+	return &model
+}
+
+func assertVersionedModel(ctx context.Context, g *gomega.WithT, repo CrudRepository, id uuid.UUID, value string, version int) {
+	model := loadVersionedModel(ctx, g, repo, id)
+	g.Expect(model.Value).To(gomega.Equal(value), "stored value should be correct")
+	g.Expect(model.Version.Version).To(gomega.BeEquivalentTo(version), "stored version should be correct")
+}
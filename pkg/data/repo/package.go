@@ -18,10 +18,12 @@ package repo
 
 import (
 	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/log"
 	"go.uber.org/fx"
 )
 
-//var logger = log.New("DB.Repo")
+var logger = log.New("DB.Repo")
 
 var globalFactory Factory
 
@@ -35,7 +37,19 @@ var Module = &bootstrap.Module{
 	},
 }
 
-func initialize(factory Factory) {
-	globalFactory = factory
-	defaultUtils = newGormUtils(factory.(*GormFactory))
+type initDI struct {
+	fx.In
+	Factory    Factory
+	Properties data.DataProperties `optional:"true"`
+}
+
+func initialize(di initDI) {
+	globalFactory = di.Factory
+	defaultUtils = newGormUtils(di.Factory.(*GormFactory))
+	if len(di.Properties.Pagination.CursorSecret) == 0 {
+		logger.Warnf(`"data.pagination.cursor-secret" is not set. Continuation tokens of repo.Cursor are signed with a random secret ` +
+			`and are rejected by other instances and after restart. Set the same secret on all instances if cursor pagination is used.`)
+		return
+	}
+	SetCursorSigningKey([]byte(di.Properties.Pagination.CursorSecret))
 }
@@ -0,0 +1,126 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package data
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	gormPluginStatementTimeout  = gormCallbackPrefix + "statement_timeout"
+	gormSettingStatementTimeout = gormCallbackPrefix + "statement_timeout"
+	gormInstanceKeyTimeoutState = gormCallbackPrefix + "statement_timeout_state"
+)
+
+type ckStatementTimeout struct{}
+
+// WithStatementTimeout returns a context that overrides the default statement timeout of any SQL statement executed with it.
+// Zero or negative value disables the timeout.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, ckStatementTimeout{}, timeout)
+}
+
+// StatementTimeout is used as a scope for gorm.DB to override the default statement timeout.
+// Zero or negative value disables the timeout.
+// e.g. db.WithContext(ctx).Scopes(StatementTimeout(5 * time.Second)).Find(...)
+func StatementTimeout(timeout time.Duration) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(gormSettingStatementTimeout, timeout)
+	}
+}
+
+// statementTimeoutPlugin applies timeout to each SQL statement, by wrapping the statement's context.
+// Note: Row and Rows are not covered, because results are consumed after the callbacks are finished.
+type statementTimeoutPlugin struct {
+	timeout time.Duration
+}
+
+type statementTimeoutState struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// Name implements gorm.Plugin
+func (p statementTimeoutPlugin) Name() string {
+	return gormPluginStatementTimeout
+}
+
+// Initialize implements gorm.Plugin. This function register callbacks wrapping all other callbacks
+func (p statementTimeoutPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	errs := []error{
+		cb.Create().Before("*").Register(p.cbName("before_create"), p.before),
+		cb.Create().After("*").Register(p.cbName("after_create"), p.after),
+		cb.Query().Before("*").Register(p.cbName("before_query"), p.before),
+		cb.Query().After("*").Register(p.cbName("after_query"), p.after),
+		cb.Update().Before("*").Register(p.cbName("before_update"), p.before),
+		cb.Update().After("*").Register(p.cbName("after_update"), p.after),
+		cb.Delete().Before("*").Register(p.cbName("before_delete"), p.before),
+		cb.Delete().After("*").Register(p.cbName("after_delete"), p.after),
+		cb.Raw().Before("*").Register(p.cbName("before_raw"), p.before),
+		cb.Raw().After("*").Register(p.cbName("after_raw"), p.after),
+	}
+	for _, e := range errs {
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (p statementTimeoutPlugin) before(db *gorm.DB) {
+	timeout := p.resolveTimeout(db)
+	if timeout <= 0 || db.Statement.Context == nil {
+		return
+	}
+	parent := db.Statement.Context
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(gormInstanceKeyTimeoutState, &statementTimeoutState{parent: parent, cancel: cancel})
+}
+
+func (p statementTimeoutPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormInstanceKeyTimeoutState)
+	if !ok {
+		return
+	}
+	if state, ok := v.(*statementTimeoutState); ok {
+		state.cancel()
+		db.Statement.Context = state.parent
+	}
+}
+
+func (p statementTimeoutPlugin) cbName(name string) string {
+	return gormPluginStatementTimeout + ":" + name
+}
+
+// resolveTimeout returns timeout overridden by scope or context, if any. Otherwise, default timeout is used
+func (p statementTimeoutPlugin) resolveTimeout(db *gorm.DB) time.Duration {
+	if v, ok := db.Get(gormSettingStatementTimeout); ok {
+		if timeout, ok := v.(time.Duration); ok {
+			return timeout
+		}
+	}
+	if db.Statement.Context != nil {
+		if timeout, ok := db.Statement.Context.Value(ckStatementTimeout{}).(time.Duration); ok {
+			return timeout
+		}
+	}
+	return p.timeout
+}
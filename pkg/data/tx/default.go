@@ -20,8 +20,10 @@ import (
     "context"
     "database/sql"
     "errors"
+    "fmt"
     "github.com/cisco-open/go-lanai/pkg/data"
     "gorm.io/gorm"
+    "time"
 )
 
 var (
@@ -34,6 +36,10 @@ var (
 type DefaultExecuter struct {
 	// maxRetries can be defined by using the FxTransactionExecuterOption and MaxRetries option
 	maxRetries int
+	// retryOptimisticLock can be defined by using the FxTransactionExecuterOption and RetryOnOptimisticLockFailure option
+	retryOptimisticLock bool
+	// statementTimeout can be defined by using the FxTransactionExecuterOption and StatementTimeout option
+	statementTimeout time.Duration
 }
 
 func NewDefaultExecuter(options ...TransactionExecuterOption) TransactionExecuter {
@@ -42,17 +48,21 @@ func NewDefaultExecuter(options ...TransactionExecuterOption) TransactionExecute
 		o(&opts)
 	}
 	return &DefaultExecuter{
-		maxRetries: opts.MaxRetries,
+		maxRetries:          opts.MaxRetries,
+		retryOptimisticLock: opts.RetryOnOptimisticLockFailure,
+		statementTimeout:    opts.StatementTimeout,
 	}
 }
 
 func (r *DefaultExecuter) ExecuteTx(ctx context.Context, db *gorm.DB, opt *sql.TxOptions, txFunc TxFunc) error {
 	retryCount := 0
 
-	// if we're in a transaction, make sure to use that db instead
-	if gormContext, ok := ctx.(GormContext); ok {
-		db = gormContext.DB()
+	// if we're in a transaction of the same datasource, make sure to use that db instead
+	db, e := currentTxDB(ctx, db)
+	if e != nil {
+		return e
 	}
+	ctx = r.withStatementTimeout(ctx)
 	for {
 		err := db.Transaction(func(txDb *gorm.DB) error {
 			txErr := txFunc(NewGormTxContext(ctx, txDb)) //nolint:contextcheck // this is equivalent to context.WithXXX
@@ -61,7 +71,7 @@ func (r *DefaultExecuter) ExecuteTx(ctx context.Context, db *gorm.DB, opt *sql.T
 		if err == nil {
 			return nil
 		}
-		if !ErrIsRetryable(err) {
+		if !ErrIsRetryable(err) && !(r.retryOptimisticLock && errors.Is(err, data.ErrorOptimisticLockFailure)) {
 			return err
 		}
 		retryCount++
@@ -72,10 +82,12 @@ func (r *DefaultExecuter) ExecuteTx(ctx context.Context, db *gorm.DB, opt *sql.T
 }
 
 func (r *DefaultExecuter) Begin(ctx context.Context, db *gorm.DB, opts ...*sql.TxOptions) (context.Context, error) {
-	//if we're in a transaction, make sure to use that db instead
-	if gormContext, ok := ctx.(GormContext); ok {
-		db = gormContext.DB()
+	//if we're in a transaction of the same datasource, make sure to use that db instead
+	db, e := currentTxDB(ctx, db)
+	if e != nil {
+		return ctx, e
 	}
+	ctx = r.withStatementTimeout(ctx)
 	tx := db.Begin(opts...)
 	if tx.Error != nil {
 		return ctx, tx.Error
@@ -138,3 +150,27 @@ func (r *DefaultExecuter) RollbackTo(ctx context.Context, name string) (context.
 	}
 	return ctx, data.NewDataError(data.ErrorCodeInvalidTransaction, ErrTmplSPFailure)
 }
+
+// withStatementTimeout overrides statement timeout of the given context, if configured
+func (r *DefaultExecuter) withStatementTimeout(ctx context.Context) context.Context {
+	if r.statementTimeout == 0 {
+		return ctx
+	}
+	return data.WithStatementTimeout(ctx, r.statementTimeout)
+}
+
+// currentTxDB returns the transaction DB in given context, or given DB if there is no transaction in progress.
+// Transactions across datasources are not atomic, so starting a transaction within a transaction of another
+// datasource results in error
+func currentTxDB(ctx context.Context, db *gorm.DB) (*gorm.DB, error) {
+	gormContext, ok := ctx.(GormContext)
+	if !ok || gormContext.DB() == nil {
+		return db, nil
+	}
+	t := gormContext.DB()
+	if txDS, ds := data.DatasourceName(t), data.DatasourceName(db); txDS != ds {
+		return nil, data.NewDataError(data.ErrorCodeInvalidTransaction, fmt.Sprintf(
+			"transaction of datasource [%s] cannot be started within transaction of datasource [%s]", ds, txDS))
+	}
+	return t, nil
+}
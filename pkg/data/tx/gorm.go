@@ -21,6 +21,7 @@ import (
 	"database/sql"
 	"errors"
 	"gorm.io/gorm"
+	"time"
 )
 
 const (
@@ -79,6 +80,10 @@ func GormTxWithContext(ctx context.Context) (tx *gorm.DB) {
 
 type TransactionExecuterOptions struct {
 	MaxRetries int
+	// RetryOnOptimisticLockFailure also retries the transaction on data.ErrorOptimisticLockFailure
+	RetryOnOptimisticLockFailure bool
+	// StatementTimeout overrides the default statement timeout of SQL statements executed within transactions
+	StatementTimeout time.Duration
 }
 
 type TransactionExecuterOption func(options *TransactionExecuterOptions)
@@ -90,6 +95,24 @@ func MaxRetries(maxRetries int, order int) TransactionExecuterOption {
 	}
 }
 
+// RetryOnOptimisticLockFailure will return a TransactionExecuterOption that enables/disables retrying transactions
+// failed with data.ErrorOptimisticLockFailure. The number of retries is limited by MaxRetries.
+// Note: retry only helps if the transaction function reloads the models it updates.
+func RetryOnOptimisticLockFailure(enabled bool) TransactionExecuterOption {
+	return func(options *TransactionExecuterOptions) {
+		options.RetryOnOptimisticLockFailure = enabled
+	}
+}
+
+// StatementTimeout will return a TransactionExecuterOption that overrides the default statement timeout of
+// SQL statements executed within transactions. Negative value disables the timeout.
+// See data.DatabaseProperties and data.WithStatementTimeout
+func StatementTimeout(timeout time.Duration) TransactionExecuterOption {
+	return func(options *TransactionExecuterOptions) {
+		options.StatementTimeout = timeout
+	}
+}
+
 type TransactionExecuter interface {
 	ExecuteTx(context.Context, *gorm.DB, *sql.TxOptions, TxFunc) error
 	Begin(ctx context.Context, db *gorm.DB, opts ...*sql.TxOptions) (context.Context, error)
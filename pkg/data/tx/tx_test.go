@@ -19,12 +19,18 @@ package tx
 import (
     "context"
     "database/sql"
+    "database/sql/driver"
+    "errors"
+    "github.com/cisco-open/go-lanai/pkg/data"
+    errorutils "github.com/cisco-open/go-lanai/pkg/utils/error"
     "github.com/cisco-open/go-lanai/test"
     "github.com/cisco-open/go-lanai/test/apptest"
     "github.com/onsi/gomega"
     "go.uber.org/fx"
+    "gorm.io/driver/postgres"
     "gorm.io/gorm"
     "testing"
+    "time"
 )
 
 type noopTxManager struct {}
@@ -41,6 +47,58 @@ func (m noopTxManager) WithDB(_ *gorm.DB) GormTxManager {
 	return m
 }
 
+// noopConnPool implements gorm.ConnPool and gorm.ConnPoolBeginner without actual connection
+type noopConnPool struct {
+	gorm.ConnPool
+	onExec func(ctx context.Context)
+}
+
+func (p noopConnPool) BeginTx(_ context.Context, _ *sql.TxOptions) (gorm.ConnPool, error) {
+	return &noopTx{onExec: p.onExec}, nil
+}
+
+// noopTx implements gorm.ConnPool and gorm.TxCommitter without actual connection
+type noopTx struct {
+	gorm.ConnPool
+	onExec func(ctx context.Context)
+}
+
+func (tx *noopTx) ExecContext(ctx context.Context, _ string, _ ...interface{}) (sql.Result, error) {
+	if tx.onExec != nil {
+		tx.onExec(ctx)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (tx *noopTx) Commit() error {
+	return nil
+}
+
+func (tx *noopTx) Rollback() error {
+	return nil
+}
+
+func newNoopDB(g *gomega.WithT) *gorm.DB {
+	db, e := gorm.Open(postgres.New(postgres.Config{
+		Conn: noopConnPool{},
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	g.Expect(e).To(gomega.Succeed(), "DB should be created")
+	return db
+}
+
+// newTimeoutTestDB creates a *gorm.DB with default statement timeout, "onExec" is invoked for each statement in transactions
+func newTimeoutTestDB(timeout time.Duration, onExec func(ctx context.Context)) *gorm.DB {
+	return data.NewGorm(func(cfg *data.GormConfig) {
+		cfg.Dialector = postgres.New(postgres.Config{
+			Conn: noopConnPool{onExec: onExec},
+		})
+		cfg.StatementTimeout = timeout
+	})
+}
+
 /*************************
 	Tests
  *************************/
@@ -56,6 +114,27 @@ func TestOverridingTxManager(t *testing.T) {
 	)
 }
 
+func TestDefaultExecuterRetry(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestRetryOnOptimisticLockFailure(), "TestRetryOnOptimisticLockFailure"),
+		test.GomegaSubTest(SubTestNoRetryOnOptimisticLockFailure(), "TestNoRetryOnOptimisticLockFailure"),
+	)
+}
+
+func TestDefaultExecuterStatementTimeout(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestDefaultStatementTimeout(), "TestDefaultStatementTimeout"),
+		test.GomegaSubTest(SubTestOverrideStatementTimeout(), "TestOverrideStatementTimeout"),
+	)
+}
+
+func TestDefaultExecuterDatasource(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestNestedTransaction(), "TestNestedTransaction"),
+		test.GomegaSubTest(SubTestCrossDatasourceTransaction(), "TestCrossDatasourceTransaction"),
+	)
+}
+
 // TODO more tests
 
 /*************************
@@ -70,4 +149,141 @@ func SubTestNoopTxManager() test.GomegaSubTestFunc {
 		})
 		g.Expect(e).To(gomega.Succeed(), "TxManager shouldn't return error")
 	}
-}
\ No newline at end of file
+}
+
+func SubTestRetryOnOptimisticLockFailure() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		db := newNoopDB(g)
+		executer := NewDefaultExecuter(MaxRetries(2, 0), RetryOnOptimisticLockFailure(true))
+		var count int
+		e := executer.ExecuteTx(ctx, db, nil, func(ctx context.Context) error {
+			if count++; count < 3 {
+				return data.ErrorOptimisticLockFailure
+			}
+			return nil
+		})
+		g.Expect(e).To(gomega.Succeed(), "transaction should succeed after retries")
+		g.Expect(count).To(gomega.Equal(3), "transaction should be retried")
+
+		count = 0
+		e = executer.ExecuteTx(ctx, db, nil, func(ctx context.Context) error {
+			count++
+			return data.ErrorOptimisticLockFailure
+		})
+		g.Expect(errors.Is(e, ErrExceededMaxRetries)).To(gomega.BeTrue(), "transaction should fail after max retries")
+		g.Expect(count).To(gomega.Equal(3), "transaction should be retried up to max retries")
+	}
+}
+
+func SubTestNoRetryOnOptimisticLockFailure() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		db := newNoopDB(g)
+		executer := NewDefaultExecuter(MaxRetries(2, 0))
+		var count int
+		e := executer.ExecuteTx(ctx, db, nil, func(ctx context.Context) error {
+			count++
+			return data.ErrorOptimisticLockFailure
+		})
+		g.Expect(errors.Is(e, data.ErrorOptimisticLockFailure)).To(gomega.BeTrue(), "error should be returned")
+		g.Expect(count).To(gomega.Equal(1), "transaction should not be retried by default")
+	}
+}
+
+func SubTestDefaultStatementTimeout() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		var deadline time.Time
+		var ok bool
+		db := newTimeoutTestDB(time.Minute, func(ctx context.Context) {
+			deadline, ok = ctx.Deadline()
+		})
+		e := NewDefaultExecuter().ExecuteTx(ctx, db, nil, func(ctx context.Context) error {
+			return GormTxWithContext(ctx).Exec("SELECT 1").Error
+		})
+		g.Expect(e).To(gomega.Succeed(), "transaction should succeed")
+		g.Expect(ok).To(gomega.BeTrue(), "statement should have deadline")
+		g.Expect(time.Until(deadline)).To(gomega.BeNumerically("~", time.Minute, time.Second), "default timeout should be used")
+	}
+}
+
+func SubTestOverrideStatementTimeout() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		var deadline time.Time
+		var ok bool
+		db := newTimeoutTestDB(time.Minute, func(ctx context.Context) {
+			deadline, ok = ctx.Deadline()
+		})
+
+		// executer option
+		executer := NewDefaultExecuter(StatementTimeout(10 * time.Second))
+		e := executer.ExecuteTx(ctx, db, nil, func(ctx context.Context) error {
+			return GormTxWithContext(ctx).Exec("SELECT 1").Error
+		})
+		g.Expect(e).To(gomega.Succeed(), "transaction should succeed")
+		g.Expect(ok).To(gomega.BeTrue(), "statement should have deadline")
+		g.Expect(time.Until(deadline)).To(gomega.BeNumerically("~", 10*time.Second, time.Second), "executer's timeout should be used")
+
+		// disabled
+		executer = NewDefaultExecuter(StatementTimeout(-1))
+		e = executer.ExecuteTx(ctx, db, nil, func(ctx context.Context) error {
+			return GormTxWithContext(ctx).Exec("SELECT 1").Error
+		})
+		g.Expect(e).To(gomega.Succeed(), "transaction should succeed")
+		g.Expect(ok).To(gomega.BeFalse(), "statement should not have deadline")
+	}
+}
+
+func SubTestNestedTransaction() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		db := newNamedTestDB("reporting")
+		executer := NewDefaultExecuter()
+		e := executer.ExecuteTx(ctx, db, nil, func(outer context.Context) error {
+			return executer.ExecuteTx(outer, db, nil, func(inner context.Context) error {
+				g.Expect(GormTxWithContext(inner).Statement.ConnPool).To(gomega.BeIdenticalTo(GormTxWithContext(outer).Statement.ConnPool),
+					"nested transaction of same datasource should join the outer one")
+				return nil
+			})
+		})
+		g.Expect(e).To(gomega.Succeed(), "nested transaction of same datasource should succeed")
+	}
+}
+
+func SubTestCrossDatasourceTransaction() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		defaultDB, reportingDB := newNamedTestDB(""), newNamedTestDB("reporting")
+		executer := NewDefaultExecuter()
+		var invoked bool
+		e := executer.ExecuteTx(ctx, defaultDB, nil, func(ctx context.Context) error {
+			e := executer.ExecuteTx(ctx, reportingDB, nil, func(ctx context.Context) error {
+				invoked = true
+				return nil
+			})
+			assertInvalidTransaction(g, e)
+
+			_, e = executer.Begin(ctx, reportingDB)
+			assertInvalidTransaction(g, e)
+			return nil
+		})
+		g.Expect(e).To(gomega.Succeed(), "outer transaction should succeed")
+		g.Expect(invoked).To(gomega.BeFalse(), "transaction of another datasource should not be started")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func newNamedTestDB(name string) *gorm.DB {
+	return data.NewGorm(func(cfg *data.GormConfig) {
+		cfg.Name = name
+		cfg.Dialector = postgres.New(postgres.Config{
+			Conn: noopConnPool{},
+		})
+	})
+}
+
+func assertInvalidTransaction(g *gomega.WithT, e error) {
+	g.Expect(e).To(gomega.HaveOccurred(), "transaction within transaction of another datasource should fail")
+	var coder errorutils.ErrorCoder
+	g.Expect(errors.As(e, &coder)).To(gomega.BeTrue(), "error should be a coded error")
+	g.Expect(coder.Code()).To(gomega.BeEquivalentTo(data.ErrorCodeInvalidTransaction), "error should have correct code")
+}
@@ -0,0 +1,284 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"context"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/security"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/cisco-open/go-lanai/pkg/utils/order"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+const (
+	gormPluginAudit = "lanai:audit"
+	fieldCreatedBy  = "CreatedBy"
+	fieldUpdatedBy  = "UpdatedBy"
+	colUpdatedBy    = "updated_by"
+)
+
+var (
+	typeUUID         = reflect.TypeOf(uuid.Nil)
+	mapKeysUpdatedBy = utils.NewStringSet(fieldUpdatedBy, colUpdatedBy)
+)
+
+type ckAuditMode struct{}
+
+const (
+	AuditFlagCreate AuditFlag = 1 << iota
+	AuditFlagUpdate
+)
+
+// AuditFlag bitwise Flag of audit fields population mode
+type AuditFlag uint
+
+const (
+	auditModeDefault = auditMode(AuditFlagCreate | AuditFlagUpdate)
+)
+
+// auditMode enum of audit population mode
+type auditMode uint
+
+func (m auditMode) hasFlags(flags ...AuditFlag) bool {
+	for _, flag := range flags {
+		if m&auditMode(flag) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SkipAudit is used as a scope for gorm.DB to skip populating CreatedBy and UpdatedBy of Audit
+// e.g. db.WithContext(ctx).Scopes(SkipAudit()).Create(...)
+// Note using this scope without context would panic
+func SkipAudit() func(*gorm.DB) *gorm.DB {
+	return AuditFields(0)
+}
+
+// AuditFields is used as a scope for gorm.DB to override which operations populate CreatedBy and UpdatedBy of Audit
+// e.g. db.WithContext(ctx).Scopes(AuditFields(AuditFlagCreate)).Save(...)
+// Note using this scope without context would panic
+func AuditFields(flags ...AuditFlag) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if tx.Statement.Context == nil {
+			panic("SkipAudit used without context")
+		}
+		var mode auditMode
+		for _, flag := range flags {
+			mode = mode | auditMode(flag)
+		}
+		ctx := context.WithValue(tx.Statement.Context, ckAuditMode{}, mode)
+		tx.Statement.Context = ctx
+		return tx
+	}
+}
+
+// SkipAudit is used for embedding models to skip populating CreatedBy and UpdatedBy.
+// It should be called within model's hooks. this function would panic if context is not set yet
+func (Audit) SkipAudit(tx *gorm.DB) {
+	SkipAudit()(tx)
+}
+
+// AuditorResolver resolves the user ID used as CreatedBy and UpdatedBy from current security.Authentication.
+// uuid.Nil should be returned if the user cannot be resolved, in which case audit fields are not populated.
+type AuditorResolver func(ctx context.Context, auth security.Authentication) uuid.UUID
+
+// DefaultAuditorResolver resolves user ID from security.UserDetails of the given security.Authentication
+func DefaultAuditorResolver(_ context.Context, auth security.Authentication) uuid.UUID {
+	if auth == nil {
+		return uuid.Nil
+	}
+	details, ok := auth.Details().(security.UserDetails)
+	if !ok {
+		return uuid.Nil
+	}
+	id, e := uuid.Parse(details.UserId())
+	if e != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+/**************************
+	GormConfigurer
+ **************************/
+
+type auditConfigurer struct {
+	resolver AuditorResolver
+}
+
+// NewAuditGormConfigurer returns a data.GormConfigurer that installs a gorm plugin populating CreatedBy and UpdatedBy
+// of any model with Audit fields on create and update, using the current security.Authentication.
+// If "resolver" is nil, DefaultAuditorResolver is used.
+func NewAuditGormConfigurer(resolver AuditorResolver) data.GormConfigurer {
+	if resolver == nil {
+		resolver = DefaultAuditorResolver
+	}
+	return &auditConfigurer{
+		resolver: resolver,
+	}
+}
+
+func (c auditConfigurer) Order() int {
+	return order.Lowest
+}
+
+func (c auditConfigurer) Configure(config *gorm.Config) {
+	if config.Plugins == nil {
+		config.Plugins = map[string]gorm.Plugin{}
+	}
+	config.Plugins[gormPluginAudit] = &auditGormPlugin{
+		resolver: c.resolver,
+	}
+}
+
+// auditGormPlugin populates CreatedBy and UpdatedBy after model's BeforeCreate and BeforeUpdate hooks
+type auditGormPlugin struct {
+	resolver AuditorResolver
+}
+
+// Name implements gorm.Plugin
+func (p auditGormPlugin) Name() string {
+	return gormPluginAudit
+}
+
+// Initialize implements gorm.Plugin. This function register audit related callbacks
+func (p auditGormPlugin) Initialize(db *gorm.DB) error {
+	if e := db.Callback().Create().After(data.GormCallbackBeforeCreate).Before("gorm:create").
+		Register(gormPluginAudit+":create", p.beforeCreate); e != nil {
+		return e
+	}
+	return db.Callback().Update().After(data.GormCallbackBeforeUpdate).Before("gorm:update").
+		Register(gormPluginAudit+":update", p.beforeUpdate)
+}
+
+func (p auditGormPlugin) beforeCreate(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+	fCreatedBy := p.auditField(tx.Statement.Schema, fieldCreatedBy)
+	fUpdatedBy := p.auditField(tx.Statement.Schema, fieldUpdatedBy)
+	if fCreatedBy == nil && fUpdatedBy == nil {
+		return
+	}
+	userId, ok := p.currentUserId(tx.Statement.Context, AuditFlagCreate)
+	if !ok {
+		return
+	}
+
+	ctx := tx.Statement.Context
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			elem := reflect.Indirect(rv.Index(i))
+			_ = tx.AddError(p.setIfZero(ctx, fCreatedBy, elem, userId))
+			_ = tx.AddError(p.setIfZero(ctx, fUpdatedBy, elem, userId))
+		}
+	case reflect.Struct:
+		_ = tx.AddError(p.setIfZero(ctx, fCreatedBy, rv, userId))
+		_ = tx.AddError(p.setIfZero(ctx, fUpdatedBy, rv, userId))
+	}
+}
+
+func (p auditGormPlugin) beforeUpdate(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+	fUpdatedBy := p.auditField(tx.Statement.Schema, fieldUpdatedBy)
+	if fUpdatedBy == nil {
+		return
+	}
+	userId, ok := p.currentUserId(tx.Statement.Context, AuditFlagUpdate)
+	if !ok {
+		return
+	}
+	if e := p.updateUpdatedBy(tx.Statement.Context, fUpdatedBy, tx.Statement.Dest, userId); e != nil {
+		_ = tx.AddError(e)
+	}
+}
+
+func (p auditGormPlugin) currentUserId(ctx context.Context, flag AuditFlag) (uuid.UUID, bool) {
+	if ctx == nil || shouldSkipAudit(ctx, flag) {
+		return uuid.Nil, false
+	}
+	userId := p.resolver(ctx, security.Get(ctx))
+	return userId, userId != uuid.Nil
+}
+
+// updateUpdatedBy set UpdatedBy to gorm update target.
+// Note: when the target is a map, explicitly set UpdatedBy is not changed
+func (p auditGormPlugin) updateUpdatedBy(ctx context.Context, f *schema.Field, dest interface{}, userId uuid.UUID) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Struct {
+		// not addressable, e.g. db.Model(&model).Updates(Model{...}), we leave it as is
+		return nil
+	}
+	for ; v.Kind() == reflect.Ptr; v = v.Elem() {
+		// SuppressWarnings go:S108 empty block is intended
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || !typeUUID.AssignableTo(v.Type().Elem()) {
+			return fmt.Errorf("cannot populate %s automatically with gorm update target type [%T]", fieldUpdatedBy, dest)
+		}
+		for iter := v.MapRange(); iter.Next(); {
+			if mapKeysUpdatedBy.Has(iter.Key().String()) {
+				return nil
+			}
+		}
+		v.SetMapIndex(reflect.ValueOf(fieldUpdatedBy).Convert(v.Type().Key()), reflect.ValueOf(userId))
+	case reflect.Struct:
+		if v.Type() != f.Schema.ModelType {
+			return nil
+		}
+		return f.Set(ctx, v, userId)
+	}
+	return nil
+}
+
+func (p auditGormPlugin) setIfZero(ctx context.Context, f *schema.Field, rv reflect.Value, userId uuid.UUID) error {
+	if f == nil || rv.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, zero := f.ValueOf(ctx, rv); !zero {
+		return nil
+	}
+	return f.Set(ctx, rv, userId)
+}
+
+func (p auditGormPlugin) auditField(s *schema.Schema, name string) *schema.Field {
+	if f, ok := s.FieldsByName[name]; ok && f.FieldType == typeUUID {
+		return f
+	}
+	return nil
+}
+
+func shouldSkipAudit(ctx context.Context, flag AuditFlag) bool {
+	switch v := ctx.Value(ckAuditMode{}).(type) {
+	case auditMode:
+		return !v.hasFlags(flag)
+	default:
+		return !auditModeDefault.hasFlags(flag)
+	}
+}
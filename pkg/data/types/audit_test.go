@@ -0,0 +1,297 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/security"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/cisco-open/go-lanai/test/sectest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"testing"
+)
+
+const (
+	auditTableSQL = `
+CREATE TABLE IF NOT EXISTS public.test_audit (
+	id UUID NOT NULL,
+	"value" STRING NULL,
+	created_at TIMESTAMPTZ NULL,
+	updated_at TIMESTAMPTZ NULL,
+	created_by UUID NULL,
+	updated_by UUID NULL,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC)
+);`
+	// customAuditor is resolved by testAuditorResolver to MockedOtherUserId
+	customAuditor = "custom-user"
+)
+
+var (
+	MockedUserId      = uuid.MustParse("d3b5e0b4-7f4e-4a3c-9c8f-2c5b0e0c6a01")
+	MockedOtherUserId = uuid.MustParse("4c1f8a52-3d9e-4b7a-8f6d-5e2a1b0c9d02")
+	AuditModelIDs     = []uuid.UUID{
+		uuid.MustParse("0f1e2d3c-4b5a-4697-8877-665544332211"),
+		uuid.MustParse("1a2b3c4d-5e6f-4a8b-9c0d-1e2f3a4b5c6d"),
+		uuid.MustParse("2b3c4d5e-6f7a-4b9c-8d1e-2f3a4b5c6d7e"),
+	}
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+type AuditModel struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value string
+	Audit
+}
+
+func (AuditModel) TableName() string {
+	return "test_audit"
+}
+
+type AuditSkippedModel struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value string
+	Audit
+}
+
+func (AuditSkippedModel) TableName() string {
+	return "test_audit"
+}
+
+func (m *AuditSkippedModel) BeforeCreate(tx *gorm.DB) error {
+	m.SkipAudit(tx)
+	return nil
+}
+
+func testAuditorResolver(ctx context.Context, auth security.Authentication) uuid.UUID {
+	if auth != nil && auth.Principal() == customAuditor {
+		return MockedOtherUserId
+	}
+	return DefaultAuditorResolver(ctx, auth)
+}
+
+func provideAuditGormConfigurer() fx.Annotated {
+	return fx.Annotated{
+		Group: data.GormConfigurerGroup,
+		Target: func() data.GormConfigurer {
+			return NewAuditGormConfigurer(testAuditorResolver)
+		},
+	}
+}
+
+/*************************
+	Test
+ *************************/
+
+type testAuditDI struct {
+	fx.In
+	DB *gorm.DB
+}
+
+func TestAuditPopulation(t *testing.T) {
+	di := &testAuditDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithProperties(
+			"data.logging.level: debug",
+			"log.levels.data: debug",
+		),
+		apptest.WithFxOptions(
+			fx.Provide(provideAuditGormConfigurer()),
+		),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupAuditTestPrepareTable(di)),
+		test.GomegaSubTest(SubTestAuditOnCreate(di), "TestAuditOnCreate"),
+		test.GomegaSubTest(SubTestAuditOnBatchCreate(di), "TestAuditOnBatchCreate"),
+		test.GomegaSubTest(SubTestAuditOnUpdate(di), "TestAuditOnUpdate"),
+		test.GomegaSubTest(SubTestAuditWithoutSecurity(di), "TestAuditWithoutSecurity"),
+		test.GomegaSubTest(SubTestSkipAudit(di), "TestSkipAudit"),
+		test.GomegaSubTest(SubTestCustomAuditorResolver(di), "TestCustomAuditorResolver"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupAuditTestPrepareTable(di *testAuditDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		r := di.DB.Exec(auditTableSQL)
+		g.Expect(r.Error).To(Succeed(), "create table if not exists shouldn't fail")
+		r = di.DB.Exec(`TRUNCATE TABLE "test_audit"`)
+		g.Expect(r.Error).To(Succeed(), "truncate table shouldn't fail")
+		return ctx, nil
+	}
+}
+
+func SubTestAuditOnCreate(di *testAuditDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = mockedSecurityWithUser(ctx, MockedUserId)
+		model := AuditModel{ID: AuditModelIDs[0], Value: "test"}
+		r := di.DB.WithContext(ctx).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[0], MockedUserId, MockedUserId)
+
+		// explicitly set values are not changed
+		model = AuditModel{ID: AuditModelIDs[1], Value: "test", Audit: Audit{CreatedBy: MockedOtherUserId}}
+		r = di.DB.WithContext(ctx).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[1], MockedOtherUserId, MockedUserId)
+	}
+}
+
+func SubTestAuditOnBatchCreate(di *testAuditDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = mockedSecurityWithUser(ctx, MockedUserId)
+		models := []*AuditModel{
+			{ID: AuditModelIDs[0], Value: "test1"},
+			{ID: AuditModelIDs[1], Value: "test2"},
+		}
+		r := di.DB.WithContext(ctx).Create(&models)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		for _, m := range models {
+			assertAuditRecord(ctx, g, di.DB, m.ID, MockedUserId, MockedUserId)
+		}
+	}
+}
+
+func SubTestAuditOnUpdate(di *testAuditDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		prepareAuditRecords(ctx, g, di.DB, MockedOtherUserId)
+		ctx = mockedSecurityWithUser(ctx, MockedUserId)
+
+		// struct
+		model := loadAuditRecord(ctx, g, di.DB, AuditModelIDs[0])
+		model.Value = "updated"
+		r := di.DB.WithContext(ctx).Save(model)
+		g.Expect(r.Error).To(Succeed(), "save should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[0], MockedOtherUserId, MockedUserId)
+
+		// map
+		values := map[string]interface{}{"Value": "updated"}
+		r = di.DB.WithContext(ctx).Model(&AuditModel{ID: AuditModelIDs[1]}).Updates(values)
+		g.Expect(r.Error).To(Succeed(), "updates should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "updates should affect one row")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[1], MockedOtherUserId, MockedUserId)
+
+		// map with explicit value
+		values = map[string]interface{}{"Value": "updated", colUpdatedBy: MockedOtherUserId}
+		r = di.DB.WithContext(ctx).Model(&AuditModel{ID: AuditModelIDs[2]}).Updates(values)
+		g.Expect(r.Error).To(Succeed(), "updates should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "updates should affect one row")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[2], MockedOtherUserId, MockedOtherUserId)
+	}
+}
+
+func SubTestAuditWithoutSecurity(di *testAuditDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		model := AuditModel{ID: AuditModelIDs[0], Value: "test"}
+		r := di.DB.WithContext(ctx).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[0], uuid.Nil, uuid.Nil)
+	}
+}
+
+func SubTestSkipAudit(di *testAuditDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = mockedSecurityWithUser(ctx, MockedUserId)
+
+		// scope
+		model := AuditModel{ID: AuditModelIDs[0], Value: "test"}
+		r := di.DB.WithContext(ctx).Scopes(SkipAudit()).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[0], uuid.Nil, uuid.Nil)
+
+		// hook
+		skipped := AuditSkippedModel{ID: AuditModelIDs[1], Value: "test"}
+		r = di.DB.WithContext(ctx).Create(&skipped)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[1], uuid.Nil, uuid.Nil)
+
+		// partial
+		model = AuditModel{ID: AuditModelIDs[2], Value: "test"}
+		r = di.DB.WithContext(ctx).Scopes(AuditFields(AuditFlagCreate)).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		model.Value = "updated"
+		r = di.DB.WithContext(ctx).Scopes(AuditFields(AuditFlagCreate)).Save(&model)
+		g.Expect(r.Error).To(Succeed(), "save should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[2], MockedUserId, MockedUserId)
+		r = di.DB.WithContext(ctx).Scopes(AuditFields(AuditFlagCreate)).
+			Model(&AuditModel{ID: AuditModelIDs[0]}).Updates(map[string]interface{}{"Value": "updated"})
+		g.Expect(r.Error).To(Succeed(), "updates should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[0], uuid.Nil, uuid.Nil)
+	}
+}
+
+func SubTestCustomAuditorResolver(di *testAuditDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = mockedSecurityWithPrincipal(ctx, customAuditor, MockedUserId)
+		model := AuditModel{ID: AuditModelIDs[0], Value: "test"}
+		r := di.DB.WithContext(ctx).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		assertAuditRecord(ctx, g, di.DB, AuditModelIDs[0], MockedOtherUserId, MockedOtherUserId)
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func mockedSecurityWithUser(ctx context.Context, userId uuid.UUID) context.Context {
+	return mockedSecurityWithPrincipal(ctx, "test-user", userId)
+}
+
+func mockedSecurityWithPrincipal(ctx context.Context, username string, userId uuid.UUID) context.Context {
+	return sectest.WithMockedSecurity(ctx, func(m *sectest.SecurityDetailsMock) {
+		m.Username = username
+		m.UserId = userId.String()
+	})
+}
+
+// prepareAuditRecords create records with all AuditModelIDs, populated by given user
+func prepareAuditRecords(ctx context.Context, g *gomega.WithT, db *gorm.DB, userId uuid.UUID) {
+	models := make([]*AuditModel, len(AuditModelIDs))
+	for i, id := range AuditModelIDs {
+		models[i] = &AuditModel{ID: id, Value: "original"}
+	}
+	r := db.WithContext(mockedSecurityWithUser(ctx, userId)).Create(&models)
+	g.Expect(r.Error).To(Succeed(), "create should not fail")
+}
+
+func loadAuditRecord(ctx context.Context, g *gomega.WithT, db *gorm.DB, id uuid.UUID) *AuditModel {
+	var model AuditModel
+	r := db.WithContext(ctx).Take(&model, id)
+	g.Expect(r.Error).To(Succeed(), "load record [%v] should not fail", id)
+	return &model
+}
+
+func assertAuditRecord(ctx context.Context, g *gomega.WithT, db *gorm.DB, id uuid.UUID, expectedCreatedBy, expectedUpdatedBy uuid.UUID) {
+	model := loadAuditRecord(ctx, g, db, id)
+	g.Expect(model.CreatedBy).To(Equal(expectedCreatedBy), "CreatedBy of record [%v] should be correct", id)
+	g.Expect(model.UpdatedBy).To(Equal(expectedUpdatedBy), "UpdatedBy of record [%v] should be correct", id)
+}
@@ -19,6 +19,7 @@ package types
 import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"reflect"
 )
 
 const (
@@ -60,3 +61,22 @@ func (sm NoopStatementModifier) Build(clause.Builder) {
 func (sm NoopStatementModifier) MergeClause(*clause.Clause) {
 	// noop
 }
+
+// structValues returns struct values of given struct, slice or array
+func structValues(rv reflect.Value) []reflect.Value {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		rows := make([]reflect.Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			if elem := reflect.Indirect(rv.Index(i)); elem.Kind() == reflect.Struct {
+				rows = append(rows, elem)
+			}
+		}
+		return rows
+	case reflect.Struct:
+		return []reflect.Value{rv}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/security"
+	"github.com/cisco-open/go-lanai/pkg/tracing"
+	"github.com/cisco-open/go-lanai/pkg/utils/order"
+	"github.com/cisco-open/go-lanai/pkg/utils/reflectutils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	gormPluginHistory    = "lanai:history"
+	historySettingsKey   = "lanai:history:snapshots"
+	historyTableSuffix   = "_history"
+	historyRedactedValue = "******"
+	fieldTenantID        = "TenantID"
+	TagHistory           = "history"
+	// HistoryTagExclude excludes the field from change history, e.g. `history:"-"`
+	HistoryTagExclude = "-"
+	// HistoryTagRedact records changes of the field without values, e.g. `history:"redact"`
+	HistoryTagRedact = "redact"
+)
+
+var (
+	typeHistory = reflect.TypeOf(History{})
+)
+
+const (
+	HistoryOperationCreate HistoryOperation = "create"
+	HistoryOperationUpdate HistoryOperation = "update"
+	HistoryOperationDelete HistoryOperation = "delete"
+)
+
+type HistoryOperation string
+
+// History is an embedded type for data model, which enables change history of the model.
+// When used as an embedded type, a row is written to the companion history table (see HistoryTableName)
+// on every create, update and delete, within the same transaction.
+// Fields can be excluded from history with tag `history:"-"`, or recorded without values with tag `history:"redact"`.
+// Values of SensitiveData (e.g. pqcrypt.EncryptedMap) are always redacted.
+// e.g.
+// <code>
+//
+//	type HistoryModel struct {
+//			ID         uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid();"`
+//			Value      string
+//			Secret     string `history:"redact"`
+//			types.History
+//	}
+//
+// </code>
+type History struct{}
+
+// HistoryTabler can be implemented by models with History to override the companion history table name.
+// By default, the history table name is model's table name with suffix "_history"
+type HistoryTabler interface {
+	HistoryTableName() string
+}
+
+// SensitiveData is implemented by data types holding sensitive values, which should never be written in plaintext.
+// e.g. pqcrypt.EncryptedMap
+type SensitiveData interface {
+	IsSensitive() bool
+}
+
+// HistoryRecord is a row of companion history table
+type HistoryRecord struct {
+	ID        uuid.UUID        `gorm:"primaryKey;type:uuid;default:gen_random_uuid();" json:"id"`
+	EntityID  string           `gorm:"index;not null" json:"entityId"`
+	Operation HistoryOperation `gorm:"not null" json:"operation"`
+	Changes   HistoryChanges   `gorm:"type:jsonb;not null" json:"changes"`
+	ActorID   *uuid.UUID       `gorm:"type:uuid" json:"actorId,omitempty"`
+	TenantID  *uuid.UUID       `gorm:"type:uuid" json:"tenantId,omitempty"`
+	TraceID   string           `json:"traceId,omitempty"`
+	CreatedAt time.Time        `gorm:"index" json:"createdAt"`
+}
+
+// HistoryChanges changed columns with old and new values, keyed by column name
+type HistoryChanges map[string]HistoryChange
+
+// Value implements driver.Valuer
+func (c HistoryChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, e := json.Marshal(c)
+	return string(data), e
+}
+
+// Scan implements sql.Scanner
+func (c *HistoryChanges) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("unable to scan %T into HistoryChanges", src)
+	}
+}
+
+// HistoryChange old and new value of a column. Old value is nil on create, and new value is nil on delete
+type HistoryChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// HistoryTableName returns the companion history table name of given model
+func HistoryTableName(db *gorm.DB, model interface{}) (string, error) {
+	if tabler, ok := model.(HistoryTabler); ok {
+		return tabler.HistoryTableName(), nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if e := stmt.Parse(model); e != nil {
+		return "", e
+	}
+	return stmt.Schema.Table + historyTableSuffix, nil
+}
+
+// IsHistoryEnabled returns true if given schema has History embedded
+func IsHistoryEnabled(s *schema.Schema) bool {
+	_, ok := reflectutils.FindStructField(s.ModelType, func(t reflect.StructField) bool {
+		return t.Anonymous && t.Type == typeHistory
+	})
+	return ok
+}
+
+/**************************
+	GormConfigurer
+ **************************/
+
+type historyConfigurer struct {
+	resolver AuditorResolver
+}
+
+// NewHistoryGormConfigurer returns a data.GormConfigurer that installs a gorm plugin writing change history
+// of any model with History embedded. "resolver" is used to resolve actor of the change.
+// If "resolver" is nil, DefaultAuditorResolver is used.
+func NewHistoryGormConfigurer(resolver AuditorResolver) data.GormConfigurer {
+	if resolver == nil {
+		resolver = DefaultAuditorResolver
+	}
+	return &historyConfigurer{
+		resolver: resolver,
+	}
+}
+
+func (c historyConfigurer) Order() int {
+	return order.Lowest
+}
+
+func (c historyConfigurer) Configure(config *gorm.Config) {
+	if config.Plugins == nil {
+		config.Plugins = map[string]gorm.Plugin{}
+	}
+	config.Plugins[gormPluginHistory] = &historyGormPlugin{
+		resolver: c.resolver,
+	}
+}
+
+// historyGormPlugin takes snapshots of affected rows before update and delete, and writes history records after
+// create, update and delete. All queries are executed using the same connection of the original statement,
+// so history records are part of the same transaction.
+type historyGormPlugin struct {
+	resolver AuditorResolver
+}
+
+// Name implements gorm.Plugin
+func (p historyGormPlugin) Name() string {
+	return gormPluginHistory
+}
+
+// Initialize implements gorm.Plugin. This function register history related callbacks
+func (p historyGormPlugin) Initialize(db *gorm.DB) error {
+	if e := db.Callback().Create().After("gorm:create").Before(data.GormCallbackAfterCreate).
+		Register(gormPluginHistory+":create", p.afterCreate); e != nil {
+		return e
+	}
+	if e := db.Callback().Update().After(data.GormCallbackBeforeUpdate).Before("gorm:update").
+		Register(gormPluginHistory+":before_update", p.takeSnapshots); e != nil {
+		return e
+	}
+	if e := db.Callback().Update().After("gorm:update").Before(data.GormCallbackAfterUpdate).
+		Register(gormPluginHistory+":update", p.afterUpdate); e != nil {
+		return e
+	}
+	if e := db.Callback().Delete().After(data.GormCallbackBeforeDelete).Before("gorm:delete").
+		Register(gormPluginHistory+":before_delete", p.takeSnapshots); e != nil {
+		return e
+	}
+	return db.Callback().Delete().After("gorm:delete").Before(data.GormCallbackAfterDelete).
+		Register(gormPluginHistory+":delete", p.afterDelete)
+}
+
+func (p historyGormPlugin) afterCreate(tx *gorm.DB) {
+	if !p.isApplicable(tx) {
+		return
+	}
+	rows := structValues(tx.Statement.ReflectValue)
+	records := make([]*HistoryRecord, 0, len(rows))
+	for _, row := range rows {
+		changes := p.diff(tx.Statement.Context, tx.Statement.Schema, reflect.Value{}, row)
+		records = append(records, p.newRecord(tx, HistoryOperationCreate, row, changes))
+	}
+	_ = tx.AddError(p.save(tx, records))
+}
+
+func (p historyGormPlugin) afterUpdate(tx *gorm.DB) {
+	if !p.isApplicable(tx) {
+		return
+	}
+	snapshots, ok := p.snapshots(tx)
+	if !ok || len(snapshots) == 0 {
+		return
+	}
+	current, e := p.load(tx, func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Where(p.primaryKeyCondition(tx.Statement.Context, tx.Statement.Schema, snapshots))
+	})
+	if e != nil {
+		_ = tx.AddError(e)
+		return
+	}
+	byID := make(map[string]reflect.Value, len(current))
+	for _, row := range current {
+		byID[p.entityID(tx.Statement.Context, tx.Statement.Schema, row)] = row
+	}
+	records := make([]*HistoryRecord, 0, len(snapshots))
+	for _, old := range snapshots {
+		row, ok := byID[p.entityID(tx.Statement.Context, tx.Statement.Schema, old)]
+		if !ok {
+			continue
+		}
+		if changes := p.diff(tx.Statement.Context, tx.Statement.Schema, old, row); len(changes) != 0 {
+			records = append(records, p.newRecord(tx, HistoryOperationUpdate, row, changes))
+		}
+	}
+	_ = tx.AddError(p.save(tx, records))
+}
+
+func (p historyGormPlugin) afterDelete(tx *gorm.DB) {
+	if !p.isApplicable(tx) {
+		return
+	}
+	snapshots, ok := p.snapshots(tx)
+	if !ok {
+		return
+	}
+	records := make([]*HistoryRecord, 0, len(snapshots))
+	for _, old := range snapshots {
+		changes := p.diff(tx.Statement.Context, tx.Statement.Schema, old, reflect.Value{})
+		records = append(records, p.newRecord(tx, HistoryOperationDelete, old, changes))
+	}
+	_ = tx.AddError(p.save(tx, records))
+}
+
+// takeSnapshots load rows that would be affected by the update/delete statement
+func (p historyGormPlugin) takeSnapshots(tx *gorm.DB) {
+	if !p.isApplicable(tx) {
+		return
+	}
+	var conds []clause.Expression
+	if c, ok := tx.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conds = append(conds, where.Exprs...)
+		}
+	}
+	// primary keys are added to WHERE clause by gorm:update/gorm:delete, so we need to do the same
+	if rows := structValues(tx.Statement.ReflectValue); len(rows) != 0 {
+		if pk := p.primaryKeyCondition(tx.Statement.Context, tx.Statement.Schema, rows); pk != nil {
+			conds = append(conds, pk)
+		}
+	}
+	if len(conds) == 0 && !tx.AllowGlobalUpdate {
+		// gorm would reject the statement anyway
+		return
+	}
+	snapshots, e := p.load(tx, func(db *gorm.DB) *gorm.DB {
+		if tx.Statement.Unscoped {
+			db = db.Unscoped()
+		}
+		if len(conds) == 0 {
+			return db
+		}
+		return db.Clauses(clause.Where{Exprs: conds})
+	})
+	if e != nil {
+		_ = tx.AddError(e)
+		return
+	}
+	tx.InstanceSet(historySettingsKey, snapshots)
+}
+
+func (p historyGormPlugin) isApplicable(tx *gorm.DB) bool {
+	return tx.Error == nil && tx.Statement.Schema != nil && IsHistoryEnabled(tx.Statement.Schema)
+}
+
+func (p historyGormPlugin) snapshots(tx *gorm.DB) ([]reflect.Value, bool) {
+	v, ok := tx.InstanceGet(historySettingsKey)
+	if !ok {
+		return nil, false
+	}
+	return v.([]reflect.Value), true
+}
+
+// load query rows of the statement's model using the same connection and context, with given scope
+func (p historyGormPlugin) load(tx *gorm.DB, scope func(db *gorm.DB) *gorm.DB) ([]reflect.Value, error) {
+	dest := reflect.New(reflect.SliceOf(tx.Statement.Schema.ModelType))
+	db := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(tx.Statement.Table)
+	if r := scope(db).Find(dest.Interface()); r.Error != nil {
+		return nil, r.Error
+	}
+	return structValues(dest.Elem()), nil
+}
+
+func (p historyGormPlugin) save(tx *gorm.DB, records []*HistoryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	table, e := HistoryTableName(tx, reflect.New(tx.Statement.Schema.ModelType).Interface())
+	if e != nil {
+		return e
+	}
+	return tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(table).Create(records).Error
+}
+
+func (p historyGormPlugin) newRecord(tx *gorm.DB, op HistoryOperation, row reflect.Value, changes HistoryChanges) *HistoryRecord {
+	ctx := tx.Statement.Context
+	record := HistoryRecord{
+		ID:        uuid.New(),
+		EntityID:  p.entityID(ctx, tx.Statement.Schema, row),
+		Operation: op,
+		Changes:   changes,
+		CreatedAt: tx.NowFunc(),
+	}
+	auth := security.Get(ctx)
+	if actor := p.resolver(ctx, auth); actor != uuid.Nil {
+		record.ActorID = &actor
+	}
+	if f, ok := tx.Statement.Schema.FieldsByName[fieldTenantID]; ok && f.FieldType == typeUUID {
+		if v, zero := f.ValueOf(ctx, row); !zero {
+			tenantID := v.(uuid.UUID)
+			record.TenantID = &tenantID
+		}
+	} else if details, ok := auth.Details().(security.TenantDetails); ok {
+		if tenantID, e := uuid.Parse(details.TenantId()); e == nil {
+			record.TenantID = &tenantID
+		}
+	}
+	if traceID := tracing.TraceIdFromContext(ctx); traceID != nil {
+		record.TraceID = fmt.Sprint(traceID)
+	}
+	return &record
+}
+
+// diff compares old and new row and returns changed columns. Invalid "old" or "new" means the row doesn't exist.
+func (p historyGormPlugin) diff(ctx context.Context, s *schema.Schema, old, new reflect.Value) HistoryChanges {
+	changes := HistoryChanges{}
+	for _, f := range s.Fields {
+		tag := f.Tag.Get(TagHistory)
+		if f.DBName == "" || tag == HistoryTagExclude {
+			continue
+		}
+		var ov, nv interface{}
+		var oZero, nZero = true, true
+		if old.IsValid() {
+			ov, oZero = f.ValueOf(ctx, old)
+		}
+		if new.IsValid() {
+			nv, nZero = f.ValueOf(ctx, new)
+		}
+		if oZero && nZero || old.IsValid() && new.IsValid() && reflect.DeepEqual(ov, nv) {
+			continue
+		}
+		change := HistoryChange{Old: ov, New: nv}
+		if tag == HistoryTagRedact || isSensitive(f) {
+			change = HistoryChange{}
+			if !oZero {
+				change.Old = historyRedactedValue
+			}
+			if !nZero {
+				change.New = historyRedactedValue
+			}
+		}
+		changes[f.DBName] = change
+	}
+	return changes
+}
+
+func (p historyGormPlugin) entityID(ctx context.Context, s *schema.Schema, row reflect.Value) string {
+	ids := make([]string, len(s.PrimaryFields))
+	for i, f := range s.PrimaryFields {
+		v, _ := f.ValueOf(ctx, row)
+		ids[i] = fmt.Sprint(v)
+	}
+	return strings.Join(ids, ",")
+}
+
+// primaryKeyCondition returns condition matching primary keys of given rows. Rows with zero primary key are ignored.
+func (p historyGormPlugin) primaryKeyCondition(ctx context.Context, s *schema.Schema, rows []reflect.Value) clause.Expression {
+	if len(s.PrimaryFields) == 0 {
+		return nil
+	}
+	var exprs []clause.Expression
+	for _, row := range rows {
+		conds := make([]clause.Expression, 0, len(s.PrimaryFields))
+		for _, f := range s.PrimaryFields {
+			v, zero := f.ValueOf(ctx, row)
+			if zero {
+				conds = nil
+				break
+			}
+			conds = append(conds, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Value: v})
+		}
+		if len(conds) != 0 {
+			exprs = append(exprs, clause.And(conds...))
+		}
+	}
+	if len(exprs) == 0 {
+		return nil
+	}
+	return clause.Or(exprs...)
+}
+
+func isSensitive(f *schema.Field) bool {
+	t := f.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Implements(reflect.TypeOf((*SensitiveData)(nil)).Elem()) ||
+		reflect.PointerTo(t).Implements(reflect.TypeOf((*SensitiveData)(nil)).Elem())
+}
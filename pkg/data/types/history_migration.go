@@ -0,0 +1,69 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/migration"
+	"gorm.io/gorm"
+)
+
+// NewHistoryMigration returns a migration step of given version that creates companion history tables of given models.
+// See HistoryTableName. The step can be rolled back by dropping the tables. e.g.
+//
+//	func registerMigrations(r *migration.Registrar, db *gorm.DB) {
+//		r.AddMigrations(types.NewHistoryMigration("1.0.0.1", db, &Device{}, &Site{}))
+//	}
+func NewHistoryMigration(version string, db *gorm.DB, models ...interface{}) *migration.Migration {
+	return migration.WithVersion(version).
+		WithTag(migration.TagPreUpgrade).
+		WithDesc("create change history tables").
+		WithFunc(func(ctx context.Context) error {
+			return CreateHistoryTableIfNotExist(ctx, db, models...)
+		}).
+		WithRollbackFunc(func(ctx context.Context) error {
+			return DropHistoryTableIfExists(ctx, db, models...)
+		})
+}
+
+// CreateHistoryTableIfNotExist creates or updates companion history tables of given models
+func CreateHistoryTableIfNotExist(ctx context.Context, db *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		table, e := HistoryTableName(db, model)
+		if e != nil {
+			return e
+		}
+		if e := db.WithContext(ctx).Table(table).AutoMigrate(&HistoryRecord{}); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// DropHistoryTableIfExists drops companion history tables of given models
+func DropHistoryTableIfExists(ctx context.Context, db *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		table, e := HistoryTableName(db, model)
+		if e != nil {
+			return e
+		}
+		if e := db.WithContext(ctx).Migrator().DropTable(table); e != nil {
+			return e
+		}
+	}
+	return nil
+}
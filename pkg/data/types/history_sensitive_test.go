@@ -0,0 +1,147 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/data/types"
+	"github.com/cisco-open/go-lanai/pkg/data/types/pqcrypt"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"testing"
+)
+
+// Note: pqcrypt depends on types, so tests with pqcrypt data types are in external test package.
+
+const sensitiveTableSQL = `
+CREATE TABLE IF NOT EXISTS public.test_sensitive (
+	id UUID NOT NULL,
+	"value" STRING NULL,
+	secret JSONB NULL,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC)
+);`
+
+var (
+	SensitiveModelID = uuid.MustParse("9c8b7a6d-5e4f-4a3b-8c2d-1e0f9a8b7c01")
+	SensitiveKeyID   = uuid.MustParse("0d1e2f3a-4b5c-4d6e-8f7a-9b0c1d2e3f04")
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+type SensitiveModel struct {
+	ID     uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value  string
+	Secret *pqcrypt.EncryptedMap
+	types.History
+}
+
+func (SensitiveModel) TableName() string {
+	return "test_sensitive"
+}
+
+/*************************
+	Test
+ *************************/
+
+type testSensitiveDI struct {
+	fx.In
+	DB *gorm.DB
+}
+
+func TestHistoryWithSensitiveData(t *testing.T) {
+	di := &testSensitiveDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithFxOptions(
+			fx.Provide(fx.Annotated{
+				Group: data.GormConfigurerGroup,
+				Target: func() data.GormConfigurer {
+					return types.NewHistoryGormConfigurer(nil)
+				},
+			}),
+		),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupSensitiveTestPrepareTables(di)),
+		test.GomegaSubTest(SubTestRedactEncryptedMap(di), "TestRedactEncryptedMap"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupSensitiveTestPrepareTables(di *testSensitiveDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		r := di.DB.Exec(sensitiveTableSQL)
+		g.Expect(r.Error).To(Succeed(), "create table if not exists shouldn't fail")
+		e := types.CreateHistoryTableIfNotExist(ctx, di.DB, &SensitiveModel{})
+		g.Expect(e).To(Succeed(), "create history table shouldn't fail")
+		for _, table := range []string{"test_sensitive", "test_sensitive_history"} {
+			r = di.DB.Exec(`TRUNCATE TABLE "` + table + `"`)
+			g.Expect(r.Error).To(Succeed(), "truncate table shouldn't fail")
+		}
+		return ctx, nil
+	}
+}
+
+func SubTestRedactEncryptedMap(di *testSensitiveDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		model := SensitiveModel{
+			ID:     SensitiveModelID,
+			Value:  "test",
+			Secret: pqcrypt.NewEncryptedMap(SensitiveKeyID, map[string]interface{}{"password": "old-password"}),
+		}
+		r := di.DB.WithContext(ctx).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+
+		r = di.DB.WithContext(ctx).Model(&SensitiveModel{ID: SensitiveModelID}).Updates(map[string]interface{}{
+			"Secret": pqcrypt.NewEncryptedMap(SensitiveKeyID, map[string]interface{}{"password": "new-password"}),
+		})
+		g.Expect(r.Error).To(Succeed(), "update should not fail")
+
+		var records []*types.HistoryRecord
+		r = di.DB.WithContext(ctx).Table("test_sensitive_history").
+			Where("entity_id = ?", SensitiveModelID.String()).Order("created_at").Find(&records)
+		g.Expect(r.Error).To(Succeed(), "loading history records should not fail")
+		g.Expect(records).To(HaveLen(2), "history should be written for create and update")
+		g.Expect(records[0].Operation).To(Equal(types.HistoryOperationCreate), "Operation should be correct")
+		g.Expect(records[0].Changes).To(HaveKeyWithValue("secret", types.HistoryChange{New: "******"}),
+			"encrypted field should not be written in plaintext")
+		g.Expect(records[1].Operation).To(Equal(types.HistoryOperationUpdate), "Operation should be correct")
+		g.Expect(records[1].Changes).To(Equal(types.HistoryChanges{
+			"secret": {Old: "******", New: "******"},
+		}), "encrypted field should not be written in plaintext")
+
+		var raw []string
+		r = di.DB.WithContext(ctx).Table("test_sensitive_history").Pluck("changes", &raw)
+		g.Expect(r.Error).To(Succeed(), "loading raw changes should not fail")
+		for _, v := range raw {
+			g.Expect(v).ToNot(ContainSubstring("password"), "encrypted data should not be written in any form")
+		}
+	}
+}
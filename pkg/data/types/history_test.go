@@ -0,0 +1,310 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"testing"
+)
+
+const (
+	historyTableSQL = `
+CREATE TABLE IF NOT EXISTS public.test_history (
+	id UUID NOT NULL,
+	tenant_id UUID NULL,
+	"value" STRING NULL,
+	secret STRING NULL,
+	ignored STRING NULL,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC)
+);`
+	customHistoryTableSQL = `
+CREATE TABLE IF NOT EXISTS public.test_custom (
+	id UUID NOT NULL,
+	"value" STRING NULL,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC)
+);`
+)
+
+var (
+	MockedTenantId  = uuid.MustParse("8e0c2d6a-1b4f-4e5d-9a7c-3f2b1d0e6c03")
+	HistoryModelIDs = []uuid.UUID{
+		uuid.MustParse("5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c01"),
+		uuid.MustParse("6b5c4d3e-2f1a-4b0c-9d8e-7f6a5b4c3d02"),
+	}
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+type HistoryModel struct {
+	ID       uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	TenantID uuid.UUID `gorm:"type:uuid;"`
+	Value    string
+	Secret   string `history:"redact"`
+	Ignored  string `history:"-"`
+	History
+}
+
+func (HistoryModel) TableName() string {
+	return "test_history"
+}
+
+type CustomHistoryModel struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value string
+	History
+}
+
+func (CustomHistoryModel) TableName() string {
+	return "test_custom"
+}
+
+func (CustomHistoryModel) HistoryTableName() string {
+	return "test_custom_changes"
+}
+
+func provideHistoryGormConfigurer() fx.Annotated {
+	return fx.Annotated{
+		Group: data.GormConfigurerGroup,
+		Target: func() data.GormConfigurer {
+			return NewHistoryGormConfigurer(nil)
+		},
+	}
+}
+
+/*************************
+	Test
+ *************************/
+
+type testHistoryDI struct {
+	fx.In
+	DB *gorm.DB
+}
+
+func TestHistory(t *testing.T) {
+	di := &testHistoryDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithFxOptions(
+			fx.Provide(provideHistoryGormConfigurer()),
+		),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupHistoryTestPrepareTables(di)),
+		test.GomegaSubTest(SubTestHistoryOnCreate(di), "TestHistoryOnCreate"),
+		test.GomegaSubTest(SubTestHistoryOnUpdate(di), "TestHistoryOnUpdate"),
+		test.GomegaSubTest(SubTestHistoryOnDelete(di), "TestHistoryOnDelete"),
+		test.GomegaSubTest(SubTestHistoryWithoutChanges(di), "TestHistoryWithoutChanges"),
+		test.GomegaSubTest(SubTestHistoryWithRollback(di), "TestHistoryWithRollback"),
+		test.GomegaSubTest(SubTestHistoryTableName(di), "TestHistoryTableName"),
+		test.GomegaSubTest(SubTestHistoryMigration(di), "TestHistoryMigration"),
+		test.GomegaSubTest(SubTestHistoryChangesValuer(), "TestHistoryChangesValuer"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupHistoryTestPrepareTables(di *testHistoryDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		for _, sql := range []string{historyTableSQL, customHistoryTableSQL, auditTableSQL} {
+			r := di.DB.Exec(sql)
+			g.Expect(r.Error).To(Succeed(), "create table if not exists shouldn't fail")
+		}
+		e := CreateHistoryTableIfNotExist(ctx, di.DB, &HistoryModel{}, &CustomHistoryModel{})
+		g.Expect(e).To(Succeed(), "create history tables shouldn't fail")
+		for _, table := range []string{"test_history", "test_history_history", "test_custom", "test_custom_changes", "test_audit"} {
+			r := di.DB.Exec(`TRUNCATE TABLE "` + table + `"`)
+			g.Expect(r.Error).To(Succeed(), "truncate table shouldn't fail")
+		}
+		return ctx, nil
+	}
+}
+
+func SubTestHistoryOnCreate(di *testHistoryDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = mockedSecurityWithUser(ctx, MockedUserId)
+		models := []*HistoryModel{
+			{ID: HistoryModelIDs[0], TenantID: MockedTenantId, Value: "test1", Secret: "secret", Ignored: "ignored"},
+			{ID: HistoryModelIDs[1], Value: "test2"},
+		}
+		r := di.DB.WithContext(ctx).Create(&models)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+
+		records := loadHistoryRecords(ctx, g, di.DB, "test_history_history", HistoryModelIDs[0])
+		g.Expect(records).To(HaveLen(1), "history should be written")
+		record := records[0]
+		g.Expect(record.ID).ToNot(Equal(uuid.Nil), "record ID should be set")
+		g.Expect(record.Operation).To(Equal(HistoryOperationCreate), "Operation should be correct")
+		g.Expect(record.ActorID).To(HaveValue(Equal(MockedUserId)), "ActorID should be correct")
+		g.Expect(record.TenantID).To(HaveValue(Equal(MockedTenantId)), "TenantID should be correct")
+		g.Expect(record.CreatedAt).ToNot(BeZero(), "CreatedAt should be set")
+		g.Expect(record.Changes).To(HaveKeyWithValue("value", HistoryChange{New: "test1"}), "changes should be correct")
+		g.Expect(record.Changes).To(HaveKeyWithValue("secret", HistoryChange{New: historyRedactedValue}), "redacted field should not be written in plaintext")
+		g.Expect(record.Changes).ToNot(HaveKey("ignored"), "excluded field should not be written")
+
+		records = loadHistoryRecords(ctx, g, di.DB, "test_history_history", HistoryModelIDs[1])
+		g.Expect(records).To(HaveLen(1), "history should be written for each row")
+		g.Expect(records[0].TenantID).To(BeNil(), "TenantID should not be set")
+		g.Expect(records[0].Changes).ToNot(HaveKey("secret"), "unchanged field should not be written")
+	}
+}
+
+func SubTestHistoryOnUpdate(di *testHistoryDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ctx = mockedSecurityWithUser(ctx, MockedUserId)
+		model := HistoryModel{ID: HistoryModelIDs[0], Value: "old", Secret: "old secret", Ignored: "old"}
+		r := di.DB.WithContext(ctx).Create(&model)
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+
+		r = di.DB.WithContext(ctx).Model(&HistoryModel{ID: HistoryModelIDs[0]}).
+			Updates(map[string]interface{}{"Value": "new", "Secret": "new secret", "Ignored": "new"})
+		g.Expect(r.Error).To(Succeed(), "update should not fail")
+
+		records := loadHistoryRecords(ctx, g, di.DB, "test_history_history", HistoryModelIDs[0])
+		g.Expect(records).To(HaveLen(2), "history should be written for create and update")
+		record := records[1]
+		g.Expect(record.Operation).To(Equal(HistoryOperationUpdate), "Operation should be correct")
+		g.Expect(record.ActorID).To(HaveValue(Equal(MockedUserId)), "ActorID should be correct")
+		g.Expect(record.Changes).To(Equal(HistoryChanges{
+			"value":  {Old: "old", New: "new"},
+			"secret": {Old: historyRedactedValue, New: historyRedactedValue},
+		}), "changes should be correct")
+	}
+}
+
+func SubTestHistoryOnDelete(di *testHistoryDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		r := di.DB.WithContext(ctx).Create(&HistoryModel{ID: HistoryModelIDs[0], Value: "old"})
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+
+		r = di.DB.WithContext(ctx).Where("value = ?", "old").Delete(&HistoryModel{})
+		g.Expect(r.Error).To(Succeed(), "delete should not fail")
+
+		records := loadHistoryRecords(ctx, g, di.DB, "test_history_history", HistoryModelIDs[0])
+		g.Expect(records).To(HaveLen(2), "history should be written for create and delete")
+		record := records[1]
+		g.Expect(record.Operation).To(Equal(HistoryOperationDelete), "Operation should be correct")
+		g.Expect(record.ActorID).To(BeNil(), "ActorID should not be set without security")
+		g.Expect(record.Changes).To(Equal(HistoryChanges{
+			"id":    {Old: HistoryModelIDs[0].String()},
+			"value": {Old: "old"},
+		}), "changes should be correct")
+	}
+}
+
+func SubTestHistoryWithoutChanges(di *testHistoryDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		r := di.DB.WithContext(ctx).Create(&HistoryModel{ID: HistoryModelIDs[0], Value: "same", Ignored: "old"})
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+
+		r = di.DB.WithContext(ctx).Model(&HistoryModel{ID: HistoryModelIDs[0]}).Updates(map[string]interface{}{"Ignored": "new"})
+		g.Expect(r.Error).To(Succeed(), "update should not fail")
+		records := loadHistoryRecords(ctx, g, di.DB, "test_history_history", HistoryModelIDs[0])
+		g.Expect(records).To(HaveLen(1), "history should not be written without changes")
+
+		// global update without conditions is rejected by gorm, no snapshot should be taken
+		r = di.DB.WithContext(ctx).Model(&HistoryModel{}).Updates(map[string]interface{}{"Value": "new"})
+		g.Expect(r.Error).To(HaveOccurred(), "global update should fail")
+		records = loadHistoryRecords(ctx, g, di.DB, "test_history_history", HistoryModelIDs[0])
+		g.Expect(records).To(HaveLen(1), "history should not be written")
+	}
+}
+
+func SubTestHistoryWithRollback(di *testHistoryDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		expected := errors.New("oops")
+		e := di.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if r := tx.Create(&HistoryModel{ID: HistoryModelIDs[0], Value: "test"}); r.Error != nil {
+				return r.Error
+			}
+			return expected
+		})
+		g.Expect(e).To(MatchError(expected), "transaction should be rolled back")
+		records := loadHistoryRecords(ctx, g, di.DB, "test_history_history", HistoryModelIDs[0])
+		g.Expect(records).To(BeEmpty(), "history should be rolled back with the change")
+	}
+}
+
+func SubTestHistoryTableName(di *testHistoryDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		name, e := HistoryTableName(di.DB, &HistoryModel{})
+		g.Expect(e).To(Succeed(), "history table name should be resolved")
+		g.Expect(name).To(Equal("test_history_history"), "default history table name should be correct")
+
+		r := di.DB.WithContext(ctx).Create(&CustomHistoryModel{ID: HistoryModelIDs[0], Value: "test"})
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		records := loadHistoryRecords(ctx, g, di.DB, "test_custom_changes", HistoryModelIDs[0])
+		g.Expect(records).To(HaveLen(1), "custom history table should be used")
+
+		r = di.DB.WithContext(ctx).Create(&AuditModel{ID: HistoryModelIDs[1], Value: "test"})
+		g.Expect(r.Error).To(Succeed(), "create should not fail")
+		g.Expect(di.DB.WithContext(ctx).Migrator().HasTable("test_audit_history")).
+			To(BeFalse(), "history should not be written for models without History")
+	}
+}
+
+func SubTestHistoryMigration(di *testHistoryDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		m := NewHistoryMigration("1.0.0.1", di.DB, &HistoryModel{}, &CustomHistoryModel{})
+		g.Expect(m.RollbackFunc).ToNot(BeNil(), "migration should be reversible")
+
+		g.Expect(m.RollbackFunc(ctx)).To(Succeed(), "rollback should not fail")
+		g.Expect(di.DB.WithContext(ctx).Migrator().HasTable("test_history_history")).To(BeFalse(), "rollback should drop history table")
+		g.Expect(di.DB.WithContext(ctx).Migrator().HasTable("test_custom_changes")).To(BeFalse(), "rollback should drop custom history table")
+		g.Expect(m.Func(ctx)).To(Succeed(), "migration should not fail")
+		g.Expect(di.DB.WithContext(ctx).Migrator().HasTable("test_history_history")).To(BeTrue(), "migration should create history table")
+		g.Expect(di.DB.WithContext(ctx).Migrator().HasTable("test_custom_changes")).To(BeTrue(), "migration should create custom history table")
+	}
+}
+
+func SubTestHistoryChangesValuer() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		changes := HistoryChanges{"value": {Old: "old", New: "new"}}
+		v, e := changes.Value()
+		g.Expect(e).To(Succeed(), "Value should not fail")
+		g.Expect(v).To(Equal(`{"value":{"old":"old","new":"new"}}`), "Value should be correct")
+
+		var scanned HistoryChanges
+		g.Expect(scanned.Scan([]byte(v.(string)))).To(Succeed(), "Scan should not fail")
+		g.Expect(scanned).To(Equal(changes), "scanned value should be correct")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func loadHistoryRecords(ctx context.Context, g *gomega.WithT, db *gorm.DB, table string, id uuid.UUID) []*HistoryRecord {
+	var records []*HistoryRecord
+	r := db.WithContext(ctx).Table(table).Where("entity_id = ?", id.String()).Order("created_at").Find(&records)
+	g.Expect(r.Error).To(Succeed(), "loading history records should not fail")
+	return records
+}
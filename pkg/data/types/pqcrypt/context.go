@@ -127,6 +127,11 @@ func (EncryptedRaw) GormDataType() string {
 	return "jsonb"
 }
 
+// IsSensitive implements types.SensitiveData, so encrypted values are never written to change history in plaintext
+func (EncryptedRaw) IsSensitive() bool {
+	return true
+}
+
 // Value implements driver.Valuer
 func (d *EncryptedRaw) Value() (driver.Value, error) {
 	//we need to check nil here instead of in the JsonbValue method
@@ -0,0 +1,236 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/utils/order"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+const (
+	gormPluginVersion  = "lanai:version"
+	versionCheckKey    = "lanai:version:expected"
+	versionPreviousKey = "lanai:version:previous"
+)
+
+// Version is an embedded type for data model, which enables optimistic locking.
+// When used as an embedded type, updates and deletes of a loaded model are only applied when the stored version
+// matches the model's version, and updates increment the version.
+// e.g.
+// <code>
+//
+//	type VersionedModel struct {
+//			ID         uuid.UUID `gorm:"primaryKey;type:uuid;default:gen_random_uuid();"`
+//			Value      string
+//			types.Version
+//	}
+//
+// </code>
+// Note: version check only applies when the model's version is known (non-zero), i.e. the model is loaded from DB.
+// repo.CrudRepository returns data.ErrorOptimisticLockFailure when version check fails. The in-memory version is only
+// incremented when the update is applied, given the gorm plugin of NewVersionGormConfigurer is installed.
+// When using *gorm.DB directly, Save should be used with Select("*"), otherwise gorm falls back to upsert
+// when no row is affected.
+type Version struct {
+	Version LockVersion `gorm:"not null;default:1" json:"version"`
+}
+
+// LockVersion implements
+// - schema.GormDataTypeInterface
+// - schema.UpdateClausesInterface
+// - schema.DeleteClausesInterface
+// this data type adds "WHERE" clause for version check and increment the version on update
+type LockVersion int64
+
+// Value implements driver.Valuer
+func (v LockVersion) Value() (driver.Value, error) {
+	return int64(v), nil
+}
+
+// Scan implements sql.Scanner
+func (v *LockVersion) Scan(src interface{}) error {
+	switch val := src.(type) {
+	case int64:
+		*v = LockVersion(val)
+	case nil:
+		*v = 0
+	default:
+		return fmt.Errorf("unable to scan %T into LockVersion", src)
+	}
+	return nil
+}
+
+func (v LockVersion) GormDataType() string {
+	return "bigint"
+}
+
+// UpdateClauses implements schema.UpdateClausesInterface,
+func (v LockVersion) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{versionClause{Field: f, Increment: true}}
+}
+
+// DeleteClauses implements schema.DeleteClausesInterface,
+func (v LockVersion) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{versionClause{Field: f}}
+}
+
+// ExpectedRowsWithVersionCheck returns number of rows expected to be affected by the executed update or delete,
+// if version check is applied. It's used to determine if optimistic locking failed:
+// <code>
+//
+//	if expected, ok := types.ExpectedRowsWithVersionCheck(r); ok && r.RowsAffected < expected {
+//		// optimistic lock failure
+//	}
+//
+// </code>
+func ExpectedRowsWithVersionCheck(db *gorm.DB) (int64, bool) {
+	if db == nil || db.Statement == nil {
+		return 0, false
+	}
+	v, ok := db.InstanceGet(versionCheckKey)
+	if !ok {
+		return 0, false
+	}
+	return v.(int64), true
+}
+
+// versionClause implements clause.Interface and gorm.StatementModifier, where gorm.StatementModifier do the real work.
+// See gorm.DeletedAt for impl. reference
+type versionClause struct {
+	NoopStatementModifier
+	Field     *schema.Field
+	Increment bool
+}
+
+func (c versionClause) ModifyStatement(stmt *gorm.Statement) {
+	if _, ok := stmt.InstanceGet(versionCheckKey); ok {
+		return
+	}
+	var conditions []clause.Expression
+	var versions []LockVersion
+	for _, row := range structValues(stmt.ReflectValue) {
+		v, zero := c.Field.ValueOf(stmt.Context, row)
+		if zero {
+			continue
+		}
+		version := v.(LockVersion)
+		versions = append(versions, version)
+		if cond := c.condition(stmt, row, version); cond != nil {
+			conditions = append(conditions, cond)
+		}
+	}
+
+	switch len(conditions) {
+	case 0:
+		return
+	case 1:
+		FixWhereClausesForStatementModifier(stmt)
+		stmt.AddClause(clause.Where{Exprs: conditions})
+	default:
+		FixWhereClausesForStatementModifier(stmt)
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.Or(conditions...)}})
+	}
+	stmt.InstanceSet(versionCheckKey, int64(len(conditions)))
+
+	if c.Increment && len(versions) == 1 {
+		stmt.InstanceSet(versionPreviousKey, versionSnapshot{Field: c.Field, Version: versions[0]})
+		stmt.SetColumn(c.Field.DBName, versions[0]+1, true)
+	}
+}
+
+// condition returns version condition of given row. When there are multiple rows, primary keys are also included,
+// because each row may have different version
+func (c versionClause) condition(stmt *gorm.Statement, row reflect.Value, version LockVersion) clause.Expression {
+	versionEq := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: c.Field.DBName}, Value: version}
+	if rv := reflect.Indirect(stmt.ReflectValue); rv.Kind() == reflect.Struct {
+		return versionEq
+	}
+	exprs := []clause.Expression{versionEq}
+	for _, f := range stmt.Schema.PrimaryFields {
+		v, zero := f.ValueOf(stmt.Context, row)
+		if zero {
+			return nil
+		}
+		exprs = append(exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Value: v})
+	}
+	return clause.And(exprs...)
+}
+
+/**************************
+	GormConfigurer
+ **************************/
+
+type versionConfigurer struct{}
+
+// NewVersionGormConfigurer returns a data.GormConfigurer that installs a gorm plugin restoring the in-memory version
+// of models with Version embedded, when the version checked update fails or affects fewer rows than expected.
+func NewVersionGormConfigurer() data.GormConfigurer {
+	return versionConfigurer{}
+}
+
+func (c versionConfigurer) Order() int {
+	return order.Lowest
+}
+
+func (c versionConfigurer) Configure(config *gorm.Config) {
+	if config.Plugins == nil {
+		config.Plugins = map[string]gorm.Plugin{}
+	}
+	config.Plugins[gormPluginVersion] = versionGormPlugin{}
+}
+
+// versionSnapshot is the model's version before versionClause increment it
+type versionSnapshot struct {
+	Field   *schema.Field
+	Version LockVersion
+}
+
+// versionGormPlugin reverts the version increment done by versionClause, if the update is not applied.
+type versionGormPlugin struct{}
+
+// Name implements gorm.Plugin
+func (p versionGormPlugin) Name() string {
+	return gormPluginVersion
+}
+
+// Initialize implements gorm.Plugin. This function register version related callbacks
+func (p versionGormPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Update().After("gorm:update").Before(data.GormCallbackAfterUpdate).
+		Register(gormPluginVersion+":update", p.afterUpdate)
+}
+
+func (p versionGormPlugin) afterUpdate(tx *gorm.DB) {
+	v, ok := tx.Statement.InstanceGet(versionPreviousKey)
+	if !ok {
+		return
+	}
+	if expected, ok := ExpectedRowsWithVersionCheck(tx); ok && tx.Error == nil && tx.RowsAffected >= expected {
+		return
+	}
+	prev := v.(versionSnapshot)
+	for _, row := range structValues(tx.Statement.ReflectValue) {
+		if row.CanAddr() {
+			_ = tx.AddError(prev.Field.Set(tx.Statement.Context, row, prev.Version))
+		}
+	}
+}
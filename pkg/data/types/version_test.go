@@ -0,0 +1,236 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/google/uuid"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"testing"
+)
+
+const versionTableSQL = `
+CREATE TABLE IF NOT EXISTS public.test_versioned (
+	id UUID NOT NULL,
+	"value" STRING NULL,
+	version INT8 NOT NULL DEFAULT 1,
+	CONSTRAINT "primary" PRIMARY KEY (id ASC)
+);`
+
+var VersionedModelIDs = []uuid.UUID{
+	uuid.MustParse("7d2c9e41-5a6b-4c3d-8e9f-0a1b2c3d4e01"),
+	uuid.MustParse("8e3d0f52-6b7c-4d4e-9f0a-1b2c3d4e5f02"),
+}
+
+/*************************
+	Setup Test
+ *************************/
+
+type VersionedModel struct {
+	ID    uuid.UUID `gorm:"primaryKey;type:uuid;"`
+	Value string
+	Version
+}
+
+func (VersionedModel) TableName() string {
+	return "test_versioned"
+}
+
+func provideVersionGormConfigurer() fx.Annotated {
+	return fx.Annotated{
+		Group:  data.GormConfigurerGroup,
+		Target: NewVersionGormConfigurer,
+	}
+}
+
+/*************************
+	Test
+ *************************/
+
+type testVersionDI struct {
+	fx.In
+	DB *gorm.DB
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	di := &testVersionDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithFxOptions(
+			fx.Provide(provideVersionGormConfigurer()),
+		),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupVersionTestPrepareTable(di)),
+		test.GomegaSubTest(SubTestVersionOnSave(di), "TestVersionOnSave"),
+		test.GomegaSubTest(SubTestVersionOnUpdates(di), "TestVersionOnUpdates"),
+		test.GomegaSubTest(SubTestStaleVersionOnSave(di), "TestStaleVersionOnSave"),
+		test.GomegaSubTest(SubTestStaleVersionOnUpdates(di), "TestStaleVersionOnUpdates"),
+		test.GomegaSubTest(SubTestVersionOnDelete(di), "TestVersionOnDelete"),
+		test.GomegaSubTest(SubTestVersionOnBatchDelete(di), "TestVersionOnBatchDelete"),
+		test.GomegaSubTest(SubTestWithoutVersion(di), "TestWithoutVersion"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupVersionTestPrepareTable(di *testVersionDI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		r := di.DB.Exec(versionTableSQL)
+		g.Expect(r.Error).To(Succeed(), "create table if not exists shouldn't fail")
+		r = di.DB.Exec(`TRUNCATE TABLE "test_versioned"`)
+		g.Expect(r.Error).To(Succeed(), "truncate table shouldn't fail")
+		r = di.DB.Create([]*VersionedModel{
+			{ID: VersionedModelIDs[0], Value: "first", Version: Version{Version: 3}},
+			{ID: VersionedModelIDs[1], Value: "second", Version: Version{Version: 1}},
+		})
+		g.Expect(r.Error).To(Succeed(), "create test data shouldn't fail")
+		return ctx, nil
+	}
+}
+
+func SubTestVersionOnSave(di *testVersionDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		model := loadVersionedModel(ctx, g, di.DB, VersionedModelIDs[0])
+		model.Value = "updated"
+		r := di.DB.WithContext(ctx).Select("*").Save(model)
+		g.Expect(r.Error).To(Succeed(), "save should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "save should be applied")
+		g.Expect(model.Version.Version).To(BeEquivalentTo(4), "in-memory version should be incremented")
+		expected, ok := ExpectedRowsWithVersionCheck(r)
+		g.Expect(ok).To(BeTrue(), "version check should be applied")
+		g.Expect(expected).To(BeEquivalentTo(1), "expected rows should be correct")
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[0], "updated", 4)
+	}
+}
+
+func SubTestVersionOnUpdates(di *testVersionDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		model := loadVersionedModel(ctx, g, di.DB, VersionedModelIDs[0])
+		r := di.DB.WithContext(ctx).Model(model).Updates(map[string]interface{}{"Value": "updated"})
+		g.Expect(r.Error).To(Succeed(), "updates should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "updates should be applied")
+		g.Expect(model.Version.Version).To(BeEquivalentTo(4), "in-memory version should be incremented")
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[0], "updated", 4)
+
+		// consecutive updates with same model
+		r = di.DB.WithContext(ctx).Model(model).Updates(map[string]interface{}{"Value": "updated again"})
+		g.Expect(r.Error).To(Succeed(), "updates should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "updates should be applied")
+		g.Expect(model.Version.Version).To(BeEquivalentTo(5), "in-memory version should be incremented")
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[0], "updated again", 5)
+	}
+}
+
+func SubTestStaleVersionOnSave(di *testVersionDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		model := VersionedModel{ID: VersionedModelIDs[0], Value: "stale", Version: Version{Version: 2}}
+		r := di.DB.WithContext(ctx).Select("*").Save(&model)
+		g.Expect(r.Error).To(Succeed(), "save should not fail")
+		g.Expect(r.RowsAffected).To(BeZero(), "save with stale version should not be applied")
+		g.Expect(model.Version.Version).To(BeEquivalentTo(2), "in-memory version should not be incremented")
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[0], "first", 3)
+	}
+}
+
+func SubTestStaleVersionOnUpdates(di *testVersionDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		model := VersionedModel{ID: VersionedModelIDs[0], Version: Version{Version: 2}}
+		r := di.DB.WithContext(ctx).Model(&model).Updates(map[string]interface{}{"Value": "stale"})
+		g.Expect(r.Error).To(Succeed(), "updates should not fail")
+		g.Expect(r.RowsAffected).To(BeZero(), "updates with stale version should not be applied")
+		g.Expect(model.Version.Version).To(BeEquivalentTo(2), "in-memory version should not be incremented")
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[0], "first", 3)
+	}
+}
+
+func SubTestVersionOnDelete(di *testVersionDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		r := di.DB.WithContext(ctx).Delete(&VersionedModel{ID: VersionedModelIDs[0], Version: Version{Version: 2}})
+		g.Expect(r.Error).To(Succeed(), "delete should not fail")
+		g.Expect(r.RowsAffected).To(BeZero(), "delete with stale version should not be applied")
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[0], "first", 3)
+
+		r = di.DB.WithContext(ctx).Delete(&VersionedModel{ID: VersionedModelIDs[0], Version: Version{Version: 3}})
+		g.Expect(r.Error).To(Succeed(), "delete should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "delete with current version should be applied")
+		assertVersionedModelCount(ctx, g, di.DB, 1)
+	}
+}
+
+func SubTestVersionOnBatchDelete(di *testVersionDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		models := []*VersionedModel{
+			{ID: VersionedModelIDs[0], Version: Version{Version: 3}},
+			{ID: VersionedModelIDs[1], Version: Version{Version: 2}},
+		}
+		r := di.DB.WithContext(ctx).Delete(&models)
+		g.Expect(r.Error).To(Succeed(), "delete should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "only rows with current version should be deleted")
+		expected, ok := ExpectedRowsWithVersionCheck(r)
+		g.Expect(ok).To(BeTrue(), "version check should be applied")
+		g.Expect(expected).To(BeEquivalentTo(2), "expected rows should be correct")
+		assertVersionedModelCount(ctx, g, di.DB, 1)
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[1], "second", 1)
+	}
+}
+
+func SubTestWithoutVersion(di *testVersionDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		r := di.DB.WithContext(ctx).Model(&VersionedModel{ID: VersionedModelIDs[1]}).
+			Updates(map[string]interface{}{"Value": "updated"})
+		g.Expect(r.Error).To(Succeed(), "updates should not fail")
+		g.Expect(r.RowsAffected).To(BeEquivalentTo(1), "updates should be applied")
+		_, ok := ExpectedRowsWithVersionCheck(r)
+		g.Expect(ok).To(BeFalse(), "version check should not be applied")
+		assertVersionedModel(ctx, g, di.DB, VersionedModelIDs[1], "updated", 1)
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func loadVersionedModel(ctx context.Context, g *gomega.WithT, db *gorm.DB, id uuid.UUID) *VersionedModel {
+	var model VersionedModel
+	r := db.WithContext(ctx).Take(&model, id)
+	g.Expect(r.Error).To(Succeed(), "loading record should not fail")
+	return &model
+}
+
+func assertVersionedModel(ctx context.Context, g *gomega.WithT, db *gorm.DB, id uuid.UUID, value string, version int) {
+	model := loadVersionedModel(ctx, g, db, id)
+	g.Expect(model.Value).To(Equal(value), "stored value should be correct")
+	g.Expect(model.Version.Version).To(BeEquivalentTo(version), "stored version should be correct")
+}
+
+func assertVersionedModelCount(ctx context.Context, g *gomega.WithT, db *gorm.DB, count int) {
+	var total int64
+	r := db.WithContext(ctx).Model(&VersionedModel{}).Count(&total)
+	g.Expect(r.Error).To(Succeed(), "counting records should not fail")
+	g.Expect(total).To(BeEquivalentTo(count), "number of stored records should be correct")
+}
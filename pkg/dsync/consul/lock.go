@@ -45,6 +45,18 @@ type ConsulLockOption struct {
 
 type consulLockState int
 
+// lockStrategy defines how the lock is acquired, monitored and released with given session.
+// Default strategy is mutual exclusive lock. See mutexStrategy
+type lockStrategy interface {
+	// acquire blocks until the lock is acquired by given session, or returns error.
+	// The returned fencing token is greater than any token previously returned for the same key
+	acquire(ctx context.Context, session string) (token uint64, err error)
+	// monitor blocks until the lock is lost or cancelled
+	monitor(ctx context.Context, session string) error
+	// release releases the lock held by given session
+	release(session string) error
+}
+
 const (
 	stateUnknown consulLockState = iota
 	stateAcquired
@@ -68,6 +80,8 @@ type ConsulLock struct {
 	session        string
 	refreshFunc    context.CancelFunc // used when current acquisition should be stopped and restarted
 	lastErr        error
+	token          uint64 // fencing token, resolved when the lock is acquired
+	strategy       lockStrategy
 }
 
 func newConsulLock(client *api.Client, opts ...ConsulLockOptions) *ConsulLock {
@@ -86,6 +100,7 @@ func newConsulLock(client *api.Client, opts ...ConsulLockOptions) *ConsulLock {
 	ret.lockLostCh = make(chan struct{}, 1)
 	close(ret.lockLostCh)
 	ret.stateCond = xsync.NewCond(&ret.mtx)
+	ret.strategy = mutexStrategy{&ret}
 
 	for _, fn := range opts {
 		fn(&ret.option)
@@ -139,6 +154,16 @@ func (l *ConsulLock) Lost() <-chan struct{} {
 	return l.lockLostCh
 }
 
+// FencingToken implements dsync.Lock. The token is the ModifyIndex of the lock's KV entry when it's acquired.
+func (l *ConsulLock) FencingToken() uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.state != stateAcquired || l.loopContext == nil {
+		return 0
+	}
+	return l.token
+}
+
 func (l *ConsulLock) lazyStart() {
 	l.mtx.Lock()
 	defer l.mtx.Unlock()
@@ -245,21 +270,24 @@ LOOP:
 		}
 
 		// try to acquire lock
-		switch e := l.acquireLock(refreshCtx, session, 0); {
+		switch token, e := l.strategy.acquire(refreshCtx, session); {
 		case errors.Is(e, context.Canceled) || errors.Is(e, context.DeadlineExceeded):
 			// current acquisition is cancelled
 			continue
 		case e == nil:
 			// lock acquired, continue
-			logger.WithContext(refreshCtx).Debugf("acquired lock [%s]", l.option.Key)
-			l.updateState(stateAcquired, func() { l.lastErr = nil })
+			logger.WithContext(refreshCtx).Debugf("acquired lock [%s] with fencing token %d", l.option.Key, token)
+			l.updateState(stateAcquired, func() {
+				l.lastErr = nil
+				l.token = token
+			})
 		default:
 			l.updateState(stateError, func() { l.lastErr = e })
 			continue
 		}
 
 		// up to this point, we have acquired the lock. enter monitor state
-		switch e := l.monitorLock(refreshCtx, session); {
+		switch e := l.strategy.monitor(refreshCtx, session); {
 		case errors.Is(e, context.Canceled) || errors.Is(e, context.DeadlineExceeded):
 			// current acquisition is cancelled
 			continue
@@ -270,8 +298,17 @@ LOOP:
 		}
 	}
 
-	// we lost lock
-	l.updateState(stateUnknown)
+	// we lost lock. Note: state is not changed if the lock is released and re-started by a new loop
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.loopContext != nil && l.loopContext != ctx {
+		return
+	}
+	if l.state == stateAcquired {
+		close(l.lockLostCh)
+	}
+	l.state = stateUnknown
+	l.stateCond.Broadcast()
 }
 
 func (l *ConsulLock) acquireLock(ctx context.Context, session string, maxWait time.Duration) error {
@@ -427,6 +464,21 @@ LOOP:
 	return err
 }
 
+// readModifyIndex read the ModifyIndex of given key, which should be held by given session if specified.
+func (l *ConsulLock) readModifyIndex(ctx context.Context, key string, session string) (uint64, error) {
+	opts := (&api.QueryOptions{
+		RequireConsistent: true,
+	}).WithContext(ctx)
+	pair, _, e := l.client.KV().Get(key, opts)
+	switch {
+	case e != nil:
+		return 0, fmt.Errorf("failed to read lock: %v", e)
+	case pair == nil || (session != "" && pair.Session != session):
+		return 0, dsync.ErrLockUnavailable.WithMessage(`lock [%s] is revoked`, key)
+	}
+	return pair.ModifyIndex, nil
+}
+
 // wait for given delay, return true if the delay is fulfilled (not cancelled by context)
 func (l *ConsulLock) delay(ctx context.Context, delay time.Duration) (success bool) {
 	select {
@@ -447,16 +499,25 @@ func (l *ConsulLock) release() error {
 		return nil
 	}
 
-	// Stop lock loop
+	// Stop lock loop and reset state, so that subsequent acquisition wouldn't see stale state
 	l.stopLoop()
+	if l.state == stateAcquired {
+		close(l.lockLostCh)
+	}
+	l.state = stateUnknown
+	l.stateCond.Broadcast()
 
 	// Release the lock explicitly if previously used session is known
 	if l.session == "" {
 		return nil
 	}
+	return l.strategy.release(l.session)
+}
+
+func (l *ConsulLock) releaseLock(session string) error {
 	pair := &api.KVPair{
 		Key:     l.option.Key,
-		Session: l.session,
+		Session: session,
 		Flags:   lockFlagValue,
 	}
 
@@ -467,3 +528,24 @@ func (l *ConsulLock) release() error {
 
 	return nil
 }
+
+// mutexStrategy is the default lockStrategy, implemented as described at https://www.consul.io/docs/guides/leader-election.html
+type mutexStrategy struct {
+	*ConsulLock
+}
+
+func (s mutexStrategy) acquire(ctx context.Context, session string) (uint64, error) {
+	if e := s.acquireLock(ctx, session, 0); e != nil {
+		return 0, e
+	}
+	return s.readModifyIndex(ctx, s.option.Key, session)
+}
+
+func (s mutexStrategy) monitor(ctx context.Context, session string) error {
+	return s.monitorLock(ctx, session)
+}
+
+func (s mutexStrategy) release(session string) error {
+	return s.releaseLock(session)
+}
+
@@ -43,6 +43,8 @@ type ConsulSyncManager struct {
 	sessionCond *xsync.Cond
 	cancelFunc  context.CancelFunc
 	locks       map[string]*ConsulLock
+	semaphores  map[string]*ConsulSemaphore
+	rwLocks     map[string]*ConsulRWLock
 }
 
 type ConsulSessionOptions func(opt *ConsulSessionOption)
@@ -63,7 +65,9 @@ func NewConsulLockManager(ctx *bootstrap.ApplicationContext, conn *consul.Connec
 			LockDelay:  2 * time.Second,
 			RetryDelay: 2 * time.Second,
 		},
-		locks: make(map[string]*ConsulLock),
+		locks:      make(map[string]*ConsulLock),
+		semaphores: make(map[string]*ConsulSemaphore),
+		rwLocks:    make(map[string]*ConsulRWLock),
 	}
 	ret.sessionCond = xsync.NewCond(&ret.mtx)
 
@@ -91,6 +95,18 @@ func (m *ConsulSyncManager) Stop(ctx context.Context) error {
 			logger.WithContext(ctx).Warnf("Failed to release lock [%s]: %v", k, e)
 		}
 	}
+	for k, s := range m.semaphores {
+		if e := s.Release(); e != nil {
+			logger.WithContext(ctx).Warnf("Failed to release semaphore [%s]: %v", k, e)
+		}
+	}
+	for k, l := range m.rwLocks {
+		for _, lock := range []*ConsulLock{l.read, l.write} {
+			if e := lock.Release(); e != nil {
+				logger.WithContext(ctx).Warnf("Failed to release read-write lock [%s]: %v", k, e)
+			}
+		}
+	}
 	return nil
 }
 
@@ -126,6 +142,67 @@ func (m *ConsulSyncManager) Lock(key string, opts ...dsync.LockOptions) (dsync.L
 	return m.locks[key], nil
 }
 
+func (m *ConsulSyncManager) Semaphore(key string, permits int, opts ...dsync.LockOptions) (dsync.Semaphore, error) {
+	switch {
+	case key == "":
+		return nil, fmt.Errorf(`cannot create distributed semaphore: key is required but missing`)
+	case permits <= 0:
+		return nil, fmt.Errorf(`cannot create distributed semaphore: permits should be positive, but got %d`, permits)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.shutdown {
+		return nil, dsync.ErrSyncManagerStopped
+	} else if s, ok := m.semaphores[key]; ok {
+		return s, nil
+	}
+
+	lock := newConsulSemaphoreLock(m.client, "semaphore", 1, permits, m.weightedLockOptions(key, "distributed semaphore", opts))
+	m.semaphores[key] = &ConsulSemaphore{ConsulLock: lock, permits: permits}
+	return m.semaphores[key], nil
+}
+
+func (m *ConsulSyncManager) RWLock(key string, opts ...dsync.LockOptions) (dsync.RWLock, error) {
+	if key == "" {
+		return nil, fmt.Errorf(`cannot create distributed read-write lock: key is required but missing`)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.shutdown {
+		return nil, dsync.ErrSyncManagerStopped
+	} else if l, ok := m.rwLocks[key]; ok {
+		return l, nil
+	}
+
+	m.rwLocks[key] = &ConsulRWLock{
+		key:   key,
+		read:  newConsulSemaphoreLock(m.client, "read", 1, rwLockLimit, m.weightedLockOptions(key, "distributed read lock", opts)),
+		write: newConsulSemaphoreLock(m.client, "write", rwLockLimit, rwLockLimit, m.weightedLockOptions(key, "distributed write lock", opts)),
+	}
+	return m.rwLocks[key], nil
+}
+
+func (m *ConsulSyncManager) weightedLockOptions(key string, name string, opts []dsync.LockOptions) ConsulLockOptions {
+	option := dsync.LockOption{
+		Valuer: dsync.NewJsonLockValuer(map[string]string{
+			"name": fmt.Sprintf("%s - %s", name, m.appCtx.Name()),
+		}),
+	}
+	for _, fn := range opts {
+		fn(&option)
+	}
+	return func(opt *ConsulLockOption) {
+		opt.Context = m.appCtx
+		opt.SessionFunc = m.waitForSession
+		opt.Key = key
+		opt.Valuer = option.Valuer
+	}
+}
+
 // startLoop requires mutex lock
 func (m *ConsulSyncManager) startLoop() error {
 	if m.shutdown {
@@ -221,6 +298,13 @@ func (m *ConsulSyncManager) sessionLoop(ctx context.Context) {
 		for _, l := range m.locks {
 			l.refresh()
 		}
+		for _, s := range m.semaphores {
+			s.refresh()
+		}
+		for _, l := range m.rwLocks {
+			l.read.refresh()
+			l.write.refresh()
+		}
 		m.mtx.Unlock()
 	}
 }
@@ -18,6 +18,7 @@ package consuldsync_test
 
 import (
 	"context"
+	"fmt"
 	"github.com/cisco-open/go-lanai/pkg/bootstrap"
 	"github.com/cisco-open/go-lanai/pkg/consul"
 	"github.com/cisco-open/go-lanai/pkg/dsync"
@@ -29,6 +30,8 @@ import (
 	"github.com/onsi/gomega"
 	. "github.com/onsi/gomega"
 	"go.uber.org/fx"
+	"net/http"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -40,8 +43,9 @@ import (
 type TestConsulDsyncDI struct {
 	fx.In
 	ittest.RecorderDI
-	AppCtx *bootstrap.ApplicationContext
-	Consul *consul.Connection
+	AppCtx     *bootstrap.ApplicationContext
+	Consul     *consul.Connection
+	Properties consul.ConnectionProperties
 }
 
 func TestConsulDSyncManager(t *testing.T) {
@@ -52,7 +56,11 @@ func TestConsulDSyncManager(t *testing.T) {
 			//consultest.HttpRecordingMode(),
 			// - Too many concurrent operations, ordering is different every time.
 			// - Latency is also required because consul lock is heavily rely on blocking HTTP transactions
-			consultest.MoreHTTPVCROptions(ittest.DisableHttpRecordOrdering(), ittest.ApplyHttpLatency()),
+			consultest.MoreHTTPVCROptions(
+				ittest.DisableHttpRecordOrdering(), ittest.ApplyHttpLatency(),
+				ittest.DisableHttpRecorderHooks(ittest.HookNameFixedDuration),
+				ittest.HttpRecordMatching(ExactQueryMatching()),
+			),
 		),
 		//apptest.WithTimeout(2*time.Minute),
 		apptest.WithFxOptions(),
@@ -62,6 +70,26 @@ func TestConsulDSyncManager(t *testing.T) {
 		test.GomegaSubTest(SubTestConsulSessionRecovery(&di), "TestSessionRecovery"),
 		test.GomegaSubTest(SubTestConsulInvalidSession(&di), "TestInvalidSession"),
 		test.GomegaSubTest(SubTestConsulCancelledContext(&di), "TestCancelledContext"),
+		test.GomegaSubTest(SubTestConsulFencingToken(&di), "TestFencingToken"),
+	)
+}
+
+func TestConsulSemaphores(t *testing.T) {
+	di := TestConsulDsyncDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		consultest.WithHttpPlayback(t,
+			//consultest.HttpRecordingMode(),
+			// Semaphore relies on blocking queries to wait for state changes, actual latency is required
+			consultest.MoreHTTPVCROptions(
+				ittest.DisableHttpRecordOrdering(), ittest.ApplyHttpLatency(),
+				ittest.DisableHttpRecorderHooks(ittest.HookNameFixedDuration),
+				ittest.HttpRecordMatching(ExactQueryMatching()),
+			),
+		),
+		apptest.WithDI(&di),
+		test.GomegaSubTest(SubTestConsulSemaphore(&di), "TestSemaphore"),
+		test.GomegaSubTest(SubTestConsulRWLock(&di), "TestRWLock"),
 	)
 }
 
@@ -72,7 +100,7 @@ func TestConsulDSyncManager(t *testing.T) {
 func SubTestConsulTryLock(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
 	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
 		const lockKey = "try-lock-test"
-		mgts := NewConsulManagers(di, g)
+		mgts := NewTaggedConsulManagers(di, g, lockKey)
 		mgts.Start(ctx, g)
 		defer mgts.Stop(ctx, g)
 
@@ -112,7 +140,7 @@ func SubTestConsulTryLock(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
 func SubTestConsulLockAndRelease(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
 	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
 		const lockKey = "lock-test"
-		mgts := NewConsulManagers(di, g, func(opt *consuldsync.ConsulSessionOption) {
+		mgts := NewTaggedConsulManagers(di, g, lockKey, func(opt *consuldsync.ConsulSessionOption) {
 			// minimum TTL and lock delay (1sec) for faster test
 			opt.TTL = 10 * time.Second
 			opt.LockDelay = 1 * time.Second
@@ -158,7 +186,7 @@ func SubTestConsulSessionRecovery(di *TestConsulDsyncDI) test.GomegaSubTestFunc
 		const lockKey = "session-renew-test"
 		const sessionName = `test-session`
 		var ttl = 10 * time.Second
-		mgts := NewConsulManagers(di, g, func(opt *consuldsync.ConsulSessionOption) {
+		mgts := NewTaggedConsulManagers(di, g, lockKey, func(opt *consuldsync.ConsulSessionOption) {
 			opt.Name = sessionName
 			// minimum TTL and lock delay (1sec) for faster test. Lower retry rate to avoid too many retries.
 			opt.TTL = ttl
@@ -211,7 +239,7 @@ func SubTestConsulSessionRecovery(di *TestConsulDsyncDI) test.GomegaSubTestFunc
 func SubTestConsulInvalidSession(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
 	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
 		const lockKey = "invalid-session-test"
-		mgts := NewConsulManagers(di, g, func(opt *consuldsync.ConsulSessionOption) {
+		mgts := NewTaggedConsulManagers(di, g, lockKey, func(opt *consuldsync.ConsulSessionOption) {
 			// minimum TTL is 10s. Creating session will time out if TTL is invalid
 			opt.TTL = 1 * time.Second
 		})
@@ -240,7 +268,7 @@ func SubTestConsulInvalidSession(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
 func SubTestConsulCancelledContext(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
 	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
 		const lockKey = "cancelled-context-test"
-		mgts := NewConsulManagers(di, g)
+		mgts := NewTaggedConsulManagers(di, g, lockKey)
 		mgts.Start(ctx, g)
 		defer mgts.Stop(ctx, g)
 
@@ -271,6 +299,130 @@ func SubTestConsulCancelledContext(di *TestConsulDsyncDI) test.GomegaSubTestFunc
 	}
 }
 
+func SubTestConsulFencingToken(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "fencing-token-test"
+		mgts := NewTaggedConsulManagers(di, g, lockKey)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 5000 * time.Millisecond
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+
+		lock1, stopFn1 := GetTestLock(g, mgts.Main, lockKey)
+		lock2, stopFn2 := GetTestLock(g, mgts.Secondary, lockKey)
+		defer stopFn2()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available before acquired")
+
+		// first holder
+		g.Expect(lock1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when lock is acquirable")
+		token1 := lock1.FencingToken()
+		g.Expect(token1).ToNot(BeZero(), "fencing token should be available after acquired")
+		g.Expect(lock1.FencingToken()).To(Equal(token1), "fencing token should not change while the lock is held")
+
+		// second holder
+		stopFn1()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available after released")
+		g.Expect(lock2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after lock is released")
+		g.Expect(lock2.FencingToken()).To(BeNumerically(">", token1), "fencing token should increase")
+	}
+}
+
+func SubTestConsulSemaphore(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "semaphore-test"
+		const permits = 2
+		mgts := NewTaggedConsulManagers(di, g, "semaphore")
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+		others := NewTaggedConsulManagers(di, g, "others")
+		others.Start(ctx, g)
+		defer others.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		var e error
+
+		// obtain semaphores
+		sem1, stopFn1 := GetTestSemaphore(g, mgts.Main, key, permits)
+		sem2, stopFn2 := GetTestSemaphore(g, mgts.Secondary, key, permits)
+		sem3, stopFn3 := GetTestSemaphore(g, others.Main, key, permits)
+		defer stopFn2()
+		defer stopFn3()
+		g.Expect(sem1.Permits()).To(Equal(permits), "semaphore should have correct permits")
+		same, e := mgts.Main.Semaphore(key, permits)
+		g.Expect(e).To(Succeed(), "getting same semaphore should not fail")
+		g.Expect(same).To(BeIdenticalTo(sem1), "same semaphore should be returned with same key")
+
+		// acquire all permits
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		g.Expect(sem1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		g.Expect(sem2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		g.Expect(sem1.FencingToken()).ToNot(BeZero(), "fencing token should be available after acquired")
+		g.Expect(sem2.FencingToken()).To(BeNumerically(">", sem1.FencingToken()), "fencing token should increase among acquisitions")
+		e = sem3.TryLock(timeoutCtx)
+		g.Expect(e).To(HaveOccurred(), "TryLock should fail when no permit is available")
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "TryLock should fail with correct error")
+		// stop background acquisition, so the recorded interactions are deterministic
+		stopFn3()
+
+		// release one permit
+		stopFn1()
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5000*time.Millisecond)
+		defer cancelFn()
+		g.Expect(sem3.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after a permit is released")
+
+		// invalid parameters
+		_, e = mgts.Main.Semaphore("", permits)
+		g.Expect(e).To(HaveOccurred(), "semaphore without key should fail")
+		_, e = mgts.Main.Semaphore(key+"-invalid", 0)
+		g.Expect(e).To(HaveOccurred(), "semaphore without positive permits should fail")
+	}
+}
+
+func SubTestConsulRWLock(di *TestConsulDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "rwlock-test"
+		mgts := NewTaggedConsulManagers(di, g, "rwlock")
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+
+		rw1, e := mgts.Main.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+		g.Expect(rw1.Key()).To(Equal(key), "read-write lock should have correct key")
+		rw2, e := mgts.Secondary.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+
+		// multiple readers
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		g.Expect(rw1.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there is no writer")
+		g.Expect(rw2.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there are other readers")
+		g.Expect(rw1.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there are readers")
+		// stop background acquisition, so the recorded interactions are deterministic
+		g.Expect(rw1.WriteLock().Release()).To(Succeed(), "Release should not fail")
+
+		// single writer
+		g.Expect(rw1.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		g.Expect(rw2.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5000*time.Millisecond)
+		defer cancelFn()
+		g.Expect(rw1.WriteLock().Lock(timeoutCtx)).To(Succeed(), "WriteLock should not fail after readers released")
+		defer func() { _ = rw1.WriteLock().Release() }()
+		g.Expect(rw2.ReadLock().TryLock(timeoutCtx)).ToNot(Succeed(), "ReadLock should fail when there is a writer")
+		g.Expect(rw2.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there is a writer")
+		_ = rw2.ReadLock().Release()
+		_ = rw2.WriteLock().Release()
+	}
+}
+
 /*************************
 	Helpers
  *************************/
@@ -285,6 +437,16 @@ func GetTestLock(g *WithT, manager dsync.SyncManager, lockName string, opts ...d
 	}
 }
 
+func GetTestSemaphore(g *WithT, manager dsync.SemaphoreManager, key string, permits int, opts ...dsync.LockOptions) (dsync.Semaphore, func()) {
+	sem, e := manager.Semaphore(key, permits, opts...)
+	g.Expect(e).To(Succeed(), "getting semaphore should not fail")
+	g.Expect(sem).ToNot(BeNil(), "getting semaphore should not return nil")
+	return sem, func() {
+		e := sem.Release()
+		g.Expect(e).To(Succeed(), "Release should not fail")
+	}
+}
+
 func RemoveSession(g *WithT, consulConn *consul.Connection, sessionName string) {
 	entries, _, e := consulConn.Client().Session().List(nil)
 	g.Expect(e).To(Succeed(), "listing current sessions should not fail")
@@ -306,16 +468,55 @@ type TestConsulManagers struct {
 	Secondary *consuldsync.ConsulSyncManager
 }
 
-func NewConsulManagers(di *TestConsulDsyncDI, g *gomega.WithT, opts ...consuldsync.ConsulSessionOptions) TestConsulManagers {
+// NewTaggedConsulManagers creates managers, each uses its own consul connection that tags outgoing requests with given prefix.
+// Lock holders issue blocking queries with identical URL, tagging keeps recorded interactions of each holder
+// distinguishable during playback.
+func NewTaggedConsulManagers(di *TestConsulDsyncDI, g *gomega.WithT, prefix string, opts ...consuldsync.ConsulSessionOptions) TestConsulManagers {
 	ret := TestConsulManagers{
-		Main:      consuldsync.NewConsulLockManager(di.AppCtx, di.Consul, opts...),
-		Secondary: consuldsync.NewConsulLockManager(di.AppCtx, di.Consul, opts...),
+		Main:      consuldsync.NewConsulLockManager(di.AppCtx, NewTaggedConsulConnection(di, g, prefix+"-main"), opts...),
+		Secondary: consuldsync.NewConsulLockManager(di.AppCtx, NewTaggedConsulConnection(di, g, prefix+"-secondary"), opts...),
 	}
 	g.Expect(ret.Main).ToNot(BeNil(), "major consul sync manager should not be nil")
 	g.Expect(ret.Secondary).ToNot(BeNil(), "minor consul sync manager should not be nil")
 	return ret
 }
 
+func NewTaggedConsulConnection(di *TestConsulDsyncDI, g *gomega.WithT, tag string) *consul.Connection {
+	conn, e := consul.New(consul.WithProperties(di.Properties), func(cfg *consul.ClientConfig) error {
+		cfg.HttpClient = &http.Client{
+			Transport: TaggedTransport{Tag: tag, Delegate: di.Recorder},
+		}
+		return nil
+	})
+	g.Expect(e).To(Succeed(), "creating consul connection should not fail")
+	return conn
+}
+
+// ExactQueryMatching requires outgoing request to have same set of queries as recorded.
+// Blocking queries with and without "index" need to be distinguished.
+func ExactQueryMatching() ittest.RecordMatcherOptions {
+	delegate := ittest.NewRecordQueryMatcher()
+	return func(opt *ittest.RecordMatcherOption) {
+		opt.QueryMatcher = func(out url.Values, record url.Values) error {
+			if len(out) != len(record) {
+				return fmt.Errorf("http queries mismatch")
+			}
+			return delegate(out, record)
+		}
+	}
+}
+
+type TaggedTransport struct {
+	Tag      string
+	Delegate http.RoundTripper
+}
+
+func (t TaggedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Test-Tag", t.Tag)
+	return t.Delegate.RoundTrip(req)
+}
+
 func (m TestConsulManagers) Start(ctx context.Context, g *gomega.WithT) {
 	e := m.Main.Start(ctx)
 	g.Expect(e).To(Succeed(), "starting major manager should not fail")
@@ -0,0 +1,333 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package consuldsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/hashicorp/consul/api"
+	"math"
+	"path"
+)
+
+const (
+	// semaphoreFlagValue is a magic flag we set to indicate a key is being used for a weighted semaphore.
+	// It is used to detect a potential conflict with a lock.
+	semaphoreFlagValue = 0x4c0b1f6f2d8e7a35
+	// semaphoreLockKey is the key within the prefix that holds the semaphore state
+	semaphoreLockKey = ".lock"
+	// rwLockLimit is the total weight of a RWLock. Each reader takes weight of 1 and writer takes the full weight.
+	rwLockLimit = math.MaxInt32
+	// maxReleaseAttempts is how many times we retry CAS update when releasing a permit
+	maxReleaseAttempts = 5
+)
+
+// ConsulSemaphore implements dsync.Semaphore
+type ConsulSemaphore struct {
+	*ConsulLock
+	permits int
+}
+
+func (s *ConsulSemaphore) Permits() int {
+	return s.permits
+}
+
+// ConsulRWLock implements dsync.RWLock
+type ConsulRWLock struct {
+	key   string
+	read  *ConsulLock
+	write *ConsulLock
+}
+
+func (l *ConsulRWLock) Key() string {
+	return l.key
+}
+
+func (l *ConsulRWLock) ReadLock() dsync.Lock {
+	return l.read
+}
+
+func (l *ConsulRWLock) WriteLock() dsync.Lock {
+	return l.write
+}
+
+/***********************
+	Weighted Semaphore
+ ***********************/
+
+// semaphoreState is the value stored in semaphore's lock key
+type semaphoreState struct {
+	// Limit is the total weight allowed
+	Limit int
+	// Holders maps holder ID to its weight
+	Holders map[string]int
+}
+
+// semaphoreStrategy implements lockStrategy using weighted semaphore. The implementation is a variant of
+// https://learn.hashicorp.com/tutorials/consul/distributed-semaphore:
+// - Each contender creates a key "<prefix>/<holder ID>" using its session.
+// - Holders and their weights are recorded in "<prefix>/.lock" and updated with check-and-set.
+// - Holders without live contender key are pruned.
+type semaphoreStrategy struct {
+	*ConsulLock
+	// instanceID distinguish holders sharing same session. Semaphores are cached per key by ConsulSyncManager,
+	// so a fixed ID per usage (e.g. "read" or "write") is sufficient.
+	instanceID string
+	weight     int
+	limit      int
+}
+
+// newConsulSemaphoreLock create a ConsulLock that acquire given weight from a weighted semaphore of given limit.
+// ConsulLockOption.Key is used as semaphore's prefix.
+func newConsulSemaphoreLock(client *api.Client, instanceID string, weight, limit int, opts ...ConsulLockOptions) *ConsulLock {
+	lock := newConsulLock(client, opts...)
+	lock.strategy = semaphoreStrategy{
+		ConsulLock: lock,
+		instanceID: instanceID,
+		weight:     weight,
+		limit:      limit,
+	}
+	return lock
+}
+
+func (s semaphoreStrategy) acquire(ctx context.Context, session string) (uint64, error) {
+	kv := s.client.KV()
+	holder := s.holderID(session)
+	contender := &api.KVPair{
+		Key:     path.Join(s.option.Key, holder),
+		Value:   s.option.Valuer(),
+		Session: session,
+		Flags:   semaphoreFlagValue,
+	}
+	switch acquired, _, e := kv.Acquire(contender, nil); {
+	case e != nil:
+		s.delay(ctx, s.option.RetryDelay)
+		return 0, fmt.Errorf("failed to create semaphore contender: %v", e)
+	case !acquired:
+		s.delay(ctx, s.option.RetryDelay)
+		return 0, fmt.Errorf("failed to create semaphore contender: session [%s] is invalid", session)
+	}
+
+	qOpts := (&api.QueryOptions{
+		WaitTime: s.option.QueryWaitTime,
+	}).WithContext(ctx)
+	for {
+		// read current state and live contenders. potentially blocking operation
+		pairs, meta, e := kv.List(s.option.Key, qOpts)
+		if e != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return 0, ctxErr
+			}
+			s.delay(ctx, s.option.RetryDelay)
+			return 0, fmt.Errorf("failed to read semaphore: %v", e)
+		}
+		lockPair, state, e := s.decodeState(pairs)
+		if e != nil {
+			return 0, e
+		}
+
+		// try to take the permit
+		if _, ok := state.Holders[holder]; !ok {
+			var used int
+			for _, w := range state.Holders {
+				used += w
+			}
+			if used+s.weight > s.limit {
+				// no permit available, update error state and wait for changes
+				s.updateState(stateError, func() {
+					s.lastErr = dsync.ErrLockUnavailable.WithMessage(`semaphore [%s] has no available permit`, s.option.Key)
+				})
+				qOpts.WaitIndex = meta.LastIndex
+				if e := ctx.Err(); e != nil {
+					return 0, e
+				}
+				continue
+			}
+			state.Holders[holder] = s.weight
+		}
+
+		// up to this point, we can take the permit. Try to update the state
+		switch ok, e := s.saveState(lockPair, state); {
+		case e != nil:
+			s.delay(ctx, s.option.RetryDelay)
+			return 0, fmt.Errorf("failed to update semaphore: %v", e)
+		case ok:
+			// Note: the semaphore state is shared among holders, the token is only monotonic among acquisitions
+			return s.readModifyIndex(ctx, path.Join(s.option.Key, semaphoreLockKey), "")
+		}
+		// state changed by others, retry immediately
+		qOpts.WaitIndex = 0
+		if e := ctx.Err(); e != nil {
+			return 0, e
+		}
+	}
+}
+
+func (s semaphoreStrategy) monitor(ctx context.Context, session string) error {
+	kv := s.client.KV()
+	holder := s.holderID(session)
+	contenderKey := path.Join(s.option.Key, holder)
+	opts := (&api.QueryOptions{
+		RequireConsistent: true,
+	}).WithContext(ctx)
+
+	for {
+		if e := ctx.Err(); e != nil {
+			return context.Canceled
+		}
+		pairs, meta, e := kv.List(s.option.Key, opts)
+		switch {
+		case e != nil && ctx.Err() != nil:
+			return context.Canceled
+		case e != nil && api.IsRetryableError(e):
+			// network error or something we can retry later
+			if s.delay(ctx, s.option.RetryDelay) {
+				opts.WaitIndex = 0
+			}
+			continue
+		case e != nil:
+			return e
+		}
+
+		_, state, e := s.decodeState(pairs)
+		if e != nil {
+			return e
+		}
+		if _, ok := state.Holders[holder]; !ok {
+			return fmt.Errorf("semaphore permit revoked")
+		}
+		var contender *api.KVPair
+		for _, pair := range pairs {
+			if pair.Key == contenderKey {
+				contender = pair
+			}
+		}
+		if contender == nil || contender.Session != session {
+			return fmt.Errorf("semaphore contender revoked by server")
+		}
+		// everything is fine, we enter long wait monitoring
+		opts.WaitIndex = meta.LastIndex
+	}
+}
+
+func (s semaphoreStrategy) release(session string) (err error) {
+	kv := s.client.KV()
+	holder := s.holderID(session)
+	// remove from holders
+	for i := 0; i < maxReleaseAttempts; i++ {
+		pairs, _, e := kv.List(s.option.Key, nil)
+		if e != nil {
+			err = e
+			break
+		}
+		lockPair, state, e := s.decodeState(pairs)
+		if e != nil {
+			err = e
+			break
+		}
+		if _, ok := state.Holders[holder]; !ok {
+			break
+		}
+		delete(state.Holders, holder)
+		if ok, e := s.saveState(lockPair, state); e != nil || ok {
+			err = e
+			break
+		}
+	}
+
+	// remove contender
+	contender := &api.KVPair{
+		Key:     path.Join(s.option.Key, holder),
+		Session: session,
+		Flags:   semaphoreFlagValue,
+	}
+	if _, _, e := kv.Release(contender, nil); e != nil && err == nil {
+		err = e
+	}
+	if _, e := kv.Delete(contender.Key, nil); e != nil && err == nil {
+		err = e
+	}
+	if err != nil {
+		return dsync.ErrUnlockFailed.WithCause(err)
+	}
+	return nil
+}
+
+func (s semaphoreStrategy) holderID(session string) string {
+	return session + "." + s.instanceID
+}
+
+// decodeState find and decode semaphore state from given pairs. Holders without live contender are pruned.
+// The returned state is never nil, the returned *api.KVPair is nil if the semaphore doesn't exist yet.
+func (s semaphoreStrategy) decodeState(pairs api.KVPairs) (*api.KVPair, *semaphoreState, error) {
+	lockKey := path.Join(s.option.Key, semaphoreLockKey)
+	var lockPair *api.KVPair
+	live := map[string]struct{}{}
+	for _, pair := range pairs {
+		switch {
+		case pair.Flags != semaphoreFlagValue:
+			return nil, nil, api.ErrSemaphoreConflict
+		case pair.Key == lockKey:
+			lockPair = pair
+		case pair.Session != "":
+			live[path.Base(pair.Key)] = struct{}{}
+		}
+	}
+
+	state := semaphoreState{Limit: s.limit, Holders: map[string]int{}}
+	if lockPair == nil {
+		return nil, &state, nil
+	}
+	if e := json.Unmarshal(lockPair.Value, &state); e != nil {
+		return nil, nil, fmt.Errorf("failed to decode semaphore: %v", e)
+	}
+	if state.Holders == nil {
+		state.Holders = map[string]int{}
+	}
+	for k := range state.Holders {
+		if _, ok := live[k]; !ok {
+			delete(state.Holders, k)
+		}
+	}
+	if state.Limit != s.limit {
+		if len(state.Holders) != 0 {
+			return nil, nil, api.ErrSemaphoreConflict
+		}
+		state.Limit = s.limit
+	}
+	return lockPair, &state, nil
+}
+
+// saveState save given state with check-and-set. Returns false if the semaphore is modified by others
+func (s semaphoreStrategy) saveState(lockPair *api.KVPair, state *semaphoreState) (bool, error) {
+	value, e := json.Marshal(state)
+	if e != nil {
+		return false, e
+	}
+	pair := &api.KVPair{
+		Key:   path.Join(s.option.Key, semaphoreLockKey),
+		Value: value,
+		Flags: semaphoreFlagValue,
+	}
+	if lockPair != nil {
+		pair.ModifyIndex = lockPair.ModifyIndex
+	}
+	ok, _, e := s.client.KV().CAS(pair, nil)
+	return ok, e
+}
@@ -23,6 +23,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go.uber.org/fx"
 )
 
 // FxGroup is a group name for uber.fx
@@ -34,6 +35,8 @@ var (
 	ErrSessionUnavailable   = newError("session is not available")
 	ErrSyncManagerStopped   = newError("sync manager stopped")
 	ErrFailedInitialization = newError("sync manager failed to start")
+	ErrUnsupported          = newError("operation is not supported")
+	ErrLockLost             = newError("lock is lost")
 )
 
 // SyncManager manage distributed locks across the application.
@@ -46,6 +49,25 @@ type SyncManager interface {
 	Lock(key string, opts ...LockOptions) (Lock, error)
 }
 
+// SemaphoreManager is an optional interface of SyncManager, implemented by SyncManager that supports distributed Semaphore.
+type SemaphoreManager interface {
+	// Semaphore returns a distributed counting semaphore with given key, which allows at most "permits" holders
+	// at the same time. If the Semaphore already exists with same key, the options are ignored and the same Semaphore is returned.
+	//
+	// All participants of the same key should use same "permits". Using a key of Lock or RWLock as Semaphore key
+	// results in undefined behavior.
+	Semaphore(key string, permits int, opts ...LockOptions) (Semaphore, error)
+}
+
+// RWLockManager is an optional interface of SyncManager, implemented by SyncManager that supports distributed RWLock.
+type RWLockManager interface {
+	// RWLock returns a distributed read-write lock with given key. If the RWLock already exists with same key,
+	// the options are ignored and the same RWLock is returned.
+	//
+	// Using a key of Lock or Semaphore as RWLock key results in undefined behavior.
+	RWLock(key string, opts ...LockOptions) (RWLock, error)
+}
+
 type SyncManagerLifecycle interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
@@ -114,6 +136,40 @@ type Lock interface {
 	// When Lost channel is signalled, there is no need to re-invoke Lock.Lock or Lock.TryLock for lock re-acquisition
 	// unless it's caused by manual Release() call, but all relying-tasks should pause.
 	Lost() <-chan struct{}
+
+	// FencingToken returns the fencing token of current lock ownership, or 0 if the lock is not currently held.
+	// Each successful acquisition of the same lock key is assigned a token greater than any previously assigned one,
+	// regardless which instance/session acquired it.
+	//
+	// Since the lock may be lost before the holder noticed (see Lost), downstream storages should reject writes
+	// carrying a token smaller than the largest one they have seen. See ContextWithFencingToken
+	FencingToken() uint64
+}
+
+// Semaphore distributed counting semaphore backed by external infrastructure service such as consul or redis.
+// Each instance of Semaphore acquires at most one permit. Lock.Lock and Lock.TryLock acquire the permit,
+// and Lock.Release returns the permit.
+//
+// Same as Lock, once acquisition is started, the Semaphore would keep trying to acquire/re-acquire the permit until
+// Lock.Release is manually invoked. Lock.Lost channel is signalled when the permit is lost.
+type Semaphore interface {
+	Lock
+	// Permits returns the max number of holders allowed at the same time
+	Permits() int
+}
+
+// RWLock distributed read-write lock backed by external infrastructure service such as consul or redis.
+// The lock can be held by an arbitrary number of readers or a single writer.
+//
+// Both RWLock.ReadLock and RWLock.WriteLock follow the same semantics of Lock, including Lock.Lost channel.
+// Note: Writers are not prioritized over readers, continuous overlapping readers may starve writers.
+type RWLock interface {
+	// Key the unique identifier of the lock
+	Key() string
+	// ReadLock returns the shared Lock for readers
+	ReadLock() Lock
+	// WriteLock returns the exclusive Lock for writers
+	WriteLock() Lock
 }
 
 /*********************
@@ -138,6 +194,114 @@ func LockWithKey(key string, opts ...LockOptions) Lock {
 	return l
 }
 
+// SemaphoreWithKey returns a distributed Semaphore with given key and permits. If the Semaphore already exists with
+// same key, the options are ignored and the same Semaphore is returned.
+//
+// This function panic if internal SyncManager is not initialized yet, doesn't implement SemaphoreManager or key is not provided.
+func SemaphoreWithKey(key string, permits int, opts ...LockOptions) Semaphore {
+	if syncManager == nil {
+		panic("SyncManager is not initialized")
+	}
+	sm, ok := syncManager.(SemaphoreManager)
+	if !ok {
+		panic(ErrUnsupported.WithMessage("SyncManager doesn't support semaphore"))
+	}
+	s, e := sm.Semaphore(key, permits, opts...)
+	if e != nil {
+		panic(e)
+	}
+	return s
+}
+
+// RWLockWithKey returns a distributed RWLock with given key. If the RWLock already exists with same key,
+// the options are ignored and the same RWLock is returned.
+//
+// This function panic if internal SyncManager is not initialized yet, doesn't implement RWLockManager or key is not provided.
+func RWLockWithKey(key string, opts ...LockOptions) RWLock {
+	if syncManager == nil {
+		panic("SyncManager is not initialized")
+	}
+	rwm, ok := syncManager.(RWLockManager)
+	if !ok {
+		panic(ErrUnsupported.WithMessage("SyncManager doesn't support read-write lock"))
+	}
+	l, e := rwm.RWLock(key, opts...)
+	if e != nil {
+		panic(e)
+	}
+	return l
+}
+
+// OptionalSyncManagerDI can be embedded in fx.In struct of components that use distributed locks only when available,
+// e.g. migration runners. Same as Module, SyncManager provided in FxGroup takes precedence.
+type OptionalSyncManagerDI struct {
+	fx.In
+	SyncManager          SyncManager   `optional:"true"`
+	SyncManagerOverrides []SyncManager `group:"dsync"`
+}
+
+// OptionalLock returns a Lock with given key from the effective SyncManager, or nil if no SyncManager is available.
+func (di OptionalSyncManagerDI) OptionalLock(key string, opts ...LockOptions) (Lock, error) {
+	manager := di.SyncManager
+	if len(di.SyncManagerOverrides) != 0 {
+		manager = di.SyncManagerOverrides[0]
+	}
+	if manager == nil {
+		return nil, nil
+	}
+	return manager.Lock(key, opts...)
+}
+
+// RunWithLock acquires the lock, executes given function and releases the lock afterward.
+// The context passed to the function is cancelled with ErrLockLost as cause when the lock is lost, see context.Cause.
+// Long-running function should stop as soon as the context is cancelled.
+func RunWithLock(ctx context.Context, lock Lock, fn func(ctx context.Context) error) error {
+	logger.WithContext(ctx).Infof("Acquiring lock [%s]...", lock.Key())
+	if e := lock.Lock(ctx); e != nil {
+		return fmt.Errorf("unable to acquire lock [%s]: %w", lock.Key(), e)
+	}
+	defer func() {
+		if e := lock.Release(); e != nil {
+			logger.WithContext(ctx).Warnf("unable to release lock [%s]: %v", lock.Key(), e)
+		}
+	}()
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func(lost <-chan struct{}) {
+		select {
+		case <-lost:
+			logger.WithContext(ctx).Warnf("lock [%s] is lost, cancelling the task", lock.Key())
+			cancel(ErrLockLost.WithMessage(`lock [%s] is lost`, lock.Key()))
+		case <-lockCtx.Done():
+		}
+	}(lock.Lost())
+	return fn(lockCtx)
+}
+
+type ckFencingToken struct{}
+
+// ContextWithFencingToken returns a new context carrying given fencing token. See Lock.FencingToken
+func ContextWithFencingToken(ctx context.Context, token uint64) context.Context {
+	return context.WithValue(ctx, ckFencingToken{}, token)
+}
+
+// ContextWithLockFencingToken returns a new context carrying the current fencing token of given Lock.
+// ErrLockUnavailable is returned if the lock is not currently held.
+func ContextWithLockFencingToken(ctx context.Context, lock Lock) (context.Context, error) {
+	token := lock.FencingToken()
+	if token == 0 {
+		return ctx, ErrLockUnavailable.WithMessage(`lock [%s] is not held`, lock.Key())
+	}
+	return ContextWithFencingToken(ctx, token), nil
+}
+
+// FencingTokenFromContext returns the fencing token carried by given context, and whether the token is available.
+func FencingTokenFromContext(ctx context.Context) (uint64, bool) {
+	token, ok := ctx.Value(ckFencingToken{}).(uint64)
+	return token, ok && token != 0
+}
+
 // NewJsonLockValuer is the default implementation of LockValuer.
 func NewJsonLockValuer(v interface{}) LockValuer {
 	return func() []byte {
@@ -0,0 +1,140 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dsync_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+// testLock implements dsync.Lock, its Lost channel is controlled by test
+type testLock struct {
+	key      string
+	lost     chan struct{}
+	locked   bool
+	released bool
+}
+
+func (l *testLock) Key() string {
+	return l.key
+}
+
+func (l *testLock) Lock(_ context.Context) error {
+	l.locked = true
+	return nil
+}
+
+func (l *testLock) TryLock(ctx context.Context) error {
+	return l.Lock(ctx)
+}
+
+func (l *testLock) Release() error {
+	l.released = true
+	return nil
+}
+
+func (l *testLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *testLock) FencingToken() uint64 {
+	return 1
+}
+
+// testSyncManager implements dsync.SyncManager, returned locks are tagged with its name
+type testSyncManager struct {
+	name string
+}
+
+func (m testSyncManager) Lock(key string, _ ...dsync.LockOptions) (dsync.Lock, error) {
+	return &testLock{key: m.name + "/" + key, lost: make(chan struct{})}, nil
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestLockUtils(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestOptionalLock(), "TestOptionalLock"),
+		test.GomegaSubTest(SubTestRunWithLock(), "TestRunWithLock"),
+		test.GomegaSubTest(SubTestRunWithLostLock(), "TestRunWithLostLock"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestOptionalLock() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		lock, e := dsync.OptionalSyncManagerDI{}.OptionalLock("test")
+		g.Expect(e).To(Succeed(), "lock without SyncManager should not fail")
+		g.Expect(lock).To(BeNil(), "lock without SyncManager should be nil")
+
+		di := dsync.OptionalSyncManagerDI{SyncManager: testSyncManager{name: "default"}}
+		lock, e = di.OptionalLock("test")
+		g.Expect(e).To(Succeed(), "lock should not fail")
+		g.Expect(lock.Key()).To(Equal("default/test"), "lock should be created by SyncManager")
+
+		di.SyncManagerOverrides = []dsync.SyncManager{testSyncManager{name: "override"}}
+		lock, e = di.OptionalLock("test")
+		g.Expect(e).To(Succeed(), "lock should not fail")
+		g.Expect(lock.Key()).To(Equal("override/test"), "lock should be created by overriding SyncManager")
+	}
+}
+
+func SubTestRunWithLock() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		lock := &testLock{key: "test", lost: make(chan struct{})}
+		e := dsync.RunWithLock(ctx, lock, func(ctx context.Context) error {
+			g.Expect(lock.locked).To(BeTrue(), "lock should be held")
+			g.Expect(ctx.Err()).To(Succeed(), "context should not be cancelled")
+			return nil
+		})
+		g.Expect(e).To(Succeed(), "run with lock should not fail")
+		g.Expect(lock.released).To(BeTrue(), "lock should be released")
+	}
+}
+
+func SubTestRunWithLostLock() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		lock := &testLock{key: "test", lost: make(chan struct{})}
+		e := dsync.RunWithLock(ctx, lock, func(ctx context.Context) error {
+			close(lock.lost)
+			select {
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		})
+		g.Expect(e).To(HaveOccurred(), "run should be cancelled when lock is lost")
+		g.Expect(errors.Is(e, dsync.ErrLockLost)).To(BeTrue(), "cause should be ErrLockLost")
+		g.Expect(lock.released).To(BeTrue(), "lock should be released")
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inmemorydsync
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/cisco-open/go-lanai/pkg/utils/xsync"
+	"sync"
+)
+
+type lockState int
+
+const (
+	stateUnknown lockState = iota
+	stateAcquired
+	stateError
+)
+
+type InMemoryLockOptions func(opt *InMemoryLockOption)
+type InMemoryLockOption struct {
+	Context context.Context
+	Key     string
+	Store   *LockStore
+	// Weight is the weight this lock takes from the lock key when acquired. Default is 1
+	Weight int
+	// Limit is the total weight allowed for the lock key. Default is 1
+	Limit int
+}
+
+// InMemoryLock implements dsync.Lock using LockStore.
+// The lock follows the same semantics of other dsync.Lock implementations: once acquisition is started,
+// it keeps trying to acquire/re-acquire the lock in the background until Release is called.
+type InMemoryLock struct {
+	mtx    sync.Mutex
+	option InMemoryLockOption
+	holder string
+	// State Variables, requires mutex lock to read and write
+	loopContext    context.Context
+	loopCancelFunc context.CancelFunc
+	lockLostCh     chan struct{}
+	state          lockState
+	stateCond      *xsync.Cond
+	lastErr        error
+	token          uint64
+}
+
+func newInMemoryLock(opts ...InMemoryLockOptions) (lock *InMemoryLock) {
+	opt := InMemoryLockOption{
+		Context: context.Background(),
+		Weight:  1,
+		Limit:   1,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	// we start with a closed lost channel
+	defer func() {
+		lock.lockLostCh = make(chan struct{}, 1)
+		close(lock.lockLostCh)
+		lock.stateCond = xsync.NewCond(&lock.mtx)
+	}()
+	return &InMemoryLock{
+		option: opt,
+		holder: utils.RandomString(16),
+	}
+}
+
+func (l *InMemoryLock) Key() string {
+	return l.option.Key
+}
+
+func (l *InMemoryLock) Lock(ctx context.Context) error {
+	l.lazyStart()
+	return l.waitForState(ctx, func(state lockState) (bool, error) {
+		switch {
+		case l.state == stateAcquired:
+			return true, nil
+		case l.loopContext == nil:
+			return true, context.Canceled
+		}
+		return false, nil
+	})
+}
+
+func (l *InMemoryLock) TryLock(ctx context.Context) error {
+	l.lazyStart()
+	// TryLock differ from Lock that it also return on any error state
+	return l.waitForState(ctx, func(state lockState) (bool, error) {
+		switch {
+		case l.state == stateAcquired:
+			return true, nil
+		case l.state == stateError:
+			return true, l.lastErr
+		case l.loopContext == nil:
+			return true, context.Canceled
+		}
+		return false, nil
+	})
+}
+
+func (l *InMemoryLock) Release() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	// Stop lock loop. Releasing the lock happens in the loop
+	if l.loopContext != nil {
+		l.stopLoop()
+	}
+	return nil
+}
+
+func (l *InMemoryLock) Lost() <-chan struct{} {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.lockLostCh
+}
+
+func (l *InMemoryLock) FencingToken() uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.state != stateAcquired || l.loopContext == nil {
+		return 0
+	}
+	return l.token
+}
+
+func (l *InMemoryLock) lazyStart() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	// Check if we're already maintaining the lock loop
+	if l.loopContext == nil {
+		l.loopContext, l.loopCancelFunc = context.WithCancel(l.option.Context)
+		go l.lockLoop(l.loopContext, l.loopCancelFunc)
+	}
+}
+
+// stopLoop stop lock loop. mutex lock is required when call this function
+func (l *InMemoryLock) stopLoop() {
+	if l.loopCancelFunc != nil {
+		l.loopCancelFunc()
+	}
+	l.loopContext = nil
+	l.loopCancelFunc = nil
+}
+
+func (l *InMemoryLock) waitForState(ctx context.Context, stateMatcher func(lockState) (bool, error)) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for {
+		if ok, e := stateMatcher(l.state); ok {
+			return e
+		}
+		switch e := l.stateCond.Wait(ctx); {
+		case errors.Is(e, context.Canceled) || errors.Is(e, context.DeadlineExceeded):
+			return e
+		}
+	}
+}
+
+// updateState atomically update state, execute additional setters and broadcast the change.
+func (l *InMemoryLock) updateState(s lockState, setters ...func()) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for _, fn := range setters {
+		fn()
+	}
+
+	if s == stateAcquired && l.state != s {
+		l.lockLostCh = make(chan struct{}, 1)
+	} else if l.state == stateAcquired && l.state != s {
+		close(l.lockLostCh)
+	}
+
+	if s == stateError || l.state != s {
+		defer l.stateCond.Broadcast()
+	}
+	l.state = s
+}
+
+// lockLoop is the main loop of attempting to maintain the lock.
+// The lock state loop between Acquired and Error
+// Note: given context may also be cancelled outside, e.g. lock is released
+func (l *InMemoryLock) lockLoop(ctx context.Context, cancelFunc context.CancelFunc) {
+	defer cancelFunc()
+	defer func() {
+		l.option.Store.release(l.option.Key, l.holder)
+		l.updateState(stateUnknown)
+	}()
+
+	for ctx.Err() == nil {
+		token, e := l.option.Store.acquire(ctx, l.option.Key, l.holder, l.option.Weight, l.option.Limit, func(e error) {
+			l.updateState(stateError, func() {
+				l.lastErr = dsync.ErrLockUnavailable.WithMessage(`lock [%s] is held by another session`, l.option.Key).WithCause(e)
+			})
+		})
+		if e != nil {
+			// current acquisition is cancelled
+			continue
+		}
+		logger.WithContext(ctx).Debugf("acquired lock [%s] with fencing token %d", l.option.Key, token)
+		l.updateState(stateAcquired, func() {
+			l.lastErr = nil
+			l.token = token
+		})
+
+		// up to this point, we have acquired the lock. enter monitor state
+		switch e := l.option.Store.monitor(ctx, l.option.Key, l.holder); {
+		case errors.Is(e, context.Canceled) || errors.Is(e, context.DeadlineExceeded):
+			// current acquisition is cancelled
+			continue
+		default:
+			// we lost the lock
+			logger.WithContext(ctx).Debugf("lost lock [%s] - %v", l.option.Key, e)
+			l.updateState(stateError, func() { l.lastErr = dsync.ErrLockUnavailable.WithCause(e) })
+		}
+	}
+}
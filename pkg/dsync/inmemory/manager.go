@@ -0,0 +1,174 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inmemorydsync
+
+import (
+	"context"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"math"
+	"sync"
+)
+
+// rwLockLimit is the total weight of a RWLock. Each reader takes weight of 1 and writer takes the full weight.
+const rwLockLimit = math.MaxInt32
+
+type InMemorySyncOptions func(opt *InMemorySyncOption)
+type InMemorySyncOption struct {
+	// Store is the LockStore shared among SyncManagers. Optional, a new LockStore is created if not set
+	Store *LockStore
+}
+
+// NewInMemorySyncManager create a dsync.SyncManager that maintains locks within current process.
+// It's suitable for tests and single-replica applications.
+func NewInMemorySyncManager(ctx context.Context, opts ...InMemorySyncOptions) *InMemorySyncManager {
+	opt := InMemorySyncOption{}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	if opt.Store == nil {
+		opt.Store = NewLockStore()
+	}
+	return &InMemorySyncManager{
+		ctx:        ctx,
+		options:    opt,
+		locks:      make(map[string]*InMemoryLock),
+		semaphores: make(map[string]*InMemorySemaphore),
+		rwLocks:    make(map[string]*InMemoryRWLock),
+	}
+}
+
+type InMemorySyncManager struct {
+	ctx        context.Context
+	options    InMemorySyncOption
+	mtx        sync.Mutex
+	locks      map[string]*InMemoryLock
+	semaphores map[string]*InMemorySemaphore
+	rwLocks    map[string]*InMemoryRWLock
+}
+
+func (m *InMemorySyncManager) Lock(key string, _ ...dsync.LockOptions) (dsync.Lock, error) {
+	if key == "" {
+		return nil, fmt.Errorf(`cannot create distributed lock: key is required but missing`)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if lock, ok := m.locks[key]; ok {
+		return lock, nil
+	}
+	m.locks[key] = m.newLock(key, 1, 1)
+	return m.locks[key], nil
+}
+
+func (m *InMemorySyncManager) Semaphore(key string, permits int, _ ...dsync.LockOptions) (dsync.Semaphore, error) {
+	switch {
+	case key == "":
+		return nil, fmt.Errorf(`cannot create distributed semaphore: key is required but missing`)
+	case permits <= 0:
+		return nil, fmt.Errorf(`cannot create distributed semaphore: permits should be positive, but got %d`, permits)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if s, ok := m.semaphores[key]; ok {
+		return s, nil
+	}
+	m.semaphores[key] = &InMemorySemaphore{InMemoryLock: m.newLock(key, 1, permits), permits: permits}
+	return m.semaphores[key], nil
+}
+
+func (m *InMemorySyncManager) RWLock(key string, _ ...dsync.LockOptions) (dsync.RWLock, error) {
+	if key == "" {
+		return nil, fmt.Errorf(`cannot create distributed read-write lock: key is required but missing`)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if l, ok := m.rwLocks[key]; ok {
+		return l, nil
+	}
+	m.rwLocks[key] = &InMemoryRWLock{
+		key:   key,
+		read:  m.newLock(key, 1, rwLockLimit),
+		write: m.newLock(key, rwLockLimit, rwLockLimit),
+	}
+	return m.rwLocks[key], nil
+}
+
+func (m *InMemorySyncManager) Start(_ context.Context) error {
+	return nil
+}
+
+func (m *InMemorySyncManager) Stop(_ context.Context) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for _, lock := range m.locks {
+		_ = lock.Release()
+	}
+	for _, s := range m.semaphores {
+		_ = s.Release()
+	}
+	for _, l := range m.rwLocks {
+		_ = l.read.Release()
+		_ = l.write.Release()
+	}
+	return nil
+}
+
+// newLock create a InMemoryLock. mutex lock is required when call this function
+func (m *InMemorySyncManager) newLock(key string, weight, limit int) *InMemoryLock {
+	return newInMemoryLock(func(opt *InMemoryLockOption) {
+		opt.Context = m.ctx
+		opt.Key = key
+		opt.Store = m.options.Store
+		opt.Weight = weight
+		opt.Limit = limit
+	})
+}
+
+// InMemorySemaphore implements dsync.Semaphore
+type InMemorySemaphore struct {
+	*InMemoryLock
+	permits int
+}
+
+func (s *InMemorySemaphore) Permits() int {
+	return s.permits
+}
+
+// InMemoryRWLock implements dsync.RWLock
+type InMemoryRWLock struct {
+	key   string
+	read  *InMemoryLock
+	write *InMemoryLock
+}
+
+func (l *InMemoryRWLock) Key() string {
+	return l.key
+}
+
+func (l *InMemoryRWLock) ReadLock() dsync.Lock {
+	return l.read
+}
+
+func (l *InMemoryRWLock) WriteLock() dsync.Lock {
+	return l.write
+}
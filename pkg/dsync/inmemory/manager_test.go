@@ -0,0 +1,278 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inmemorydsync_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	inmemorydsync "github.com/cisco-open/go-lanai/pkg/dsync/inmemory"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+/*************************
+	Test Setup
+ *************************/
+
+type TestSyncManagers struct {
+	Store     *inmemorydsync.LockStore
+	Main      *inmemorydsync.InMemorySyncManager
+	Secondary *inmemorydsync.InMemorySyncManager
+}
+
+// NewSyncManagers create two managers sharing same LockStore, which simulates two application instances
+func NewSyncManagers(ctx context.Context) *TestSyncManagers {
+	store := inmemorydsync.NewLockStore()
+	withStore := func(opt *inmemorydsync.InMemorySyncOption) {
+		opt.Store = store
+	}
+	return &TestSyncManagers{
+		Store:     store,
+		Main:      inmemorydsync.NewInMemorySyncManager(ctx, withStore),
+		Secondary: inmemorydsync.NewInMemorySyncManager(ctx, withStore),
+	}
+}
+
+func (m *TestSyncManagers) Start(ctx context.Context, g *WithT) {
+	g.Expect(m.Main.Start(ctx)).To(Succeed(), "starting main manager should not fail")
+	g.Expect(m.Secondary.Start(ctx)).To(Succeed(), "starting secondary manager should not fail")
+}
+
+func (m *TestSyncManagers) Stop(ctx context.Context, g *WithT) {
+	g.Expect(m.Main.Stop(ctx)).To(Succeed(), "stopping main manager should not fail")
+	g.Expect(m.Secondary.Stop(ctx)).To(Succeed(), "stopping secondary manager should not fail")
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestInMemoryDSyncManager(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestTryLock(), "TestTryLock"),
+		test.GomegaSubTest(SubTestLockAndRelease(), "TestLockAndRelease"),
+		test.GomegaSubTest(SubTestLockRevocation(), "TestLockRevocation"),
+		test.GomegaSubTest(SubTestSemaphore(), "TestSemaphore"),
+		test.GomegaSubTest(SubTestRWLock(), "TestRWLock"),
+		test.GomegaSubTest(SubTestFencingToken(), "TestFencingToken"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestTryLock() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "try-lock-test"
+		mgts := NewSyncManagers(ctx)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+		lock1, stopFn1 := GetTestLock(g, mgts.Main, lockKey)
+		defer stopFn1()
+		lock2, stopFn2 := GetTestLock(g, mgts.Secondary, lockKey)
+		defer stopFn2()
+
+		g.Expect(lock1.TryLock(timeoutCtx)).To(Succeed(), "TryLock should not fail when lock is acquirable")
+		e := lock2.TryLock(timeoutCtx)
+		g.Expect(e).To(HaveOccurred(), "TryLock should fail when lock is not acquirable")
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "TryLock should fail with correct error")
+		g.Expect(lock1.TryLock(timeoutCtx)).To(Succeed(), "TryLock should not fail when lock is already acquired")
+
+		same, e := mgts.Main.Lock(lockKey)
+		g.Expect(e).To(Succeed(), "getting same lock should not fail")
+		g.Expect(same).To(BeIdenticalTo(lock1), "same lock should be returned with same key")
+		_, e = mgts.Main.Lock("")
+		g.Expect(e).To(HaveOccurred(), "lock without key should fail")
+	}
+}
+
+func SubTestLockAndRelease() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "lock-test"
+		mgts := NewSyncManagers(ctx)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		lock1, stopFn1 := GetTestLock(g, mgts.Main, lockKey)
+		lock2, stopFn2 := GetTestLock(g, mgts.Secondary, lockKey)
+		defer stopFn2()
+
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancelFn()
+		g.Expect(lock1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when lock is acquirable")
+		g.Expect(lock2.Lock(timeoutCtx)).ToNot(Succeed(), "Lock should timeout when lock is not acquirable")
+
+		// release main lock in another thread, wait until released or timed out
+		go stopFn1()
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+		g.Expect(lock2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after lock is released")
+	}
+}
+
+func SubTestLockRevocation() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "lock-revocation-test"
+		mgts := NewSyncManagers(ctx)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+		lock, stopFn := GetTestLock(g, mgts.Main, lockKey)
+		defer stopFn()
+		g.Expect(lock.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when lock is acquirable")
+		token := lock.FencingToken()
+
+		// revoke and validate Lost() channel works properly
+		lost := lock.Lost()
+		mgts.Store.Revoke(lockKey)
+		select {
+		case <-lost:
+		case <-timeoutCtx.Done():
+			t.Errorf("expect signal of lost lock after revocation, got nothing")
+		}
+
+		// lock should be re-acquired in the background
+		g.Expect(lock.Lock(timeoutCtx)).To(Succeed(), "Lock should be re-acquired after revocation")
+		g.Expect(lock.FencingToken()).To(BeNumerically(">", token), "fencing token should increase after re-acquired")
+	}
+}
+
+func SubTestSemaphore() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "semaphore-test"
+		const permits = 2
+		mgts := NewSyncManagers(ctx)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+		other := inmemorydsync.NewInMemorySyncManager(ctx, func(opt *inmemorydsync.InMemorySyncOption) {
+			opt.Store = mgts.Store
+		})
+		defer func() { _ = other.Stop(ctx) }()
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+		sem1, stopFn1 := GetTestSemaphore(g, mgts.Main, key, permits)
+		sem2, stopFn2 := GetTestSemaphore(g, mgts.Secondary, key, permits)
+		sem3, stopFn3 := GetTestSemaphore(g, other, key, permits)
+		defer stopFn2()
+		defer stopFn3()
+		g.Expect(sem1.Permits()).To(Equal(permits), "semaphore should have correct permits")
+
+		// acquire all permits
+		g.Expect(sem1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		g.Expect(sem2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		e := sem3.TryLock(timeoutCtx)
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "TryLock should fail when no permit is available")
+
+		// release one permit
+		stopFn1()
+		g.Expect(sem3.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after a permit is released")
+
+		// invalid parameters
+		_, e = mgts.Main.Semaphore("", permits)
+		g.Expect(e).To(HaveOccurred(), "semaphore without key should fail")
+		_, e = mgts.Main.Semaphore(key+"-invalid", 0)
+		g.Expect(e).To(HaveOccurred(), "semaphore without positive permits should fail")
+	}
+}
+
+func SubTestRWLock() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "rwlock-test"
+		mgts := NewSyncManagers(ctx)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+		rw1, e := mgts.Main.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+		g.Expect(rw1.Key()).To(Equal(key), "read-write lock should have correct key")
+		rw2, e := mgts.Secondary.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+
+		// multiple readers
+		g.Expect(rw1.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there is no writer")
+		g.Expect(rw2.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there are other readers")
+		g.Expect(rw1.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there are readers")
+
+		// single writer
+		g.Expect(rw1.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		g.Expect(rw2.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		g.Expect(rw1.WriteLock().Lock(timeoutCtx)).To(Succeed(), "WriteLock should not fail after readers released")
+		g.Expect(rw2.ReadLock().TryLock(timeoutCtx)).ToNot(Succeed(), "ReadLock should fail when there is a writer")
+		g.Expect(rw2.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there is a writer")
+	}
+}
+
+func SubTestFencingToken() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "fencing-token-test"
+		mgts := NewSyncManagers(ctx)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+		lock1, stopFn1 := GetTestLock(g, mgts.Main, lockKey)
+		lock2, stopFn2 := GetTestLock(g, mgts.Secondary, lockKey)
+		defer stopFn2()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available before acquired")
+
+		g.Expect(lock1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when lock is acquirable")
+		token1 := lock1.FencingToken()
+		g.Expect(token1).ToNot(BeZero(), "fencing token should be available after acquired")
+
+		stopFn1()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available after released")
+		g.Expect(lock2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after lock is released")
+		g.Expect(lock2.FencingToken()).To(BeNumerically(">", token1), "fencing token should increase")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func GetTestLock(g *WithT, manager dsync.SyncManager, lockName string) (dsync.Lock, func()) {
+	lock, e := manager.Lock(lockName)
+	g.Expect(e).To(Succeed(), "getting lock should not fail")
+	g.Expect(lock).ToNot(BeNil(), "getting lock should not return nil")
+	return lock, func() {
+		g.Expect(lock.Release()).To(Succeed(), "Release should not fail")
+	}
+}
+
+func GetTestSemaphore(g *WithT, manager dsync.SemaphoreManager, key string, permits int) (dsync.Semaphore, func()) {
+	sem, e := manager.Semaphore(key, permits)
+	g.Expect(e).To(Succeed(), "getting semaphore should not fail")
+	g.Expect(sem).ToNot(BeNil(), "getting semaphore should not return nil")
+	return sem, func() {
+		g.Expect(sem.Release()).To(Succeed(), "Release should not fail")
+	}
+}
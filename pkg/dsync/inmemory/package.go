@@ -0,0 +1,64 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inmemorydsync
+
+import (
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/log"
+	"go.uber.org/fx"
+)
+
+var logger = log.New("DSync")
+
+var Module = &bootstrap.Module{
+	Name:       "distributed-in-memory",
+	Precedence: bootstrap.DistributedLockPrecedence,
+	Options: []fx.Option{
+		fx.Provide(BindInMemorySyncProperties, provideSyncManager),
+	},
+	Modules: []*bootstrap.Module{dsync.Module},
+}
+
+func Use() {
+	bootstrap.Register(Module)
+}
+
+/**************************
+	Provider
+***************************/
+
+type syncDI struct {
+	fx.In
+	AppCtx     *bootstrap.ApplicationContext
+	Properties InMemorySyncProperties
+}
+
+type syncOut struct {
+	fx.Out
+	Managers []dsync.SyncManager `group:"dsync,flatten"`
+}
+
+// provideSyncManager contributes the in-memory dsync.SyncManager to FX group only when it's enabled
+func provideSyncManager(di syncDI) syncOut {
+	if !di.Properties.Enabled {
+		return syncOut{}
+	}
+	return syncOut{
+		Managers: []dsync.SyncManager{NewInMemorySyncManager(di.AppCtx)},
+	}
+}
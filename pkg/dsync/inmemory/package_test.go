@@ -0,0 +1,73 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inmemorydsync_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	inmemorydsync "github.com/cisco-open/go-lanai/pkg/dsync/inmemory"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"testing"
+)
+
+/*************************
+	Tests
+ *************************/
+
+type TestModuleDI struct {
+	fx.In
+	Managers []dsync.SyncManager `group:"dsync"`
+}
+
+func TestModuleInit(t *testing.T) {
+	di := TestModuleDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithModules(inmemorydsync.Module),
+		apptest.WithProperties("dsync.in-memory.enabled: true"),
+		apptest.WithDI(&di),
+		test.GomegaSubTest(SubTestSyncManager(&di), "TestSyncManager"),
+		test.GomegaSubTest(SubTestLeadershipLock(), "TestLeadershipLock"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestSyncManager(di *TestModuleDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		g.Expect(di.Managers).To(HaveLen(1), "in-memory SyncManager should be provided when enabled")
+		g.Expect(di.Managers[0]).To(BeAssignableToTypeOf(&inmemorydsync.InMemorySyncManager{}), "SyncManager should have correct type")
+		lock := dsync.LockWithKey("test-lock")
+		g.Expect(lock).ToNot(BeNil(), "lock should not be nil")
+		g.Expect(dsync.LockWithKey("test-lock")).To(BeIdenticalTo(lock), "re-getting lock should be same instance")
+	}
+}
+
+func SubTestLeadershipLock() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		lock := dsync.LeadershipLock()
+		g.Expect(lock).ToNot(BeNil(), "leadership lock should not be nil")
+		g.Expect(lock.TryLock(ctx)).To(Succeed(), "leadership lock should not return error when acquired")
+		g.Expect(lock.FencingToken()).ToNot(BeZero(), "leadership lock should have fencing token")
+	}
+}
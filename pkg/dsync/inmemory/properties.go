@@ -0,0 +1,46 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inmemorydsync
+
+import (
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/pkg/errors"
+)
+
+const (
+	PropertiesPrefix = "dsync.in-memory"
+)
+
+type InMemorySyncProperties struct {
+	// Enabled whether the in-memory dsync.SyncManager is used. When enabled, it takes precedence over
+	// dsync.SyncManager provided by other packages (e.g. consuldsync, redisdsync)
+	Enabled bool `json:"enabled"`
+}
+
+// NewInMemorySyncProperties create a InMemorySyncProperties with default values
+func NewInMemorySyncProperties() *InMemorySyncProperties {
+	return &InMemorySyncProperties{}
+}
+
+// BindInMemorySyncProperties create and bind InMemorySyncProperties
+func BindInMemorySyncProperties(ctx *bootstrap.ApplicationContext) InMemorySyncProperties {
+	props := NewInMemorySyncProperties()
+	if err := ctx.Config().Bind(props, PropertiesPrefix); err != nil {
+		panic(errors.Wrap(err, "failed to bind InMemorySyncProperties"))
+	}
+	return *props
+}
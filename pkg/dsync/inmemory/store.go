@@ -0,0 +1,142 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inmemorydsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/utils/xsync"
+	"sync"
+)
+
+var errNoPermit = errors.New("no permit available")
+
+// LockStore is the in-process registry of lock ownership. Every lock managed by InMemorySyncManager is a weighted
+// semaphore in the store: a mutex lock is a semaphore with single permit, and a RWLock's reader and writer take
+// weight of 1 and full weight respectively.
+//
+// SyncManagers sharing the same LockStore compete for the same locks, which can be used to simulate multiple
+// application instances within a single process (e.g. in tests)
+type LockStore struct {
+	mtx     sync.Mutex
+	cond    *xsync.Cond
+	entries map[string]*storeEntry
+	tokens  map[string]uint64
+}
+
+type storeEntry struct {
+	limit   int
+	holders map[string]int
+}
+
+func NewLockStore() *LockStore {
+	s := &LockStore{
+		entries: map[string]*storeEntry{},
+		tokens:  map[string]uint64{},
+	}
+	s.cond = xsync.NewCond(&s.mtx)
+	return s
+}
+
+// Revoke forcibly removes all holders of given key, as if the lock is revoked by operator.
+// Affected locks would signal Lost channel and try to re-acquire the lock
+func (s *LockStore) Revoke(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.entries[key]; ok {
+		delete(s.entries, key)
+		s.cond.Broadcast()
+	}
+}
+
+// acquire blocks until the holder is granted given weight of the key or the context is cancelled.
+// onFailure is invoked each time the attempt failed. The returned token is the fencing token of this acquisition.
+// Note: onFailure is invoked while store's mutex is held
+func (s *LockStore) acquire(ctx context.Context, key, holder string, weight, limit int, onFailure func(error)) (uint64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for {
+		if e := ctx.Err(); e != nil {
+			return 0, e
+		}
+		e := s.tryAcquire(key, holder, weight, limit)
+		if e == nil {
+			s.tokens[key]++
+			return s.tokens[key], nil
+		}
+		onFailure(e)
+		if e := s.cond.Wait(ctx); e != nil {
+			return 0, e
+		}
+	}
+}
+
+// monitor blocks until the holder no longer holds the key or the context is cancelled
+func (s *LockStore) monitor(ctx context.Context, key, holder string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for {
+		if entry, ok := s.entries[key]; !ok || entry.holders[holder] == 0 {
+			return fmt.Errorf("lock [%s] is revoked", key)
+		}
+		if e := s.cond.Wait(ctx); e != nil {
+			return e
+		}
+	}
+}
+
+func (s *LockStore) release(key, holder string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	if _, ok := entry.holders[holder]; !ok {
+		return
+	}
+	delete(entry.holders, holder)
+	if len(entry.holders) == 0 {
+		delete(s.entries, key)
+	}
+	s.cond.Broadcast()
+}
+
+// tryAcquire grants the holder given weight if possible. mutex lock is required when call this function
+func (s *LockStore) tryAcquire(key, holder string, weight, limit int) error {
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &storeEntry{limit: limit, holders: map[string]int{}}
+		s.entries[key] = entry
+	}
+	if _, ok := entry.holders[holder]; ok {
+		return nil
+	}
+	if entry.limit != limit {
+		return fmt.Errorf("lock [%s] is held with different limit", key)
+	}
+	var used int
+	for _, w := range entry.holders {
+		used += w
+	}
+	if used+weight > limit {
+		return errNoPermit
+	}
+	entry.holders[holder] = weight
+	return nil
+}
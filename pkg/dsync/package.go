@@ -65,7 +65,8 @@ func initialize(di initDI) error {
 	}
 	if syncManager == nil {
 		return ErrFailedInitialization.WithMessage(`unable to initialize distributed lock system and leadership lock. ` +
-			`Hint: provide a dsync.SyncManager with 'consuldsync.Use()' or 'redisdsync.Use()' or with your own implementation `)
+			`Hint: provide a dsync.SyncManager with 'consuldsync.Use()', 'redisdsync.Use()', 'postgresdsync.Use()', 'inmemorydsync.Use()' ` +
+			`or with your own implementation `)
 	}
 	syncLc, ok := syncManager.(SyncManagerLifecycle)
 
@@ -0,0 +1,275 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresdsync_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+/*************************
+	Fake PostgreSQL
+ *************************/
+
+var errFakeServerDown = errors.New("connection refused")
+
+// FakePostgres is an in-memory database/sql driver that understands the few statements used by PostgresSyncManager.
+// Advisory locks follow PostgreSQL's semantics: they are bound to the connection (session), reentrant within the same
+// session, and released when the session is closed.
+type FakePostgres struct {
+	// Version is the result of "SELECT version()"
+	Version  string
+	mtx      sync.Mutex
+	down     bool
+	connects int
+	seq      int64
+	conns    map[*fakeConn]struct{}
+	locks    map[int64]*advisoryHolders
+}
+
+type advisoryHolders struct {
+	exclusive map[*fakeConn]int
+	shared    map[*fakeConn]int
+}
+
+func NewFakePostgres() *FakePostgres {
+	return &FakePostgres{
+		Version: "PostgreSQL 16.0 (fake)",
+		conns:   map[*fakeConn]struct{}{},
+		locks:   map[int64]*advisoryHolders{},
+	}
+}
+
+// DB returns a connection pool of this fake server
+func (s *FakePostgres) DB() *sql.DB {
+	return sql.OpenDB(s)
+}
+
+// Shutdown simulates server down. All existing sessions are terminated and new sessions are refused.
+func (s *FakePostgres) Shutdown() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.down = true
+	for c := range s.conns {
+		s.terminate(c)
+	}
+}
+
+// Restart recovers the server from Shutdown
+func (s *FakePostgres) Restart() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.down = false
+}
+
+// Connects returns the number of attempts of opening new session
+func (s *FakePostgres) Connects() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.connects
+}
+
+// Sessions returns the number of live sessions
+func (s *FakePostgres) Sessions() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.conns)
+}
+
+func (s *FakePostgres) Connect(_ context.Context) (driver.Conn, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.connects++
+	if s.down {
+		return nil, errFakeServerDown
+	}
+	c := &fakeConn{server: s}
+	s.conns[c] = struct{}{}
+	return c, nil
+}
+
+func (s *FakePostgres) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// terminate requires mutex lock
+func (s *FakePostgres) terminate(c *fakeConn) {
+	c.dead = true
+	delete(s.conns, c)
+	for _, h := range s.locks {
+		delete(h.exclusive, c)
+		delete(h.shared, c)
+	}
+}
+
+func (s *FakePostgres) query(c *fakeConn, query string, args []driver.NamedValue) (driver.Value, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if c.dead {
+		return nil, driver.ErrBadConn
+	}
+	var key int64
+	if len(args) != 0 {
+		key, _ = args[0].Value.(int64)
+	}
+	switch {
+	case strings.HasPrefix(query, "SELECT version()"):
+		return s.Version, nil
+	case strings.HasPrefix(query, "CREATE SEQUENCE"):
+		return nil, nil
+	case strings.HasPrefix(query, "SELECT nextval("):
+		s.seq++
+		return s.seq, nil
+	case strings.HasPrefix(query, "SELECT pg_try_advisory_lock_shared("):
+		return s.tryLock(c, key, true), nil
+	case strings.HasPrefix(query, "SELECT pg_try_advisory_lock("):
+		return s.tryLock(c, key, false), nil
+	case strings.HasPrefix(query, "SELECT pg_advisory_unlock_shared("):
+		return s.unlock(c, key, true), nil
+	case strings.HasPrefix(query, "SELECT pg_advisory_unlock("):
+		return s.unlock(c, key, false), nil
+	default:
+		return nil, fmt.Errorf("unsupported statement: %s", query)
+	}
+}
+
+// tryLock requires mutex lock. Locks held by the same session never conflict
+func (s *FakePostgres) tryLock(c *fakeConn, key int64, shared bool) bool {
+	h, ok := s.locks[key]
+	if !ok {
+		h = &advisoryHolders{exclusive: map[*fakeConn]int{}, shared: map[*fakeConn]int{}}
+		s.locks[key] = h
+	}
+	holders := []map[*fakeConn]int{h.exclusive}
+	if !shared {
+		holders = append(holders, h.shared)
+	}
+	for _, m := range holders {
+		for holder := range m {
+			if holder != c {
+				return false
+			}
+		}
+	}
+	if shared {
+		h.shared[c]++
+	} else {
+		h.exclusive[c]++
+	}
+	return true
+}
+
+// unlock requires mutex lock
+func (s *FakePostgres) unlock(c *fakeConn, key int64, shared bool) bool {
+	h, ok := s.locks[key]
+	if !ok {
+		return false
+	}
+	m := h.exclusive
+	if shared {
+		m = h.shared
+	}
+	if m[c] == 0 {
+		return false
+	}
+	if m[c]--; m[c] == 0 {
+		delete(m, c)
+	}
+	return true
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(_ string) (driver.Conn, error) {
+	return nil, fmt.Errorf("use FakePostgres.DB() instead")
+}
+
+type fakeConn struct {
+	server *FakePostgres
+	dead   bool
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	v, e := c.server.query(c, query, args)
+	if e != nil {
+		return nil, e
+	}
+	return &fakeRows{value: v}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, e := c.server.query(c, query, args); e != nil {
+		return nil, e
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) Ping(_ context.Context) error {
+	if !c.IsValid() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *fakeConn) IsValid() bool {
+	c.server.mtx.Lock()
+	defer c.server.mtx.Unlock()
+	return !c.dead
+}
+
+func (c *fakeConn) Prepare(_ string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statement is not supported")
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transaction is not supported")
+}
+
+func (c *fakeConn) Close() error {
+	c.server.mtx.Lock()
+	defer c.server.mtx.Unlock()
+	c.server.terminate(c)
+	return nil
+}
+
+type fakeRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"?column?"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
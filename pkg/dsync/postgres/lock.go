@@ -0,0 +1,413 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresdsync
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/utils/xsync"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type lockState int
+
+const (
+	stateUnknown lockState = iota
+	stateAcquired
+	stateError
+)
+
+const (
+	sqlTryLock       = `SELECT pg_try_advisory_lock($1)`
+	sqlTryLockShared = `SELECT pg_try_advisory_lock_shared($1)`
+	sqlUnlock        = `SELECT pg_advisory_unlock($1)`
+	sqlUnlockShared  = `SELECT pg_advisory_unlock_shared($1)`
+)
+
+type PostgresLockOptions func(opt *PostgresLockOption)
+type PostgresLockOption struct {
+	Context context.Context
+	// SessionFunc opens a dedicated database connection (session). Session-level advisory locks are bound to the
+	// connection, and are released automatically by the server when the connection is closed.
+	SessionFunc func(context.Context) (*sql.Conn, error)
+	Key         string
+	// Slots is the number of advisory lock keys the lock may take. Any one of them grants the lock.
+	// It's 1 for mutex lock and number of permits for semaphore
+	Slots int
+	// Shared indicates the advisory lock is taken in shared mode. Used by readers of RWLock
+	Shared bool
+	// RetryDelay how long we wait before next attempt when the lock is held by others or error occurred
+	RetryDelay time.Duration
+	// HeartbeatInterval how often the session is checked while the lock is held
+	HeartbeatInterval time.Duration
+	// Timeout of each database statement
+	Timeout time.Duration
+	// FencingTokenFunc generates fencing token each time the lock is acquired. See dsync.Lock FencingToken.
+	// Optional, no fencing token is available if not set
+	FencingTokenFunc func(ctx context.Context) (uint64, error)
+}
+
+// PostgresLock implements dsync.Lock using session-level advisory locks.
+// See https://www.postgresql.org/docs/current/explicit-locking.html#ADVISORY-LOCKS
+type PostgresLock struct {
+	mtx    sync.Mutex
+	option PostgresLockOption
+	keys   []int64
+	// State Variables, requires mutex lock to read and write
+	loopContext    context.Context
+	loopCancelFunc context.CancelFunc
+	lockLostCh     chan struct{}
+	state          lockState
+	stateCond      *xsync.Cond
+	lastErr        error
+	token          uint64
+}
+
+func newPostgresLock(opts ...PostgresLockOptions) (lock *PostgresLock) {
+	opt := PostgresLockOption{
+		Context:           context.Background(),
+		Slots:             1,
+		RetryDelay:        1 * time.Second,
+		HeartbeatInterval: 5 * time.Second,
+		Timeout:           5 * time.Second,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	keys := make([]int64, opt.Slots)
+	for i := range keys {
+		keys[i] = advisoryKey(opt.Key, i)
+	}
+	// we start with a closed lost channel
+	defer func() {
+		lock.lockLostCh = make(chan struct{}, 1)
+		close(lock.lockLostCh)
+		lock.stateCond = xsync.NewCond(&lock.mtx)
+	}()
+	return &PostgresLock{
+		option: opt,
+		keys:   keys,
+	}
+}
+
+func (l *PostgresLock) Key() string {
+	return l.option.Key
+}
+
+func (l *PostgresLock) Lock(ctx context.Context) error {
+	l.lazyStart()
+	return l.waitForState(ctx, func(state lockState) (bool, error) {
+		switch {
+		case l.state == stateAcquired:
+			return true, nil
+		case l.loopContext == nil:
+			return true, context.Canceled
+		}
+		return false, nil
+	})
+}
+
+func (l *PostgresLock) TryLock(ctx context.Context) error {
+	l.lazyStart()
+	// TryLock differ from Lock that it also return on any error state
+	return l.waitForState(ctx, func(state lockState) (bool, error) {
+		switch {
+		case l.state == stateAcquired:
+			return true, nil
+		case l.state == stateError:
+			return true, l.lastErr
+		case l.loopContext == nil:
+			return true, context.Canceled
+		}
+		return false, nil
+	})
+}
+
+func (l *PostgresLock) Release() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	// Stop lock loop. Releasing the lock happens in the loop
+	if l.loopContext != nil {
+		l.stopLoop()
+	}
+	return nil
+}
+
+func (l *PostgresLock) Lost() <-chan struct{} {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.lockLostCh
+}
+
+func (l *PostgresLock) FencingToken() uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.state != stateAcquired || l.loopContext == nil {
+		return 0
+	}
+	return l.token
+}
+
+func (l *PostgresLock) lazyStart() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	// Check if we're already maintaining the lock loop
+	if l.loopContext == nil {
+		l.loopContext, l.loopCancelFunc = context.WithCancel(l.option.Context)
+		go l.lockLoop(l.loopContext, l.loopCancelFunc)
+	}
+}
+
+// stopLoop stop lock loop. mutex lock is required when call this function
+func (l *PostgresLock) stopLoop() {
+	if l.loopCancelFunc != nil {
+		l.loopCancelFunc()
+	}
+	l.loopContext = nil
+	l.loopCancelFunc = nil
+}
+
+func (l *PostgresLock) waitForState(ctx context.Context, stateMatcher func(lockState) (bool, error)) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for {
+		if ok, e := stateMatcher(l.state); ok {
+			return e
+		}
+		switch e := l.stateCond.Wait(ctx); {
+		case errors.Is(e, context.Canceled) || errors.Is(e, context.DeadlineExceeded):
+			return e
+		}
+	}
+}
+
+// updateState atomically update state, execute additional setters and broadcast the change.
+func (l *PostgresLock) updateState(s lockState, setters ...func()) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for _, fn := range setters {
+		fn()
+	}
+
+	if s == stateAcquired && l.state != s {
+		l.lockLostCh = make(chan struct{}, 1)
+	} else if l.state == stateAcquired && l.state != s {
+		close(l.lockLostCh)
+	}
+
+	if s == stateError || l.state != s {
+		defer l.stateCond.Broadcast()
+	}
+	l.state = s
+}
+
+// lockLoop is the main loop of attempting to maintain the lock.
+// The lock state loop between Acquired and Error
+// Note: given context may also be cancelled outside, e.g. lock is released
+func (l *PostgresLock) lockLoop(ctx context.Context, cancelFunc context.CancelFunc) {
+	defer cancelFunc()
+	defer l.updateState(stateUnknown)
+
+	// the session is kept across attempts, and discarded on any error
+	var conn *sql.Conn
+	defer func() {
+		if conn != nil {
+			closeConn(conn)
+		}
+	}()
+	for ctx.Err() == nil {
+		if conn == nil {
+			var e error
+			switch conn, e = l.openSession(ctx); {
+			case e != nil && ctx.Err() != nil:
+				// current acquisition is cancelled
+				continue
+			case e != nil:
+				l.updateState(stateError, func() {
+					l.lastErr = dsync.ErrLockUnavailable.WithMessage(`unable to open database session for lock [%s]`, l.option.Key).WithCause(e)
+				})
+				l.delay(ctx, l.option.RetryDelay)
+				continue
+			}
+		}
+
+		// try to acquire lock
+		key, e := l.tryLock(ctx, conn)
+		switch {
+		case e != nil && ctx.Err() != nil:
+			// current acquisition is cancelled
+			continue
+		case e != nil:
+			closeConn(conn)
+			conn = nil
+			l.updateState(stateError, func() {
+				l.lastErr = dsync.ErrLockUnavailable.WithMessage(`unable to acquire lock [%s]`, l.option.Key).WithCause(e)
+			})
+			l.delay(ctx, l.option.RetryDelay)
+			continue
+		case key == 0:
+			l.updateState(stateError, func() {
+				l.lastErr = dsync.ErrLockUnavailable.WithMessage(`lock [%s] is held by another session`, l.option.Key)
+			})
+			l.delay(ctx, l.option.RetryDelay)
+			continue
+		}
+
+		// lock acquired, generate fencing token
+		token, e := l.nextFencingToken(ctx)
+		if e != nil {
+			l.unlock(conn, key)
+			l.updateState(stateError, func() {
+				l.lastErr = dsync.ErrLockUnavailable.WithMessage(`unable to generate fencing token for lock [%s]`, l.option.Key).WithCause(e)
+			})
+			l.delay(ctx, l.option.RetryDelay)
+			continue
+		}
+		logger.WithContext(ctx).Debugf("acquired lock [%s] with fencing token %d", l.option.Key, token)
+		l.updateState(stateAcquired, func() {
+			l.lastErr = nil
+			l.token = token
+		})
+
+		// up to this point, we have acquired the lock. The lock is held until released or session is lost
+		if e := l.keepSession(ctx, conn); e != nil {
+			logger.WithContext(ctx).Debugf("lost lock [%s] - session lost: %v", l.option.Key, e)
+			closeConn(conn)
+			conn = nil
+			l.updateState(stateError, func() {
+				l.lastErr = dsync.ErrLockUnavailable.WithMessage(`lock [%s] is lost due to session lost`, l.option.Key).WithCause(e)
+			})
+			continue
+		}
+		l.unlock(conn, key)
+	}
+}
+
+func (l *PostgresLock) openSession(ctx context.Context) (*sql.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.option.Timeout)
+	defer cancel()
+	return l.option.SessionFunc(ctx)
+}
+
+// keepSession periodically checks the session. It returns error when the session is lost,
+// or nil when given context is cancelled
+func (l *PostgresLock) keepSession(ctx context.Context, conn *sql.Conn) error {
+	for {
+		select {
+		case <-time.After(l.option.HeartbeatInterval):
+		case <-ctx.Done():
+			return nil
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, l.option.Timeout)
+		e := conn.PingContext(pingCtx)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case e != nil:
+			return e
+		}
+	}
+}
+
+// tryLock attempts to take any of the lock's advisory keys in random order. Returns the key taken or 0 if none is available
+func (l *PostgresLock) tryLock(ctx context.Context, conn *sql.Conn) (int64, error) {
+	query := sqlTryLock
+	if l.option.Shared {
+		query = sqlTryLockShared
+	}
+	offset := rand.Intn(len(l.keys))
+	for i := range l.keys {
+		key := l.keys[(i+offset)%len(l.keys)]
+		var ok bool
+		if e := l.exec(ctx, conn, query, key, &ok); e != nil {
+			return 0, e
+		}
+		if ok {
+			return key, nil
+		}
+	}
+	return 0, nil
+}
+
+// unlock releases given advisory key. Errors are ignored, since the lock is released by server when session is lost
+func (l *PostgresLock) unlock(conn *sql.Conn, key int64) {
+	query := sqlUnlock
+	if l.option.Shared {
+		query = sqlUnlockShared
+	}
+	var ok bool
+	if e := l.exec(context.Background(), conn, query, key, &ok); e != nil || !ok {
+		logger.WithContext(l.option.Context).Debugf("failed to release lock [%s]: %v", l.option.Key, e)
+	}
+}
+
+func (l *PostgresLock) exec(ctx context.Context, conn *sql.Conn, query string, key int64, dest *bool) error {
+	ctx, cancel := context.WithTimeout(ctx, l.option.Timeout)
+	defer cancel()
+	return conn.QueryRowContext(ctx, query, key).Scan(dest)
+}
+
+func (l *PostgresLock) nextFencingToken(ctx context.Context) (uint64, error) {
+	if l.option.FencingTokenFunc == nil {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, l.option.Timeout)
+	defer cancel()
+	return l.option.FencingTokenFunc(ctx)
+}
+
+// wait for given delay, return true if the delay is fulfilled (not cancelled by context)
+func (l *PostgresLock) delay(ctx context.Context, delay time.Duration) (success bool) {
+	select {
+	case <-time.After(delay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+/***********************
+	helpers
+ ***********************/
+
+// closeConn discards the connection instead of returning it to the pool,
+// so the server would release any advisory locks held by it
+func closeConn(conn *sql.Conn) {
+	_ = conn.Raw(func(_ interface{}) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
+
+// advisoryKey returns the 64-bit advisory lock key of given lock key and slot, 0 is never returned
+func advisoryKey(key string, slot int) int64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "dsync:%s#%d", key, slot)
+	if v := int64(h.Sum64()); v != 0 {
+		return v
+	}
+	return 1
+}
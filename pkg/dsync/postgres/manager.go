@@ -0,0 +1,250 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresdsync
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PostgresSyncManager implements SyncManager leveraging PostgreSQL's session-level advisory locks.
+// Each lock is held by its own dedicated database connection (session), which is periodically checked.
+// When the connection is lost, the server releases all advisory locks held by it and the lock is considered lost.
+// Dedicated connections are required because advisory locks are reentrant within the same session.
+// Note: every lock in use occupies one connection of the *gorm.DB's pool, the pool should be sized accordingly.
+//
+// Fencing tokens are generated from a database sequence, which is created during Start if not exists.
+//
+// Note: the database must implement advisory locks with actual mutual exclusion. CockroachDB accepts advisory lock
+// functions for compatibility, but they don't provide mutual exclusion. Start fails if CockroachDB is detected.
+// See https://www.postgresql.org/docs/current/explicit-locking.html#ADVISORY-LOCKS
+type PostgresSyncManager struct {
+	mtx        sync.Mutex
+	appCtx     *bootstrap.ApplicationContext
+	db         *gorm.DB
+	option     PostgresSyncOption
+	shutdown   bool
+	locks      map[string]*PostgresLock
+	semaphores map[string]*PostgresSemaphore
+	rwLocks    map[string]*PostgresRWLock
+}
+
+type PostgresSyncOptions func(opt *PostgresSyncOption)
+type PostgresSyncOption struct {
+	// HeartbeatInterval how often the dedicated connection of each acquired lock is checked
+	HeartbeatInterval time.Duration
+	// RetryDelay how long we wait after the lock is held by others or a retryable error (usually network error)
+	RetryDelay time.Duration
+	// Timeout of each database statement
+	Timeout time.Duration
+	// FencingTokenSequence is the name of database sequence used for generating fencing tokens.
+	// Fencing token is not available if set to empty
+	FencingTokenSequence string
+}
+
+func NewPostgresSyncManager(ctx *bootstrap.ApplicationContext, db *gorm.DB, opts ...PostgresSyncOptions) (ret *PostgresSyncManager) {
+	ret = &PostgresSyncManager{
+		appCtx: ctx,
+		db:     db,
+		option: PostgresSyncOption{
+			HeartbeatInterval:    5 * time.Second,
+			RetryDelay:           1 * time.Second,
+			Timeout:              5 * time.Second,
+			FencingTokenSequence: "dsync_fencing_token_seq",
+		},
+		locks:      make(map[string]*PostgresLock),
+		semaphores: make(map[string]*PostgresSemaphore),
+		rwLocks:    make(map[string]*PostgresRWLock),
+	}
+
+	for _, fn := range opts {
+		fn(&ret.option)
+	}
+	return
+}
+
+func (m *PostgresSyncManager) Start(ctx context.Context) error {
+	var version string
+	if e := m.db.WithContext(ctx).Raw(`SELECT version()`).Scan(&version).Error; e != nil {
+		return fmt.Errorf("unable to determine database version: %v", e)
+	}
+	if strings.Contains(strings.ToLower(version), "cockroach") {
+		return fmt.Errorf("advisory locks don't provide mutual exclusion on [%s], PostgreSQL is required", version)
+	}
+	if len(m.option.FencingTokenSequence) == 0 {
+		return nil
+	}
+	// sessions are lazily opened by each lock, we only prepare fencing token sequence here
+	e := m.db.WithContext(ctx).
+		Exec(`CREATE SEQUENCE IF NOT EXISTS ?`, clause.Table{Name: m.option.FencingTokenSequence}).Error
+	if e != nil {
+		return fmt.Errorf("unable to create fencing token sequence [%s]: %v", m.option.FencingTokenSequence, e)
+	}
+	return nil
+}
+
+func (m *PostgresSyncManager) Stop(ctx context.Context) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	// enter shutdown mode
+	m.shutdown = true
+	// release all existing locks
+	for _, l := range m.locks {
+		_ = l.Release()
+	}
+	for _, s := range m.semaphores {
+		_ = s.Release()
+	}
+	for _, l := range m.rwLocks {
+		_ = l.read.Release()
+		_ = l.write.Release()
+	}
+	return nil
+}
+
+func (m *PostgresSyncManager) Lock(key string, _ ...dsync.LockOptions) (dsync.Lock, error) {
+	if key == "" {
+		return nil, fmt.Errorf(`cannot create distributed lock: key is required but missing`)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.shutdown {
+		return nil, dsync.ErrSyncManagerStopped
+	} else if lock, ok := m.locks[key]; ok {
+		return lock, nil
+	}
+	m.locks[key] = newPostgresLock(m.lockOptions(key, 1, false))
+	return m.locks[key], nil
+}
+
+func (m *PostgresSyncManager) Semaphore(key string, permits int, _ ...dsync.LockOptions) (dsync.Semaphore, error) {
+	switch {
+	case key == "":
+		return nil, fmt.Errorf(`cannot create distributed semaphore: key is required but missing`)
+	case permits <= 0:
+		return nil, fmt.Errorf(`cannot create distributed semaphore: permits should be positive, but got %d`, permits)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.shutdown {
+		return nil, dsync.ErrSyncManagerStopped
+	} else if s, ok := m.semaphores[key]; ok {
+		return s, nil
+	}
+	lock := newPostgresLock(m.lockOptions(key, permits, false))
+	m.semaphores[key] = &PostgresSemaphore{PostgresLock: lock, permits: permits}
+	return m.semaphores[key], nil
+}
+
+func (m *PostgresSyncManager) RWLock(key string, _ ...dsync.LockOptions) (dsync.RWLock, error) {
+	if key == "" {
+		return nil, fmt.Errorf(`cannot create distributed read-write lock: key is required but missing`)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.shutdown {
+		return nil, dsync.ErrSyncManagerStopped
+	} else if l, ok := m.rwLocks[key]; ok {
+		return l, nil
+	}
+	m.rwLocks[key] = &PostgresRWLock{
+		key:   key,
+		read:  newPostgresLock(m.lockOptions(key, 1, true)),
+		write: newPostgresLock(m.lockOptions(key, 1, false)),
+	}
+	return m.rwLocks[key], nil
+}
+
+func (m *PostgresSyncManager) lockOptions(key string, slots int, shared bool) PostgresLockOptions {
+	return func(opt *PostgresLockOption) {
+		opt.Context = m.appCtx
+		opt.SessionFunc = m.openConn
+		opt.HeartbeatInterval = m.option.HeartbeatInterval
+		opt.Key = key
+		opt.Slots = slots
+		opt.Shared = shared
+		opt.RetryDelay = m.option.RetryDelay
+		opt.Timeout = m.option.Timeout
+		if len(m.option.FencingTokenSequence) != 0 {
+			opt.FencingTokenFunc = m.nextFencingToken
+		}
+	}
+}
+
+// nextFencingToken generates fencing token using database sequence. The sequence is shared by all lock keys,
+// so the token is monotonic for each individual key as well
+func (m *PostgresSyncManager) nextFencingToken(ctx context.Context) (uint64, error) {
+	var token uint64
+	if e := m.db.WithContext(ctx).Raw(`SELECT nextval(?)`, m.option.FencingTokenSequence).Scan(&token).Error; e != nil {
+		return 0, e
+	}
+	return token, nil
+}
+
+// openConn opens a dedicated connection from the pool
+func (m *PostgresSyncManager) openConn(ctx context.Context) (*sql.Conn, error) {
+	sqlDB, e := m.db.DB()
+	if e != nil {
+		return nil, e
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.option.Timeout)
+	defer cancel()
+	return sqlDB.Conn(ctx)
+}
+
+// PostgresSemaphore implements dsync.Semaphore
+type PostgresSemaphore struct {
+	*PostgresLock
+	permits int
+}
+
+func (s *PostgresSemaphore) Permits() int {
+	return s.permits
+}
+
+// PostgresRWLock implements dsync.RWLock
+type PostgresRWLock struct {
+	key   string
+	read  *PostgresLock
+	write *PostgresLock
+}
+
+func (l *PostgresRWLock) Key() string {
+	return l.key
+}
+
+func (l *PostgresRWLock) ReadLock() dsync.Lock {
+	return l.read
+}
+
+func (l *PostgresRWLock) WriteLock() dsync.Lock {
+	return l.write
+}
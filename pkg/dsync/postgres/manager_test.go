@@ -0,0 +1,495 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresdsync_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	postgresdsync "github.com/cisco-open/go-lanai/pkg/dsync/postgres"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"testing"
+	"time"
+)
+
+/*************************
+	Tests
+ *************************/
+
+type TestPostgresDsyncDI struct {
+	fx.In
+	AppCtx *bootstrap.ApplicationContext
+}
+
+func TestPostgresDSyncManager(t *testing.T) {
+	di := TestPostgresDsyncDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithDI(&di),
+		test.GomegaSubTest(SubTestTryLock(&di), "TestTryLock"),
+		test.GomegaSubTest(SubTestLockAndRelease(&di), "TestLockAndRelease"),
+		test.GomegaSubTest(SubTestLockRecovery(&di), "TestSessionLostRecovery"),
+		test.GomegaSubTest(SubTestSessionUnavailable(&di), "TestSessionUnavailable"),
+		test.GomegaSubTest(SubTestCancelledContext(&di), "TestCancelledContext"),
+		test.GomegaSubTest(SubTestDedicatedSession(&di), "TestDedicatedSession"),
+		test.GomegaSubTest(SubTestSemaphore(&di), "TestSemaphore"),
+		test.GomegaSubTest(SubTestRWLock(&di), "TestRWLock"),
+		test.GomegaSubTest(SubTestFencingToken(&di), "TestFencingToken"),
+		test.GomegaSubTest(SubTestCockroachDB(&di), "TestCockroachDB"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestTryLock(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "try-lock-test"
+		mgts := NewSyncManagers(di, g, NewFakePostgres())
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		var e error
+		var lock1, lock2 dsync.Lock
+		var stopFn func()
+
+		// obtain locks
+		lock1, stopFn = GetTestLock(g, mgts.Main, lockKey)
+		defer stopFn()
+		lock2, stopFn = GetTestLock(g, mgts.Secondary, lockKey)
+		defer stopFn()
+
+		// main lock - 1st pass
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		e = lock1.TryLock(timeoutCtx)
+		g.Expect(e).To(Succeed(), "TryLock should not fail when lock is acquirable")
+
+		// minor lock - try
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		e = lock2.TryLock(timeoutCtx)
+		g.Expect(e).To(HaveOccurred(), "TryLock should fail when lock is not acquirable")
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "TryLock should fail with correct error")
+
+		// 2nd pass
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		e = lock1.TryLock(timeoutCtx)
+		g.Expect(e).To(Succeed(), "TryLock should not fail when lock is already acquired")
+	}
+}
+
+func SubTestLockAndRelease(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "lock-test"
+		mgts := NewSyncManagers(di, g, NewFakePostgres())
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 200 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		var e error
+		var lock1, lock2 dsync.Lock
+		var stopFn1, stopFn2 func()
+
+		// obtain locks
+		lock1, stopFn1 = GetTestLock(g, mgts.Main, lockKey)
+		lock2, stopFn2 = GetTestLock(g, mgts.Secondary, lockKey)
+
+		// main lock - acquire
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		e = lock1.Lock(timeoutCtx)
+		g.Expect(e).To(Succeed(), "Lock should not fail when lock is acquirable")
+
+		// minor lock - acquire
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		e = lock2.Lock(timeoutCtx)
+		g.Expect(e).To(HaveOccurred(), "Lock should timeout when lock is not acquirable")
+
+		// release main lock in another thread, wait until released or timed out
+		go stopFn1()
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5000*time.Millisecond)
+		defer cancelFn()
+		e = lock2.Lock(timeoutCtx)
+		defer stopFn2()
+		g.Expect(e).To(Succeed(), "Lock should not fail after lock is released")
+	}
+}
+
+func SubTestLockRecovery(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "lock-recovery-test"
+		var heartbeat = 50 * time.Millisecond
+		server := NewFakePostgres()
+		mgts := NewSyncManagers(di, g, server, func(opt *postgresdsync.PostgresSyncOption) {
+			opt.HeartbeatInterval = heartbeat
+		})
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		var e error
+		var lock1 dsync.Lock
+		var stopFn func()
+
+		// obtain locks
+		lock1, stopFn = GetTestLock(g, mgts.Main, lockKey)
+		defer stopFn()
+
+		// main lock - acquire
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		e = lock1.Lock(timeoutCtx)
+		g.Expect(e).To(Succeed(), "Lock should not fail when lock is acquirable")
+
+		// cause some interruption
+		server.Shutdown()
+		defer server.Restart() // just in case
+
+		// Wait until revoked, validate Lost() channel works properly
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, heartbeat*10)
+		defer cancelFn()
+		select {
+		case <-lock1.Lost():
+			e = lock1.TryLock(timeoutCtx)
+			g.Expect(e).To(HaveOccurred(), "TryLock should fail when database become unavailable")
+		case <-timeoutCtx.Done():
+			t.Errorf("expect signal of lost lock after session lost, got nothing")
+		}
+
+		// finish interruption
+		server.Restart()
+
+		// Try to re-acquire after database is recovered
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5*time.Second)
+		defer cancelFn()
+		e = lock1.Lock(timeoutCtx)
+		g.Expect(e).To(Succeed(), "Lock should be eventually acquirable after session is recovered")
+	}
+}
+
+func SubTestSessionUnavailable(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "session-unavailable-test"
+		var retryDelay = 50 * time.Millisecond
+		server := NewFakePostgres()
+		mgts := NewSyncManagers(di, g, server, func(opt *postgresdsync.PostgresSyncOption) {
+			opt.RetryDelay = retryDelay
+		})
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+		lock1, stopFn := GetTestLock(g, mgts.Main, lockKey)
+		defer stopFn()
+
+		// database is down before the lock is acquired
+		server.Shutdown()
+		defer server.Restart() // just in case
+		connects := server.Connects()
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+		e := lock1.TryLock(timeoutCtx)
+		g.Expect(e).To(HaveOccurred(), "TryLock should fail when session is unavailable")
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "TryLock should fail with correct error")
+
+		// the lock loop should back off between attempts
+		time.Sleep(retryDelay * 5)
+		g.Expect(server.Connects()-connects).To(BeNumerically("<=", 10), "session should not be re-opened without delay")
+
+		// recover
+		server.Restart()
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5*time.Second)
+		defer cancelFn()
+		g.Expect(lock1.Lock(timeoutCtx)).To(Succeed(), "Lock should be eventually acquirable after session is available")
+	}
+}
+
+func SubTestCancelledContext(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "cancelled-context-test"
+		mgts := NewSyncManagers(di, g, NewFakePostgres())
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		var e error
+		var lock1, lock2 dsync.Lock
+		var stopFn1, stopFn2 func()
+
+		// obtain locks
+		lock1, stopFn1 = GetTestLock(g, mgts.Main, lockKey)
+		lock2, stopFn2 = GetTestLock(g, mgts.Secondary, lockKey)
+
+		// main lock - acquire
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		e = lock1.Lock(timeoutCtx)
+		g.Expect(e).To(Succeed(), "Lock should not fail when lock is acquirable")
+
+		// release lock1 AFTER context is cancelled,
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		time.AfterFunc(100*time.Millisecond, cancelFn)
+		time.AfterFunc(200*time.Millisecond, stopFn1)
+		e = lock2.Lock(timeoutCtx)
+		defer stopFn2()
+		g.Expect(e).To(HaveOccurred(), "Lock should fail before the lock become acquirable (cancelled)")
+	}
+}
+
+func SubTestDedicatedSession(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "dedicated-session-test"
+		server := NewFakePostgres()
+		mgts := NewSyncManagers(di, g, server)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 1000*time.Millisecond)
+		defer cancelFn()
+
+		// advisory locks are reentrant within a session, locks of the same manager should not share session
+		rw, e := mgts.Main.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+		g.Expect(rw.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there is no writer")
+		defer func() { _ = rw.ReadLock().Release() }()
+		e = rw.WriteLock().TryLock(timeoutCtx)
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "WriteLock should fail when there is a reader of the same manager")
+		_ = rw.WriteLock().Release()
+
+		// sessions are closed when locks are released
+		g.Expect(rw.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		g.Eventually(server.Sessions).WithTimeout(time.Second).WithPolling(10*time.Millisecond).
+			Should(BeZero(), "sessions should be closed after locks are released")
+	}
+}
+
+func SubTestSemaphore(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "semaphore-test"
+		const permits = 2
+		server := NewFakePostgres()
+		mgts := NewSyncManagers(di, g, server)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+		others := NewSyncManagers(di, g, server)
+		others.Start(ctx, g)
+		defer others.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		var e error
+
+		// obtain semaphores
+		sem1, stopFn1 := GetTestSemaphore(g, mgts.Main, key, permits)
+		sem2, stopFn2 := GetTestSemaphore(g, mgts.Secondary, key, permits)
+		sem3, stopFn3 := GetTestSemaphore(g, others.Main, key, permits)
+		defer stopFn2()
+		defer stopFn3()
+		g.Expect(sem1.Permits()).To(Equal(permits), "semaphore should have correct permits")
+		same, e := mgts.Main.Semaphore(key, permits)
+		g.Expect(e).To(Succeed(), "getting same semaphore should not fail")
+		g.Expect(same).To(BeIdenticalTo(sem1), "same semaphore should be returned with same key")
+
+		// acquire all permits
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		g.Expect(sem1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		g.Expect(sem2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		e = sem3.TryLock(timeoutCtx)
+		g.Expect(e).To(HaveOccurred(), "TryLock should fail when no permit is available")
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "TryLock should fail with correct error")
+
+		// release one permit
+		stopFn1()
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5000*time.Millisecond)
+		defer cancelFn()
+		g.Expect(sem3.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after a permit is released")
+		select {
+		case <-sem3.Lost():
+			t.Errorf("Lost() should not be signalled while permit is held")
+		default:
+		}
+
+		// invalid parameters
+		_, e = mgts.Main.Semaphore("", permits)
+		g.Expect(e).To(HaveOccurred(), "semaphore without key should fail")
+		_, e = mgts.Main.Semaphore(key+"-invalid", 0)
+		g.Expect(e).To(HaveOccurred(), "semaphore without positive permits should fail")
+	}
+}
+
+func SubTestRWLock(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "rwlock-test"
+		mgts := NewSyncManagers(di, g, NewFakePostgres())
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+
+		rw1, e := mgts.Main.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+		g.Expect(rw1.Key()).To(Equal(key), "read-write lock should have correct key")
+		rw2, e := mgts.Secondary.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+
+		// multiple readers
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		g.Expect(rw1.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there is no writer")
+		g.Expect(rw2.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there are other readers")
+		g.Expect(rw1.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there are readers")
+		_ = rw1.WriteLock().Release()
+
+		// single writer
+		g.Expect(rw1.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		g.Expect(rw2.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5000*time.Millisecond)
+		defer cancelFn()
+		g.Expect(rw1.WriteLock().Lock(timeoutCtx)).To(Succeed(), "WriteLock should not fail after readers released")
+		defer func() { _ = rw1.WriteLock().Release() }()
+		g.Expect(rw2.ReadLock().TryLock(timeoutCtx)).ToNot(Succeed(), "ReadLock should fail when there is a writer")
+		g.Expect(rw2.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there is a writer")
+		_ = rw2.ReadLock().Release()
+		_ = rw2.WriteLock().Release()
+	}
+}
+
+func SubTestFencingToken(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "fencing-token-test"
+		mgts := NewSyncManagers(di, g, NewFakePostgres())
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 5000 * time.Millisecond
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+
+		lock1, stopFn1 := GetTestLock(g, mgts.Main, lockKey)
+		lock2, stopFn2 := GetTestLock(g, mgts.Secondary, lockKey)
+		defer stopFn2()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available before acquired")
+
+		// first holder
+		g.Expect(lock1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when lock is acquirable")
+		token1 := lock1.FencingToken()
+		g.Expect(token1).ToNot(BeZero(), "fencing token should be available after acquired")
+		g.Expect(lock1.FencingToken()).To(Equal(token1), "fencing token should be stable while the lock is held")
+
+		// second holder
+		stopFn1()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available after released")
+		g.Expect(lock2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after lock is released")
+		g.Expect(lock2.FencingToken()).To(BeNumerically(">", token1), "fencing token should increase")
+	}
+}
+
+func SubTestCockroachDB(di *TestPostgresDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		server := NewFakePostgres()
+		server.Version = "CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)"
+		mgts := NewSyncManagers(di, g, server)
+		e := mgts.Main.Start(ctx)
+		g.Expect(e).To(HaveOccurred(), "starting manager on CockroachDB should fail")
+		g.Expect(e.Error()).To(ContainSubstring("mutual exclusion"), "error should explain why CockroachDB is not supported")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func GetTestLock(g *WithT, manager dsync.SyncManager, lockName string, opts ...dsync.LockOptions) (dsync.Lock, func()) {
+	lock, e := manager.Lock(lockName, opts...)
+	g.Expect(e).To(Succeed(), "getting lock should not fail")
+	g.Expect(lock).ToNot(BeNil(), "getting lock should not return nil")
+	return lock, func() {
+		e := lock.Release()
+		g.Expect(e).To(Succeed(), "Release should not fail")
+	}
+}
+
+func GetTestSemaphore(g *WithT, manager dsync.SemaphoreManager, key string, permits int, opts ...dsync.LockOptions) (dsync.Semaphore, func()) {
+	sem, e := manager.Semaphore(key, permits, opts...)
+	g.Expect(e).To(Succeed(), "getting semaphore should not fail")
+	g.Expect(sem).ToNot(BeNil(), "getting semaphore should not return nil")
+	return sem, func() {
+		e := sem.Release()
+		g.Expect(e).To(Succeed(), "Release should not fail")
+	}
+}
+
+// TestPostgresManagers to mimic distributed environment, we always needs multiple managers
+type TestPostgresManagers struct {
+	Main      *postgresdsync.PostgresSyncManager
+	Secondary *postgresdsync.PostgresSyncManager
+}
+
+func NewSyncManagers(di *TestPostgresDsyncDI, g *gomega.WithT, server *FakePostgres, opts ...postgresdsync.PostgresSyncOptions) TestPostgresManagers {
+	db, e := gorm.Open(postgres.New(postgres.Config{Conn: server.DB()}), &gorm.Config{
+		Logger: gormlogger.Discard,
+	})
+	g.Expect(e).To(Succeed(), "opening database for sync manager should not fail")
+	opts = append([]postgresdsync.PostgresSyncOptions{func(opt *postgresdsync.PostgresSyncOption) {
+		opt.RetryDelay = 10 * time.Millisecond
+		opt.Timeout = 500 * time.Millisecond
+	}}, opts...)
+	ret := TestPostgresManagers{
+		Main:      postgresdsync.NewPostgresSyncManager(di.AppCtx, db, opts...),
+		Secondary: postgresdsync.NewPostgresSyncManager(di.AppCtx, db, opts...),
+	}
+	g.Expect(ret.Main).ToNot(BeNil(), "major postgres sync manager should not be nil")
+	g.Expect(ret.Secondary).ToNot(BeNil(), "minor postgres sync manager should not be nil")
+	return ret
+}
+
+func (m TestPostgresManagers) Start(ctx context.Context, g *gomega.WithT) {
+	e := m.Main.Start(ctx)
+	g.Expect(e).To(Succeed(), "starting major manager should not fail")
+	e = m.Secondary.Start(ctx)
+	g.Expect(e).To(Succeed(), "starting minor manager should not fail")
+}
+
+func (m TestPostgresManagers) Stop(ctx context.Context, g *gomega.WithT) {
+	e := m.Main.Stop(ctx)
+	g.Expect(e).To(Succeed(), "stopping major manager should not fail")
+	e = m.Secondary.Stop(ctx)
+	g.Expect(e).To(Succeed(), "stopping minor manager should not fail")
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresdsync
+
+import (
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/log"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"time"
+)
+
+var logger = log.New("DSync")
+
+var Module = &bootstrap.Module{
+	Name:       "distributed-postgres",
+	Precedence: bootstrap.DistributedLockPrecedence,
+	Options: []fx.Option{
+		fx.Provide(BindPostgresSyncProperties, provideSyncManager),
+	},
+	Modules: []*bootstrap.Module{dsync.Module},
+}
+
+func Use() {
+	bootstrap.Register(Module)
+}
+
+/**************************
+	Provider
+***************************/
+
+type syncDI struct {
+	fx.In
+	AppCtx     *bootstrap.ApplicationContext
+	Properties PostgresSyncProperties
+	DB         *gorm.DB `optional:"true"`
+}
+
+type syncOut struct {
+	fx.Out
+	Managers []dsync.SyncManager `group:"dsync,flatten"`
+}
+
+// provideSyncManager contributes the PostgreSQL dsync.SyncManager to FX group only when it's enabled
+func provideSyncManager(di syncDI) (syncOut, error) {
+	if !di.Properties.Enabled {
+		return syncOut{}, nil
+	}
+	if di.DB == nil {
+		return syncOut{}, fmt.Errorf("*gorm.DB is required for 'postgresdsync' package")
+	}
+	manager := NewPostgresSyncManager(di.AppCtx, di.DB, func(opt *PostgresSyncOption) {
+		opt.HeartbeatInterval = time.Duration(di.Properties.HeartbeatInterval)
+		opt.RetryDelay = time.Duration(di.Properties.RetryDelay)
+		opt.Timeout = time.Duration(di.Properties.Timeout)
+		opt.FencingTokenSequence = di.Properties.FencingTokenSequence
+	})
+	return syncOut{
+		Managers: []dsync.SyncManager{manager},
+	}, nil
+}
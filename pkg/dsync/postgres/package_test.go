@@ -0,0 +1,64 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresdsync_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	inmemorydsync "github.com/cisco-open/go-lanai/pkg/dsync/inmemory"
+	postgresdsync "github.com/cisco-open/go-lanai/pkg/dsync/postgres"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"testing"
+)
+
+/*************************
+	Tests
+ *************************/
+
+type TestModuleDI struct {
+	fx.In
+	Properties postgresdsync.PostgresSyncProperties
+	Managers   []dsync.SyncManager `group:"dsync"`
+}
+
+func TestModuleDisabled(t *testing.T) {
+	di := TestModuleDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithModules(postgresdsync.Module, inmemorydsync.Module),
+		apptest.WithProperties("dsync.in-memory.enabled: true"),
+		apptest.WithDI(&di),
+		test.GomegaSubTest(SubTestDisabledBackend(&di), "TestDisabledBackend"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestDisabledBackend(di *TestModuleDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		g.Expect(di.Properties.Enabled).To(BeFalse(), "postgres backend should be disabled by default")
+		g.Expect(di.Properties.FencingTokenSequence).ToNot(BeEmpty(), "fencing token sequence should have default value")
+		g.Expect(di.Managers).To(HaveLen(1), "only enabled SyncManager should be provided")
+		g.Expect(di.Managers[0]).To(BeAssignableToTypeOf(&inmemorydsync.InMemorySyncManager{}), "SyncManager should have correct type")
+	}
+}
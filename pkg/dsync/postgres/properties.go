@@ -0,0 +1,61 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresdsync
+
+import (
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	PropertiesPrefix = "dsync.postgres"
+)
+
+type PostgresSyncProperties struct {
+	// Enabled whether the PostgreSQL advisory lock based dsync.SyncManager is used. When enabled, it takes precedence
+	// over dsync.SyncManager provided by other packages (e.g. consuldsync, redisdsync)
+	Enabled bool `json:"enabled"`
+	// HeartbeatInterval see PostgresSyncOption.HeartbeatInterval
+	HeartbeatInterval utils.Duration `json:"heartbeat-interval"`
+	// RetryDelay see PostgresSyncOption.RetryDelay
+	RetryDelay utils.Duration `json:"retry-delay"`
+	// Timeout see PostgresSyncOption.Timeout
+	Timeout utils.Duration `json:"timeout"`
+	// FencingTokenSequence see PostgresSyncOption.FencingTokenSequence
+	FencingTokenSequence string `json:"fencing-token-sequence"`
+}
+
+// NewPostgresSyncProperties create a PostgresSyncProperties with default values
+func NewPostgresSyncProperties() *PostgresSyncProperties {
+	return &PostgresSyncProperties{
+		HeartbeatInterval:    utils.Duration(5 * time.Second),
+		RetryDelay:           utils.Duration(1 * time.Second),
+		Timeout:              utils.Duration(5 * time.Second),
+		FencingTokenSequence: "dsync_fencing_token_seq",
+	}
+}
+
+// BindPostgresSyncProperties create and bind PostgresSyncProperties
+func BindPostgresSyncProperties(ctx *bootstrap.ApplicationContext) PostgresSyncProperties {
+	props := NewPostgresSyncProperties()
+	if err := ctx.Config().Bind(props, PropertiesPrefix); err != nil {
+		panic(errors.Wrap(err, "failed to bind PostgresSyncProperties"))
+	}
+	return *props
+}
@@ -40,11 +40,24 @@ type RedisLockOption struct {
 	// MaxExtendRetries how many times we attempt to extend the lock before give up.
 	// Default is 3
 	MaxExtendRetries int
+	// FencingTokenFunc generates fencing token each time the lock is acquired. See dsync.Lock FencingToken.
+	// When the token cannot be generated, the acquisition is considered failed.
+	// Optional, no fencing token is available if not set
+	FencingTokenFunc func(ctx context.Context) (uint64, error)
+}
+
+// redisMutex is the underlying lock primitive of RedisLock. *redsync.Mutex is the default implementation
+type redisMutex interface {
+	Name() string
+	Until() time.Time
+	TryLockContext(ctx context.Context) error
+	ExtendContext(ctx context.Context) (bool, error)
+	Unlock() (bool, error)
 }
 
 type RedisLock struct {
 	mtx     sync.Mutex
-	rsMutex *redsync.Mutex
+	rsMutex redisMutex
 	option  RedisLockOption
 	// State Variables, requires mutex lock to read and write
 	loopContext    context.Context
@@ -53,9 +66,24 @@ type RedisLock struct {
 	state          lockState
 	stateCond      *xsync.Cond
 	lastErr        error
+	token          uint64
 }
 
 func newRedisLock(rs *redsync.Redsync, opts ...RedisLockOptions) (lock *RedisLock) {
+	opt := newRedisLockOption(opts...)
+	// Note: we only use TryLock and perform indefinite retries, so WithTries is set to 1 in order get proper error
+	// See redsync.Mutex.TryLockContext for details
+	rsMutex := rs.NewMutex(opt.Name,
+		redsync.WithExpiry(opt.AutoExpiry),
+		redsync.WithTries(1),
+		redsync.WithTimeoutFactor(opt.TimeoutFactor),
+		redsync.WithShufflePools(true),
+		redsync.WithGenValueFunc(genValueFunc(opt.Valuer)),
+	)
+	return newRedisLockWithMutex(rsMutex, opt)
+}
+
+func newRedisLockOption(opts ...RedisLockOptions) RedisLockOption {
 	opt := RedisLockOption{
 		Valuer: dsync.NewJsonLockValuer(map[string]string{
 			"name": "redis distributed lock",
@@ -68,16 +96,10 @@ func newRedisLock(rs *redsync.Redsync, opts ...RedisLockOptions) (lock *RedisLoc
 	for _, fn := range opts {
 		fn(&opt)
 	}
-	// Note: we only use TryLock and perform indefinite retries, so WithTries is set to 1 in order get proper error
-	// See redsync.Mutex.TryLockContext for details
-	rsMutex := rs.NewMutex(opt.Name,
-		redsync.WithExpiry(opt.AutoExpiry),
-		redsync.WithTries(1),
-		redsync.WithTimeoutFactor(opt.TimeoutFactor),
-		redsync.WithShufflePools(true),
-		redsync.WithGenValueFunc(genValueFunc(opt.Valuer)),
-	)
+	return opt
+}
 
+func newRedisLockWithMutex(rsMutex redisMutex, opt RedisLockOption) (lock *RedisLock) {
 	// we start with a closed lost channel
 	defer func() {
 		lock.lockLostCh = make(chan struct{}, 1)
@@ -133,6 +155,15 @@ func (l *RedisLock) Lost() <-chan struct{} {
 	return l.lockLostCh
 }
 
+func (l *RedisLock) FencingToken() uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.state != stateAcquired || l.loopContext == nil {
+		return 0
+	}
+	return l.token
+}
+
 func (l *RedisLock) lazyStart() {
 	l.mtx.Lock()
 	defer l.mtx.Unlock()
@@ -225,9 +256,21 @@ LOOP:
 			// current acquisition is cancelled
 			continue
 		case e == nil:
-			// lock acquired, continue
-			logger.WithContext(ctx).Debugf("acquired lock [%s]", l.option.Name)
-			l.updateState(stateAcquired, func() { l.lastErr = nil })
+			// lock acquired, generate fencing token
+			token, e := l.nextFencingToken(ctx)
+			if e != nil {
+				_, _ = l.rsMutex.Unlock()
+				l.updateState(stateError, func() {
+					l.lastErr = dsync.ErrLockUnavailable.WithMessage(`unable to generate fencing token for lock [%s]`, l.option.Name).WithCause(e)
+				})
+				l.delay(ctx, l.option.RetryDelay)
+				continue
+			}
+			logger.WithContext(ctx).Debugf("acquired lock [%s] with fencing token %d", l.option.Name, token)
+			l.updateState(stateAcquired, func() {
+				l.lastErr = nil
+				l.token = token
+			})
 		default:
 			l.updateState(stateError, func() {
 				l.lastErr = dsync.ErrLockUnavailable.WithMessage(`lock [%s] is held by another session`, l.option.Name).WithCause(e)
@@ -286,6 +329,13 @@ LOOP:
 	return err
 }
 
+func (l *RedisLock) nextFencingToken(ctx context.Context) (uint64, error) {
+	if l.option.FencingTokenFunc == nil {
+		return 0, nil
+	}
+	return l.option.FencingTokenFunc(ctx)
+}
+
 // wait for given delay, return true if the delay is fulfilled (not cancelled by context)
 func (l *RedisLock) delay(ctx context.Context, delay time.Duration) (success bool) {
 	select {
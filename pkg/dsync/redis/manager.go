@@ -42,19 +42,23 @@ func NewRedisSyncManager(appCtx *bootstrap.ApplicationContext, opts ...RedisSync
 	}
 
 	return &RedisSyncManager{
-		appCtx:  appCtx,
-		options: opt,
-		syncer:  redsync.New(pools...),
-		locks:   make(map[string]*RedisLock),
+		appCtx:     appCtx,
+		options:    opt,
+		syncer:     redsync.New(pools...),
+		locks:      make(map[string]*RedisLock),
+		semaphores: make(map[string]*RedisSemaphore),
+		rwLocks:    make(map[string]*RedisRWLock),
 	}
 }
 
 type RedisSyncManager struct {
-	appCtx   *bootstrap.ApplicationContext
-	options  RedisSyncOption
-	mtx      sync.Mutex
-	syncer   *redsync.Redsync
-	locks    map[string]*RedisLock
+	appCtx     *bootstrap.ApplicationContext
+	options    RedisSyncOption
+	mtx        sync.Mutex
+	syncer     *redsync.Redsync
+	locks      map[string]*RedisLock
+	semaphores map[string]*RedisSemaphore
+	rwLocks    map[string]*RedisRWLock
 }
 
 func (m *RedisSyncManager) Lock(key string, opts ...dsync.LockOptions) (dsync.Lock, error) {
@@ -84,10 +88,58 @@ func (m *RedisSyncManager) Lock(key string, opts ...dsync.LockOptions) (dsync.Lo
 		opt.AutoExpiry = m.options.TTL
 		opt.RetryDelay = m.options.RetryDelay
 		opt.TimeoutFactor = m.options.TimeoutFactor
+		opt.FencingTokenFunc = m.fencingTokenFunc(key)
 	})
 	return m.locks[key], nil
 }
 
+func (m *RedisSyncManager) Semaphore(key string, permits int, opts ...dsync.LockOptions) (dsync.Semaphore, error) {
+	switch {
+	case key == "":
+		return nil, fmt.Errorf(`cannot create distributed semaphore: key is required but missing`)
+	case permits <= 0:
+		return nil, fmt.Errorf(`cannot create distributed semaphore: permits should be positive, but got %d`, permits)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if s, ok := m.semaphores[key]; ok {
+		return s, nil
+	}
+
+	lock, e := m.newWeightedLock(key, 1, permits, "distributed semaphore", opts)
+	if e != nil {
+		return nil, e
+	}
+	m.semaphores[key] = &RedisSemaphore{RedisLock: lock, permits: permits}
+	return m.semaphores[key], nil
+}
+
+func (m *RedisSyncManager) RWLock(key string, opts ...dsync.LockOptions) (dsync.RWLock, error) {
+	if key == "" {
+		return nil, fmt.Errorf(`cannot create distributed read-write lock: key is required but missing`)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if l, ok := m.rwLocks[key]; ok {
+		return l, nil
+	}
+
+	read, e := m.newWeightedLock(key, 1, rwLockLimit, "distributed read lock", opts)
+	if e != nil {
+		return nil, e
+	}
+	write, e := m.newWeightedLock(key, rwLockLimit, rwLockLimit, "distributed write lock", opts)
+	if e != nil {
+		return nil, e
+	}
+	m.rwLocks[key] = &RedisRWLock{key: key, read: read, write: write}
+	return m.rwLocks[key], nil
+}
+
 func (m *RedisSyncManager) Start(_ context.Context) error {
 	return nil
 }
@@ -101,8 +153,78 @@ func (m *RedisSyncManager) Stop(_ context.Context) error {
 			failed = append(failed, k)
 		}
 	}
+	for k, s := range m.semaphores {
+		if e := s.Release(); e != nil {
+			failed = append(failed, k)
+		}
+	}
+	for k, l := range m.rwLocks {
+		if e := l.release(); e != nil {
+			failed = append(failed, k)
+		}
+	}
 	if len(failed) > 0 {
 		return dsync.ErrUnlockFailed.WithMessage(`unable to release locks %v`, failed)
 	}
 	return nil
 }
+
+// newWeightedLock create a RedisLock backed by weighted semaphore. mutex lock is required when call this function
+func (m *RedisSyncManager) newWeightedLock(key string, weight, limit int, name string, opts []dsync.LockOptions) (*RedisLock, error) {
+	lockOpt := dsync.LockOption{
+		Valuer: dsync.NewJsonLockValuer(map[string]string{
+			"name": fmt.Sprintf("%s - %s", name, m.appCtx.Name()),
+		}),
+	}
+	for _, fn := range opts {
+		fn(&lockOpt)
+	}
+
+	opt := newRedisLockOption(func(opt *RedisLockOption) {
+		opt.Context = m.appCtx
+		opt.Name = key
+		opt.Valuer = lockOpt.Valuer
+		opt.AutoExpiry = m.options.TTL
+		opt.RetryDelay = m.options.RetryDelay
+		opt.TimeoutFactor = m.options.TimeoutFactor
+		opt.FencingTokenFunc = m.fencingTokenFunc(key)
+	})
+	mutex, e := newRedisSemaphoreMutex(m.options.Clients, weight, limit, opt)
+	if e != nil {
+		return nil, e
+	}
+	return newRedisLockWithMutex(mutex, opt), nil
+}
+
+// fencingTokenFunc returns a function that generates fencing token of given lock key using INCR command.
+// The command is performed on all clients and the largest value is used, quorum is required.
+func (m *RedisSyncManager) fencingTokenFunc(key string) func(ctx context.Context) (uint64, error) {
+	counterKey := fmt.Sprintf("{%s}:fencing-token", key)
+	timeout := time.Duration(float64(m.options.TTL) * m.options.TimeoutFactor)
+	quorum := len(m.options.Clients)/2 + 1
+	return func(ctx context.Context) (uint64, error) {
+		var token uint64
+		var n int
+		var err error
+		for _, client := range m.options.Clients {
+			cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+			v, e := client.Incr(cmdCtx, counterKey).Uint64()
+			cancel()
+			switch {
+			case e != nil:
+				err = e
+				continue
+			case v > token:
+				token = v
+			}
+			n++
+		}
+		if n < quorum {
+			if err == nil {
+				err = fmt.Errorf("quorum is not reached")
+			}
+			return 0, err
+		}
+		return token, nil
+	}
+}
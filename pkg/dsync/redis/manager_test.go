@@ -56,6 +56,9 @@ func TestRedisDSyncManager(t *testing.T) {
 		test.GomegaSubTest(SubTestLockRecovery(&di, true), "TestRedisDownRecovery"),
 		test.GomegaSubTest(SubTestLockRecovery(&di, false), "TestRedisErrorRecovery"),
 		test.GomegaSubTest(SubTestCancelledContext(&di), "TestCancelledContext"),
+		test.GomegaSubTest(SubTestSemaphore(&di), "TestSemaphore"),
+		test.GomegaSubTest(SubTestRWLock(&di), "TestRWLock"),
+		test.GomegaSubTest(SubTestFencingToken(&di), "TestFencingToken"),
 	)
 }
 
@@ -245,6 +248,147 @@ func SubTestCancelledContext(di *TestRedisDsyncDI) test.GomegaSubTestFunc {
 	}
 }
 
+func SubTestSemaphore(di *TestRedisDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "semaphore-test"
+		const permits = 2
+		opts := func(opt *redisdsync.RedisSyncOption) {
+			opt.TTL = 1 * time.Second
+			opt.RetryDelay = 10 * time.Millisecond
+		}
+		mgts := NewSyncManagers(di, g, opts)
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+		others := NewSyncManagers(di, g, opts)
+		others.Start(ctx, g)
+		defer others.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+		var e error
+
+		// obtain semaphores
+		sem1, stopFn1 := GetTestSemaphore(g, mgts.Main, key, permits)
+		sem2, stopFn2 := GetTestSemaphore(g, mgts.Secondary, key, permits)
+		sem3, stopFn3 := GetTestSemaphore(g, others.Main, key, permits)
+		defer stopFn2()
+		defer stopFn3()
+		g.Expect(sem1.Permits()).To(Equal(permits), "semaphore should have correct permits")
+		same, e := mgts.Main.Semaphore(key, permits)
+		g.Expect(e).To(Succeed(), "getting same semaphore should not fail")
+		g.Expect(same).To(BeIdenticalTo(sem1), "same semaphore should be returned with same key")
+
+		// acquire all permits
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		g.Expect(sem1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		g.Expect(sem2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when permit is available")
+		e = sem3.TryLock(timeoutCtx)
+		g.Expect(e).To(HaveOccurred(), "TryLock should fail when no permit is available")
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "TryLock should fail with correct error")
+
+		// release one permit
+		stopFn1()
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5000*time.Millisecond)
+		defer cancelFn()
+		g.Expect(sem3.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after a permit is released")
+		select {
+		case <-sem3.Lost():
+			t.Errorf("Lost() should not be signalled while permit is held")
+		default:
+		}
+
+		// invalid parameters
+		_, e = mgts.Main.Semaphore("", permits)
+		g.Expect(e).To(HaveOccurred(), "semaphore without key should fail")
+		_, e = mgts.Main.Semaphore(key+"-invalid", 0)
+		g.Expect(e).To(HaveOccurred(), "semaphore without positive permits should fail")
+	}
+}
+
+func SubTestRWLock(di *TestRedisDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const key = "rwlock-test"
+		mgts := NewSyncManagers(di, g, func(opt *redisdsync.RedisSyncOption) {
+			opt.TTL = 1 * time.Second
+			opt.RetryDelay = 10 * time.Millisecond
+		})
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 1000 * time.Millisecond
+		var timeoutCtx context.Context
+		var cancelFn context.CancelFunc
+
+		rw1, e := mgts.Main.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+		g.Expect(rw1.Key()).To(Equal(key), "read-write lock should have correct key")
+		rw2, e := mgts.Secondary.RWLock(key)
+		g.Expect(e).To(Succeed(), "getting read-write lock should not fail")
+
+		// multiple readers
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+		g.Expect(rw1.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there is no writer")
+		g.Expect(rw2.ReadLock().Lock(timeoutCtx)).To(Succeed(), "ReadLock should not fail when there are other readers")
+		g.Expect(rw1.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there are readers")
+
+		// single writer
+		g.Expect(rw1.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		g.Expect(rw2.ReadLock().Release()).To(Succeed(), "Release should not fail")
+		timeoutCtx, cancelFn = context.WithTimeout(ctx, 5000*time.Millisecond)
+		defer cancelFn()
+		g.Expect(rw1.WriteLock().Lock(timeoutCtx)).To(Succeed(), "WriteLock should not fail after readers released")
+		defer func() { _ = rw1.WriteLock().Release() }()
+		g.Expect(rw2.ReadLock().TryLock(timeoutCtx)).ToNot(Succeed(), "ReadLock should fail when there is a writer")
+		g.Expect(rw2.WriteLock().TryLock(timeoutCtx)).ToNot(Succeed(), "WriteLock should fail when there is a writer")
+		_ = rw2.ReadLock().Release()
+		_ = rw2.WriteLock().Release()
+	}
+}
+
+func SubTestFencingToken(di *TestRedisDsyncDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "fencing-token-test"
+		mgts := NewSyncManagers(di, g, func(opt *redisdsync.RedisSyncOption) {
+			opt.TTL = 1 * time.Second
+			opt.RetryDelay = 10 * time.Millisecond
+		})
+		mgts.Start(ctx, g)
+		defer mgts.Stop(ctx, g)
+
+		var timeout = 5000 * time.Millisecond
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+
+		lock1, stopFn1 := GetTestLock(g, mgts.Main, lockKey)
+		lock2, stopFn2 := GetTestLock(g, mgts.Secondary, lockKey)
+		defer stopFn2()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available before acquired")
+		_, e := dsync.ContextWithLockFencingToken(ctx, lock1)
+		g.Expect(e).To(MatchError(dsync.ErrLockUnavailable), "context with fencing token should fail before acquired")
+
+		// first holder
+		g.Expect(lock1.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail when lock is acquirable")
+		token1 := lock1.FencingToken()
+		g.Expect(token1).ToNot(BeZero(), "fencing token should be available after acquired")
+		tokenCtx, e := dsync.ContextWithLockFencingToken(ctx, lock1)
+		g.Expect(e).To(Succeed(), "context with fencing token should not fail after acquired")
+		token, ok := dsync.FencingTokenFromContext(tokenCtx)
+		g.Expect(ok).To(BeTrue(), "fencing token should be available in context")
+		g.Expect(token).To(Equal(token1), "fencing token in context should be correct")
+
+		// second holder
+		stopFn1()
+		g.Expect(lock1.FencingToken()).To(BeZero(), "fencing token should not be available after released")
+		g.Expect(lock2.Lock(timeoutCtx)).To(Succeed(), "Lock should not fail after lock is released")
+		g.Expect(lock2.FencingToken()).To(BeNumerically(">", token1), "fencing token should increase")
+		_, ok = dsync.FencingTokenFromContext(ctx)
+		g.Expect(ok).To(BeFalse(), "fencing token should not be available in context without token")
+	}
+}
+
 /*************************
 	Helpers
  *************************/
@@ -259,6 +403,16 @@ func GetTestLock(g *WithT, manager dsync.SyncManager, lockName string, opts ...d
 	}
 }
 
+func GetTestSemaphore(g *WithT, manager dsync.SemaphoreManager, key string, permits int, opts ...dsync.LockOptions) (dsync.Semaphore, func()) {
+	sem, e := manager.Semaphore(key, permits, opts...)
+	g.Expect(e).To(Succeed(), "getting semaphore should not fail")
+	g.Expect(sem).ToNot(BeNil(), "getting semaphore should not return nil")
+	return sem, func() {
+		e := sem.Release()
+		g.Expect(e).To(Succeed(), "Release should not fail")
+	}
+}
+
 func StopRedisServer(ctx context.Context, g *WithT) {
 	server := embedded.CurrentRedisServer(ctx)
 	g.Expect(server).ToNot(BeNil(), "embedded redis server should be available")
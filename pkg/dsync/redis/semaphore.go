@@ -0,0 +1,238 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redisdsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	redislib "github.com/go-redis/redis/v8"
+	"math"
+	"sync"
+	"time"
+)
+
+// rwLockLimit is the total weight of a RWLock. Each reader takes weight of 1 and writer takes the full weight.
+const rwLockLimit = math.MaxInt32
+
+var errNoPermit = errors.New("no permit available")
+
+// RedisSemaphore implements dsync.Semaphore
+type RedisSemaphore struct {
+	*RedisLock
+	permits int
+}
+
+func (s *RedisSemaphore) Permits() int {
+	return s.permits
+}
+
+// RedisRWLock implements dsync.RWLock
+type RedisRWLock struct {
+	key   string
+	read  *RedisLock
+	write *RedisLock
+}
+
+func (l *RedisRWLock) Key() string {
+	return l.key
+}
+
+func (l *RedisRWLock) ReadLock() dsync.Lock {
+	return l.read
+}
+
+func (l *RedisRWLock) WriteLock() dsync.Lock {
+	return l.write
+}
+
+func (l *RedisRWLock) release() error {
+	e1 := l.read.Release()
+	e2 := l.write.Release()
+	if e1 != nil {
+		return e1
+	}
+	return e2
+}
+
+/***********************
+	Weighted Semaphore
+ ***********************/
+
+// acquireScript atomically prunes expired holders and grants the holder given weight if total weight doesn't exceed
+// the limit. Granted holder's expiry is refreshed.
+// KEYS[1]: hash of holder -> weight, KEYS[2]: sorted set of holder -> expiry in ms
+// ARGV[1]: holder, ARGV[2]: weight, ARGV[3]: limit, ARGV[4]: TTL in ms
+var acquireScript = redislib.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local ttl = tonumber(ARGV[4])
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', now)
+for _, h in ipairs(expired) do
+	redis.call('HDEL', KEYS[1], h)
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	local used = 0
+	for _, w in ipairs(redis.call('HVALS', KEYS[1])) do
+		used = used + tonumber(w)
+	end
+	if used + tonumber(ARGV[2]) > tonumber(ARGV[3]) then
+		return 0
+	end
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+redis.call('ZADD', KEYS[2], now + ttl, ARGV[1])
+for _, k in ipairs(KEYS) do
+	if redis.call('PTTL', k) < ttl then
+		redis.call('PEXPIRE', k, ttl)
+	end
+end
+return 1
+`)
+
+// extendScript refreshes holder's expiry if the holder still holds the permit
+// KEYS and ARGV are same as acquireScript
+var extendScript = redislib.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local ttl = tonumber(ARGV[4])
+local expiry = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 or not expiry or tonumber(expiry) < now then
+	return 0
+end
+redis.call('ZADD', KEYS[2], now + ttl, ARGV[1])
+for _, k in ipairs(KEYS) do
+	if redis.call('PTTL', k) < ttl then
+		redis.call('PEXPIRE', k, ttl)
+	end
+end
+return 1
+`)
+
+// releaseScript removes the holder
+// KEYS are same as acquireScript, ARGV[1]: holder
+var releaseScript = redislib.NewScript(`
+local n = redis.call('HDEL', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+return n
+`)
+
+// redisSemaphoreMutex implements redisMutex using weighted semaphore, backed by Lua scripts.
+// Similar to redsync.Mutex, operations are performed on all clients and succeed only if quorum is reached.
+type redisSemaphoreMutex struct {
+	mtx     sync.Mutex
+	name    string
+	keys    []string
+	holder  string
+	weight  int
+	limit   int
+	clients []redislib.UniversalClient
+	quorum  int
+	option  RedisLockOption
+	until   time.Time
+}
+
+func newRedisSemaphoreMutex(clients []redislib.UniversalClient, weight, limit int, opt RedisLockOption) (*redisSemaphoreMutex, error) {
+	holder, e := genValueFunc(opt.Valuer)()
+	if e != nil {
+		return nil, e
+	}
+	// use hash tag, so all keys are in the same slot in cluster mode
+	return &redisSemaphoreMutex{
+		name:    opt.Name,
+		keys:    []string{fmt.Sprintf("{%s}:semaphore:holders", opt.Name), fmt.Sprintf("{%s}:semaphore:expiry", opt.Name)},
+		holder:  holder,
+		weight:  weight,
+		limit:   limit,
+		clients: clients,
+		quorum:  len(clients)/2 + 1,
+		option:  opt,
+	}, nil
+}
+
+func (m *redisSemaphoreMutex) Name() string {
+	return m.name
+}
+
+func (m *redisSemaphoreMutex) Until() time.Time {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.until
+}
+
+func (m *redisSemaphoreMutex) TryLockContext(ctx context.Context) error {
+	start := time.Now()
+	n, e := m.runScript(ctx, acquireScript, m.holder, m.weight, m.limit, m.option.AutoExpiry.Milliseconds())
+	if e := ctx.Err(); e != nil {
+		return e
+	}
+	if n < m.quorum {
+		_, _ = m.runScript(context.Background(), releaseScript, m.holder)
+		if e != nil {
+			return e
+		}
+		return errNoPermit
+	}
+	m.updateUntil(start)
+	return nil
+}
+
+func (m *redisSemaphoreMutex) ExtendContext(ctx context.Context) (bool, error) {
+	start := time.Now()
+	n, e := m.runScript(ctx, extendScript, m.holder, m.weight, m.limit, m.option.AutoExpiry.Milliseconds())
+	if n < m.quorum {
+		return false, e
+	}
+	m.updateUntil(start)
+	return true, nil
+}
+
+func (m *redisSemaphoreMutex) Unlock() (bool, error) {
+	n, e := m.runScript(context.Background(), releaseScript, m.holder)
+	if n < m.quorum {
+		return false, e
+	}
+	return true, nil
+}
+
+// updateUntil calculate validity of the permit, taking clock drift into account. Same as redsync.Mutex
+func (m *redisSemaphoreMutex) updateUntil(start time.Time) {
+	drift := time.Duration(int64(float64(m.option.AutoExpiry)*0.01) + 2)
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.until = start.Add(m.option.AutoExpiry - time.Since(start) - drift)
+}
+
+// runScript run given script on all clients and returns number of clients that returned positive result.
+// The returned error is the last error encountered, if any
+func (m *redisSemaphoreMutex) runScript(ctx context.Context, script *redislib.Script, args ...interface{}) (n int, err error) {
+	timeout := time.Duration(float64(m.option.AutoExpiry) * m.option.TimeoutFactor)
+	for _, client := range m.clients {
+		cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+		v, e := script.Run(cmdCtx, client, m.keys, args...).Int()
+		cancel()
+		switch {
+		case e != nil:
+			err = e
+		case v > 0:
+			n++
+		}
+	}
+	return
+}
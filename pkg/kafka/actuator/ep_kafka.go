@@ -0,0 +1,211 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaactuator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/actuator"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	"github.com/cisco-open/go-lanai/pkg/web"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	ID              = "kafka"
+	EnableByDefault = false
+)
+
+type GroupInput struct {
+	Group string `uri:"group" binding:"required"`
+}
+
+type ResetInput struct {
+	Group string `uri:"group" binding:"required"`
+	// Topic of which the offsets are reset
+	Topic string `json:"topic" binding:"required"`
+	// ResetTo one of "earliest", "latest" or "timestamp"
+	ResetTo kafka.OffsetResetMode `json:"resetTo" binding:"required"`
+	// Timestamp is required when ResetTo is "timestamp"
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type ReadOutput struct {
+	Bindings []kafka.BindingInfo `json:"bindings"`
+	Topics   []*kafka.TopicInfo  `json:"topics"`
+	Groups   []*Group            `json:"groups"`
+}
+
+type Group struct {
+	*kafka.GroupInfo
+	Offsets []kafka.PartitionLag `json:"offsets"`
+}
+
+type ResetOutput struct {
+	Group   string          `json:"group"`
+	Topic   string          `json:"topic"`
+	Offsets map[int32]int64 `json:"offsets"`
+}
+
+// KafkaEndpoint implements actuator.Endpoint, actuator.WebEndpoint
+//
+//goland:noinspection GoNameStartsWithPackageName
+type KafkaEndpoint struct {
+	actuator.WebEndpointBase
+	binder     kafka.AdminBinder
+	pathSuffix map[actuator.Operation]string
+}
+
+func newEndpoint(di regDI) *KafkaEndpoint {
+	ep := KafkaEndpoint{}
+	ep.binder, _ = di.Binder.(kafka.AdminBinder)
+	ep.pathSuffix = map[actuator.Operation]string{
+		actuator.NewReadOperation(ep.ReadAll):     "",
+		actuator.NewReadOperation(ep.ReadAll):     "/",
+		actuator.NewReadOperation(ep.ReadGroup):   "/groups/:group",
+		actuator.NewWriteOperation(ep.ResetGroup): "/groups/:group",
+	}
+	ops := make([]actuator.Operation, 0, len(ep.pathSuffix))
+	for k := range ep.pathSuffix {
+		ops = append(ops, k)
+	}
+	ep.WebEndpointBase = actuator.MakeWebEndpointBase(func(opt *actuator.EndpointOption) {
+		opt.Id = ID
+		opt.Ops = ops
+		opt.Properties = &di.MgtProperties.Endpoints
+		opt.EnabledByDefault = EnableByDefault
+	})
+	return &ep
+}
+
+// Mappings implements WebEndpoint
+func (ep *KafkaEndpoint) Mappings(op actuator.Operation, group string) ([]web.Mapping, error) {
+	builder, e := ep.RestMappingBuilder(op, group, ep.MappingPath, ep.MappingName)
+	if e != nil {
+		return nil, e
+	}
+	return []web.Mapping{builder.Build()}, nil
+}
+
+func (ep *KafkaEndpoint) MappingPath(op actuator.Operation, props *actuator.WebEndpointsProperties) string {
+	path := ep.WebEndpointBase.MappingPath(op, props)
+	suffix, _ := ep.pathSuffix[op]
+	return path + suffix
+}
+
+// ReadAll returns all bindings, their topics and consumer groups
+func (ep *KafkaEndpoint) ReadAll(_ context.Context, _ *struct{}) (interface{}, error) {
+	if ep.binder == nil {
+		return nil, errBinderNotSupported()
+	}
+	bindings := ep.binder.Bindings()
+	out := ReadOutput{
+		Bindings: bindings,
+		Topics:   make([]*kafka.TopicInfo, 0, len(bindings)),
+		Groups:   make([]*Group, 0),
+	}
+
+	admin := ep.binder.Admin()
+	topicsByGroup := map[string][]string{}
+	for _, b := range bindings {
+		topic, e := admin.Topic(b.Topic)
+		if e != nil {
+			return nil, e
+		}
+		out.Topics = append(out.Topics, topic)
+		if b.Type == kafka.BindingTypeGroupConsumer {
+			topicsByGroup[b.Group] = append(topicsByGroup[b.Group], b.Topic)
+		}
+	}
+	for group, topics := range topicsByGroup {
+		g, e := ep.group(admin, group, topics)
+		if e != nil {
+			return nil, e
+		}
+		out.Groups = append(out.Groups, g)
+	}
+	sort.SliceStable(out.Groups, func(i, j int) bool {
+		return out.Groups[i].Group < out.Groups[j].Group
+	})
+	return out, nil
+}
+
+// ReadGroup returns members and offsets of one consumer group used by the bindings
+func (ep *KafkaEndpoint) ReadGroup(_ context.Context, in *GroupInput) (interface{}, error) {
+	if ep.binder == nil {
+		return nil, errBinderNotSupported()
+	}
+	var topics []string
+	for _, b := range ep.binder.Bindings() {
+		if b.Type == kafka.BindingTypeGroupConsumer && b.Group == in.Group {
+			topics = append(topics, b.Topic)
+		}
+	}
+	if len(topics) == 0 {
+		return nil, web.NewHttpError(http.StatusNotFound, fmt.Errorf("consumer group %s not found", in.Group))
+	}
+	return ep.group(ep.binder.Admin(), in.Group, topics)
+}
+
+// ResetGroup reset offsets of a consumer group on given topic. The group must not have active members
+func (ep *KafkaEndpoint) ResetGroup(_ context.Context, in *ResetInput) (interface{}, error) {
+	if ep.binder == nil {
+		return nil, errBinderNotSupported()
+	}
+	reset := kafka.OffsetReset{Mode: in.ResetTo}
+	switch {
+	case in.ResetTo == kafka.OffsetResetTimestamp && in.Timestamp == nil:
+		return nil, web.NewHttpError(http.StatusBadRequest, fmt.Errorf("timestamp is required to reset to timestamp"))
+	case in.Timestamp != nil:
+		reset.Timestamp = *in.Timestamp
+	}
+	offsets, e := ep.binder.Admin().ResetOffsets(in.Group, in.Topic, reset)
+	switch {
+	case errors.Is(e, kafka.ErrorSubTypeIllegalConsumerUsage):
+		return nil, web.NewHttpError(http.StatusConflict, e)
+	case e != nil:
+		return nil, e
+	}
+	return &ResetOutput{
+		Group:   in.Group,
+		Topic:   in.Topic,
+		Offsets: offsets,
+	}, nil
+}
+
+/*******************
+	Helpers
+ *******************/
+
+func (ep *KafkaEndpoint) group(admin *kafka.Admin, group string, topics []string) (*Group, error) {
+	info, e := admin.Group(group)
+	if e != nil {
+		return nil, e
+	}
+	lags, e := admin.ConsumerLag(group, topics...)
+	if e != nil {
+		return nil, e
+	}
+	return &Group{GroupInfo: info, Offsets: lags}, nil
+}
+
+func errBinderNotSupported() error {
+	return web.NewHttpError(http.StatusNotImplemented, fmt.Errorf("kafka binder doesn't support admin operations"))
+}
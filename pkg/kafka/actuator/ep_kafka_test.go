@@ -0,0 +1,245 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaactuator_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	kafkaactuator "github.com/cisco-open/go-lanai/pkg/kafka/actuator"
+	"github.com/cisco-open/go-lanai/pkg/kafka/testdata"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/actuatortest"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/sectest"
+	. "github.com/cisco-open/go-lanai/test/utils/gomega"
+	"github.com/cisco-open/go-lanai/test/webtest"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*************************
+	Test Setup
+ *************************/
+
+const (
+	TestTopic = `test-actuator-topic`
+	TestGroup = `test.actuator.group`
+)
+
+func ProvideTestConsumer(binder kafka.Binder) (kafka.GroupConsumer, error) {
+	return binder.Consume(TestTopic, TestGroup)
+}
+
+func SetupMockedTopicAndGroup() test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		testdata.MockExistingTopic(ctx, TestTopic, 0)
+		testdata.MockGroup(ctx, TestTopic, TestGroup, 0)
+		testdata.MockTopicOffsets(ctx, TestTopic, 0, 3, 15)
+		testdata.MockCommittedOffset(ctx, TestTopic, TestGroup, 0, 10)
+		return ctx, nil
+	}
+}
+
+/*************************
+	Tests
+ *************************/
+
+type TestEpDI struct {
+	fx.In
+	Consumer kafka.GroupConsumer
+}
+
+func TestKafkaEndpoint(t *testing.T) {
+	di := TestEpDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithTimeout(60*time.Second),
+		testdata.WithMockedBroker(),
+		webtest.WithMockedServer(webtest.AddDefaultRequestOptions(v3RequestOptions())),
+		sectest.WithMockedMiddleware(),
+		actuatortest.WithEndpoints(actuatortest.DisableAllEndpoints()),
+		apptest.WithModules(kafkaactuator.Module),
+		apptest.WithProperties("management.endpoint.kafka.enabled: true"),
+		apptest.WithFxOptions(
+			fx.Provide(ProvideTestConsumer),
+		),
+		apptest.WithDI(&di),
+		test.SubTestSetup(SetupMockedTopicAndGroup()),
+		test.GomegaSubTest(SubTestReadAll(mockedSecurityAdmin()), "TestReadAll"),
+		test.GomegaSubTest(SubTestReadGroup(mockedSecurityAdmin()), "TestReadGroup"),
+		test.GomegaSubTest(SubTestResetGroup(mockedSecurityAdmin()), "TestResetGroup"),
+		test.GomegaSubTest(SubTestResetGroupWithActiveMembers(mockedSecurityAdmin()), "TestResetGroupWithActiveMembers"),
+		test.GomegaSubTest(SubTestWithoutAccess(mockedSecurityNonAdmin()), "TestWithoutAccess"),
+		test.GomegaSubTest(SubTestWithoutAuth(), "TestWithoutAuth"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestReadAll(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		mockGroupDescription(ctx, "Stable", "member-1")
+		req := webtest.NewRequest(ctx, http.MethodGet, "/admin/kafka", nil)
+		resp := webtest.MustExec(ctx, req)
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusOK), "response should have correct status code")
+		body := readBody(g, resp.Response)
+		g.Expect(body).To(HaveJsonPathWithValue(fmt.Sprintf("$.bindings[?(@.topic=='%s')].group", TestTopic), TestGroup),
+			"response should contain consumer binding")
+		g.Expect(body).To(HaveJsonPathWithValue(fmt.Sprintf("$.topics[?(@.topic=='%s')].partitions[0].newest", TestTopic), float64(15)),
+			"response should contain topic offsets")
+		g.Expect(body).To(HaveJsonPathWithValue(fmt.Sprintf("$.groups[?(@.group=='%s')].state", TestGroup), "Stable"),
+			"response should contain group state")
+		g.Expect(body).To(HaveJsonPathWithValue(fmt.Sprintf("$.groups[?(@.group=='%s')].offsets[0].lag", TestGroup), float64(5)),
+			"response should contain group lag")
+	}
+}
+
+func SubTestReadGroup(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		mockGroupDescription(ctx, "Stable", "member-1")
+		req := webtest.NewRequest(ctx, http.MethodGet, "/admin/kafka/groups/"+TestGroup, nil)
+		resp := webtest.MustExec(ctx, req)
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusOK), "response should have correct status code")
+		body := readBody(g, resp.Response)
+		g.Expect(body).To(HaveJsonPathWithValue("$.group", TestGroup), "response should contain group name")
+		g.Expect(body).To(HaveJsonPathWithValue("$.members[0].memberId", "member-1"), "response should contain group members")
+		g.Expect(body).To(HaveJsonPathWithValue("$.offsets[0].committed", float64(10)), "response should contain committed offset")
+		g.Expect(body).To(HaveJsonPathWithValue("$.offsets[0].lag", float64(5)), "response should contain lag")
+
+		// non-existing
+		req = webtest.NewRequest(ctx, http.MethodGet, "/admin/kafka/groups/non-existing", nil)
+		resp = webtest.MustExec(ctx, req)
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusNotFound), "unknown group should yield 404")
+	}
+}
+
+func SubTestResetGroup(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		mockGroupDescription(ctx, "Empty")
+		resp := webtest.MustExec(ctx, newResetRequest(ctx, TestGroup, `{"topic":"%s","resetTo":"earliest"}`))
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusOK), "response should have correct status code")
+		body := readBody(g, resp.Response)
+		g.Expect(body).To(HaveJsonPathWithValue("$.group", TestGroup), "response should contain group name")
+		g.Expect(body).To(HaveJsonPathWithValue("$.topic", TestTopic), "response should contain topic")
+		g.Expect(body).To(HaveJsonPathWithValue("$.offsets.0", float64(3)), "offsets should be reset to earliest")
+
+		// timestamp
+		ts := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		testdata.MockTimestampOffset(ctx, TestTopic, 0, ts.UnixMilli(), 7)
+		tmpl := fmt.Sprintf(`{"topic":"%%s","resetTo":"timestamp","timestamp":"%s"}`, ts.Format(time.RFC3339Nano))
+		resp = webtest.MustExec(ctx, newResetRequest(ctx, TestGroup, tmpl))
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusOK), "response should have correct status code")
+		body = readBody(g, resp.Response)
+		g.Expect(body).To(HaveJsonPathWithValue("$.offsets.0", float64(7)), "offsets should be reset to timestamp")
+
+		// timestamp is missing
+		resp = webtest.MustExec(ctx, newResetRequest(ctx, TestGroup, `{"topic":"%s","resetTo":"timestamp"}`))
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusBadRequest), "missing timestamp should yield 400")
+	}
+}
+
+func SubTestResetGroupWithActiveMembers(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		mockGroupDescription(ctx, "Stable", "member-1")
+		resp := webtest.MustExec(ctx, newResetRequest(ctx, TestGroup, `{"topic":"%s","resetTo":"latest"}`))
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusConflict), "reset group with active members should yield 409")
+	}
+}
+
+func SubTestWithoutAccess(secOpts sectest.SecurityContextOptions) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		ctx = sectest.ContextWithSecurity(ctx, secOpts)
+		req := webtest.NewRequest(ctx, http.MethodGet, "/admin/kafka", nil)
+		resp := webtest.MustExec(ctx, req)
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusForbidden), "response should have correct status code")
+
+		resp = webtest.MustExec(ctx, newResetRequest(ctx, TestGroup, `{"topic":"%s","resetTo":"earliest"}`))
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusForbidden), "response should have correct status code")
+	}
+}
+
+func SubTestWithoutAuth() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *WithT) {
+		req := webtest.NewRequest(ctx, http.MethodGet, "/admin/kafka", nil)
+		resp := webtest.MustExec(ctx, req)
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusUnauthorized), "response should have correct status code")
+
+		resp = webtest.MustExec(ctx, newResetRequest(ctx, TestGroup, `{"topic":"%s","resetTo":"earliest"}`))
+		g.Expect(resp.Response.StatusCode).To(Equal(http.StatusUnauthorized), "response should have correct status code")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func mockedSecurityAdmin() sectest.SecurityContextOptions {
+	return sectest.MockedAuthentication(func(d *sectest.SecurityDetailsMock) {
+		d.Permissions = utils.NewStringSet("IS_API_ADMIN")
+	})
+}
+
+func mockedSecurityNonAdmin() sectest.SecurityContextOptions {
+	return sectest.MockedAuthentication(func(d *sectest.SecurityDetailsMock) {
+		d.Permissions = utils.NewStringSet("not_worthy")
+	})
+}
+
+func v3RequestOptions() webtest.RequestOptions {
+	return func(req *http.Request) {
+		req.Header.Set("Accept", "application/json")
+	}
+}
+
+func mockGroupDescription(ctx context.Context, state string, members ...string) {
+	desc := &sarama.GroupDescription{
+		GroupId: TestGroup,
+		State:   state,
+		Members: map[string]*sarama.GroupMemberDescription{},
+	}
+	for _, id := range members {
+		desc.Members[id] = &sarama.GroupMemberDescription{MemberId: id, ClientId: "client-" + id}
+	}
+	testdata.MockGroupDescription(ctx, TestGroup, desc)
+}
+
+// newResetRequest create reset request with given body template. The template should have exactly one "%s" for topic
+func newResetRequest(ctx context.Context, group, bodyTmpl string) *http.Request {
+	body := fmt.Sprintf(bodyTmpl, TestTopic)
+	return webtest.NewRequest(ctx, http.MethodPost, "/admin/kafka/groups/"+group, strings.NewReader(body),
+		webtest.ContentType("application/json"))
+}
+
+func readBody(g *WithT, resp *http.Response) []byte {
+	body, e := io.ReadAll(resp.Body)
+	g.Expect(e).To(Succeed(), "response body should be readable")
+	return body
+}
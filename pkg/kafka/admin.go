@@ -0,0 +1,341 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"sort"
+	"time"
+)
+
+type BindingType string
+
+const (
+	BindingTypeProducer      BindingType = "producer"
+	BindingTypeSubscriber    BindingType = "subscriber"
+	BindingTypeGroupConsumer BindingType = "consumer"
+)
+
+// BindingInfo describes a Producer, Subscriber or GroupConsumer created by Binder
+type BindingInfo struct {
+	Type   BindingType `json:"type"`
+	Topic  string      `json:"topic"`
+	Group  string      `json:"group,omitempty"`
+	Closed bool        `json:"closed"`
+}
+
+// AdminBinder is implemented by Binder that exposes its bindings and administrative operations of topics and groups
+type AdminBinder interface {
+	Binder
+	// Bindings returns all Producer, Subscriber and GroupConsumer created by the Binder, including retry bindings
+	Bindings() []BindingInfo
+	// Admin returns the Admin using the Binder's connections
+	Admin() *Admin
+}
+
+// PartitionOffsets oldest and newest (high watermark) offsets of a topic partition
+type PartitionOffsets struct {
+	Partition int32 `json:"partition"`
+	Oldest    int64 `json:"oldest"`
+	Newest    int64 `json:"newest"`
+}
+
+type TopicInfo struct {
+	Topic      string             `json:"topic"`
+	Partitions []PartitionOffsets `json:"partitions"`
+}
+
+// PartitionLag committed offset and lag of a consumer group on a topic partition.
+// When the group has no committed offset, Committed is -1 and Lag is the number of available messages.
+type PartitionLag struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Committed int64  `json:"committed"`
+	Newest    int64  `json:"newest"`
+	Lag       int64  `json:"lag"`
+}
+
+type GroupMember struct {
+	MemberID    string             `json:"memberId"`
+	ClientID    string             `json:"clientId"`
+	ClientHost  string             `json:"clientHost"`
+	Assignments map[string][]int32 `json:"assignments,omitempty"`
+}
+
+type GroupInfo struct {
+	Group    string        `json:"group"`
+	State    string        `json:"state"`
+	Protocol string        `json:"protocol,omitempty"`
+	Members  []GroupMember `json:"members"`
+}
+
+type OffsetResetMode string
+
+const (
+	OffsetResetEarliest  OffsetResetMode = "earliest"
+	OffsetResetLatest    OffsetResetMode = "latest"
+	OffsetResetTimestamp OffsetResetMode = "timestamp"
+)
+
+// OffsetReset specify where a consumer group's offsets are reset to.
+// With OffsetResetTimestamp, offsets are reset to the first message at or after Timestamp,
+// or the newest offset if no such message.
+type OffsetReset struct {
+	Mode      OffsetResetMode
+	Timestamp time.Time
+}
+
+/**************************
+	Admin
+ **************************/
+
+// Admin provides information and administrative operations of topics and consumer groups
+type Admin struct {
+	clientProvider func() (sarama.Client, error)
+	adminProvider  func() (sarama.ClusterAdmin, error)
+}
+
+func NewAdmin(clientProvider func() (sarama.Client, error), adminProvider func() (sarama.ClusterAdmin, error)) *Admin {
+	return &Admin{
+		clientProvider: clientProvider,
+		adminProvider:  adminProvider,
+	}
+}
+
+// Topic returns partitions and their offsets of given topic
+func (a *Admin) Topic(topic string) (*TopicInfo, error) {
+	client, e := a.client()
+	if e != nil {
+		return nil, e
+	}
+	partitions, e := client.Partitions(topic)
+	if e != nil {
+		return nil, translateSaramaBindingError(e, "unable to get partitions of topic [%s]: %v", topic, e)
+	}
+	info := TopicInfo{
+		Topic:      topic,
+		Partitions: make([]PartitionOffsets, len(partitions)),
+	}
+	for i, p := range partitions {
+		info.Partitions[i].Partition = p
+		if info.Partitions[i].Oldest, e = client.GetOffset(topic, p, sarama.OffsetOldest); e != nil {
+			return nil, translateSaramaBindingError(e, "unable to get offset of [%s-%d]: %v", topic, p, e)
+		}
+		if info.Partitions[i].Newest, e = client.GetOffset(topic, p, sarama.OffsetNewest); e != nil {
+			return nil, translateSaramaBindingError(e, "unable to get offset of [%s-%d]: %v", topic, p, e)
+		}
+	}
+	return &info, nil
+}
+
+// Group returns state and members of given consumer group
+func (a *Admin) Group(group string) (*GroupInfo, error) {
+	admin, e := a.adminProvider()
+	if e != nil {
+		return nil, e
+	}
+	descs, e := admin.DescribeConsumerGroups([]string{group})
+	switch {
+	case e != nil:
+		return nil, translateSaramaBindingError(e, "unable to describe group [%s]: %v", group, e)
+	case len(descs) == 0:
+		return nil, NewKafkaError(ErrorCodeBindingInternal, fmt.Sprintf("group [%s] is not found", group))
+	}
+	desc := descs[0]
+	info := GroupInfo{
+		Group:    group,
+		State:    desc.State,
+		Protocol: desc.Protocol,
+		Members:  make([]GroupMember, 0, len(desc.Members)),
+	}
+	for id, m := range desc.Members {
+		member := GroupMember{
+			MemberID:   id,
+			ClientID:   m.ClientId,
+			ClientHost: m.ClientHost,
+		}
+		if assignment, e := m.GetMemberAssignment(); e == nil && assignment != nil {
+			member.Assignments = assignment.Topics
+		}
+		info.Members = append(info.Members, member)
+	}
+	sort.SliceStable(info.Members, func(i, j int) bool {
+		return info.Members[i].MemberID < info.Members[j].MemberID
+	})
+	return &info, nil
+}
+
+// ConsumerLag returns committed offsets and lags of given consumer group on all partitions of given topics
+func (a *Admin) ConsumerLag(group string, topics ...string) ([]PartitionLag, error) {
+	client, e := a.client()
+	if e != nil {
+		return nil, e
+	}
+	admin, e := a.adminProvider()
+	if e != nil {
+		return nil, e
+	}
+	topicPartitions := make(map[string][]int32)
+	for _, topic := range topics {
+		if topicPartitions[topic], e = client.Partitions(topic); e != nil {
+			return nil, translateSaramaBindingError(e, "unable to get partitions of topic [%s]: %v", topic, e)
+		}
+	}
+	committed, e := admin.ListConsumerGroupOffsets(group, topicPartitions)
+	if e != nil {
+		return nil, translateSaramaBindingError(e, "unable to list offsets of group [%s]: %v", group, e)
+	}
+
+	lags := make([]PartitionLag, 0, len(topicPartitions))
+	for _, topic := range topics {
+		for _, p := range topicPartitions[topic] {
+			lag := PartitionLag{Topic: topic, Partition: p, Committed: -1}
+			if block := committed.GetBlock(topic, p); block != nil && errors.Is(block.Err, sarama.ErrNoError) {
+				lag.Committed = block.Offset
+			}
+			if lag.Newest, e = client.GetOffset(topic, p, sarama.OffsetNewest); e != nil {
+				return nil, translateSaramaBindingError(e, "unable to get offset of [%s-%d]: %v", topic, p, e)
+			}
+			from := lag.Committed
+			if from < 0 {
+				if from, e = client.GetOffset(topic, p, sarama.OffsetOldest); e != nil {
+					return nil, translateSaramaBindingError(e, "unable to get offset of [%s-%d]: %v", topic, p, e)
+				}
+			}
+			if lag.Lag = lag.Newest - from; lag.Lag < 0 {
+				lag.Lag = 0
+			}
+			lags = append(lags, lag)
+		}
+	}
+	return lags, nil
+}
+
+// ResetOffsets commits offsets of given consumer group on all partitions of given topic, and returns the new offsets.
+// The group must not have active members, otherwise the broker would reject the commit.
+func (a *Admin) ResetOffsets(group, topic string, reset OffsetReset) (map[int32]int64, error) {
+	info, e := a.Group(group)
+	if e != nil {
+		return nil, e
+	}
+	if len(info.Members) != 0 {
+		return nil, ErrorSubTypeIllegalConsumerUsage.WithMessage("cannot reset offsets of group [%s] with %d active members", group, len(info.Members))
+	}
+
+	client, e := a.client()
+	if e != nil {
+		return nil, e
+	}
+	var ts int64
+	switch reset.Mode {
+	case OffsetResetEarliest:
+		ts = sarama.OffsetOldest
+	case OffsetResetLatest:
+		ts = sarama.OffsetNewest
+	case OffsetResetTimestamp:
+		ts = reset.Timestamp.UnixMilli()
+	default:
+		return nil, ErrorSubTypeIllegalConsumerUsage.WithMessage("unsupported offset reset mode [%s]", reset.Mode)
+	}
+
+	partitions, e := client.Partitions(topic)
+	if e != nil {
+		return nil, translateSaramaBindingError(e, "unable to get partitions of topic [%s]: %v", topic, e)
+	}
+	offsets := make(map[int32]int64, len(partitions))
+	req := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: sarama.GroupGenerationUndefined,
+		RetentionTime:           -1,
+	}
+	for _, p := range partitions {
+		offset, e := client.GetOffset(topic, p, ts)
+		if e == nil && offset < 0 {
+			// no message after the timestamp
+			offset, e = client.GetOffset(topic, p, sarama.OffsetNewest)
+		}
+		if e != nil {
+			return nil, translateSaramaBindingError(e, "unable to get offset of [%s-%d]: %v", topic, p, e)
+		}
+		offsets[p] = offset
+		req.AddBlock(topic, p, offset, 0, "")
+	}
+
+	coordinator, e := client.Coordinator(group)
+	if e != nil {
+		return nil, translateSaramaBindingError(e, "unable to find coordinator of group [%s]: %v", group, e)
+	}
+	resp, e := coordinator.CommitOffset(req)
+	if e != nil {
+		return nil, translateSaramaBindingError(e, "unable to commit offsets of group [%s]: %v", group, e)
+	}
+	for _, blocks := range resp.Errors {
+		for p, kerr := range blocks {
+			if !errors.Is(kerr, sarama.ErrNoError) {
+				return nil, NewKafkaError(ErrorCodeBindingInternal, fmt.Sprintf("unable to commit offset of [%s-%d]: %v", topic, p, kerr), kerr)
+			}
+		}
+	}
+	return offsets, nil
+}
+
+func (a *Admin) client() (sarama.Client, error) {
+	client, e := a.clientProvider()
+	if e == nil && client == nil {
+		e = NewKafkaError(ErrorCodeIllegalState, "kafka client is not initialized yet")
+	}
+	return client, e
+}
+
+/**************************
+	Binder
+ **************************/
+
+// Bindings implements AdminBinder
+func (b *SaramaKafkaBinder) Bindings() []BindingInfo {
+	b.bindingsMtx.RLock()
+	defer b.bindingsMtx.RUnlock()
+	bindings := make([]BindingInfo, 0, len(b.producers)+len(b.subscribers)+len(b.consumerGroups))
+	for topic, p := range b.producers {
+		bindings = append(bindings, BindingInfo{Type: BindingTypeProducer, Topic: topic, Closed: p.Closed()})
+	}
+	for topic, s := range b.subscribers {
+		bindings = append(bindings, BindingInfo{Type: BindingTypeSubscriber, Topic: topic, Closed: s.Closed()})
+	}
+	for topic, c := range b.consumerGroups {
+		info := BindingInfo{Type: BindingTypeGroupConsumer, Topic: topic, Closed: c.Closed()}
+		if gc, ok := c.(GroupConsumer); ok {
+			info.Group = gc.Group()
+		}
+		bindings = append(bindings, info)
+	}
+	sort.SliceStable(bindings, func(i, j int) bool {
+		if bindings[i].Type != bindings[j].Type {
+			return bindings[i].Type < bindings[j].Type
+		}
+		return bindings[i].Topic < bindings[j].Topic
+	})
+	return bindings
+}
+
+// Admin implements AdminBinder
+func (b *SaramaKafkaBinder) Admin() *Admin {
+	return NewAdmin(b.globalClientProvider, b.clusterAdminProvider)
+}
@@ -0,0 +1,238 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_test
+
+import (
+    "context"
+    "errors"
+    "github.com/IBM/sarama"
+    "github.com/cisco-open/go-lanai/pkg/actuator/health"
+    "github.com/cisco-open/go-lanai/pkg/kafka"
+    "github.com/cisco-open/go-lanai/pkg/kafka/testdata"
+    "github.com/cisco-open/go-lanai/test"
+    "github.com/cisco-open/go-lanai/test/apptest"
+    "github.com/onsi/gomega"
+    . "github.com/onsi/gomega"
+    "go.uber.org/fx"
+    "testing"
+    "time"
+)
+
+/*************************
+	Tests
+ *************************/
+
+func TestAdmin(t *testing.T) {
+	di := TestBinderDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithTimeout(60 * time.Second),
+		testdata.WithMockedBroker(),
+		apptest.WithFxOptions(
+			fx.Provide(kafka.BindKafkaProperties, kafka.ProvideKafkaBinder),
+		),
+		apptest.WithDI(&di),
+		test.GomegaSubTest(SubTestBindings(&di), "TestBindings"),
+		test.GomegaSubTest(SubTestTopicOffsets(&di), "TestTopicOffsets"),
+		test.GomegaSubTest(SubTestConsumerLag(&di), "TestConsumerLag"),
+		test.GomegaSubTest(SubTestDescribeGroup(&di), "TestDescribeGroup"),
+		test.GomegaSubTest(SubTestResetOffsets(&di), "TestResetOffsets"),
+		test.GomegaSubTest(SubTestResetOffsetsWithActiveMembers(&di), "TestResetOffsetsWithActiveMembers"),
+		test.GomegaSubTest(SubTestConsumerLagHealth(&di), "TestConsumerLagHealth"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestBindings(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-admin-bindings`
+		const group = `test.admin.bindings`
+		testdata.MockExistingTopic(ctx, topic, 0)
+		_, e := di.Binder.Consume(topic, group)
+		g.Expect(e).To(Succeed(), "bind consumer should not fail")
+
+		g.Expect(di.Binder).To(BeAssignableToTypeOf(kafka.AdminBinder(&kafka.SaramaKafkaBinder{})))
+		bindings := di.Binder.(kafka.AdminBinder).Bindings()
+		g.Expect(bindings).To(ContainElement(kafka.BindingInfo{
+			Type:  kafka.BindingTypeGroupConsumer,
+			Topic: topic,
+			Group: group,
+		}), "bindings should contain the consumer")
+	}
+}
+
+func SubTestTopicOffsets(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-admin-topic`
+		testdata.MockExistingTopic(ctx, topic, 0)
+		testdata.MockTopicOffsets(ctx, topic, 0, 5, 20)
+
+		info, e := di.Binder.(kafka.AdminBinder).Admin().Topic(topic)
+		g.Expect(e).To(Succeed(), "topic info should not fail")
+		g.Expect(info.Topic).To(Equal(topic), "topic should be correct")
+		g.Expect(info.Partitions).To(ConsistOf(kafka.PartitionOffsets{Partition: 0, Oldest: 5, Newest: 20}), "offsets should be correct")
+	}
+}
+
+func SubTestConsumerLag(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-admin-lag`
+		const group = `test.admin.lag`
+		admin := di.Binder.(kafka.AdminBinder).Admin()
+		testdata.MockExistingTopic(ctx, topic, 0)
+		testdata.MockTopicOffsets(ctx, topic, 0, 2, 30)
+
+		// no committed offsets
+		testdata.MockCommittedOffset(ctx, `other-topic`, group, 0, 0)
+		lags, e := admin.ConsumerLag(group, topic)
+		g.Expect(e).To(Succeed(), "consumer lag should not fail")
+		g.Expect(lags).To(ConsistOf(kafka.PartitionLag{
+			Topic: topic, Partition: 0, Committed: -1, Newest: 30, Lag: 28,
+		}), "lag without committed offsets should be correct")
+
+		// with committed offsets
+		testdata.MockCommittedOffset(ctx, topic, group, 0, 10)
+		lags, e = admin.ConsumerLag(group, topic)
+		g.Expect(e).To(Succeed(), "consumer lag should not fail")
+		g.Expect(lags).To(ConsistOf(kafka.PartitionLag{
+			Topic: topic, Partition: 0, Committed: 10, Newest: 30, Lag: 20,
+		}), "lag with committed offsets should be correct")
+	}
+}
+
+func SubTestDescribeGroup(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-admin-describe`
+		const group = `test.admin.describe`
+		testdata.MockCommittedOffset(ctx, topic, group, 0, 0)
+		testdata.MockGroupDescription(ctx, group, &sarama.GroupDescription{
+			GroupId:      group,
+			State:        "Stable",
+			ProtocolType: "consumer",
+			Protocol:     sarama.RangeBalanceStrategyName,
+			Members: map[string]*sarama.GroupMemberDescription{
+				"member-1": {MemberId: "member-1", ClientId: "client-1", ClientHost: "/127.0.0.1"},
+			},
+		})
+
+		info, e := di.Binder.(kafka.AdminBinder).Admin().Group(group)
+		g.Expect(e).To(Succeed(), "describe group should not fail")
+		g.Expect(info.Group).To(Equal(group), "group should be correct")
+		g.Expect(info.State).To(Equal("Stable"), "state should be correct")
+		g.Expect(info.Protocol).To(Equal(sarama.RangeBalanceStrategyName), "protocol should be correct")
+		g.Expect(info.Members).To(HaveLen(1), "members should be correct")
+		g.Expect(info.Members[0].MemberID).To(Equal("member-1"), "member ID should be correct")
+		g.Expect(info.Members[0].ClientID).To(Equal("client-1"), "client ID should be correct")
+	}
+}
+
+func SubTestResetOffsets(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-admin-reset`
+		const group = `test.admin.reset`
+		admin := di.Binder.(kafka.AdminBinder).Admin()
+		testdata.MockExistingTopic(ctx, topic, 0)
+		testdata.MockTopicOffsets(ctx, topic, 0, 3, 15)
+		testdata.MockCommittedOffset(ctx, topic, group, 0, 10)
+		testdata.MockGroupDescription(ctx, group, &sarama.GroupDescription{
+			GroupId: group,
+			State:   "Empty",
+		})
+
+		offsets, e := admin.ResetOffsets(group, topic, kafka.OffsetReset{Mode: kafka.OffsetResetEarliest})
+		g.Expect(e).To(Succeed(), "reset to earliest should not fail")
+		g.Expect(offsets).To(Equal(map[int32]int64{0: 3}), "offsets reset to earliest should be correct")
+
+		offsets, e = admin.ResetOffsets(group, topic, kafka.OffsetReset{Mode: kafka.OffsetResetLatest})
+		g.Expect(e).To(Succeed(), "reset to latest should not fail")
+		g.Expect(offsets).To(Equal(map[int32]int64{0: 15}), "offsets reset to latest should be correct")
+
+		ts := time.Now().Add(-time.Hour)
+		testdata.MockTimestampOffset(ctx, topic, 0, ts.UnixMilli(), 7)
+		offsets, e = admin.ResetOffsets(group, topic, kafka.OffsetReset{Mode: kafka.OffsetResetTimestamp, Timestamp: ts})
+		g.Expect(e).To(Succeed(), "reset to timestamp should not fail")
+		g.Expect(offsets).To(Equal(map[int32]int64{0: 7}), "offsets reset to timestamp should be correct")
+	}
+}
+
+func SubTestResetOffsetsWithActiveMembers(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-admin-reset-active`
+		const group = `test.admin.reset.active`
+		testdata.MockExistingTopic(ctx, topic, 0)
+		testdata.MockCommittedOffset(ctx, topic, group, 0, 10)
+		testdata.MockGroupDescription(ctx, group, &sarama.GroupDescription{
+			GroupId: group,
+			State:   "Stable",
+			Members: map[string]*sarama.GroupMemberDescription{
+				"member-1": {MemberId: "member-1", ClientId: "client-1"},
+			},
+		})
+
+		_, e := di.Binder.(kafka.AdminBinder).Admin().ResetOffsets(group, topic, kafka.OffsetReset{Mode: kafka.OffsetResetEarliest})
+		g.Expect(e).To(HaveOccurred(), "reset with active members should fail")
+		g.Expect(errors.Is(e, kafka.ErrorSubTypeIllegalConsumerUsage)).To(BeTrue(), "error should be correct")
+	}
+}
+
+func SubTestConsumerLagHealth(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-admin-lag-health`
+		const group = `test.admin.lag.health`
+		testdata.MockExistingTopic(ctx, topic, 0)
+		testdata.MockTopicOffsets(ctx, topic, 0, 0, 100)
+		testdata.MockCommittedOffset(ctx, topic, group, 0, 40)
+		// consumer bound by SubTestBindings has no lag
+		testdata.MockCommittedOffset(ctx, `test-admin-bindings`, `test.admin.bindings`, 0, 0)
+		testdata.MockTopicOffsets(ctx, `test-admin-bindings`, 0, 0, 0)
+		_, e := di.Binder.Consume(topic, group)
+		g.Expect(e).To(Succeed(), "bind consumer should not fail")
+
+		opts := health.Options{ShowDetails: true}
+		indicator := kafka.NewConsumerLagHealthIndicator(di.Binder, kafka.ConsumerLagHealthProperties{
+			Enabled: true, DegradedThreshold: 100, DownThreshold: 1000,
+		})
+		h := indicator.Health(ctx, opts)
+		g.Expect(h.Status()).To(Equal(health.StatusUp), "health status should be UP when lag is low")
+		g.Expect(h.(*health.DetailedHealth).Details).To(HaveKeyWithValue("maxLag", BeEquivalentTo(60)), "health details should be correct")
+
+		indicator = kafka.NewConsumerLagHealthIndicator(di.Binder, kafka.ConsumerLagHealthProperties{
+			Enabled: true, DegradedThreshold: 50, DownThreshold: 1000,
+		})
+		h = indicator.Health(ctx, opts)
+		g.Expect(h.Status()).To(Equal(health.StatusDegraded), "health status should be DEGRADED when lag is above threshold")
+
+		indicator = kafka.NewConsumerLagHealthIndicator(di.Binder, kafka.ConsumerLagHealthProperties{
+			Enabled: true, DegradedThreshold: 10, DownThreshold: 60,
+		})
+		h = indicator.Health(ctx, opts)
+		g.Expect(h.Status()).To(Equal(health.StatusDown), "health status should be DOWN when lag is above threshold")
+	}
+}
+
+func SubTestConsumerLagHealthWithoutAdmin() test.GomegaSubTestFunc {
+    return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+        // binder without admin support
+        var binder struct{ kafka.Binder }
+        indicator := kafka.NewConsumerLagHealthIndicator(binder, kafka.ConsumerLagHealthProperties{Enabled: true})
+        h := indicator.Health(ctx, health.Options{ShowDetails: true})
+        g.Expect(h.Status()).To(Equal(health.StatusUnknown), "health status should be UNKNOWN without admin support")
+    }
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// MessageBatch is the MessageContext.RawMessage of a batch MessageContext.
+// Each element is the MessageContext of an individual message.
+// See NewBatchMessageContext
+type MessageBatch []*MessageContext
+
+// NewBatchMessageContext creates a MessageContext representing given batch of messages.
+// The returned MessageContext has MessageBatch as RawMessage, and its Message.Payload is []*Message of all messages.
+// ConsumerDispatchInterceptor and ConsumerHandlerInterceptor are invoked once per batch with such MessageContext.
+func NewBatchMessageContext(ctx context.Context, batch MessageBatch) *MessageContext {
+	msgs := make([]*Message, len(batch))
+	for i := range batch {
+		msgs[i] = &batch[i].Message
+	}
+	msgCtx := &MessageContext{
+		Context: ctx,
+		Message: Message{
+			Headers: Headers{},
+			Payload: msgs,
+		},
+		RawMessage: batch,
+	}
+	if len(batch) != 0 {
+		msgCtx.Source = batch[0].Source
+		msgCtx.Topic = batch[0].Topic
+	}
+	return msgCtx
+}
+
+type batchOption struct {
+	size    int
+	maxWait time.Duration
+}
+
+// batchOption returns the largest size and shortest max wait among all batch handlers.
+// "ok" is false if there is no batch handler
+func (d *Dispatcher) batchOption() (size int, maxWait time.Duration, ok bool) {
+	for _, h := range d.handlers {
+		if h.batch == nil {
+			continue
+		}
+		if !ok || h.batch.size > size {
+			size = h.batch.size
+		}
+		if !ok || h.batch.maxWait < maxWait {
+			maxWait = h.batch.maxWait
+		}
+		ok = true
+	}
+	return
+}
+
+// parseBatchParams parse and validate input params of batch MessageHandlerFunc. All params except context are slices
+func (d *Dispatcher) parseBatchParams(fn MessageHandlerFunc, h *handler) error {
+	t := h.fn.Type()
+	for i := t.NumIn() - 1; i >= 0; i-- {
+		it := t.In(i)
+		switch {
+		case it.AssignableTo(reflectTypeContext):
+			if i != 0 {
+				return ErrorSubTypeIllegalConsumerUsage.WithMessage("invalid batch MessageHandlerFunc signature %v, first input param must be context.Context", fn)
+			}
+			h.params.count++
+			continue
+		case it.Kind() != reflect.Slice:
+			return ErrorSubTypeIllegalConsumerUsage.WithMessage("invalid batch MessageHandlerFunc signature %v, input parameter at index %v must be slice", fn, i)
+		}
+		switch et := it.Elem(); {
+		case et.ConvertibleTo(reflectTypeHeaders):
+			h.params.headers = param{i, it}
+		case et.ConvertibleTo(reflectTypeMetadata):
+			h.params.metadata = param{i, it}
+		case et.ConvertibleTo(reflectTypeMessage):
+			h.params.message = param{i, it}
+		case h.params.payload.t == nil && d.isSupportedMessagePayloadType(et):
+			h.params.payload = param{i, it}
+		default:
+			return ErrorSubTypeIllegalConsumerUsage.WithMessage("invalid batch MessageHandlerFunc signature %v, unknown input parameters at index %v", fn, i)
+		}
+		h.params.count++
+	}
+	return nil
+}
+
+// dispatchEach dispatch messages of the batch to given non-batch handler one by one
+func (d *Dispatcher) dispatchEach(msgCtx *MessageContext, h *handler, batch MessageBatch) error {
+	for _, item := range batch {
+		itemCtx := *item
+		itemCtx.Context = msgCtx.Context
+		if h.filterFunc != nil && !h.filterFunc(itemCtx.Context, &itemCtx.Message) {
+			continue
+		}
+		if e := d.dispatch(&itemCtx, h); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// dispatchBatch dispatch the batch to given batch handler, in chunks no larger than the handler's batch size
+func (d *Dispatcher) dispatchBatch(msgCtx *MessageContext, h *handler, batch MessageBatch) error {
+	filtered := batch
+	if h.filterFunc != nil {
+		filtered = make(MessageBatch, 0, len(batch))
+		for _, item := range batch {
+			if h.filterFunc(msgCtx.Context, &item.Message) {
+				filtered = append(filtered, item)
+			}
+		}
+	}
+	for start := 0; start < len(filtered); start += h.batch.size {
+		end := start + h.batch.size
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		if e := d.dispatchChunk(msgCtx, h, filtered[start:end]); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) dispatchChunk(msgCtx *MessageContext, h *handler, chunk MessageBatch) (err error) {
+	// note: we need to make shallow copies of messages because we need to decode the payload
+	msgs := make([]*Message, len(chunk))
+	for i := range chunk {
+		msg := chunk[i].Message
+		msgs[i] = &msg
+	}
+
+	// invoke handler Interceptors.
+	ctx, msg := msgCtx.Context, Message{Headers: Headers{}, Payload: msgs}
+	for _, interceptor := range h.interceptors {
+		ctx, err = interceptor.BeforeHandling(ctx, &msg)
+		if err != nil {
+			return ErrorSubTypeConsumerGeneral.WithMessage("consumer handler interceptor error: %v", err)
+		}
+	}
+
+	defer func() {
+		for _, interceptor := range h.interceptors {
+			ctx, err = interceptor.AfterHandling(ctx, &msg, err)
+		}
+	}()
+
+	// decode payloads
+	if h.params.payload.t != nil {
+		for _, m := range msgs {
+			if err = d.decodePayload(ctx, h.params.payload.t.Elem(), m); err != nil {
+				return
+			}
+		}
+	}
+
+	err = d.invokeBatchHandler(ctx, h, msgs, chunk)
+	return
+}
+
+func (d *Dispatcher) invokeBatchHandler(ctx context.Context, handler *handler, msgs []*Message, chunk MessageBatch) (err error) {
+	// prepare input params
+	in := make([]reflect.Value, handler.params.count)
+	in[0] = reflect.ValueOf(ctx)
+	if e := d.assignSlice(in, handler.params.payload, len(msgs), func(i int) interface{} { return msgs[i].Payload }); e != nil {
+		return e
+	}
+	if e := d.assignSlice(in, handler.params.headers, len(msgs), func(i int) interface{} { return msgs[i].Headers }); e != nil {
+		return e
+	}
+	if e := d.assignSlice(in, handler.params.message, len(msgs), func(i int) interface{} { return msgs[i] }); e != nil {
+		return e
+	}
+	if e := d.assignSlice(in, handler.params.metadata, len(msgs), func(i int) interface{} { return d.metadataOf(chunk[i].RawMessage) }); e != nil {
+		return e
+	}
+
+	// invoke
+	out := handler.fn.Call(in)
+
+	// post process output
+	err, _ = out[0].Interface().(error)
+	return
+}
+
+// assignSlice creates a slice of given param's type, and set each element using given value function
+func (d *Dispatcher) assignSlice(params []reflect.Value, p param, n int, valueFn func(i int) interface{}) error {
+	if p.i >= len(params) || p.t == nil {
+		return nil
+	}
+	et := p.t.Elem()
+	slice := reflect.MakeSlice(p.t, n, n)
+	for i := 0; i < n; i++ {
+		v := reflect.ValueOf(valueFn(i))
+		if !v.IsValid() || !v.Type().ConvertibleTo(et) {
+			return ErrorSubTypeIllegalConsumerUsage.WithMessage("failed to prepare parameters for batch message handler: cannot assign %v to %v", v, et)
+		}
+		slice.Index(i).Set(v.Convert(et))
+	}
+	params[p.i] = slice
+	return nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_test
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "github.com/IBM/sarama"
+    "github.com/cisco-open/go-lanai/pkg/kafka"
+    "github.com/cisco-open/go-lanai/pkg/kafka/testdata"
+    "github.com/cisco-open/go-lanai/test"
+    "github.com/cisco-open/go-lanai/test/apptest"
+    "github.com/onsi/gomega"
+    . "github.com/onsi/gomega"
+    "go.uber.org/fx"
+    "sync"
+    "testing"
+    "time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const batchTopic = `test-batch`
+const batchConsumerTopic = `test-batch-consumer`
+const batchConsumerGroup = `test.batch.group`
+
+type BatchPayload struct {
+	Value string `json:"value"`
+}
+
+type BatchRecorder struct {
+	Payloads [][]*BatchPayload
+	Metas    [][]*kafka.MessageMetadata
+	Error    error
+}
+
+func (r *BatchRecorder) HandleFunc(_ context.Context, payloads []*BatchPayload, metas []*kafka.MessageMetadata) error {
+	r.Payloads = append(r.Payloads, payloads)
+	r.Metas = append(r.Metas, metas)
+	return r.Error
+}
+
+func NewTestBatch(n int) kafka.MessageBatch {
+	batch := make(kafka.MessageBatch, n)
+	for i := range batch {
+		raw := &sarama.ConsumerMessage{
+			Topic:     batchTopic,
+			Partition: 0,
+			Offset:    int64(i),
+			Value:     []byte(fmt.Sprintf(`{"value":"test-%d"}`, i)),
+		}
+		batch[i] = &kafka.MessageContext{
+			Context: context.Background(),
+			Topic:   batchTopic,
+			Message: kafka.Message{
+				Headers: kafka.Headers{kafka.HeaderContentType: kafka.MIMETypeJson},
+				Payload: raw.Value,
+			},
+			RawMessage: raw,
+		}
+	}
+	return batch
+}
+
+func ProvideTestBatchConsumer(binder kafka.Binder, lc fx.Lifecycle) (kafka.GroupConsumer, *BatchConsumerHandler, error) {
+    consumer, e := binder.Consume(batchConsumerTopic, batchConsumerGroup)
+    if e != nil {
+        return nil, nil, e
+    }
+    handler := &BatchConsumerHandler{
+        CH: make(chan []*kafka.MessageMetadata, 2),
+    }
+    lc.Append(fx.StopHook(func(context.Context) { close(handler.CH) }))
+    return consumer, handler, consumer.AddHandler(handler.HandleFunc, kafka.Batch(2, time.Second))
+}
+
+// BatchConsumerHandler fails the first "Failures" batches
+type BatchConsumerHandler struct {
+    mtx      sync.Mutex
+    CH       chan []*kafka.MessageMetadata
+    Failures int
+}
+
+func (h *BatchConsumerHandler) SetFailures(n int) {
+    h.mtx.Lock()
+    defer h.mtx.Unlock()
+    h.Failures = n
+}
+
+func (h *BatchConsumerHandler) HandleFunc(_ context.Context, _ []*kafka.Message, metas []*kafka.MessageMetadata) error {
+    h.CH <- metas
+    h.mtx.Lock()
+    defer h.mtx.Unlock()
+    if h.Failures > 0 {
+        h.Failures--
+        return errors.New("oops")
+    }
+    return nil
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestBatchDispatch(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestBatchHandler(), "TestBatchHandler"),
+		test.GomegaSubTest(SubTestBatchHandlerWithSingleMessage(), "TestBatchHandlerWithSingleMessage"),
+		test.GomegaSubTest(SubTestBatchWithRegularHandler(), "TestBatchWithRegularHandler"),
+		test.GomegaSubTest(SubTestBatchHandlerInvalidSignature(), "TestBatchHandlerInvalidSignature"),
+		test.GomegaSubTest(SubTestBatchRetry(), "TestBatchRetry"),
+	)
+}
+
+type TestBatchConsumerDI struct {
+    fx.In
+    TestBinderDI
+    Consumer kafka.GroupConsumer
+    Handler  *BatchConsumerHandler
+}
+
+func TestBatchConsumer(t *testing.T) {
+    di := TestBatchConsumerDI{}
+    test.RunTest(context.Background(), t,
+        apptest.Bootstrap(),
+        apptest.WithTimeout(60*time.Second),
+        testdata.WithMockedBroker(),
+        apptest.WithModules(kafka.Module),
+        apptest.WithFxOptions(
+            fx.Provide(ProvideTestBatchConsumer),
+        ),
+        apptest.WithDI(&di),
+        test.SubTestSetup(SubSetupStartBinder(&di.TestBinderDI)),
+        test.GomegaSubTest(SubTestBatchConsumerRedelivery(&di), "TestFailedBatchRedelivery"),
+    )
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestBatchHandler() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d := &kafka.Dispatcher{}
+		recorder := &BatchRecorder{}
+		e := d.AddHandler(recorder.HandleFunc, kafka.Batch(2, time.Second))
+		g.Expect(e).To(Succeed(), "adding batch handler should not fail")
+
+		e = d.Dispatch(kafka.NewBatchMessageContext(ctx, NewTestBatch(3)))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(recorder.Payloads).To(HaveLen(2), "handler should be invoked in chunks of batch size")
+		g.Expect(recorder.Payloads[0]).To(HaveLen(2), "first chunk should have correct size")
+		g.Expect(recorder.Payloads[1]).To(HaveLen(1), "last chunk should have remaining messages")
+		g.Expect(recorder.Payloads[0][0].Value).To(Equal("test-0"), "payload should be decoded")
+		g.Expect(recorder.Payloads[1][0].Value).To(Equal("test-2"), "payload should be decoded")
+		g.Expect(recorder.Metas[0][1].Offset).To(Equal(1), "metadata should be correct")
+		g.Expect(recorder.Metas[1][0].Offset).To(Equal(2), "metadata should be correct")
+	}
+}
+
+func SubTestBatchHandlerWithSingleMessage() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d := &kafka.Dispatcher{}
+		var received [][]*kafka.Message
+		e := d.AddHandler(func(_ context.Context, msgs []*kafka.Message) error {
+			received = append(received, msgs)
+			return nil
+		}, kafka.Batch(10, time.Second))
+		g.Expect(e).To(Succeed(), "adding batch handler should not fail")
+
+		e = d.Dispatch(NewTestBatch(1)[0])
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(received).To(HaveLen(1), "handler should be invoked")
+		g.Expect(received[0]).To(HaveLen(1), "handler should receive batch of single message")
+	}
+}
+
+func SubTestBatchWithRegularHandler() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d := &kafka.Dispatcher{}
+		var received []*BatchPayload
+		e := d.AddHandler(func(_ context.Context, payload *BatchPayload) error {
+			received = append(received, payload)
+			return nil
+		})
+		g.Expect(e).To(Succeed(), "adding handler should not fail")
+
+		e = d.Dispatch(kafka.NewBatchMessageContext(ctx, NewTestBatch(3)))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(received).To(HaveLen(3), "regular handler should be invoked for each message")
+		g.Expect(received[2].Value).To(Equal("test-2"), "payload should be decoded")
+	}
+}
+
+func SubTestBatchHandlerInvalidSignature() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d := &kafka.Dispatcher{}
+		e := d.AddHandler(func(_ context.Context, _ *BatchPayload) error {
+			return nil
+		}, kafka.Batch(10, time.Second))
+		g.Expect(e).To(HaveOccurred(), "batch handler with non-slice param should fail")
+		g.Expect(errors.Is(e, kafka.ErrorSubTypeIllegalConsumerUsage)).To(BeTrue(), "error should be correct")
+	}
+}
+
+func SubTestBatchRetry() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		sent := make([]sentMessage, 0, 3)
+		interceptor := kafka.NewRetryInterceptor(DefaultTestRetryPolicy(), func(topic string) (kafka.Producer, error) {
+			return MockedRetryProducer{topic: topic, sent: &sent}, nil
+		})
+		d := &kafka.Dispatcher{Interceptors: []kafka.ConsumerDispatchInterceptor{interceptor}}
+		recorder := &BatchRecorder{Error: errors.New("oops")}
+		e := d.AddHandler(recorder.HandleFunc, kafka.Batch(10, time.Second))
+		g.Expect(e).To(Succeed(), "adding batch handler should not fail")
+
+		e = d.Dispatch(kafka.NewBatchMessageContext(ctx, NewTestBatch(3)))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(sent).To(HaveLen(3), "each message of failed batch should be sent")
+		for i, msg := range sent {
+			g.Expect(msg.Topic).To(Equal(kafka.RetryTopicName(batchTopic, 1)), "message should be sent to first retry topic")
+			g.Expect(msg.Message.Headers).To(HaveKeyWithValue(kafka.HeaderOriginalOffset, fmt.Sprintf("%d", i)), "message should have original offset header")
+		}
+	}
+}
+
+func SubTestBatchConsumerRedelivery(di *TestBatchConsumerDI) test.GomegaSubTestFunc {
+    return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+        di.Handler.SetFailures(1)
+        testdata.MockExistingTopic(ctx, batchConsumerTopic, 0)
+        testdata.MockGroupMessage(ctx, batchConsumerTopic, batchConsumerGroup, 0, 0, MakeMockedMessage(WithValue([]byte("binary-0"))))
+        testdata.MockSubscribedMessage(ctx, batchConsumerTopic, 0, 1, MakeMockedMessage(WithValue([]byte("binary-1"))))
+        testdata.MockGroup(ctx, batchConsumerTopic, batchConsumerGroup, 0)
+
+        metas, e := WaitForHandlerInvocation(ctx, di.Handler.CH, 50*time.Second)
+        g.Expect(e).To(Succeed(), "handler should be triggered")
+        g.Expect(metas).To(HaveLen(2), "handler should receive the whole batch")
+        AssertMetadata(g, metas[0], 0, 0, nil)
+        AssertMetadata(g, metas[1], 0, 1, nil)
+
+        // failed batch should be re-delivered as a whole
+        metas, e = WaitForHandlerInvocation(ctx, di.Handler.CH, 50*time.Second)
+        g.Expect(e).To(Succeed(), "handler should be re-triggered")
+        g.Expect(metas).To(HaveLen(2), "handler should receive the whole batch again")
+        AssertMetadata(g, metas[0], 0, 0, nil)
+        AssertMetadata(g, metas[1], 0, 1, nil)
+    }
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/cisco-open/go-lanai/pkg/bootstrap"
 	"github.com/cisco-open/go-lanai/pkg/certs"
+	"github.com/cisco-open/go-lanai/pkg/utils"
 	"github.com/cisco-open/go-lanai/pkg/utils/loop"
 	"io"
 	"math"
@@ -49,10 +50,14 @@ type SaramaKafkaBinder struct {
 	producerInterceptors []ProducerMessageInterceptor
 	consumerInterceptors []ConsumerDispatchInterceptor
 	handlerInterceptors  []ConsumerHandlerInterceptor
+	decoders             []Decoder
 	monitor              *loop.Loop
 	tlsCertsManager      certs.Manager
 
-	// TODO consider mutex lock for following fields
+	// following fields are protected by bindingsMtx. Bindings are accessed by other goroutines (watchdog, health
+	// indicators, actuator endpoints, etc.). We use a separate lock, because the mutex lock is held during Shutdown,
+	// while closing bindings may wait for in-flight handlers that look up bindings (e.g. retry producers)
+	bindingsMtx    sync.RWMutex
 	producers      map[string]BindingLifecycle
 	subscribers    map[string]BindingLifecycle
 	consumerGroups map[string]BindingLifecycle
@@ -62,6 +67,7 @@ type SaramaKafkaBinder struct {
 	adminClient       sarama.ClusterAdmin
 	tlsSource         certs.Source
 	provisioner       *saramaTopicProvisioner
+	txManager         *saramaTransactionManager
 	closed            bool
 	monitorCtx        context.Context
 	monitorCancelFunc context.CancelFunc
@@ -75,6 +81,7 @@ type BinderOption struct {
 	ProducerInterceptors []ProducerMessageInterceptor
 	ConsumerInterceptors []ConsumerDispatchInterceptor
 	HandlerInterceptors  []ConsumerHandlerInterceptor
+	Decoders             []Decoder
 	TLSCertsManager      certs.Manager
 }
 
@@ -93,6 +100,7 @@ func NewBinder(ctx context.Context, opts ...BinderOptions) *SaramaKafkaBinder {
 		producerInterceptors: opt.ProducerInterceptors,
 		consumerInterceptors: opt.ConsumerInterceptors,
 		handlerInterceptors:  opt.HandlerInterceptors,
+		decoders:             opt.Decoders,
 		monitor:              loop.NewLoop(),
 		producers:            make(map[string]BindingLifecycle),
 		subscribers:          make(map[string]BindingLifecycle),
@@ -127,6 +135,8 @@ func (b *SaramaKafkaBinder) prepareDefaults(ctx context.Context, saramaDefaults
 			dispatchInterceptors: b.consumerInterceptors,
 			handlerInterceptors:  b.handlerInterceptors,
 			msgLogger:            newSaramaMessageLogger(),
+			retry:                defaultRetryPolicy(),
+			decoders:             b.decoders,
 		},
 	}
 
@@ -138,16 +148,16 @@ func (b *SaramaKafkaBinder) prepareDefaults(ctx context.Context, saramaDefaults
 
 // CloseProducer release resources for dynamic producers
 func (b *SaramaKafkaBinder) CloseProducer(ctx context.Context, topic string) {
-	if p, ok := b.producers[topic]; ok {
+	if p, ok := b.binding(b.producers, topic); ok {
 		if e := p.Close(); e != nil {
 			logger.WithContext(ctx).Errorf("error while closing kafka producer: %v", e)
 		}
 	}
-	delete(b.producers, topic)
+	b.removeBinding(b.producers, topic)
 }
 
 func (b *SaramaKafkaBinder) Produce(topic string, options ...ProducerOptions) (Producer, error) {
-	if p, ok := b.producers[topic]; ok && !p.Closed() {
+	if p, ok := b.binding(b.producers, topic); ok && !p.Closed() {
 		logger.Warnf(errTmplProducerExists, topic)
 		return nil, NewKafkaError(ErrorCodeProducerExists, errTmplProducerExists, topic)
 	}
@@ -172,12 +182,12 @@ func (b *SaramaKafkaBinder) Produce(topic string, options ...ProducerOptions) (P
 		return nil, err
 	}
 
-	b.producers[topic] = p
+	b.setBinding(b.producers, topic, p)
 	return p, b.tryScheduleStart(p)
 }
 
 func (b *SaramaKafkaBinder) Subscribe(topic string, options ...ConsumerOptions) (Subscriber, error) {
-	if s, ok := b.subscribers[topic]; ok && !s.Closed() {
+	if s, ok := b.binding(b.subscribers, topic); ok && !s.Closed() {
 		logger.Warnf(errTmplSubscriberExists, topic)
 		return nil, NewKafkaError(ErrorCodeConsumerExists, errTmplSubscriberExists, topic)
 	}
@@ -193,17 +203,36 @@ func (b *SaramaKafkaBinder) Subscribe(topic string, options ...ConsumerOptions)
 	props := b.loadProperties(cfg.name)
 	WithConsumerProperties(&props.Consumer)(&cfg)
 
+	// all subscribers receive messages from retry topics, so we target the retried messages to current subscriber
+	retryTopics, err := b.prepareRetry(topic, &cfg, utils.RandomString(16))
+	if err != nil {
+		return nil, err
+	}
+
 	sub, err := newSaramaSubscriber(topic, b.brokers, &cfg, b.provisioner)
 	if err != nil {
 		return nil, err
 	}
 
-	b.subscribers[topic] = sub
+	for _, rt := range retryTopics {
+		if s, ok := b.binding(b.subscribers, rt); ok && !s.Closed() {
+			return nil, NewKafkaError(ErrorCodeConsumerExists, errTmplSubscriberExists, rt)
+		}
+		retrySub, e := newSaramaSubscriber(rt, b.brokers, &cfg, b.provisioner)
+		if e != nil {
+			return nil, e
+		}
+		retrySub.dispatcher = sub.dispatcher
+		b.setBinding(b.subscribers, rt, retrySub)
+		_ = b.tryScheduleStart(retrySub)
+	}
+
+	b.setBinding(b.subscribers, topic, sub)
 	return sub, b.tryScheduleStart(sub)
 }
 
 func (b *SaramaKafkaBinder) Consume(topic string, group string, options ...ConsumerOptions) (GroupConsumer, error) {
-	if c, ok := b.consumerGroups[topic]; ok && !c.Closed() {
+	if c, ok := b.binding(b.consumerGroups, topic); ok && !c.Closed() {
 		logger.Warnf(errTmplConsumerGroupExists, topic)
 		return nil, NewKafkaError(ErrorCodeConsumerExists, errTmplConsumerGroupExists, topic)
 	}
@@ -219,16 +248,44 @@ func (b *SaramaKafkaBinder) Consume(topic string, group string, options ...Consu
 	props := b.loadProperties(cfg.name)
 	WithConsumerProperties(&props.Consumer)(&cfg)
 
+	retryTopics, err := b.prepareRetry(topic, &cfg, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.consumer.exactlyOnce && b.txManager == nil {
+		return nil, NewKafkaError(ErrorCodeBindingInternal, "exactly-once consumer requires transaction to be enabled")
+	}
+
 	cg, err := newSaramaGroupConsumer(topic, group, b.brokers, &cfg, b.provisioner)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.consumer.exactlyOnce {
+		cg.txManager = b.txManager
+	}
 
-	b.consumerGroups[topic] = cg
+	for _, rt := range retryTopics {
+		if c, ok := b.binding(b.consumerGroups, rt); ok && !c.Closed() {
+			return nil, NewKafkaError(ErrorCodeConsumerExists, errTmplConsumerGroupExists, rt)
+		}
+		retryCG, e := newSaramaGroupConsumer(rt, group, b.brokers, &cfg, b.provisioner)
+		if e != nil {
+			return nil, e
+		}
+		retryCG.dispatcher = cg.dispatcher
+		retryCG.txManager = cg.txManager
+		b.setBinding(b.consumerGroups, rt, retryCG)
+		_ = b.tryScheduleStart(retryCG)
+	}
+
+	b.setBinding(b.consumerGroups, topic, cg)
 	return cg, b.tryScheduleStart(cg)
 }
 
 func (b *SaramaKafkaBinder) ListTopics() (topics []string) {
+	b.bindingsMtx.RLock()
+	defer b.bindingsMtx.RUnlock()
 	topics = make([]string, 0, len(b.producers)+len(b.subscribers)+len(b.consumerGroups))
 	for t := range b.producers {
 		topics = append(topics, t)
@@ -242,6 +299,13 @@ func (b *SaramaKafkaBinder) ListTopics() (topics []string) {
 	return topics
 }
 
+func (b *SaramaKafkaBinder) Transaction(ctx context.Context, fn TransactionFunc) error {
+	if b.txManager == nil {
+		return ErrorSubTypeIllegalProducerUsage.WithMessage("transaction is not enabled. Please set [%s.transaction.enabled] to true", ConfigKafkaPrefix)
+	}
+	return b.txManager.execute(ctx, fn)
+}
+
 func (b *SaramaKafkaBinder) Client() sarama.Client {
 	return b.globalClient
 }
@@ -303,6 +367,10 @@ func (b *SaramaKafkaBinder) Initialize(ctx context.Context) (err error) {
 			globalClient: b.globalClientProvider,
 			adminClient:  b.clusterAdminProvider,
 		}
+
+		if b.properties.Transaction.Enabled {
+			b.txManager = newSaramaTransactionManager(b.brokers, &b.defaults, &b.properties.Transaction)
+		}
 	})
 
 	return
@@ -349,7 +417,7 @@ func (b *SaramaKafkaBinder) Shutdown(ctx context.Context) error {
 	b.monitorCancelFunc = nil
 
 	logger.WithContext(ctx).Debugf("closing producers...")
-	for _, p := range b.producers {
+	for _, p := range b.bindingsSnapshot(b.producers) {
 		if e := p.Close(); e != nil {
 			// since application is shutting down, we just log the error
 			logger.WithContext(ctx).Errorf("error while closing kafka producer: %v", e)
@@ -357,7 +425,7 @@ func (b *SaramaKafkaBinder) Shutdown(ctx context.Context) error {
 	}
 
 	logger.WithContext(ctx).Debugf("closing subscribers...")
-	for _, p := range b.subscribers {
+	for _, p := range b.bindingsSnapshot(b.subscribers) {
 		if e := p.Close(); e != nil {
 			// since application is shutting down, we just log the error
 			logger.WithContext(ctx).Errorf("error while closing kafka subscriber: %v", e)
@@ -365,13 +433,20 @@ func (b *SaramaKafkaBinder) Shutdown(ctx context.Context) error {
 	}
 
 	logger.WithContext(ctx).Debugf("closing group consumers...")
-	for _, p := range b.consumerGroups {
+	for _, p := range b.bindingsSnapshot(b.consumerGroups) {
 		if e := p.Close(); e != nil {
 			// since application is shutting down, we just log the error
 			logger.WithContext(ctx).Errorf("error while closing kafka consumer: %v", e)
 		}
 	}
 
+	if b.txManager != nil {
+		logger.WithContext(ctx).Debugf("closing transactional producers...")
+		if e := b.txManager.Close(); e != nil {
+			logger.WithContext(ctx).Errorf("error while closing kafka transactional producer: %v", e)
+		}
+	}
+
 	logger.WithContext(ctx).Debugf("closing connections...")
 	if e := b.adminClient.Close(); e != nil {
 		logger.WithContext(ctx).Errorf("error while closing kafka admin client: %v", e)
@@ -403,6 +478,47 @@ func (b *SaramaKafkaBinder) Done() <-chan struct{} {
 	return done
 }
 
+// prepareRetry provisions retry topics and dead-letter topic according to the binding's RetryPolicy,
+// and installs RetryInterceptor to the binding config. The returned retry topics need to be consumed
+// using the same dispatcher of the original binding.
+func (b *SaramaKafkaBinder) prepareRetry(topic string, cfg *bindingConfig, target string) (retryTopics []string, err error) {
+	policy := cfg.consumer.retry
+	if !policy.Enabled() {
+		return nil, nil
+	}
+
+	for n := 1; n < policy.MaxAttempts; n++ {
+		retryTopics = append(retryTopics, RetryTopicName(topic, n))
+	}
+	toProvision := retryTopics
+	if policy.DeadLetter {
+		toProvision = append(toProvision, DeadLetterTopicName(topic))
+	}
+	for _, t := range toProvision {
+		if _, e := b.retryProducer(t); e != nil {
+			return nil, e
+		}
+	}
+
+	interceptor := NewRetryInterceptor(policy, b.retryProducer)
+	interceptor.Target = target
+	// Note: the interceptor slice is shared with defaults, we need to make a copy
+	cfg.consumer.dispatchInterceptors = append(
+		append([]ConsumerDispatchInterceptor{}, cfg.consumer.dispatchInterceptors...), interceptor,
+	)
+	return retryTopics, nil
+}
+
+// retryProducer returns existing Producer of given retry topic or dead-letter topic, or create one if not exist
+func (b *SaramaKafkaBinder) retryProducer(topic string) (Producer, error) {
+	if lc, ok := b.binding(b.producers, topic); ok && !lc.Closed() {
+		if p, ok := lc.(Producer); ok {
+			return p, nil
+		}
+	}
+	return b.Produce(topic)
+}
+
 // loadProperties load properties for particular topic
 func (b *SaramaKafkaBinder) loadProperties(name string) *BindingProperties {
 	prefix := ConfigKafkaBindingPrefix + "." + strings.ToLower(name)
@@ -437,6 +553,39 @@ func (b *SaramaKafkaBinder) clusterAdminProvider() (sarama.ClusterAdmin, error)
 	return newClient, nil
 }
 
+// binding returns the binding of given topic from given bindings map
+func (b *SaramaKafkaBinder) binding(bindings map[string]BindingLifecycle, topic string) (BindingLifecycle, bool) {
+	b.bindingsMtx.RLock()
+	defer b.bindingsMtx.RUnlock()
+	lc, ok := bindings[topic]
+	return lc, ok
+}
+
+// setBinding put the binding of given topic into given bindings map
+func (b *SaramaKafkaBinder) setBinding(bindings map[string]BindingLifecycle, topic string, lc BindingLifecycle) {
+	b.bindingsMtx.Lock()
+	defer b.bindingsMtx.Unlock()
+	bindings[topic] = lc
+}
+
+// removeBinding removes the binding of given topic from given bindings map
+func (b *SaramaKafkaBinder) removeBinding(bindings map[string]BindingLifecycle, topic string) {
+	b.bindingsMtx.Lock()
+	defer b.bindingsMtx.Unlock()
+	delete(bindings, topic)
+}
+
+// bindingsSnapshot returns a copy of given bindings map, which is safe to iterate without lock
+func (b *SaramaKafkaBinder) bindingsSnapshot(bindings map[string]BindingLifecycle) map[string]BindingLifecycle {
+	b.bindingsMtx.RLock()
+	defer b.bindingsMtx.RUnlock()
+	snapshot := make(map[string]BindingLifecycle, len(bindings))
+	for k, v := range bindings {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
 // tryScheduleStart try to schedule start given BindingLifecycle using monitor loop if started, otherwise do nothing
 func (b *SaramaKafkaBinder) tryScheduleStart(lc BindingLifecycle) error {
 	b.RLock()
@@ -466,10 +615,10 @@ func (b *SaramaKafkaBinder) tryStartTaskFunc(loopCtx context.Context) loop.TaskF
 			b.producers, b.subscribers, b.consumerGroups,
 		}
 		for _, bindings := range toProcess {
-			for k, lc := range bindings {
+			for k, lc := range b.bindingsSnapshot(bindings) {
 				switch e := lc.Start(loopCtx); {
 				case errors.Is(e, ErrorStartClosedBinding):
-					delete(bindings, k)
+					b.removeBinding(bindings, k)
 				case e != nil:
 					allStarted = false
 				}
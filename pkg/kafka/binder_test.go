@@ -18,6 +18,7 @@ package kafka_test
 
 import (
     "context"
+    "errors"
     "fmt"
     "github.com/cisco-open/go-lanai/pkg/actuator/health"
     "github.com/cisco-open/go-lanai/pkg/bootstrap"
@@ -61,11 +62,27 @@ func TestBinder(t *testing.T) {
 		test.GomegaSubTest(SubTestBindProducerAddPartition(&di), "TestBindProducerAddPartition"),
 		test.GomegaSubTest(SubTestBindSubscriber(&di), "TestBindSubscriber"),
 		test.GomegaSubTest(SubTestBindConsumer(&di), "TestBindConsumer"),
+		test.GomegaSubTest(SubTestTransactionDisabled(&di), "TestTransactionDisabled"),
 		test.GomegaSubTest(SubTestBinderHealth(&di), "TestBinderHealth"),
 		test.GomegaSubTest(SubTestShutdown(&di), "TestShutdown"),
 	)
 }
 
+func TestBinderWithRetry(t *testing.T) {
+	di := TestBinderDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithTimeout(60 * time.Second),
+		testdata.WithMockedBroker(),
+		apptest.WithFxOptions(
+			fx.Provide(kafka.BindKafkaProperties, kafka.ProvideKafkaBinder),
+		),
+		apptest.WithDI(&di),
+		test.SubTestSetup(SubSetupStartBinder(&di)),
+		test.GomegaSubTest(SubTestBindConsumerWithRetry(&di), "TestBindConsumerWithRetry"),
+	)
+}
+
 /*************************
 	Sub-Test Cases
  *************************/
@@ -147,6 +164,27 @@ func SubTestBindConsumer(di *TestBinderDI) test.GomegaSubTestFunc {
 	}
 }
 
+func SubTestTransactionDisabled(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-exactly-once-consumer`
+		const group = `test.group`
+
+		var invoked bool
+		e := di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+			invoked = true
+			return nil
+		})
+		g.Expect(e).To(HaveOccurred(), "transaction should fail when not enabled")
+		g.Expect(errors.Is(e, kafka.ErrorSubTypeIllegalProducerUsage)).To(BeTrue(), "error should be correct")
+		g.Expect(invoked).To(BeFalse(), "transaction function should not be invoked")
+
+		testdata.MockExistingTopic(ctx, topic, 0)
+		_, e = di.Binder.Consume(topic, group, kafka.ExactlyOnce())
+		g.Expect(e).To(HaveOccurred(), "exactly-once consumer should fail when transaction is not enabled")
+		g.Expect(di.Binder.ListTopics()).ToNot(ContainElement(topic), "list topics should be correct")
+	}
+}
+
 func SubTestBinderHealth(di *TestBinderDI) test.GomegaSubTestFunc {
 	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
 		indicator := kafka.NewHealthIndicator(di.Binder)
@@ -162,6 +200,36 @@ func SubTestBinderHealth(di *TestBinderDI) test.GomegaSubTestFunc {
 	}
 }
 
+func SubTestBindConsumerWithRetry(di *TestBinderDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-retry-consumer`
+		const group = `test.group`
+		retryTopics := []string{kafka.RetryTopicName(topic, 1), kafka.RetryTopicName(topic, 2)}
+		dlt := kafka.DeadLetterTopicName(topic)
+
+		testdata.MockExistingTopic(ctx, topic, 0)
+		for _, rt := range append(retryTopics, dlt) {
+			testdata.MockCreateTopic(ctx, rt)
+		}
+		consumer, e := di.Binder.Consume(topic, group, kafka.RetryAttempts(3, 10*time.Millisecond, 2), kafka.DeadLetter(true))
+		g.Expect(e).To(Succeed(), "bind consumer with retry should not fail")
+		g.Expect(consumer.Topic()).To(Equal(topic), "consumer's topic should be correct")
+
+		// retry topics and dead-letter topic should be provisioned
+		topics := di.Binder.ListTopics()
+		g.Expect(topics).To(ContainElement(topic), "list topics should contain main topic")
+		g.Expect(topics).To(ContainElements(retryTopics), "list topics should contain retry topics")
+		g.Expect(topics).To(ContainElement(dlt), "list topics should contain dead-letter topic")
+
+		// retry topics should be consumed by the same group
+		for _, rt := range retryTopics {
+			_, e = di.Binder.Consume(rt, group)
+			g.Expect(e).To(HaveOccurred(), "binding consumer on retry topic should fail when it's already consumed")
+			g.Expect(errors.Is(e, kafka.ErrorSubTypeProvisioning)).To(BeTrue(), "error should be correct")
+		}
+	}
+}
+
 func SubTestShutdown(di *TestBinderDI) test.GomegaSubTestFunc {
 	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
 		syncCh := make(chan struct{}, 1)
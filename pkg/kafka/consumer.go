@@ -23,6 +23,7 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/cisco-open/go-lanai/pkg/utils/order"
 	"sync"
+	"time"
 )
 
 type saramaGroupConsumer struct {
@@ -33,6 +34,7 @@ type saramaGroupConsumer struct {
 	config      *bindingConfig
 	dispatcher  *saramaDispatcher
 	provisioner *saramaTopicProvisioner
+	txManager   *saramaTransactionManager
 	started     bool
 	consumer    sarama.ConsumerGroup
 	cancelFunc  context.CancelFunc
@@ -194,12 +196,26 @@ func (h saramaGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
 
 // ConsumeClaim is run in separate goroutine
 func (h saramaGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	if size, maxWait, ok := h.dispatcher.batchOption(); ok {
+		return h.consumeBatches(session, claim, size, maxWait)
+	}
 	for {
 		select {
 		case msg, ok := <-claim.Messages():
 			if !ok {
 				return nil
 			}
+			if h.owner.txManager != nil {
+				// offsets committed within transactions need to be in order.
+				// When a transaction is aborted, we stop consuming this claim. The session would be re-created and
+				// the failed message would be re-delivered from the last committed offset.
+				// Otherwise, transactions of following messages would commit higher offsets and skip the failed one.
+				if e := h.handleMessageInTransaction(session.Context(), session, msg); e != nil {
+					h.backoff(session.Context())
+					return nil
+				}
+				continue
+			}
 			go h.handleMessage(session.Context(), session, msg)
 		case <-session.Context().Done():
 			return nil
@@ -211,9 +227,111 @@ func (h saramaGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, cl
 func (h saramaGroupHandler) handleMessage(ctx context.Context, session sarama.ConsumerGroupSession, raw *sarama.ConsumerMessage) {
 	if e := h.dispatcher.Dispatch(ctx, raw, h.owner); e != nil {
 		logger.WithContext(ctx).Warnf("failed to handle message: %v", e)
-		// TODO we should consider limit retry count, or let Handler decide whether to retry by specifying a special error type
 		session.ResetOffset(raw.Topic, raw.Partition, raw.Offset, e.Error())
 		return
 	}
 	session.MarkMessage(raw, "")
 }
+
+// handleMessageInTransaction dispatch the message within a Transaction and commit the consumed offset within the same
+// transaction. The transaction is aborted and the offset is reset to the failed message if any error occurred.
+func (h saramaGroupHandler) handleMessageInTransaction(ctx context.Context, session sarama.ConsumerGroupSession, raw *sarama.ConsumerMessage) error {
+	e := h.owner.txManager.execute(ctx, func(tx Transaction) error {
+		if e := h.dispatcher.Dispatch(tx, raw, h.owner); e != nil {
+			return e
+		}
+		return tx.AddOffset(h.owner.group, raw.Topic, raw.Partition, raw.Offset)
+	})
+	if e != nil {
+		logger.WithContext(ctx).Warnf("failed to handle message: %v", e)
+		session.ResetOffset(raw.Topic, raw.Partition, raw.Offset, e.Error())
+		return e
+	}
+	// Note: sarama doesn't commit offsets of transactions without any produced record, so we also mark the message.
+	// When the offset is already committed within the transaction, committing the same offset again is harmless.
+	session.MarkMessage(raw, "")
+	return nil
+}
+
+// backoff waits for configured consumer retry backoff or until the session is done.
+// This is to avoid re-joining the group in a tight loop when a message keeps failing.
+func (h saramaGroupHandler) backoff(ctx context.Context) {
+	timer := time.NewTimer(h.owner.config.sarama.Consumer.Retry.Backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// consumeBatches accumulates messages of the claimed partition and dispatch them in batches.
+// A batch is dispatched when "size" messages are accumulated or "maxWait" elapsed since its first message.
+// Batches are handled sequentially, so offsets are always committed in order.
+// When a batch fails, we stop consuming this claim, so the whole batch is re-delivered from the last committed offset
+// in the next session. Otherwise, following batches would commit higher offsets and skip the failed one.
+func (h saramaGroupHandler) consumeBatches(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, size int, maxWait time.Duration) error {
+	batch := make([]*sarama.ConsumerMessage, 0, size)
+	timer := time.NewTimer(maxWait)
+	timer.Stop()
+	defer timer.Stop()
+	flush := func() (err error) {
+		timer.Stop()
+		if len(batch) != 0 {
+			err = h.handleBatch(session.Context(), session, batch)
+			batch = make([]*sarama.ConsumerMessage, 0, size)
+		}
+		return
+	}
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				_ = flush()
+				return nil
+			}
+			if len(batch) == 0 {
+				timer.Reset(maxWait)
+			}
+			if batch = append(batch, msg); len(batch) < size {
+				continue
+			}
+			if e := flush(); e != nil {
+				h.backoff(session.Context())
+				return nil
+			}
+		case <-timer.C:
+			if e := flush(); e != nil {
+				h.backoff(session.Context())
+				return nil
+			}
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// handleBatch dispatch given messages as a batch. Offset of the last message is committed only if the whole batch
+// is handled successfully. When transaction is enabled, the batch is handled within a single Transaction.
+// The offset is reset to the first message of the batch if any error occurred.
+func (h saramaGroupHandler) handleBatch(ctx context.Context, session sarama.ConsumerGroupSession, raws []*sarama.ConsumerMessage) error {
+	first, last := raws[0], raws[len(raws)-1]
+	var e error
+	if h.owner.txManager != nil {
+		e = h.owner.txManager.execute(ctx, func(tx Transaction) error {
+			if e := h.dispatcher.DispatchBatch(tx, raws, h.owner); e != nil {
+				return e
+			}
+			return tx.AddOffset(h.owner.group, last.Topic, last.Partition, last.Offset)
+		})
+	} else {
+		e = h.dispatcher.DispatchBatch(ctx, raws, h.owner)
+	}
+	if e != nil {
+		logger.WithContext(ctx).Warnf("failed to handle batch of %d messages: %v", len(raws), e)
+		session.ResetOffset(first.Topic, first.Partition, first.Offset, e.Error())
+		return e
+	}
+	// Note: see handleMessageInTransaction for why we mark the message even with transaction
+	session.MarkMessage(last, "")
+	return nil
+}
@@ -56,6 +56,17 @@ type Encoder interface {
 	Encode(v interface{}) ([]byte, error)
 }
 
+// Decoder decodes message payload that is not supported by built-in decoders, e.g. schema registry wire format.
+// Built-in decoders support "application/json", "text/plain" and "application/octet-stream".
+// Other payloads are decoded by the first Decoder that can decode it.
+type Decoder interface {
+	// CanDecode returns true if the Decoder can decode the payload with given content type.
+	// Note: "contentType" is empty if the message doesn't have HeaderContentType header.
+	CanDecode(contentType string, data []byte) bool
+	// Decode decodes data into "v", which is a pointer of the handler's payload type
+	Decode(ctx context.Context, data []byte, v interface{}) error
+}
+
 // MessageContext internal use only, used by Interceptors and processors
 type MessageContext struct {
 	context.Context
@@ -89,8 +100,28 @@ type Binder interface {
 
 	// ListTopics list of topics of all managed bindings
 	ListTopics() []string
+
+	// Transaction execute given TransactionFunc within a Kafka transaction. Messages sent by any Producer using
+	// the Transaction (or any context derived from it) as context are committed atomically if the function returns nil,
+	// or aborted otherwise. If given context already carries a Transaction, the function joins the ongoing transaction.
+	// Transaction requires "kafka.transaction.enabled" to be true.
+	Transaction(ctx context.Context, fn TransactionFunc) error
+}
+
+// Transaction is a context.Context carrying an ongoing Kafka transaction.
+// Consumers reading from topics written by transactions should use ReadCommitted option.
+type Transaction interface {
+	context.Context
+	// TransactionalID returns the transactional ID of the producer that owns the transaction
+	TransactionalID() string
+	// AddOffset add the offset of a consumed message to the transaction, so it's committed for the consumer group
+	// if and only if the transaction is committed.
+	AddOffset(group string, topic string, partition int32, offset int64) error
 }
 
+// TransactionFunc is executed within a Transaction. See Binder.Transaction
+type TransactionFunc func(tx Transaction) error
+
 type BinderLifecycle interface {
 	// Initialize should be called only once, before Shutdown is executed.
 	Initialize(ctx context.Context) error
@@ -144,6 +175,24 @@ type Producer interface {
 	ReadyCh() <-chan struct{}
 }
 
+// RawMessage is a message prepared by Producer, with encoded key and payload. See MessageWriter
+type RawMessage struct {
+	Topic   string
+	Key     []byte
+	Headers Headers
+	Payload []byte
+}
+
+// MessageWriter is an alternative destination of messages sent by Producer, e.g. a transactional outbox.
+// When configured via WithMessageWriter, messages are prepared as usual (all ProducerMessageInterceptor applied)
+// and passed to the MessageWriter before being sent to brokers.
+type MessageWriter interface {
+	// Write writes the prepared message. Returns true if the message is handled by the writer,
+	// or false if the message should be sent to brokers as usual.
+	// Any returned error is passed to ProducerMessageFinalizer as if the sending failed.
+	Write(ctx context.Context, msg *RawMessage) (handled bool, err error)
+}
+
 type ProducerMessageInterceptor interface {
 	// Intercept is called before raw message is prepared and send.
 	// Implementations can modify fields of MessageContext to manipulate sending behaviour.
@@ -248,12 +297,16 @@ type producerConfig struct {
 	keyEncoder   Encoder
 	interceptors []ProducerMessageInterceptor
 	provisioning topicConfig
+	writer       MessageWriter
 }
 
 type consumerConfig struct {
 	dispatchInterceptors []ConsumerDispatchInterceptor
 	handlerInterceptors  []ConsumerHandlerInterceptor
 	msgLogger            MessageLogger
+	retry                RetryPolicy
+	exactlyOnce          bool
+	decoders             []Decoder
 }
 
 type topicConfig struct {
@@ -85,6 +85,7 @@ type handler struct {
 	params       params
 	filterFunc   MessageFilterFunc
 	interceptors []ConsumerHandlerInterceptor
+	batch        *batchOption
 }
 
 /**************************
@@ -97,6 +98,7 @@ type Dispatcher struct {
 	handlers     []*handler
 	Interceptors []ConsumerDispatchInterceptor
 	Logger       MessageLogger
+	Decoders     []Decoder
 }
 
 func (d *Dispatcher) AddHandler(fn MessageHandlerFunc, opts ...DispatchOptions) error {
@@ -115,7 +117,12 @@ func (d *Dispatcher) AddHandler(fn MessageHandlerFunc, opts ...DispatchOptions)
 
 	// parse and validate input params
 	t := f.Type()
-	for i := t.NumIn() - 1; i >= 0; i-- {
+	if h.batch != nil {
+		if e := d.parseBatchParams(fn, &h); e != nil {
+			return e
+		}
+	}
+	for i := t.NumIn() - 1; h.batch == nil && i >= 0; i-- {
 		switch it := t.In(i); {
 		case it.AssignableTo(reflectTypeContext):
 			if i != 0 {
@@ -164,7 +171,10 @@ func (d *Dispatcher) Dispatch(msgCtx *MessageContext) (err error) {
 	// invoke Interceptors
 	for _, interceptor := range d.Interceptors {
 		msgCtx, err = interceptor.Intercept(msgCtx)
-		if err != nil {
+		switch {
+		case errors.Is(err, errSkipDispatch):
+			return nil
+		case err != nil:
 			return ErrorSubTypeConsumerGeneral.WithMessage("consumer dispatch interceptor error: %v", err)
 		}
 	}
@@ -178,7 +188,23 @@ func (d *Dispatcher) Dispatch(msgCtx *MessageContext) (err error) {
 		d.Logger.LogReceivedMessage(msgCtx.Context, msgCtx.RawMessage)
 	}
 
+	batch, isBatch := msgCtx.RawMessage.(MessageBatch)
 	for _, h := range d.handlers {
+		switch {
+		case isBatch && h.batch != nil:
+			err = d.dispatchBatch(msgCtx, h, batch)
+		case isBatch:
+			err = d.dispatchEach(msgCtx, h, batch)
+		case h.batch != nil:
+			err = d.dispatchBatch(msgCtx, h, MessageBatch{msgCtx})
+		}
+		if err != nil {
+			return
+		}
+		if isBatch || h.batch != nil {
+			continue
+		}
+
 		// apply filters
 		if h.filterFunc != nil {
 			if ok := h.filterFunc(msgCtx.Context, &msgCtx.Message); !ok {
@@ -242,7 +268,7 @@ func (d *Dispatcher) finalizeDispatch(msgCtx *MessageContext, err error) error {
 	Helpers
  ********************/
 
-func (d *Dispatcher) decodePayload(_ context.Context, typ reflect.Type, msg *Message) error {
+func (d *Dispatcher) decodePayload(ctx context.Context, typ reflect.Type, msg *Message) error {
 	if _, ok := msg.Payload.([]byte); !ok || typ == nil {
 		return nil
 	}
@@ -259,11 +285,30 @@ func (d *Dispatcher) decodePayload(_ context.Context, typ reflect.Type, msg *Mes
 	case contentType == MIMETypeBinary:
 		//  do nothing
 	default:
-		return ErrorSubTypeDecoding.WithMessage("unsupported MIME type %s", contentType)
+		return d.decodeWithDecoders(ctx, typ, contentType, msg)
 	}
 	return nil
 }
 
+func (d *Dispatcher) decodeWithDecoders(ctx context.Context, typ reflect.Type, contentType string, msg *Message) error {
+	data := msg.Payload.([]byte)
+	for _, dec := range d.Decoders {
+		if !dec.CanDecode(contentType, data) {
+			continue
+		}
+		ptr, v := d.instantiateByType(typ)
+		if e := dec.Decode(ctx, data, ptr.Interface()); e != nil {
+			if errors.Is(e, ErrorCategoryKafka) {
+				return e
+			}
+			return ErrorSubTypeDecoding.WithCause(e, "unable to decode as %s: %v", contentType, e)
+		}
+		msg.Payload = v.Interface()
+		return nil
+	}
+	return ErrorSubTypeDecoding.WithMessage("unsupported MIME type %s", contentType)
+}
+
 func (d *Dispatcher) invokeHandler(ctx context.Context, handler *handler, msg *Message, msgCtx *MessageContext) (err error) {
 	// prepare input params
 	in := make([]reflect.Value, handler.params.count)
@@ -280,19 +325,7 @@ func (d *Dispatcher) invokeHandler(ctx context.Context, handler *handler, msg *M
 
 	// message metadata
 	if handler.params.metadata.i != 0 {
-		var meta *MessageMetadata
-		switch raw := msgCtx.RawMessage.(type) {
-		case *sarama.ConsumerMessage:
-			meta = &MessageMetadata{
-				Key:       raw.Key,
-				Partition: int(raw.Partition),
-				Offset:    int(raw.Offset),
-				Timestamp: raw.Timestamp,
-			}
-		default:
-			meta = &MessageMetadata{}
-		}
-
+		meta := d.metadataOf(msgCtx.RawMessage)
 		if e := handler.params.metadata.assign(in, reflect.ValueOf(meta)); e != nil {
 			return e
 		}
@@ -306,6 +339,20 @@ func (d *Dispatcher) invokeHandler(ctx context.Context, handler *handler, msg *M
 	return
 }
 
+func (d *Dispatcher) metadataOf(rawMsg interface{}) *MessageMetadata {
+	switch raw := rawMsg.(type) {
+	case *sarama.ConsumerMessage:
+		return &MessageMetadata{
+			Key:       raw.Key,
+			Partition: int(raw.Partition),
+			Offset:    int(raw.Offset),
+			Timestamp: raw.Timestamp,
+		}
+	default:
+		return &MessageMetadata{}
+	}
+}
+
 // instantiateByType
 // "ptr" is the pointer regardless if given type is Ptr or other type
 // "value" is actually the value with given type
@@ -350,11 +397,28 @@ func newSaramaDispatcher(cfg *bindingConfig) *saramaDispatcher {
 			handlers:     []*handler{},
 			Interceptors: cfg.consumer.dispatchInterceptors,
 			Logger:       cfg.msgLogger,
+			Decoders:     cfg.consumer.decoders,
 		},
 	}
 }
 
 func (d *saramaDispatcher) Dispatch(ctx context.Context, raw *sarama.ConsumerMessage, source interface{}) (err error) {
+	return d.Dispatcher.Dispatch(d.newMessageContext(ctx, raw, source))
+}
+
+// DispatchBatch dispatch given messages as a batch. See MessageBatch
+func (d *saramaDispatcher) DispatchBatch(ctx context.Context, raws []*sarama.ConsumerMessage, source interface{}) (err error) {
+	if len(raws) == 0 {
+		return nil
+	}
+	batch := make(MessageBatch, len(raws))
+	for i := range raws {
+		batch[i] = d.newMessageContext(ctx, raws[i], source)
+	}
+	return d.Dispatcher.Dispatch(NewBatchMessageContext(ctx, batch))
+}
+
+func (d *saramaDispatcher) newMessageContext(ctx context.Context, raw *sarama.ConsumerMessage, source interface{}) *MessageContext {
 	// parse header
 	headers := Headers{}
 	for _, rh := range raw.Headers {
@@ -365,7 +429,7 @@ func (d *saramaDispatcher) Dispatch(ctx context.Context, raw *sarama.ConsumerMes
 	}
 
 	// create message context
-	msgCtx := &MessageContext{
+	return &MessageContext{
 		Context: ctx,
 		Message: Message{
 			Headers: headers,
@@ -375,8 +439,6 @@ func (d *saramaDispatcher) Dispatch(ctx context.Context, raw *sarama.ConsumerMes
 		Topic:      raw.Topic,
 		RawMessage: raw,
 	}
-
-	return d.Dispatcher.Dispatch(msgCtx)
 }
 
 func (d *saramaDispatcher) AddHandler(fn MessageHandlerFunc, cfg *consumerConfig, opts []DispatchOptions) error {
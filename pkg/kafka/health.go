@@ -18,7 +18,9 @@ package kafka
 
 import (
 	"context"
+	"fmt"
 	"github.com/cisco-open/go-lanai/pkg/actuator/health"
+	"strconv"
 )
 
 type HealthIndicator struct {
@@ -53,3 +55,71 @@ func (i *HealthIndicator) Health(_ context.Context, opts health.Options) health.
 	}
 	return health.NewDetailedHealth(health.StatusUp, "kafka refresh metadata succeeded", details)
 }
+
+// ConsumerLagHealthIndicator reports lags of all GroupConsumer bindings.
+// The status is determined by the max lag of any partition and configured thresholds.
+type ConsumerLagHealthIndicator struct {
+	binder     AdminBinder
+	properties ConsumerLagHealthProperties
+}
+
+// NewConsumerLagHealthIndicator creates ConsumerLagHealthIndicator. The indicator reports UNKNOWN status
+// if given Binder doesn't implement AdminBinder
+func NewConsumerLagHealthIndicator(binder Binder, props ConsumerLagHealthProperties) *ConsumerLagHealthIndicator {
+	ab, _ := binder.(AdminBinder)
+	return &ConsumerLagHealthIndicator{binder: ab, properties: props}
+}
+
+func (i *ConsumerLagHealthIndicator) Name() string {
+	return "kafkaConsumerLag"
+}
+
+func (i *ConsumerLagHealthIndicator) Health(_ context.Context, opts health.Options) health.Health {
+	if i.binder == nil {
+		return health.NewDetailedHealth(health.StatusUnknown, "kafka binder doesn't support admin operations", nil)
+	}
+	topicsByGroup := map[string][]string{}
+	for _, b := range i.binder.Bindings() {
+		if b.Type == BindingTypeGroupConsumer && !b.Closed {
+			topicsByGroup[b.Group] = append(topicsByGroup[b.Group], b.Topic)
+		}
+	}
+
+	admin := i.binder.Admin()
+	var maxLag int64
+	groups := map[string]interface{}{}
+	for group, topics := range topicsByGroup {
+		lags, e := admin.ConsumerLag(group, topics...)
+		if e != nil {
+			return health.NewDetailedHealth(health.StatusUnknown, fmt.Sprintf("unable to get lag of group [%s]: %v", group, e), nil)
+		}
+		groupDetails := map[string]map[string]int64{}
+		for _, lag := range lags {
+			if _, ok := groupDetails[lag.Topic]; !ok {
+				groupDetails[lag.Topic] = map[string]int64{}
+			}
+			groupDetails[lag.Topic][strconv.Itoa(int(lag.Partition))] = lag.Lag
+			if lag.Lag > maxLag {
+				maxLag = lag.Lag
+			}
+		}
+		groups[group] = groupDetails
+	}
+
+	var details map[string]interface{}
+	if opts.ShowDetails {
+		details = map[string]interface{}{
+			"maxLag": maxLag,
+			"groups": groups,
+		}
+	}
+	desc := fmt.Sprintf("max consumer lag is %d", maxLag)
+	switch {
+	case i.properties.DownThreshold > 0 && maxLag >= i.properties.DownThreshold:
+		return health.NewDetailedHealth(health.StatusDown, desc, details)
+	case i.properties.DegradedThreshold > 0 && maxLag >= i.properties.DegradedThreshold:
+		return health.NewDetailedHealth(health.StatusDegraded, desc, details)
+	default:
+		return health.NewDetailedHealth(health.StatusUp, desc, details)
+	}
+}
@@ -83,5 +83,9 @@ func (l saramaMessageLogger) LogReceivedMessage(ctx context.Context, msg interfa
 			logMsg = logMsg + fmt.Sprintf(" Key=%x", m.Key)
 		}
 		logger.WithContext(ctx).WithLevel(l.level).Printf(logMsg)
+	case MessageBatch:
+		for _, item := range m {
+			l.LogReceivedMessage(ctx, item.RawMessage)
+		}
 	}
 }
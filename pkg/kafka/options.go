@@ -98,6 +98,9 @@ func WithProducerProperties(p *ProducerProperties) ProducerOptions {
 		utils.MustSetIfNotNil(&cfg.sarama.Producer.Timeout, p.AckTimeout)
 		utils.MustSetIfNotNil(&cfg.sarama.Producer.Retry.Max, p.MaxRetry)
 		utils.MustSetIfNotNil(&cfg.sarama.Producer.Retry.Backoff, p.Backoff)
+		if p.Idempotent != nil && *p.Idempotent {
+			Idempotent()(cfg)
+		}
 		utils.MustSetIfNotNil(&cfg.producer.provisioning.autoCreateTopic, p.Provisioning.AutoCreateTopic)
 		utils.MustSetIfNotNil(&cfg.producer.provisioning.autoAddPartitions, p.Provisioning.AutoAddPartitions)
 		utils.MustSetIfNotNil(&cfg.producer.provisioning.allowLowerPartitions, p.Provisioning.AllowLowerPartitions)
@@ -157,6 +160,27 @@ func AckTimeout(timeout time.Duration) ProducerOptions {
 	}
 }
 
+// WithMessageWriter configures Producer to pass prepared messages to given MessageWriter before sending them to brokers.
+// Messages handled by the writer are not sent to brokers by the Producer. See MessageWriter
+func WithMessageWriter(writer MessageWriter) ProducerOptions {
+	return func(config *bindingConfig) {
+		config.producer.writer = writer
+	}
+}
+
+// Idempotent enables idempotent producer, which guarantees exactly one copy of each message is written to the topic.
+// Idempotent producer requires all replicas to ack, and at most one in-flight request per broker.
+func Idempotent() ProducerOptions {
+	return func(config *bindingConfig) {
+		config.sarama.Producer.Idempotent = true
+		config.sarama.Producer.RequiredAcks = sarama.WaitForAll
+		config.sarama.Net.MaxOpenRequests = 1
+		if config.sarama.Producer.Retry.Max < 1 {
+			config.sarama.Producer.Retry.Max = 1
+		}
+	}
+}
+
 /***********************
   Options for consumer
 ************************/
@@ -171,6 +195,75 @@ func WithConsumerProperties(p *ConsumerProperties) ConsumerOptions {
 		utils.MustSetIfNotNil(&cfg.sarama.Consumer.Group.Rebalance.Timeout, p.Group.JoinTimeout)
 		utils.MustSetIfNotNil(&cfg.sarama.Consumer.Group.Rebalance.Retry.Max, p.Group.MaxRetry)
 		utils.MustSetIfNotNil(&cfg.sarama.Consumer.Group.Rebalance.Retry.Backoff, p.Group.Backoff)
+		utils.MustSetIfNotNil(&cfg.consumer.retry.MaxAttempts, p.Retry.MaxAttempts)
+		utils.MustSetIfNotNil(&cfg.consumer.retry.Backoff, p.Retry.Backoff)
+		utils.MustSetIfNotNil(&cfg.consumer.retry.Multiplier, p.Retry.Multiplier)
+		utils.MustSetIfNotNil(&cfg.consumer.retry.MaxBackoff, p.Retry.MaxBackoff)
+		utils.MustSetIfNotNil(&cfg.consumer.retry.DeadLetter, p.Retry.DeadLetter)
+		if len(p.Retry.NonRetryableErrors) != 0 {
+			cfg.consumer.retry.NonRetryableTypes = append(
+				append([]string{}, cfg.consumer.retry.NonRetryableTypes...), p.Retry.NonRetryableErrors...,
+			)
+		}
+		if p.ExactlyOnce != nil && *p.ExactlyOnce {
+			ExactlyOnce()(cfg)
+		}
+	}
+}
+
+// RetryAttempts is a ConsumerOptions that enables delayed retry topics. Failed messages are re-published to
+// "<topic>.retry.N" and attempted at most maxAttempts times (including the first attempt).
+// The backoff of N-th retry is calculated using given backoff and multiplier. Fixed backoff is used if multiplier <= 1.
+// See RetryPolicy
+func RetryAttempts(maxAttempts int, backoff time.Duration, multiplier float64) ConsumerOptions {
+	return func(cfg *bindingConfig) {
+		cfg.consumer.retry.MaxAttempts = maxAttempts
+		cfg.consumer.retry.Backoff = backoff
+		cfg.consumer.retry.Multiplier = multiplier
+	}
+}
+
+// DeadLetter is a ConsumerOptions that enables or disables dead-letter topic "<topic>.DLT".
+// When enabled, messages are sent to the dead-letter topic when retries are exhausted or the error is non-retryable.
+// See RetryPolicy
+func DeadLetter(enabled bool) ConsumerOptions {
+	return func(cfg *bindingConfig) {
+		cfg.consumer.retry.DeadLetter = enabled
+	}
+}
+
+// NonRetryableErrors is a ConsumerOptions that specify errors that should not be retried. Errors are matched using errors.Is.
+// See RetryPolicy
+func NonRetryableErrors(errs ...error) ConsumerOptions {
+	return func(cfg *bindingConfig) {
+		cfg.consumer.retry.NonRetryable = append(append([]error{}, cfg.consumer.retry.NonRetryable...), errs...)
+	}
+}
+
+// ReadCommitted is a ConsumerOptions that makes consumer only read messages of committed transactions.
+// Messages of aborted transactions are skipped.
+func ReadCommitted() ConsumerOptions {
+	return func(cfg *bindingConfig) {
+		cfg.sarama.Consumer.IsolationLevel = sarama.ReadCommitted
+	}
+}
+
+// ExactlyOnce is a ConsumerOptions that enables exactly-once processing on GroupConsumer.
+// Each message is dispatched within a Transaction (see Binder.Transaction). Messages sent by handlers using the given
+// context and the consumed offset are committed atomically. Messages of the same partition are processed sequentially.
+// This option also implies ReadCommitted and requires "kafka.transaction.enabled" to be true.
+func ExactlyOnce() ConsumerOptions {
+	return func(cfg *bindingConfig) {
+		cfg.consumer.exactlyOnce = true
+		ReadCommitted()(cfg)
+	}
+}
+
+// Decoders is a ConsumerOptions that adds decoders for payloads not supported by built-in decoders.
+// See Decoder
+func Decoders(decoders ...Decoder) ConsumerOptions {
+	return func(cfg *bindingConfig) {
+		cfg.consumer.decoders = append(append([]Decoder{}, cfg.consumer.decoders...), decoders...)
 	}
 }
 
@@ -258,6 +351,34 @@ func AddInterceptors(interceptors ...ConsumerHandlerInterceptor) DispatchOptions
 	}
 }
 
+// Batch returns a DispatchOptions that registers a batch MessageHandlerFunc, which accepts slices of messages.
+// GroupConsumer accumulates messages of each partition until "size" messages are received or "maxWait" elapsed since
+// the first message of the batch, whichever comes first. Offsets are committed after the whole batch is handled.
+// Batch MessageHandlerFunc conform with following signature:
+//
+//		func (ctx context.Context, [OPTIONAL_BATCH_INPUT_PARAMS...]) error
+//
+// Where OPTIONAL_BATCH_INPUT_PARAMS are slices of components described in MessageHandlerFunc, e.g.
+//
+//	func Handle(ctx context.Context, payloads []*MyStruct) error
+//	func Handle(ctx context.Context, payloads []*MyStruct, metas []*MessageMetadata) error
+//	func Handle(ctx context.Context, raws []*Message) error
+//
+// Note: When multiple batch handlers are registered to the same GroupConsumer, the largest "size" and shortest "maxWait"
+// are used to accumulate messages, and each handler receives batches no larger than its own "size".
+// Subscriber doesn't accumulate messages, batch handlers always receive batches of single message.
+func Batch(size int, maxWait time.Duration) DispatchOptions {
+	return func(h *handler) {
+		if size < 1 {
+			size = 1
+		}
+		h.batch = &batchOption{
+			size:    size,
+			maxWait: maxWait,
+		}
+	}
+}
+
 func noop() func(h *handler) {
 	return func(_ *handler) {
 		// noop
@@ -0,0 +1,37 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaoutbox
+
+import (
+	"github.com/cisco-open/go-lanai/pkg/migration"
+	"gorm.io/gorm"
+)
+
+// NewMigration returns a migration step of given version that creates the outbox table "kafka_outbox".
+// The step can be rolled back by dropping the table. e.g.
+//
+//	func registerMigrations(r *migration.Registrar, db *gorm.DB) {
+//		r.AddMigrations(kafkaoutbox.NewMigration("1.0.0.1", db))
+//	}
+func NewMigration(version string, db *gorm.DB) *migration.Migration {
+	outbox := NewGormOutbox(db)
+	return migration.WithVersion(version).
+		WithTag(migration.TagPreUpgrade).
+		WithDesc("create kafka outbox table").
+		WithFunc(outbox.CreateTableIfNotExist).
+		WithRollbackFunc(outbox.DropTableIfExists)
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kafkaoutbox
+// Provides transactional outbox for kafka.Producer, backed by relational database via GORM (pkg/data).
+// Messages sent within a tx.Transaction are written to the outbox table as part of the DB transaction,
+// and published to Kafka by a Relay running on the leader instance (see dsync.LeadershipLock).
+//
+// The outbox table "kafka_outbox" is not created automatically. Register NewMigration in the service's migration app,
+// or use GormOutbox.CreateTableIfNotExist as a migration step.
+package kafkaoutbox
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/data/tx"
+	"github.com/cisco-open/go-lanai/pkg/data/types/pqx"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	"gorm.io/gorm"
+	"time"
+)
+
+// OutboxMessage is the GORM model of pending or sent outbox message.
+// Key, Headers and Payload are stored as prepared by kafka.Producer
+type OutboxMessage struct {
+	ID        uint64             `gorm:"primaryKey;autoIncrement"`
+	Topic     string             `gorm:"type:text;not null"`
+	Key       []byte             `gorm:"type:bytea"`
+	Headers   pqx.JsonbStringMap `gorm:"type:jsonb"`
+	Payload   []byte             `gorm:"type:bytea"`
+	CreatedAt time.Time
+	SentAt    *time.Time `gorm:"index"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "kafka_outbox"
+}
+
+// GormOutbox implements kafka.MessageWriter.
+// When a kafka.Producer configured with kafka.WithMessageWriter sends a message within tx.Transaction,
+// the message is written to the outbox table using the transaction (see tx.GormTxWithContext) instead of being sent
+// to brokers. Messages sent outside any transaction are sent to brokers as usual.
+type GormOutbox struct {
+	db *gorm.DB
+}
+
+func NewGormOutbox(db *gorm.DB) *GormOutbox {
+	return &GormOutbox{
+		db: db,
+	}
+}
+
+// CreateTableIfNotExist creates or updates table "kafka_outbox". It's compatible with migration.MigrationFunc
+func (o *GormOutbox) CreateTableIfNotExist(ctx context.Context) error {
+	return o.db.WithContext(ctx).AutoMigrate(&OutboxMessage{})
+}
+
+// DropTableIfExists drops table "kafka_outbox". It's compatible with migration.MigrationFunc
+func (o *GormOutbox) DropTableIfExists(ctx context.Context) error {
+	return o.db.WithContext(ctx).Migrator().DropTable(&OutboxMessage{})
+}
+
+func (o *GormOutbox) Write(ctx context.Context, msg *kafka.RawMessage) (bool, error) {
+	db := tx.GormTxWithContext(ctx)
+	if db == nil {
+		return false, nil
+	}
+	model := OutboxMessage{
+		Topic:   msg.Topic,
+		Key:     msg.Key,
+		Headers: pqx.JsonbStringMap(msg.Headers),
+		Payload: msg.Payload,
+	}
+	if e := db.Create(&model).Error; e != nil {
+		return true, e
+	}
+	return true, nil
+}
+
+// ProducerOptions returns kafka.ProducerOptions that enables the outbox on the kafka.Producer
+func (o *GormOutbox) ProducerOptions() kafka.ProducerOptions {
+	return kafka.WithMessageWriter(o)
+}
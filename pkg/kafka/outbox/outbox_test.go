@@ -0,0 +1,151 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaoutbox_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data/tx"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	kafkaoutbox "github.com/cisco-open/go-lanai/pkg/kafka/outbox"
+	"github.com/cisco-open/go-lanai/pkg/migration"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+/*************************
+	Test
+ *************************/
+
+//func TestMain(m *testing.M) {
+//	suitetest.RunTests(m,
+//		dbtest.EnableDBRecordMode(),
+//	)
+//}
+
+func TestGormOutbox(t *testing.T) {
+	di := &dbtest.DI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupTestPrepareOutboxTable(di)),
+		test.GomegaSubTest(SubTestWriteWithoutTransaction(di), "TestWriteWithoutTransaction"),
+		test.GomegaSubTest(SubTestWriteWithinTransaction(di), "TestWriteWithinTransaction"),
+		test.GomegaSubTest(SubTestWriteWithinRollbackTransaction(di), "TestWriteWithinRollbackTransaction"),
+		test.GomegaSubTest(SubTestMigration(di), "TestMigration"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SetupTestPrepareOutboxTable(di *dbtest.DI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		outbox := kafkaoutbox.NewGormOutbox(di.DB)
+		g.Expect(outbox.CreateTableIfNotExist(ctx)).To(Succeed(), "create table should not fail")
+		rs := di.DB.WithContext(ctx).Exec(`TRUNCATE TABLE "kafka_outbox"`)
+		g.Expect(rs.Error).To(Succeed(), "truncate table should not fail")
+		return ctx, nil
+	}
+}
+
+func SubTestWriteWithoutTransaction(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		outbox := kafkaoutbox.NewGormOutbox(di.DB)
+		handled, e := outbox.Write(ctx, &kafka.RawMessage{Topic: "test-topic", Payload: []byte(`{}`)})
+		g.Expect(e).To(Succeed(), "Write should not fail")
+		g.Expect(handled).To(BeFalse(), "message should not be handled outside transaction")
+		g.Expect(LoadOutboxMessages(ctx, g, di.DB)).To(BeEmpty(), "no message should be written to outbox table")
+	}
+}
+
+func SubTestWriteWithinTransaction(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		outbox := kafkaoutbox.NewGormOutbox(di.DB)
+		e := di.DB.WithContext(ctx).Transaction(func(txDB *gorm.DB) error {
+			handled, e := outbox.Write(tx.NewGormTxContext(ctx, txDB), &kafka.RawMessage{
+				Topic:   "test-topic",
+				Key:     []byte("test-key"),
+				Headers: kafka.Headers{kafka.HeaderContentType: kafka.MIMETypeJson},
+				Payload: []byte(`{}`),
+			})
+			g.Expect(handled).To(BeTrue(), "message should be handled within transaction")
+			return e
+		})
+		g.Expect(e).To(Succeed(), "Write should not fail")
+
+		msgs := LoadOutboxMessages(ctx, g, di.DB)
+		g.Expect(msgs).To(HaveLen(1), "message should be written to outbox table")
+		g.Expect(msgs[0].Topic).To(Equal("test-topic"), "message should have correct topic")
+		g.Expect(msgs[0].Key).To(BeEquivalentTo("test-key"), "message should have correct key")
+		g.Expect(msgs[0].Headers).To(HaveKeyWithValue(kafka.HeaderContentType, kafka.MIMETypeJson), "message should have correct headers")
+		g.Expect(msgs[0].Payload).To(BeEquivalentTo(`{}`), "message should have correct payload")
+		g.Expect(msgs[0].SentAt).To(BeNil(), "message should be pending")
+	}
+}
+
+func SubTestWriteWithinRollbackTransaction(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		outbox := kafkaoutbox.NewGormOutbox(di.DB)
+		expected := errors.New("oops")
+		e := di.DB.WithContext(ctx).Transaction(func(txDB *gorm.DB) error {
+			handled, e := outbox.Write(tx.NewGormTxContext(ctx, txDB), &kafka.RawMessage{
+				Topic:   "test-topic",
+				Payload: []byte(`{}`),
+			})
+			g.Expect(e).To(Succeed(), "Write should not fail")
+			g.Expect(handled).To(BeTrue(), "message should be handled within transaction")
+			return expected
+		})
+		g.Expect(e).To(MatchError(expected), "transaction should be rolled back")
+		g.Expect(LoadOutboxMessages(ctx, g, di.DB)).To(BeEmpty(), "message should be rolled back with transaction")
+	}
+}
+
+func SubTestMigration(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		m := kafkaoutbox.NewMigration("1.0.0.1", di.DB)
+		g.Expect(m.Tags.Has(migration.TagPreUpgrade)).To(BeTrue(), "migration should be tagged as pre-upgrade")
+		g.Expect(m.RollbackFunc).ToNot(BeNil(), "migration should be reversible")
+
+		g.Expect(m.RollbackFunc(ctx)).To(Succeed(), "rollback should not fail")
+		g.Expect(di.DB.WithContext(ctx).Migrator().HasTable("kafka_outbox")).To(BeFalse(), "rollback should drop outbox table")
+		g.Expect(m.Func(ctx)).To(Succeed(), "migration should not fail")
+		g.Expect(di.DB.WithContext(ctx).Migrator().HasTable("kafka_outbox")).To(BeTrue(), "migration should create outbox table")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func LoadOutboxMessages(ctx context.Context, g *gomega.WithT, db *gorm.DB) []*kafkaoutbox.OutboxMessage {
+	var msgs []*kafkaoutbox.OutboxMessage
+	rs := db.WithContext(ctx).Order("id").Find(&msgs)
+	g.Expect(rs.Error).To(Succeed(), "loading outbox messages should not fail")
+	return msgs
+}
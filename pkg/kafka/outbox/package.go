@@ -0,0 +1,108 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaoutbox
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	"github.com/cisco-open/go-lanai/pkg/log"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"time"
+)
+
+var logger = log.New("Kafka.Outbox")
+
+var Module = &bootstrap.Module{
+	Name:       "kafka-outbox",
+	Precedence: bootstrap.KafkaPrecedence,
+	Options: []fx.Option{
+		fx.Provide(BindOutboxProperties, provideOutbox),
+		fx.Invoke(initialize),
+	},
+	Modules: []*bootstrap.Module{kafka.Module, dsync.Module},
+}
+
+func Use() {
+	bootstrap.Register(Module)
+}
+
+/**************************
+	Provider
+***************************/
+
+type outboxDI struct {
+	fx.In
+	DB *gorm.DB `optional:"true"`
+}
+
+func provideOutbox(di outboxDI) (*GormOutbox, error) {
+	if di.DB == nil {
+		return nil, fmt.Errorf("*gorm.DB is required for 'kafkaoutbox' package")
+	}
+	return NewGormOutbox(di.DB), nil
+}
+
+/**************************
+	Initialize
+***************************/
+
+type initDI struct {
+	fx.In
+	Lifecycle       fx.Lifecycle
+	Properties      OutboxProperties
+	KafkaProperties kafka.KafkaProperties
+	Binder          kafka.Binder
+	Outbox          *GormOutbox
+}
+
+func initialize(di initDI) {
+	if !di.Properties.Relay.Enabled {
+		return
+	}
+	relay := NewRelay(di.Outbox.db, syncProducerFactory(di.Binder, di.KafkaProperties), func(opt *RelayOption) {
+		opt.Interval = time.Duration(di.Properties.Relay.Interval)
+		opt.BatchSize = di.Properties.Relay.BatchSize
+		opt.Retention = time.Duration(di.Properties.Relay.Retention)
+	})
+	di.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.WithContext(ctx).Infof("Starting outbox relay with interval %v", di.Properties.Relay.Interval)
+			return relay.Start(ctx)
+		},
+		OnStop: relay.Stop,
+	})
+}
+
+// syncProducerFactory creates sarama.SyncProducer using the same configuration as kafka.Binder's client
+func syncProducerFactory(binder kafka.Binder, props kafka.KafkaProperties) SyncProducerFactory {
+	return func() (sarama.SyncProducer, error) {
+		sb, ok := binder.(kafka.SaramaBinder)
+		if !ok || sb.Client() == nil {
+			return nil, fmt.Errorf("outbox relay requires kafka.SaramaBinder")
+		}
+		cfg := *sb.Client().Config() // make a copy
+		cfg.Producer.Return.Successes = true
+		cfg.Producer.Return.Errors = true
+		cfg.Producer.RequiredAcks = sarama.WaitForAll
+		return sarama.NewSyncProducer(props.Brokers, &cfg)
+	}
+}
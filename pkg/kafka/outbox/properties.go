@@ -0,0 +1,63 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaoutbox
+
+import (
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	PropertiesPrefix = "kafka.outbox"
+)
+
+type OutboxProperties struct {
+	// Relay properties of the outbox Relay
+	Relay RelayProperties `json:"relay"`
+}
+
+type RelayProperties struct {
+	// Enabled whether the Relay is scheduled. Disable it if the outbox table is relayed by other services
+	Enabled bool `json:"enabled"`
+	// Interval see RelayOption.Interval
+	Interval utils.Duration `json:"interval"`
+	// BatchSize see RelayOption.BatchSize
+	BatchSize int `json:"batch-size"`
+	// Retention see RelayOption.Retention
+	Retention utils.Duration `json:"retention"`
+}
+
+func NewOutboxProperties() *OutboxProperties {
+	return &OutboxProperties{
+		Relay: RelayProperties{
+			Enabled:   true,
+			Interval:  utils.Duration(time.Second),
+			BatchSize: 100,
+			Retention: utils.Duration(24 * time.Hour),
+		},
+	}
+}
+
+func BindOutboxProperties(ctx *bootstrap.ApplicationContext) OutboxProperties {
+	props := NewOutboxProperties()
+	if err := ctx.Config().Bind(props, PropertiesPrefix); err != nil {
+		panic(errors.Wrap(err, "failed to bind OutboxProperties"))
+	}
+	return *props
+}
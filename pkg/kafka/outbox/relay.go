@@ -0,0 +1,182 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaoutbox
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/cisco-open/go-lanai/pkg/scheduler"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+const (
+	RelayTaskName = "kafka-outbox-relay"
+)
+
+// SyncProducerFactory creates sarama.SyncProducer used by Relay
+type SyncProducerFactory func() (sarama.SyncProducer, error)
+
+type RelayOptions func(opt *RelayOption)
+
+type RelayOption struct {
+	// Interval is the delay between two relay runs
+	Interval time.Duration
+	// BatchSize max number of messages loaded from the outbox table at a time
+	BatchSize int
+	// Retention how long sent messages are kept in the outbox table. Sent messages are kept forever if not positive.
+	Retention time.Duration
+}
+
+// Relay publishes pending messages in the outbox table to Kafka, in the order they were written.
+// Messages are marked as sent once they are acknowledged by brokers, so a message may be published more than once
+// if the relay crashes in between (at-least-once delivery).
+type Relay struct {
+	mtx             sync.Mutex
+	db              *gorm.DB
+	producerFactory SyncProducerFactory
+	producer        sarama.SyncProducer
+	canceller       scheduler.TaskCanceller
+	option          RelayOption
+}
+
+func NewRelay(db *gorm.DB, producerFactory SyncProducerFactory, opts ...RelayOptions) *Relay {
+	opt := RelayOption{
+		Interval:  time.Second,
+		BatchSize: 100,
+		Retention: 24 * time.Hour,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	if opt.BatchSize < 1 {
+		opt.BatchSize = 1
+	}
+	return &Relay{
+		db:              db,
+		producerFactory: producerFactory,
+		option:          opt,
+	}
+}
+
+// Start schedules the relay with fixed delay. The relay only runs on the leader instance (see scheduler.LeaderOnly)
+func (r *Relay) Start(_ context.Context) (err error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.canceller != nil {
+		return nil
+	}
+	r.canceller, err = scheduler.Repeat(r.Run,
+		scheduler.Name(RelayTaskName),
+		scheduler.WithDelay(r.option.Interval),
+		scheduler.LeaderOnly(),
+	)
+	return
+}
+
+// Stop cancels the scheduled relay and release resources
+func (r *Relay) Stop(_ context.Context) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.canceller != nil {
+		r.canceller.Cancel()
+		r.canceller = nil
+	}
+	if r.producer == nil {
+		return nil
+	}
+	defer func() { r.producer = nil }()
+	return r.producer.Close()
+}
+
+// Run publishes all pending messages and purges expired sent messages.
+// Publishing stops at the first failed message, so the order is preserved.
+func (r *Relay) Run(ctx context.Context) error {
+	producer, e := r.syncProducer()
+	if e != nil {
+		return e
+	}
+	for {
+		var msgs []*OutboxMessage
+		if e := r.db.WithContext(ctx).
+			Where("sent_at IS NULL").
+			Order("id").
+			Limit(r.option.BatchSize).
+			Find(&msgs).Error; e != nil {
+			return e
+		}
+		for _, msg := range msgs {
+			if e := ctx.Err(); e != nil {
+				return e
+			}
+			if e := r.publish(ctx, producer, msg); e != nil {
+				return e
+			}
+		}
+		if len(msgs) < r.option.BatchSize {
+			break
+		}
+	}
+	return r.purge(ctx)
+}
+
+func (r *Relay) publish(ctx context.Context, producer sarama.SyncProducer, msg *OutboxMessage) error {
+	pm := &sarama.ProducerMessage{
+		Topic:   msg.Topic,
+		Headers: make([]sarama.RecordHeader, 0, len(msg.Headers)),
+		Value:   sarama.ByteEncoder(msg.Payload),
+	}
+	if len(msg.Key) != 0 {
+		pm.Key = sarama.ByteEncoder(msg.Key)
+	}
+	for k, v := range msg.Headers {
+		pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+	}
+	if _, _, e := producer.SendMessage(pm); e != nil {
+		return fmt.Errorf("unable to publish outbox message [%d] to [%s]: %w", msg.ID, msg.Topic, e)
+	}
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&OutboxMessage{}).
+		Where("id = ?", msg.ID).
+		Update("sent_at", &now).Error
+}
+
+func (r *Relay) purge(ctx context.Context) error {
+	if r.option.Retention <= 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Where("sent_at < ?", time.Now().Add(-r.option.Retention)).
+		Delete(&OutboxMessage{}).Error
+}
+
+func (r *Relay) syncProducer() (sarama.SyncProducer, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.producer != nil {
+		return r.producer, nil
+	}
+	p, e := r.producerFactory()
+	if e != nil {
+		return nil, e
+	}
+	r.producer = p
+	return p, nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaoutbox_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/cisco-open/go-lanai/pkg/data/types/pqx"
+	kafkaoutbox "github.com/cisco-open/go-lanai/pkg/kafka/outbox"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+// TestProducer wraps mocks.SyncProducer and records sent messages
+type TestProducer struct {
+	*mocks.SyncProducer
+	Sent []*sarama.ProducerMessage
+}
+
+func NewTestProducer(t *testing.T) *TestProducer {
+	return &TestProducer{
+		SyncProducer: mocks.NewSyncProducer(t, nil),
+	}
+}
+
+// ExpectSend expects given number of messages to be sent, followed by a failure if "fail" is true
+func (p *TestProducer) ExpectSend(n int, fail bool) *TestProducer {
+	record := func(msg *sarama.ProducerMessage) error {
+		p.Sent = append(p.Sent, msg)
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		p.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(record)
+	}
+	if fail {
+		p.ExpectSendMessageAndFail(errors.New("oops"))
+	}
+	return p
+}
+
+func (p *TestProducer) Factory() kafkaoutbox.SyncProducerFactory {
+	return func() (sarama.SyncProducer, error) {
+		return p, nil
+	}
+}
+
+/*************************
+	Test
+ *************************/
+
+func TestRelay(t *testing.T) {
+	di := &dbtest.DI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupTestPrepareOutboxTable(di)),
+		test.GomegaSubTest(SubTestRelayPublish(di), "TestPublish"),
+		test.GomegaSubTest(SubTestRelayPublishInBatches(di), "TestPublishInBatches"),
+		test.GomegaSubTest(SubTestRelayStopAtFailure(di), "TestStopAtFailure"),
+		test.GomegaSubTest(SubTestRelayPurge(di), "TestPurge"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestRelayPublish(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		pending := PrepareOutboxMessages(ctx, g, di.DB, 3, nil)
+		producer := NewTestProducer(t).ExpectSend(3, false)
+		relay := kafkaoutbox.NewRelay(di.DB, producer.Factory())
+		defer func() { g.Expect(relay.Stop(ctx)).To(Succeed(), "stopping relay should not fail") }()
+
+		g.Expect(relay.Run(ctx)).To(Succeed(), "relay should not fail")
+		AssertPublished(g, producer.Sent, pending...)
+		for _, msg := range LoadOutboxMessages(ctx, g, di.DB) {
+			g.Expect(msg.SentAt).ToNot(BeNil(), "published message [%d] should be marked as sent", msg.ID)
+		}
+	}
+}
+
+func SubTestRelayPublishInBatches(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		pending := PrepareOutboxMessages(ctx, g, di.DB, 3, nil)
+		producer := NewTestProducer(t).ExpectSend(3, false)
+		relay := kafkaoutbox.NewRelay(di.DB, producer.Factory(), func(opt *kafkaoutbox.RelayOption) {
+			opt.BatchSize = 2
+		})
+		defer func() { g.Expect(relay.Stop(ctx)).To(Succeed(), "stopping relay should not fail") }()
+
+		g.Expect(relay.Run(ctx)).To(Succeed(), "relay should not fail")
+		AssertPublished(g, producer.Sent, pending...)
+		for _, msg := range LoadOutboxMessages(ctx, g, di.DB) {
+			g.Expect(msg.SentAt).ToNot(BeNil(), "published message [%d] should be marked as sent", msg.ID)
+		}
+	}
+}
+
+func SubTestRelayStopAtFailure(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		pending := PrepareOutboxMessages(ctx, g, di.DB, 3, nil)
+
+		// 2nd message fails
+		producer := NewTestProducer(t).ExpectSend(1, true)
+		relay := kafkaoutbox.NewRelay(di.DB, producer.Factory())
+		g.Expect(relay.Run(ctx)).To(HaveOccurred(), "relay should fail")
+		g.Expect(relay.Stop(ctx)).To(Succeed(), "stopping relay should not fail")
+		AssertPublished(g, producer.Sent, pending[0])
+		msgs := LoadOutboxMessages(ctx, g, di.DB)
+		g.Expect(msgs).To(HaveLen(3), "outbox should have correct messages")
+		g.Expect(msgs[0].SentAt).ToNot(BeNil(), "published message should be marked as sent")
+		g.Expect(msgs[1].SentAt).To(BeNil(), "failed message should remain pending")
+		g.Expect(msgs[2].SentAt).To(BeNil(), "message after the failed one should remain pending")
+
+		// next run should resume from the failed message
+		producer = NewTestProducer(t).ExpectSend(2, false)
+		relay = kafkaoutbox.NewRelay(di.DB, producer.Factory())
+		g.Expect(relay.Run(ctx)).To(Succeed(), "relay should not fail")
+		g.Expect(relay.Stop(ctx)).To(Succeed(), "stopping relay should not fail")
+		AssertPublished(g, producer.Sent, pending[1:]...)
+		for _, msg := range LoadOutboxMessages(ctx, g, di.DB) {
+			g.Expect(msg.SentAt).ToNot(BeNil(), "published message [%d] should be marked as sent", msg.ID)
+		}
+	}
+}
+
+func SubTestRelayPurge(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		expired := time.Now().Add(-48 * time.Hour)
+		recent := time.Now().Add(-time.Hour)
+		PrepareOutboxMessages(ctx, g, di.DB, 1, &expired)
+		kept := PrepareOutboxMessages(ctx, g, di.DB, 1, &recent)
+		pending := PrepareOutboxMessages(ctx, g, di.DB, 1, nil)
+
+		producer := NewTestProducer(t).ExpectSend(1, false)
+		relay := kafkaoutbox.NewRelay(di.DB, producer.Factory(), func(opt *kafkaoutbox.RelayOption) {
+			opt.Retention = 24 * time.Hour
+		})
+		defer func() { g.Expect(relay.Stop(ctx)).To(Succeed(), "stopping relay should not fail") }()
+
+		g.Expect(relay.Run(ctx)).To(Succeed(), "relay should not fail")
+		AssertPublished(g, producer.Sent, pending...)
+		msgs := LoadOutboxMessages(ctx, g, di.DB)
+		g.Expect(msgs).To(HaveLen(2), "expired message should be purged")
+		g.Expect(msgs[0].ID).To(Equal(kept[0].ID), "recently sent message should be kept")
+		g.Expect(msgs[1].ID).To(Equal(pending[0].ID), "newly sent message should be kept")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+// PrepareOutboxMessages inserts given number of messages with given SentAt into the outbox table
+func PrepareOutboxMessages(ctx context.Context, g *gomega.WithT, db *gorm.DB, n int, sentAt *time.Time) []*kafkaoutbox.OutboxMessage {
+	msgs := make([]*kafkaoutbox.OutboxMessage, n)
+	for i := range msgs {
+		msgs[i] = &kafkaoutbox.OutboxMessage{
+			Topic:   fmt.Sprintf("test-topic-%d", i),
+			Key:     []byte(fmt.Sprintf("test-key-%d", i)),
+			Headers: pqx.JsonbStringMap{"test-header": fmt.Sprintf("test-value-%d", i)},
+			Payload: []byte(fmt.Sprintf(`{"value":%d}`, i)),
+			SentAt:  sentAt,
+		}
+	}
+	rs := db.WithContext(ctx).Create(&msgs)
+	g.Expect(rs.Error).To(Succeed(), "inserting outbox messages should not fail")
+	return msgs
+}
+
+func AssertPublished(g *gomega.WithT, sent []*sarama.ProducerMessage, expected ...*kafkaoutbox.OutboxMessage) {
+	g.Expect(sent).To(HaveLen(len(expected)), "correct number of messages should be published")
+	for i := range expected {
+		g.Expect(sent[i].Topic).To(Equal(expected[i].Topic), "message should be published in order to correct topic")
+		key, _ := sent[i].Key.Encode()
+		g.Expect(key).To(BeEquivalentTo(expected[i].Key), "published message should have correct key")
+		payload, _ := sent[i].Value.Encode()
+		g.Expect(payload).To(BeEquivalentTo(expected[i].Payload), "published message should have correct payload")
+		g.Expect(sent[i].Headers).To(HaveLen(len(expected[i].Headers)), "published message should have correct headers")
+		for _, h := range sent[i].Headers {
+			g.Expect(expected[i].Headers).To(HaveKeyWithValue(string(h.Key), string(h.Value)), "published message should have correct headers")
+		}
+	}
+}
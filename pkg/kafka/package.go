@@ -53,6 +53,7 @@ type binderDI struct {
 	ProducerInterceptors []ProducerMessageInterceptor  `group:"kafka"`
 	ConsumerInterceptors []ConsumerDispatchInterceptor `group:"kafka"`
 	HandlerInterceptors  []ConsumerHandlerInterceptor  `group:"kafka"`
+	Decoders             []Decoder                     `group:"kafka"`
 	TLSCertsManager      certs.Manager                 `optional:"true"`
 }
 
@@ -64,6 +65,7 @@ func ProvideKafkaBinder(di binderDI) Binder {
 			ProducerInterceptors: append(opt.ProducerInterceptors, filterZeroValues(di.ProducerInterceptors)...),
 			ConsumerInterceptors: append(opt.ConsumerInterceptors, filterZeroValues(di.ConsumerInterceptors)...),
 			HandlerInterceptors:  append(opt.HandlerInterceptors, filterZeroValues(di.HandlerInterceptors)...),
+			Decoders:             append(opt.Decoders, filterZeroValues(di.Decoders)...),
 			TLSCertsManager:      di.TLSCertsManager,
 		}
 	})
@@ -96,6 +98,9 @@ func initialize(di initDI) {
 	}
 
 	di.HealthRegistrar.MustRegister(NewHealthIndicator(di.Binder))
+	if _, ok := di.Binder.(AdminBinder); ok && di.Properties.Health.ConsumerLag.Enabled {
+		di.HealthRegistrar.MustRegister(NewConsumerLagHealthIndicator(di.Binder, di.Properties.Health.ConsumerLag))
+	}
 }
 
 func filterZeroValues[T any](values []T) []T {
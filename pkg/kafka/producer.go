@@ -33,6 +33,7 @@ type saramaProducer struct {
 	keyEncoder   Encoder
 	msgLogger    MessageLogger
 	interceptors []ProducerMessageInterceptor
+	writer       MessageWriter
 	syncProducer sarama.SyncProducer
 	readyCh      chan struct{}
 	closed       bool
@@ -54,6 +55,7 @@ func newSaramaProducer(topic string, addrs []string, config *bindingConfig) (*sa
 		keyEncoder:   config.producer.keyEncoder,
 		msgLogger:    config.msgLogger,
 		interceptors: config.producer.interceptors,
+		writer:       config.producer.writer,
 		readyCh:      make(chan struct{}),
 	}
 	return p, nil
@@ -69,7 +71,14 @@ func (p *saramaProducer) Send(ctx context.Context, message interface{}, options
 	syncProducer = p.syncProducer
 	p.RUnlock()
 
-	if syncProducer == nil {
+	// messages sent within a transaction are sent via the transactional producer
+	if tx, ok := TransactionFromContext(ctx); ok {
+		if stx, ok := tx.(*saramaTransaction); ok {
+			syncProducer = stx.producer
+		}
+	}
+
+	if syncProducer == nil && p.writer == nil {
 		return NewKafkaError(ErrorSubTypeCodeIllegalProducerUsage, fmt.Sprintf(`producer for topic "%s" is not started yet`, p.topic))
 	}
 
@@ -100,6 +109,16 @@ func (p *saramaProducer) Send(ctx context.Context, message interface{}, options
 	}
 	msgCtx.RawMessage = saramaMessage
 
+	// try message writer
+	if p.writer != nil {
+		if handled, e := p.write(msgCtx, saramaMessage); handled || e != nil {
+			return p.finalizeSend(msgCtx, -1, -1, e)
+		}
+		if syncProducer == nil {
+			return NewKafkaError(ErrorSubTypeCodeIllegalProducerUsage, fmt.Sprintf(`producer for topic "%s" is not started yet`, p.topic))
+		}
+	}
+
 	// do send
 	switch msgCtx.Mode {
 	case modeSync:
@@ -178,6 +197,23 @@ func (p *saramaProducer) prepare(ctx context.Context, v interface{}) *MessageCon
 	return &msgCtx
 }
 
+// write encodes given message and pass it to MessageWriter
+func (p *saramaProducer) write(msgCtx *MessageContext, msg *sarama.ProducerMessage) (handled bool, err error) {
+	raw := RawMessage{
+		Topic:   msg.Topic,
+		Headers: msgCtx.Message.Headers,
+	}
+	if msg.Key != nil {
+		if raw.Key, err = msg.Key.Encode(); err != nil {
+			return false, ErrorSubTypeEncoding.WithCause(err, "unable to encode message key: %v", err)
+		}
+	}
+	if raw.Payload, err = msg.Value.Encode(); err != nil {
+		return false, ErrorSubTypeEncoding.WithCause(err, "unable to encode message payload: %v", err)
+	}
+	return p.writer.Write(msgCtx.Context, &raw)
+}
+
 func (p *saramaProducer) finalizeSend(msgCtx *MessageContext, partition int32, offset int64, err error) error {
 
 	p.msgLogger.LogSentMessage(msgCtx.Context, msgCtx.RawMessage)
@@ -55,6 +55,7 @@ func TestProducer(t *testing.T) {
 		test.GomegaSubTest(SubTestSendWithLocalAck(&di), "TestSendWithLocalAck"),
 		test.GomegaSubTest(SubTestSendWithoutAck(&di), "TestSendWithoutAck"),
 		test.GomegaSubTest(SubTestSendWithAllAck(&di), "TestSendWithAllAck"),
+		test.GomegaSubTest(SubTestSendWithMessageWriter(&di), "TestSendWithMessageWriter"),
 	)
 }
 
@@ -135,6 +136,39 @@ func SubTestSendWithAllAck(di *TestProducerDI) test.GomegaSubTestFunc {
 	}
 }
 
+func SubTestSendWithMessageWriter(di *TestProducerDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test.producer-writer`
+		var e error
+		writer := &TestMessageWriter{Handle: true}
+		producer := TryBindTestProducer(ctx, t, g, &di.TestBinderDI, topic, kafka.WithMessageWriter(writer))
+		msg := kafka.Message{
+			Headers: kafka.Headers{"test-header": "test-header-value"},
+			Payload: map[string]interface{}{
+				"test-body-string-field": "value",
+			},
+		}
+
+		// handled by writer
+		e = producer.Send(ctx, &msg, kafka.WithKey("test-key"))
+		g.Expect(e).To(Succeed(), "producer Send(&msg) should not fail")
+		g.Expect(writer.Written).To(HaveLen(1), "message should be written")
+		written := writer.Written[0]
+		g.Expect(written.Topic).To(Equal(topic), "written message should have correct topic")
+		g.Expect(written.Key).To(Equal([]byte("test-key")), "written message should have encoded key")
+		g.Expect(written.Payload).To(MatchJSON(`{"test-body-string-field":"value"}`), "written message should have encoded payload")
+		g.Expect(written.Headers).To(HaveKeyWithValue("test-header", "test-header-value"), "written message should have original headers")
+		g.Expect(written.Headers).To(HaveKeyWithValue(kafka.HeaderContentType, kafka.MIMETypeJson), "written message should have content type")
+
+		// not handled by writer
+		writer.Handle = false
+		testdata.MockProduce(ctx, topic, false)
+		e = producer.Send(ctx, &msg)
+		g.Expect(e).To(Succeed(), "producer Send(&msg) should not fail")
+		g.Expect(writer.Written).To(HaveLen(2), "message should be passed to writer")
+	}
+}
+
 /*************************
 	Helpers
  *************************/
@@ -177,3 +211,12 @@ func (enc *TestEncoder) Encode(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
 }
 
+type TestMessageWriter struct {
+	Handle  bool
+	Written []*kafka.RawMessage
+}
+
+func (w *TestMessageWriter) Write(_ context.Context, msg *kafka.RawMessage) (bool, error) {
+	w.Written = append(w.Written, msg)
+	return w.Handle, nil
+}
@@ -34,11 +34,13 @@ const (
 
 //goland:noinspection GoNameStartsWithPackageName
 type KafkaProperties struct {
-	Brokers  utils.CommaSeparatedSlice `json:"brokers"`
-	Net      Net                       `json:"net"`
-	Metadata Metadata                  `json:"metadata"`
-	Binder   BinderProperties          `json:"binder"`
-	ClientId string                    `json:"client-id"`
+	Brokers     utils.CommaSeparatedSlice `json:"brokers"`
+	Net         Net                       `json:"net"`
+	Metadata    Metadata                  `json:"metadata"`
+	Binder      BinderProperties          `json:"binder"`
+	ClientId    string                    `json:"client-id"`
+	Transaction TransactionProperties     `json:"transaction"`
+	Health      HealthProperties          `json:"health"`
 }
 
 type Net struct {
@@ -75,6 +77,32 @@ type BinderProperties struct {
 	WatchdogHeartbeat      utils.Duration `json:"watchdog-heartbeat"`
 }
 
+type TransactionProperties struct {
+	// Enabled whether Binder.Transaction is supported
+	Enabled bool `json:"enabled"`
+	// IDPrefix prefix of transactional IDs. Each transactional producer uses "<prefix><index>" as transactional ID.
+	// Each application instance should have a distinct prefix. If not set, a random prefix is generated.
+	IDPrefix string `json:"id-prefix"`
+	// Timeout how long a transaction can remain unresolved before the broker aborts it
+	Timeout utils.Duration `json:"timeout"`
+	// PoolSize max number of concurrent transactions
+	PoolSize int `json:"pool-size"`
+}
+
+type HealthProperties struct {
+	ConsumerLag ConsumerLagHealthProperties `json:"consumer-lag"`
+}
+
+// ConsumerLagHealthProperties configures health indicator of consumer lag. Thresholds are max lag of any partition.
+type ConsumerLagHealthProperties struct {
+	// Enabled whether the consumer lag health indicator is registered
+	Enabled bool `json:"enabled"`
+	// DegradedThreshold the status is DEGRADED when lag is greater or equal to this value. Disabled if not positive
+	DegradedThreshold int64 `json:"degraded-threshold"`
+	// DownThreshold the status is DOWN when lag is greater or equal to this value. Disabled if not positive
+	DownThreshold int64 `json:"down-threshold"`
+}
+
 const (
 	AckModeModeAll   AckMode = "all"
 	AckModeModeLocal AckMode = "local"
@@ -108,6 +136,7 @@ type ProducerProperties struct {
 	AckTimeout   *utils.Duration        `json:"ack-timeout"`
 	MaxRetry     *int                   `json:"max-retry"`
 	Backoff      *utils.Duration        `json:"backoff-interval"`
+	Idempotent   *bool                  `json:"idempotent"`
 	Provisioning ProvisioningProperties `json:"provisioning"`
 }
 
@@ -115,6 +144,11 @@ type ConsumerProperties struct {
 	LogLevel *log.LoggingLevel       `json:"log-level"`
 	Backoff  *utils.Duration         `json:"backoff-interval"`
 	Group    ConsumerGroupProperties `json:"group"`
+	Retry    ConsumerRetryProperties `json:"retry"`
+
+	// ExactlyOnce whether messages are processed within transactions. Requires "kafka.transaction.enabled".
+	// Only applicable to GroupConsumer
+	ExactlyOnce *bool `json:"exactly-once"`
 }
 
 type ProvisioningProperties struct {
@@ -141,6 +175,28 @@ type ConsumerGroupProperties struct {
 	Backoff     *utils.Duration `json:"backoff-interval"`
 }
 
+// ConsumerRetryProperties configures RetryPolicy of the consumer binding
+type ConsumerRetryProperties struct {
+	// MaxAttempts max number of attempts of a message, including the first attempt.
+	// Delayed retry topics "<topic>.retry.N" are used if it's greater than 1
+	MaxAttempts *int `json:"max-attempts"`
+
+	// Backoff how long the message is delayed before the first retry
+	Backoff *utils.Duration `json:"backoff-interval"`
+
+	// Multiplier is applied to Backoff for each subsequent retry
+	Multiplier *float64 `json:"backoff-multiplier"`
+
+	// MaxBackoff is the upper limit of the backoff
+	MaxBackoff *utils.Duration `json:"max-backoff-interval"`
+
+	// NonRetryableErrors type names of errors that should not be retried, e.g. "*json.SyntaxError"
+	NonRetryableErrors utils.CommaSeparatedSlice `json:"non-retryable-errors"`
+
+	// DeadLetter whether failed messages are sent to dead-letter topic "<topic>.DLT"
+	DeadLetter *bool `json:"dead-letter"`
+}
+
 func BindKafkaProperties(ctx *bootstrap.ApplicationContext) KafkaProperties {
 	props := KafkaProperties{
 		Net: Net{
@@ -161,6 +217,15 @@ func BindKafkaProperties(ctx *bootstrap.ApplicationContext) KafkaProperties {
 			HeartbeatCurveFactor:   0.5,
 			HeartbeatCurveMidpoint: 10, // recommend > 5
 		},
+		Transaction: TransactionProperties{
+			Timeout:  utils.Duration(time.Minute),
+			PoolSize: 5,
+		},
+		Health: HealthProperties{
+			ConsumerLag: ConsumerLagHealthProperties{
+				DegradedThreshold: 1000,
+			},
+		},
 		ClientId: ctx.Name(),
 	}
 	if err := ctx.Config().Bind(&props, ConfigKafkaPrefix); err != nil {
@@ -0,0 +1,325 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/cisco-open/go-lanai/pkg/utils/order"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Headers added to messages sent to retry topics and dead-letter topic.
+// Original headers of the failed message are always preserved
+const (
+	HeaderRetryAttempts         = "retry_topic-attempts"
+	HeaderRetryBackoffTimestamp = "retry_topic-backoff-timestamp"
+	HeaderRetryTarget           = "retry_topic-target"
+	HeaderOriginalTopic         = "kafka_dlt-original-topic"
+	HeaderOriginalPartition     = "kafka_dlt-original-partition"
+	HeaderOriginalOffset        = "kafka_dlt-original-offset"
+	HeaderOriginalTimestamp     = "kafka_dlt-original-timestamp"
+	HeaderExceptionClass        = "kafka_dlt-exception-fqcn"
+	HeaderExceptionMessage      = "kafka_dlt-exception-message"
+	HeaderExceptionStacktrace   = "kafka_dlt-exception-stacktrace"
+)
+
+const (
+	retryTopicInfix       = ".retry."
+	deadLetterTopicSuffix = ".DLT"
+)
+
+// errSkipDispatch is returned by ConsumerDispatchInterceptor when the message should be silently ignored
+var errSkipDispatch = errors.New("message dispatch skipped")
+
+// RetryTopicName returns the name of the N-th delayed retry topic of given topic, e.g. "my-topic.retry.1"
+func RetryTopicName(topic string, n int) string {
+	return topic + retryTopicInfix + strconv.Itoa(n)
+}
+
+// DeadLetterTopicName returns the name of the dead-letter topic of given topic, e.g. "my-topic.DLT"
+func DeadLetterTopicName(topic string) string {
+	return topic + deadLetterTopicSuffix
+}
+
+// RetryPolicy controls how failed messages are retried via delayed retry topics and dead-letter topic.
+// The message is attempted at most MaxAttempts times: once on the original topic and once on each of the
+// "<topic>.retry.N" topics, where N is from 1 to MaxAttempts-1.
+type RetryPolicy struct {
+	// MaxAttempts max number of attempts of a message, including the first attempt.
+	// Retry topics are not used if it's less than 2
+	MaxAttempts int
+	// Backoff how long the message is delayed before the first retry
+	Backoff time.Duration
+	// Multiplier is applied to Backoff for each subsequent retry. Fixed backoff is used if it's less than 1
+	Multiplier float64
+	// MaxBackoff is the upper limit of the backoff. No limit if it's not positive
+	MaxBackoff time.Duration
+	// NonRetryable errors are sent to dead-letter topic directly. Errors are matched using errors.Is
+	NonRetryable []error
+	// NonRetryableTypes are type names of non-retryable errors, e.g. "*json.SyntaxError".
+	// Any error in the error chain with matching type name is non-retryable
+	NonRetryableTypes []string
+	// DeadLetter whether failed messages are sent to "<topic>.DLT" when retries are exhausted or error is non-retryable.
+	// If disabled, the error is returned to the GroupConsumer or Subscriber after retries are exhausted.
+	DeadLetter bool
+}
+
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  1,
+		Backoff:      time.Second,
+		Multiplier:   1,
+		NonRetryable: []error{ErrorSubTypeDecoding, ErrorSubTypeIllegalConsumerUsage},
+	}
+}
+
+// Enabled returns true if either retry topics or dead-letter topic is used
+func (p RetryPolicy) Enabled() bool {
+	return p.MaxAttempts > 1 || p.DeadLetter
+}
+
+// BackoffOf returns the delay before the N-th retry, N starts from 1
+func (p RetryPolicy) BackoffOf(n int) time.Duration {
+	backoff := float64(p.Backoff)
+	if p.Multiplier > 1 && n > 1 {
+		backoff = backoff * math.Pow(p.Multiplier, float64(n-1))
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// IsRetryable returns false if given error matches any configured non-retryable errors or types
+func (p RetryPolicy) IsRetryable(err error) bool {
+	for _, target := range p.NonRetryable {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+	if len(p.NonRetryableTypes) == 0 {
+		return true
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		typeName := reflect.TypeOf(e).String()
+		for _, t := range p.NonRetryableTypes {
+			if t == typeName {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// RetryProducerFunc returns a Producer of given topic. It's used by RetryInterceptor to send messages to
+// retry topics and dead-letter topic
+type RetryProducerFunc func(topic string) (Producer, error)
+
+// RetryInterceptor implements ConsumerDispatchInterceptor and ConsumerDispatchFinalizer.
+// When a message fails, it's re-published to next retry topic or dead-letter topic based on the RetryPolicy,
+// and the error is considered handled. The same RetryInterceptor is used by the bindings of the original topic and
+// all its retry topics. Messages from retry topics are held until their backoff elapsed before dispatched to handlers.
+//
+// When Target is set, retried messages are only handled by the interceptor with same Target. It's used by Subscriber,
+// where all instances receive the same messages from retry topics.
+type RetryInterceptor struct {
+	Policy       RetryPolicy
+	Target       string
+	ProducerFunc RetryProducerFunc
+}
+
+func NewRetryInterceptor(policy RetryPolicy, producerFunc RetryProducerFunc) *RetryInterceptor {
+	return &RetryInterceptor{
+		Policy:       policy,
+		ProducerFunc: producerFunc,
+	}
+}
+
+// Order returns the lowest precedence, so the retry finalizer sees the error after all other finalizers
+func (i *RetryInterceptor) Order() int {
+	return order.Lowest
+}
+
+// Intercept implements ConsumerDispatchInterceptor. It waits until message's backoff elapsed.
+// For MessageBatch, it waits until backoff of all messages elapsed.
+func (i *RetryInterceptor) Intercept(msgCtx *MessageContext) (*MessageContext, error) {
+	var ts int64
+	switch batch := msgCtx.RawMessage.(type) {
+	case MessageBatch:
+		filtered := make(MessageBatch, 0, len(batch))
+		for _, item := range batch {
+			if target, ok := item.Message.Headers[HeaderRetryTarget]; ok && target != i.Target {
+				continue
+			}
+			filtered = append(filtered, item)
+			if v := i.backoffTimestamp(item); v > ts {
+				ts = v
+			}
+		}
+		switch {
+		case len(filtered) == 0:
+			return msgCtx, errSkipDispatch
+		case len(filtered) != len(batch):
+			msgCtx = NewBatchMessageContext(msgCtx.Context, filtered)
+		}
+	default:
+		if target, ok := msgCtx.Message.Headers[HeaderRetryTarget]; ok && target != i.Target {
+			return msgCtx, errSkipDispatch
+		}
+		ts = i.backoffTimestamp(msgCtx)
+	}
+	if delay := time.Until(time.UnixMilli(ts)); ts > 0 && delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-msgCtx.Context.Done():
+			return msgCtx, msgCtx.Context.Err()
+		}
+	}
+	return msgCtx, nil
+}
+
+// Finalize implements ConsumerDispatchFinalizer. It re-publishes failed message to next retry topic or dead-letter topic.
+// For MessageBatch, each message of the batch is re-published.
+// The error is returned as-is if any message cannot be re-published.
+func (i *RetryInterceptor) Finalize(msgCtx *MessageContext, err error) (*MessageContext, error) {
+	if err == nil || errors.Is(err, errSkipDispatch) {
+		return msgCtx, err
+	}
+
+	batch, ok := msgCtx.RawMessage.(MessageBatch)
+	if !ok {
+		return msgCtx, i.retry(msgCtx, err)
+	}
+	var retErr error
+	for _, item := range batch {
+		itemCtx := *item
+		itemCtx.Context = msgCtx.Context
+		if e := i.retry(&itemCtx, err); e != nil {
+			retErr = e
+		}
+	}
+	return msgCtx, retErr
+}
+
+// retry re-publishes the failed message to next retry topic or dead-letter topic.
+// Returns nil if the message is re-published, otherwise returns given error as-is
+func (i *RetryInterceptor) retry(msgCtx *MessageContext, err error) error {
+	headers := msgCtx.Message.Headers
+	attempts, e := strconv.Atoi(headers[HeaderRetryAttempts])
+	if e != nil || attempts < 1 {
+		attempts = 1
+	}
+	topic := msgCtx.Topic
+	if orig, ok := headers[HeaderOriginalTopic]; ok {
+		topic = orig
+	}
+
+	var dest string
+	out := i.failedMessage(msgCtx, topic, err)
+	switch {
+	case attempts < i.Policy.MaxAttempts && i.Policy.IsRetryable(err):
+		dest = RetryTopicName(topic, attempts)
+		out.Headers[HeaderRetryAttempts] = strconv.Itoa(attempts + 1)
+		out.Headers[HeaderRetryBackoffTimestamp] = strconv.FormatInt(time.Now().Add(i.Policy.BackoffOf(attempts)).UnixMilli(), 10)
+		if len(i.Target) != 0 {
+			out.Headers[HeaderRetryTarget] = i.Target
+		}
+	case i.Policy.DeadLetter:
+		dest = DeadLetterTopicName(topic)
+		out.Headers[HeaderRetryAttempts] = strconv.Itoa(attempts)
+	default:
+		return err
+	}
+
+	if e := i.send(msgCtx, dest, out); e != nil {
+		logger.WithContext(msgCtx.Context).Warnf("failed to send message to [%s]: %v", dest, e)
+		return err
+	}
+	logger.WithContext(msgCtx.Context).Debugf("failed message (attempt %d) is sent to [%s]: %v", attempts, dest, err)
+	return nil
+}
+
+func (i *RetryInterceptor) backoffTimestamp(msgCtx *MessageContext) int64 {
+	ts, e := strconv.ParseInt(msgCtx.Message.Headers[HeaderRetryBackoffTimestamp], 10, 64)
+	if e != nil {
+		return 0
+	}
+	return ts
+}
+
+// failedMessage copy the failed message with original headers, and add error information to headers
+func (i *RetryInterceptor) failedMessage(msgCtx *MessageContext, topic string, err error) *Message {
+	headers := Headers{}
+	for k, v := range msgCtx.Message.Headers {
+		headers[k] = v
+	}
+	delete(headers, HeaderRetryBackoffTimestamp)
+	delete(headers, HeaderRetryTarget)
+	if _, ok := headers[HeaderOriginalTopic]; !ok {
+		headers[HeaderOriginalTopic] = topic
+		if raw, ok := msgCtx.RawMessage.(*sarama.ConsumerMessage); ok {
+			headers[HeaderOriginalPartition] = strconv.Itoa(int(raw.Partition))
+			headers[HeaderOriginalOffset] = strconv.FormatInt(raw.Offset, 10)
+			headers[HeaderOriginalTimestamp] = strconv.FormatInt(raw.Timestamp.UnixMilli(), 10)
+		}
+	}
+	headers[HeaderExceptionClass] = fmt.Sprintf("%T", err)
+	headers[HeaderExceptionMessage] = err.Error()
+	headers[HeaderExceptionStacktrace] = fmt.Sprintf("%+v", err)
+	return &Message{
+		Headers: headers,
+		Payload: msgCtx.Message.Payload,
+	}
+}
+
+func (i *RetryInterceptor) send(msgCtx *MessageContext, topic string, msg *Message) error {
+	if i.ProducerFunc == nil {
+		return fmt.Errorf("producer is not available")
+	}
+	p, e := i.ProducerFunc(topic)
+	if e != nil {
+		return e
+	}
+	opts := []MessageOptions{WithEncoder(passthroughEncoder{mimeType: msg.Headers[HeaderContentType]})}
+	if raw, ok := msgCtx.RawMessage.(*sarama.ConsumerMessage); ok && len(raw.Key) != 0 {
+		opts = append(opts, WithKey(raw.Key))
+	}
+	// Note: the message is re-published regardless whether the consumer's context is cancelled
+	return p.Send(context.WithoutCancel(msgCtx.Context), msg, opts...)
+}
+
+// passthroughEncoder send []byte payload as-is and keep the original MIME type
+type passthroughEncoder struct {
+	mimeType string
+}
+
+func (enc passthroughEncoder) MIMEType() string {
+	if len(enc.mimeType) == 0 {
+		return MIMETypeBinary
+	}
+	return enc.mimeType
+}
+
+func (enc passthroughEncoder) Encode(v interface{}) ([]byte, error) {
+	return binaryEncoder{}.Encode(v)
+}
@@ -0,0 +1,233 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_test
+
+import (
+    "context"
+    "errors"
+    "github.com/cisco-open/go-lanai/pkg/kafka"
+    "github.com/cisco-open/go-lanai/test"
+    "github.com/onsi/gomega"
+    . "github.com/onsi/gomega"
+    "testing"
+    "time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const retryTopic = `test-retry`
+
+type sentMessage struct {
+	Topic   string
+	Message *kafka.Message
+}
+
+type MockedRetryProducer struct {
+	topic string
+	sent  *[]sentMessage
+}
+
+func (p MockedRetryProducer) Topic() string {
+	return p.topic
+}
+
+func (p MockedRetryProducer) Send(_ context.Context, message interface{}, _ ...kafka.MessageOptions) error {
+	*p.sent = append(*p.sent, sentMessage{Topic: p.topic, Message: message.(*kafka.Message)})
+	return nil
+}
+
+func (p MockedRetryProducer) ReadyCh() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+func NewTestRetryDispatcher(policy kafka.RetryPolicy, target string, handlerErr error) (*kafka.Dispatcher, *[]sentMessage) {
+	sent := make([]sentMessage, 0, 2)
+	interceptor := kafka.NewRetryInterceptor(policy, func(topic string) (kafka.Producer, error) {
+		return MockedRetryProducer{topic: topic, sent: &sent}, nil
+	})
+	interceptor.Target = target
+	d := &kafka.Dispatcher{Interceptors: []kafka.ConsumerDispatchInterceptor{interceptor}}
+	_ = d.AddHandler(func(_ context.Context, _ *kafka.Message) error {
+		return handlerErr
+	})
+	return d, &sent
+}
+
+func NewTestMessageContext(topic string, headers kafka.Headers) *kafka.MessageContext {
+	if headers == nil {
+		headers = kafka.Headers{}
+	}
+	return &kafka.MessageContext{
+		Context: context.Background(),
+		Topic:   topic,
+		Message: kafka.Message{
+			Headers: headers,
+			Payload: []byte(`{"value":"test"}`),
+		},
+	}
+}
+
+func DefaultTestRetryPolicy() kafka.RetryPolicy {
+	return kafka.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     10 * time.Millisecond,
+		Multiplier:  2,
+		DeadLetter:  true,
+	}
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestRetryInterceptor(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestRetryTopicNames(), "TestRetryTopicNames"),
+		test.GomegaSubTest(SubTestRetryPolicyBackoff(), "TestRetryPolicyBackoff"),
+		test.GomegaSubTest(SubTestRetryToRetryTopic(), "TestRetryToRetryTopic"),
+		test.GomegaSubTest(SubTestRetryExhausted(), "TestRetryExhausted"),
+		test.GomegaSubTest(SubTestRetryNonRetryable(), "TestRetryNonRetryable"),
+		test.GomegaSubTest(SubTestRetryWithoutDeadLetter(), "TestRetryWithoutDeadLetter"),
+		test.GomegaSubTest(SubTestRetryTarget(), "TestRetryTarget"),
+		test.GomegaSubTest(SubTestRetrySuccess(), "TestRetrySuccess"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestRetryTopicNames() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		g.Expect(kafka.RetryTopicName(retryTopic, 1)).To(Equal(retryTopic+".retry.1"), "retry topic name should be correct")
+		g.Expect(kafka.DeadLetterTopicName(retryTopic)).To(Equal(retryTopic+".DLT"), "dead-letter topic name should be correct")
+	}
+}
+
+func SubTestRetryPolicyBackoff() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		policy := kafka.RetryPolicy{Backoff: time.Second, Multiplier: 2, MaxBackoff: 5 * time.Second}
+		g.Expect(policy.BackoffOf(1)).To(Equal(time.Second), "first backoff should be correct")
+		g.Expect(policy.BackoffOf(2)).To(Equal(2*time.Second), "second backoff should be correct")
+		g.Expect(policy.BackoffOf(3)).To(Equal(4*time.Second), "third backoff should be correct")
+		g.Expect(policy.BackoffOf(4)).To(Equal(5*time.Second), "backoff should be capped")
+
+		policy = kafka.RetryPolicy{Backoff: time.Second}
+		g.Expect(policy.BackoffOf(3)).To(Equal(time.Second), "fixed backoff should be correct")
+		g.Expect(policy.Enabled()).To(BeFalse(), "policy should be disabled")
+	}
+}
+
+func SubTestRetryToRetryTopic() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d, sent := NewTestRetryDispatcher(DefaultTestRetryPolicy(), "", errors.New("oops"))
+
+		// first attempt
+		e := d.Dispatch(NewTestMessageContext(retryTopic, kafka.Headers{"custom": "value"}))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(*sent).To(HaveLen(1), "message should be sent")
+		msg := (*sent)[0]
+		g.Expect(msg.Topic).To(Equal(kafka.RetryTopicName(retryTopic, 1)), "message should be sent to first retry topic")
+		g.Expect(msg.Message.Payload).To(Equal([]byte(`{"value":"test"}`)), "payload should be preserved")
+		g.Expect(msg.Message.Headers).To(HaveKeyWithValue("custom", "value"), "original headers should be preserved")
+		g.Expect(msg.Message.Headers).To(HaveKeyWithValue(kafka.HeaderRetryAttempts, "2"), "message should have attempts header")
+		g.Expect(msg.Message.Headers).To(HaveKeyWithValue(kafka.HeaderOriginalTopic, retryTopic), "message should have original topic header")
+		g.Expect(msg.Message.Headers).To(HaveKeyWithValue(kafka.HeaderExceptionMessage, "oops"), "message should have exception header")
+		g.Expect(msg.Message.Headers).To(HaveKey(kafka.HeaderRetryBackoffTimestamp), "message should have backoff header")
+		g.Expect(msg.Message.Headers).ToNot(HaveKey(kafka.HeaderRetryTarget), "message should not have target header")
+
+		// second attempt
+		start := time.Now()
+		e = d.Dispatch(NewTestMessageContext(msg.Topic, msg.Message.Headers))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(time.Since(start)).To(BeNumerically(">=", 5*time.Millisecond), "dispatch should wait for backoff")
+		g.Expect(*sent).To(HaveLen(2), "message should be sent")
+		msg = (*sent)[1]
+		g.Expect(msg.Topic).To(Equal(kafka.RetryTopicName(retryTopic, 2)), "message should be sent to second retry topic")
+		g.Expect(msg.Message.Headers).To(HaveKeyWithValue(kafka.HeaderRetryAttempts, "3"), "message should have attempts header")
+		g.Expect(msg.Message.Headers).To(HaveKeyWithValue(kafka.HeaderOriginalTopic, retryTopic), "original topic should be preserved")
+	}
+}
+
+func SubTestRetryExhausted() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d, sent := NewTestRetryDispatcher(DefaultTestRetryPolicy(), "", errors.New("oops"))
+		e := d.Dispatch(NewTestMessageContext(kafka.RetryTopicName(retryTopic, 2), kafka.Headers{
+			kafka.HeaderRetryAttempts: "3",
+			kafka.HeaderOriginalTopic: retryTopic,
+		}))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(*sent).To(HaveLen(1), "message should be sent")
+		g.Expect((*sent)[0].Topic).To(Equal(kafka.DeadLetterTopicName(retryTopic)), "message should be sent to dead-letter topic")
+		g.Expect((*sent)[0].Message.Headers).To(HaveKeyWithValue(kafka.HeaderRetryAttempts, "3"), "message should have attempts header")
+	}
+}
+
+func SubTestRetryNonRetryable() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		policy := DefaultTestRetryPolicy()
+		policy.NonRetryable = []error{kafka.ErrorSubTypeDecoding}
+		d, sent := NewTestRetryDispatcher(policy, "", kafka.ErrorSubTypeDecoding.WithMessage("cannot decode"))
+		e := d.Dispatch(NewTestMessageContext(retryTopic, nil))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(*sent).To(HaveLen(1), "message should be sent")
+		g.Expect((*sent)[0].Topic).To(Equal(kafka.DeadLetterTopicName(retryTopic)), "message should be sent to dead-letter topic")
+	}
+}
+
+func SubTestRetryWithoutDeadLetter() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		policy := DefaultTestRetryPolicy()
+		policy.DeadLetter = false
+		d, sent := NewTestRetryDispatcher(policy, "", errors.New("oops"))
+		e := d.Dispatch(NewTestMessageContext(kafka.RetryTopicName(retryTopic, 2), kafka.Headers{
+			kafka.HeaderRetryAttempts: "3",
+			kafka.HeaderOriginalTopic: retryTopic,
+		}))
+		g.Expect(e).To(HaveOccurred(), "dispatch should fail")
+		g.Expect(*sent).To(BeEmpty(), "message should not be sent")
+	}
+}
+
+func SubTestRetryTarget() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d, sent := NewTestRetryDispatcher(DefaultTestRetryPolicy(), "target-1", errors.New("oops"))
+		e := d.Dispatch(NewTestMessageContext(retryTopic, nil))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(*sent).To(HaveLen(1), "message should be sent")
+		g.Expect((*sent)[0].Message.Headers).To(HaveKeyWithValue(kafka.HeaderRetryTarget, "target-1"), "message should have target header")
+
+		// message targeting other subscriber should be skipped
+		other, otherSent := NewTestRetryDispatcher(DefaultTestRetryPolicy(), "target-2", errors.New("oops"))
+		e = other.Dispatch(NewTestMessageContext(kafka.RetryTopicName(retryTopic, 1), (*sent)[0].Message.Headers))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(*otherSent).To(BeEmpty(), "message should be skipped")
+	}
+}
+
+func SubTestRetrySuccess() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		d, sent := NewTestRetryDispatcher(DefaultTestRetryPolicy(), "", nil)
+		e := d.Dispatch(NewTestMessageContext(retryTopic, nil))
+		g.Expect(e).To(Succeed(), "dispatch should not fail")
+		g.Expect(*sent).To(BeEmpty(), "message should not be sent")
+	}
+}
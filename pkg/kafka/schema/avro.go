@@ -0,0 +1,491 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+)
+
+// avroSchemaCache caches parsed Avro schemas by schema text
+var avroSchemaCache sync.Map
+
+// avroType is a parsed Avro schema.
+// Logical types are encoded as their underlying types, and "default" values are used when record fields are missing.
+type avroType struct {
+	kind     string
+	name     string
+	fields   []avroField
+	symbols  []string
+	items    *avroType
+	values   *avroType
+	branches []*avroType
+	size     int
+}
+
+type avroField struct {
+	name   string
+	typ    *avroType
+	def    interface{}
+	hasDef bool
+}
+
+func parseAvroSchema(text string) (*avroType, error) {
+	if cached, ok := avroSchemaCache.Load(text); ok {
+		return cached.(*avroType), nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	if e := dec.Decode(&v); e != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %v", e)
+	}
+	p := avroParser{named: map[string]*avroType{}}
+	t, e := p.parse(v, "")
+	if e != nil {
+		return nil, e
+	}
+	avroSchemaCache.Store(text, t)
+	return t, nil
+}
+
+type avroParser struct {
+	named map[string]*avroType
+}
+
+func (p *avroParser) parse(v interface{}, namespace string) (*avroType, error) {
+	switch s := v.(type) {
+	case string:
+		if isAvroPrimitive(s) {
+			return &avroType{kind: s}, nil
+		}
+		if t, ok := p.named[avroFullName(s, namespace)]; ok {
+			return t, nil
+		}
+		if t, ok := p.named[s]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("unknown Avro type [%s]", s)
+	case []interface{}:
+		t := &avroType{kind: "union"}
+		for _, b := range s {
+			bt, e := p.parse(b, namespace)
+			if e != nil {
+				return nil, e
+			}
+			t.branches = append(t.branches, bt)
+		}
+		return t, nil
+	case map[string]interface{}:
+		return p.parseComplex(s, namespace)
+	default:
+		return nil, fmt.Errorf("invalid Avro schema %v", v)
+	}
+}
+
+func (p *avroParser) parseComplex(m map[string]interface{}, namespace string) (t *avroType, err error) {
+	kind, _ := m["type"].(string)
+	switch kind {
+	case "record", "error", "enum", "fixed":
+		name, _ := m["name"].(string)
+		if ns, ok := m["namespace"].(string); ok && !strings.Contains(name, ".") {
+			namespace = ns
+		}
+		t = &avroType{kind: kind, name: avroFullName(name, namespace)}
+		if idx := strings.LastIndex(t.name, "."); idx >= 0 {
+			namespace = t.name[:idx]
+		}
+		// register before parsing fields to support recursive types
+		p.named[t.name] = t
+	case "array", "map":
+		t = &avroType{kind: kind}
+	default:
+		// primitive types with attributes, e.g. logical types, or nested type definition
+		return p.parse(m["type"], namespace)
+	}
+
+	switch kind {
+	case "record", "error":
+		t.kind = "record"
+		fields, _ := m["fields"].([]interface{})
+		for _, f := range fields {
+			fm, ok := f.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid field of Avro record [%s]", t.name)
+			}
+			field := avroField{}
+			field.name, _ = fm["name"].(string)
+			if field.typ, err = p.parse(fm["type"], namespace); err != nil {
+				return nil, err
+			}
+			field.def, field.hasDef = fm["default"]
+			t.fields = append(t.fields, field)
+		}
+	case "enum":
+		symbols, _ := m["symbols"].([]interface{})
+		for _, s := range symbols {
+			t.symbols = append(t.symbols, fmt.Sprint(s))
+		}
+	case "fixed":
+		size, _ := m["size"].(json.Number)
+		n, _ := size.Int64()
+		t.size = int(n)
+	case "array":
+		t.items, err = p.parse(m["items"], namespace)
+	case "map":
+		t.values, err = p.parse(m["values"], namespace)
+	}
+	return t, err
+}
+
+func isAvroPrimitive(name string) bool {
+	switch name {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	default:
+		return false
+	}
+}
+
+func avroFullName(name, namespace string) string {
+	if strings.Contains(name, ".") || len(namespace) == 0 {
+		return name
+	}
+	return namespace + "." + name
+}
+
+/**************************
+	Encoding
+ **************************/
+
+// avroMarshal encodes given value with Avro binary encoding.
+// The value is converted to generic form using its JSON representation, so JSON tags are used as Avro field names.
+func avroMarshal(t *avroType, v interface{}) ([]byte, error) {
+	data, e := json.Marshal(v)
+	if e != nil {
+		return nil, e
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if e := dec.Decode(&generic); e != nil {
+		return nil, e
+	}
+	var buf bytes.Buffer
+	if e := avroEncode(&buf, t, generic); e != nil {
+		return nil, e
+	}
+	return buf.Bytes(), nil
+}
+
+func avroEncode(w *bytes.Buffer, t *avroType, v interface{}) error {
+	switch t.kind {
+	case "null":
+		if v != nil {
+			return fmt.Errorf("expect null, but got %v", v)
+		}
+	case "boolean":
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("expect boolean, but got %v", v)
+		}
+		if b {
+			w.WriteByte(1)
+		} else {
+			w.WriteByte(0)
+		}
+	case "int", "long":
+		n, ok := v.(json.Number)
+		i, e := n.Int64()
+		if !ok || e != nil {
+			return fmt.Errorf("expect %s, but got %v", t.kind, v)
+		}
+		avroWriteLong(w, i)
+	case "float", "double":
+		n, ok := v.(json.Number)
+		f, e := n.Float64()
+		if !ok || e != nil {
+			return fmt.Errorf("expect %s, but got %v", t.kind, v)
+		}
+		if t.kind == "float" {
+			w.Write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(f))))
+		} else {
+			w.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(f)))
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expect string, but got %v", v)
+		}
+		avroWriteLong(w, int64(len(s)))
+		w.WriteString(s)
+	case "bytes", "fixed":
+		// []byte is represented as base64 string in JSON
+		s, ok := v.(string)
+		b, e := base64.StdEncoding.DecodeString(s)
+		if !ok || e != nil {
+			return fmt.Errorf("expect %s as base64 string, but got %v", t.kind, v)
+		}
+		if t.kind == "fixed" {
+			if len(b) != t.size {
+				return fmt.Errorf("expect fixed of size %d, but got %d bytes", t.size, len(b))
+			}
+		} else {
+			avroWriteLong(w, int64(len(b)))
+		}
+		w.Write(b)
+	case "enum":
+		s, _ := v.(string)
+		for i := range t.symbols {
+			if t.symbols[i] == s {
+				avroWriteLong(w, int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("expect enum symbol of [%s], but got %v", t.name, v)
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok && v != nil {
+			return fmt.Errorf("expect array, but got %v", v)
+		}
+		if len(items) != 0 {
+			avroWriteLong(w, int64(len(items)))
+		}
+		for _, item := range items {
+			if e := avroEncode(w, t.items, item); e != nil {
+				return e
+			}
+		}
+		avroWriteLong(w, 0)
+	case "map":
+		m, ok := v.(map[string]interface{})
+		if !ok && v != nil {
+			return fmt.Errorf("expect map, but got %v", v)
+		}
+		if len(m) != 0 {
+			avroWriteLong(w, int64(len(m)))
+		}
+		for k, mv := range m {
+			avroWriteLong(w, int64(len(k)))
+			w.WriteString(k)
+			if e := avroEncode(w, t.values, mv); e != nil {
+				return e
+			}
+		}
+		avroWriteLong(w, 0)
+	case "record":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expect record [%s], but got %v", t.name, v)
+		}
+		for _, f := range t.fields {
+			fv, ok := m[f.name]
+			if !ok && f.hasDef {
+				fv = f.def
+			}
+			if e := avroEncode(w, f.typ, fv); e != nil {
+				return fmt.Errorf("field [%s.%s]: %v", t.name, f.name, e)
+			}
+		}
+	case "union":
+		for i, b := range t.branches {
+			if avroAccepts(b, v) {
+				avroWriteLong(w, int64(i))
+				return avroEncode(w, b, v)
+			}
+		}
+		return fmt.Errorf("value %v doesn't match any type of union", v)
+	default:
+		return fmt.Errorf("unsupported Avro type [%s]", t.kind)
+	}
+	return nil
+}
+
+// avroAccepts returns true if given generic value can be encoded as given type. Used for selecting union branch
+func avroAccepts(t *avroType, v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return t.kind == "null"
+	case bool:
+		return t.kind == "boolean"
+	case json.Number:
+		switch t.kind {
+		case "int", "long":
+			_, e := val.Int64()
+			return e == nil
+		case "float", "double":
+			return true
+		}
+	case string:
+		switch t.kind {
+		case "string", "bytes", "fixed":
+			return true
+		case "enum":
+			for _, s := range t.symbols {
+				if s == val {
+					return true
+				}
+			}
+		}
+	case map[string]interface{}:
+		return t.kind == "record" || t.kind == "map"
+	case []interface{}:
+		return t.kind == "array"
+	}
+	return false
+}
+
+func avroWriteLong(w *bytes.Buffer, v int64) {
+	w.Write(binary.AppendVarint(nil, v))
+}
+
+/**************************
+	Decoding
+ **************************/
+
+// avroUnmarshal decodes Avro binary data into "v" using its JSON representation.
+func avroUnmarshal(t *avroType, data []byte, v interface{}) error {
+	generic, e := avroDecode(bytes.NewReader(data), t)
+	if e != nil {
+		return e
+	}
+	jsonData, e := json.Marshal(generic)
+	if e != nil {
+		return e
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
+func avroDecode(r *bytes.Reader, t *avroType) (interface{}, error) {
+	switch t.kind {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, e := r.ReadByte()
+		return b != 0, e
+	case "int", "long":
+		return binary.ReadVarint(r)
+	case "float":
+		var bits uint32
+		e := binary.Read(r, binary.LittleEndian, &bits)
+		return float64(math.Float32frombits(bits)), e
+	case "double":
+		var bits uint64
+		e := binary.Read(r, binary.LittleEndian, &bits)
+		return math.Float64frombits(bits), e
+	case "string":
+		b, e := avroReadBytes(r, -1)
+		return string(b), e
+	case "bytes", "fixed":
+		size := -1
+		if t.kind == "fixed" {
+			size = t.size
+		}
+		b, e := avroReadBytes(r, size)
+		return base64.StdEncoding.EncodeToString(b), e
+	case "enum":
+		i, e := binary.ReadVarint(r)
+		if e != nil || i < 0 || int(i) >= len(t.symbols) {
+			return nil, fmt.Errorf("invalid symbol index of enum [%s]", t.name)
+		}
+		return t.symbols[i], nil
+	case "array":
+		items := make([]interface{}, 0)
+		e := avroReadBlocks(r, func() error {
+			item, e := avroDecode(r, t.items)
+			items = append(items, item)
+			return e
+		})
+		return items, e
+	case "map":
+		m := make(map[string]interface{})
+		e := avroReadBlocks(r, func() error {
+			k, e := avroReadBytes(r, -1)
+			if e != nil {
+				return e
+			}
+			m[string(k)], e = avroDecode(r, t.values)
+			return e
+		})
+		return m, e
+	case "record":
+		m := make(map[string]interface{}, len(t.fields))
+		for _, f := range t.fields {
+			fv, e := avroDecode(r, f.typ)
+			if e != nil {
+				return nil, fmt.Errorf("field [%s.%s]: %v", t.name, f.name, e)
+			}
+			m[f.name] = fv
+		}
+		return m, nil
+	case "union":
+		i, e := binary.ReadVarint(r)
+		if e != nil || i < 0 || int(i) >= len(t.branches) {
+			return nil, fmt.Errorf("invalid branch index of union")
+		}
+		return avroDecode(r, t.branches[i])
+	default:
+		return nil, fmt.Errorf("unsupported Avro type [%s]", t.kind)
+	}
+}
+
+// avroReadBytes read bytes of given size. If size is negative, the size is read from data first
+func avroReadBytes(r *bytes.Reader, size int) ([]byte, error) {
+	if size < 0 {
+		n, e := binary.ReadVarint(r)
+		if e != nil {
+			return nil, e
+		}
+		if n < 0 || n > int64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		size = int(n)
+	}
+	b := make([]byte, size)
+	_, e := io.ReadFull(r, b)
+	return b, e
+}
+
+func avroReadBlocks(r *bytes.Reader, readFn func() error) error {
+	for {
+		count, e := binary.ReadVarint(r)
+		switch {
+		case e != nil:
+			return e
+		case count == 0:
+			return nil
+		case count < 0:
+			// negative count is followed by block size in bytes
+			count = -count
+			if _, e := binary.ReadVarint(r); e != nil {
+				return e
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			if e := readFn(); e != nil {
+				return e
+			}
+		}
+	}
+}
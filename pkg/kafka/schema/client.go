@@ -0,0 +1,273 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SchemaType string
+
+const (
+	SchemaTypeAvro     SchemaType = "AVRO"
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+	SchemaTypeJSON     SchemaType = "JSON"
+)
+
+// Schema is a schema registered in schema registry.
+// Note: Type is empty for Avro schema, as returned by schema registry. Use Schema.SchemaType to get the effective type.
+type Schema struct {
+	ID      int        `json:"id,omitempty"`
+	Subject string     `json:"subject,omitempty"`
+	Version int        `json:"version,omitempty"`
+	Type    SchemaType `json:"schemaType,omitempty"`
+	Schema  string     `json:"schema"`
+}
+
+// SchemaType returns the effective type of the schema. Avro is the default
+func (s Schema) SchemaType() SchemaType {
+	if len(s.Type) == 0 {
+		return SchemaTypeAvro
+	}
+	return s.Type
+}
+
+// Client is a client of Confluent compatible schema registry
+type Client interface {
+	// Register registers the schema under given subject and returns the schema ID.
+	// If the schema is already registered, the existing ID is returned.
+	Register(ctx context.Context, subject string, schema *Schema) (int, error)
+	// Lookup finds the registered schema under given subject. It doesn't register the schema if not found.
+	Lookup(ctx context.Context, subject string, schema *Schema) (*Schema, error)
+	// SchemaByID returns the schema of given ID
+	SchemaByID(ctx context.Context, id int) (*Schema, error)
+	// LatestSchema returns the latest version of the schema registered under given subject
+	LatestSchema(ctx context.Context, subject string) (*Schema, error)
+}
+
+// ValueSubject returns subject name of message payload in given topic, using "TopicNameStrategy", e.g. "my-topic-value"
+func ValueSubject(topic string) string {
+	return topic + "-value"
+}
+
+// KeySubject returns subject name of message key in given topic, using "TopicNameStrategy", e.g. "my-topic-key"
+func KeySubject(topic string) string {
+	return topic + "-key"
+}
+
+// RegistryError is the error returned by schema registry
+type RegistryError struct {
+	StatusCode int    `json:"-"`
+	ErrorCode  int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+func (e RegistryError) Error() string {
+	return fmt.Sprintf("schema registry error %d (HTTP %d): %s", e.ErrorCode, e.StatusCode, e.Message)
+}
+
+/**************************
+	HTTP Client
+ **************************/
+
+const mimeTypeRegistry = "application/vnd.schemaregistry.v1+json"
+
+type ClientOptions func(opt *ClientOption)
+type ClientOption struct {
+	// URL base URL of the schema registry, e.g. "http://localhost:8081"
+	URL string
+	// Username and Password are used for HTTP basic auth if Username is not empty
+	Username string
+	Password string
+	// HttpClient used to send requests. http.DefaultClient is used if nil
+	HttpClient *http.Client
+	// LatestTTL how long the result of LatestSchema is cached. Schemas by ID and registered schemas are cached forever.
+	LatestTTL time.Duration
+}
+
+type latestEntry struct {
+	schema  *Schema
+	expires time.Time
+}
+
+// httpClient implements Client with local caching
+type httpClient struct {
+	ClientOption
+	mtx        sync.RWMutex
+	byID       map[int]*Schema
+	registered map[string]*Schema
+	latest     map[string]latestEntry
+}
+
+// NewClient creates a Client of schema registry using its REST API. Results are cached locally.
+func NewClient(opts ...ClientOptions) Client {
+	opt := ClientOption{
+		HttpClient: http.DefaultClient,
+		LatestTTL:  time.Minute,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	opt.URL = strings.TrimRight(opt.URL, "/")
+	return &httpClient{
+		ClientOption: opt,
+		byID:         make(map[int]*Schema),
+		registered:   make(map[string]*Schema),
+		latest:       make(map[string]latestEntry),
+	}
+}
+
+func (c *httpClient) Register(ctx context.Context, subject string, schema *Schema) (int, error) {
+	key := c.registeredKey(subject, schema)
+	c.mtx.RLock()
+	cached, ok := c.registered[key]
+	c.mtx.RUnlock()
+	if ok {
+		return cached.ID, nil
+	}
+
+	var resp Schema
+	path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	if e := c.do(ctx, http.MethodPost, path, c.requestBody(schema), &resp); e != nil {
+		return 0, e
+	}
+	c.cache(key, &Schema{ID: resp.ID, Subject: subject, Type: schema.Type, Schema: schema.Schema})
+	return resp.ID, nil
+}
+
+func (c *httpClient) Lookup(ctx context.Context, subject string, schema *Schema) (*Schema, error) {
+	key := c.registeredKey(subject, schema)
+	c.mtx.RLock()
+	cached, ok := c.registered[key]
+	c.mtx.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	var resp Schema
+	path := fmt.Sprintf("/subjects/%s", url.PathEscape(subject))
+	if e := c.do(ctx, http.MethodPost, path, c.requestBody(schema), &resp); e != nil {
+		return nil, e
+	}
+	c.cache(key, &resp)
+	return &resp, nil
+}
+
+func (c *httpClient) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	c.mtx.RLock()
+	cached, ok := c.byID[id]
+	c.mtx.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	var resp Schema
+	if e := c.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp); e != nil {
+		return nil, e
+	}
+	resp.ID = id
+	c.cache("", &resp)
+	return &resp, nil
+}
+
+func (c *httpClient) LatestSchema(ctx context.Context, subject string) (*Schema, error) {
+	c.mtx.RLock()
+	cached, ok := c.latest[subject]
+	c.mtx.RUnlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.schema, nil
+	}
+
+	var resp Schema
+	path := fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject))
+	if e := c.do(ctx, http.MethodGet, path, nil, &resp); e != nil {
+		return nil, e
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.latest[subject] = latestEntry{schema: &resp, expires: time.Now().Add(c.LatestTTL)}
+	c.byID[resp.ID] = &resp
+	return &resp, nil
+}
+
+func (c *httpClient) cache(registeredKey string, schema *Schema) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(registeredKey) != 0 {
+		c.registered[registeredKey] = schema
+	}
+	if _, ok := c.byID[schema.ID]; !ok {
+		c.byID[schema.ID] = schema
+	}
+}
+
+func (c *httpClient) registeredKey(subject string, schema *Schema) string {
+	return subject + "\x00" + string(schema.SchemaType()) + "\x00" + schema.Schema
+}
+
+func (c *httpClient) requestBody(schema *Schema) interface{} {
+	body := Schema{Schema: schema.Schema}
+	if schema.SchemaType() != SchemaTypeAvro {
+		body.Type = schema.Type
+	}
+	return body
+}
+
+func (c *httpClient) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, e := json.Marshal(body)
+		if e != nil {
+			return e
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, e := http.NewRequestWithContext(ctx, method, c.URL+path, reader)
+	if e != nil {
+		return e
+	}
+	req.Header.Set("Accept", mimeTypeRegistry)
+	if body != nil {
+		req.Header.Set("Content-Type", mimeTypeRegistry)
+	}
+	if len(c.Username) != 0 {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, e := c.HttpClient.Do(req)
+	if e != nil {
+		return e
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		regErr := RegistryError{StatusCode: resp.StatusCode}
+		if e := json.NewDecoder(resp.Body).Decode(&regErr); e != nil {
+			regErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return regErr
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema_test
+
+import (
+	"context"
+	"errors"
+	kafkaschema "github.com/cisco-open/go-lanai/pkg/kafka/schema"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const testSubject = `test-topic-value`
+
+type CountingHandler struct {
+	http.Handler
+	Count atomic.Int32
+}
+
+func (h *CountingHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.Count.Add(1)
+	h.Handler.ServeHTTP(rw, req)
+}
+
+func NewTestRegistryServer(t *testing.T) (*httptest.Server, *CountingHandler) {
+	handler := &CountingHandler{Handler: kafkaschema.NewInMemoryRegistry()}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, handler
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestClient(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestClientRegister(), "TestClientRegister"),
+		test.GomegaSubTest(SubTestClientLookup(), "TestClientLookup"),
+		test.GomegaSubTest(SubTestClientNotFound(), "TestClientNotFound"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestClientRegister() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		server, handler := NewTestRegistryServer(t)
+		client := kafkaschema.NewClient(func(opt *kafkaschema.ClientOption) {
+			opt.URL = server.URL
+		})
+		schema := &kafkaschema.Schema{Type: kafkaschema.SchemaTypeJSON, Schema: `{"type":"object"}`}
+		id, e := client.Register(ctx, testSubject, schema)
+		g.Expect(e).To(Succeed(), "register should not fail")
+		g.Expect(id).To(Equal(1), "schema ID should be correct")
+
+		id, e = client.Register(ctx, testSubject, schema)
+		g.Expect(e).To(Succeed(), "register again should not fail")
+		g.Expect(id).To(Equal(1), "schema ID should be the same")
+		g.Expect(handler.Count.Load()).To(BeEquivalentTo(1), "registered schema should be cached")
+
+		found, e := client.SchemaByID(ctx, id)
+		g.Expect(e).To(Succeed(), "get schema by ID should not fail")
+		g.Expect(found.SchemaType()).To(Equal(kafkaschema.SchemaTypeJSON), "schema type should be correct")
+		g.Expect(found.Schema).To(Equal(schema.Schema), "schema should be correct")
+		g.Expect(handler.Count.Load()).To(BeEquivalentTo(1), "schema by ID should be cached")
+
+		latest, e := client.LatestSchema(ctx, testSubject)
+		g.Expect(e).To(Succeed(), "get latest schema should not fail")
+		g.Expect(latest.ID).To(Equal(1), "latest schema ID should be correct")
+		g.Expect(latest.Version).To(Equal(1), "latest schema version should be correct")
+	}
+}
+
+func SubTestClientLookup() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		server, _ := NewTestRegistryServer(t)
+		client := kafkaschema.NewClient(func(opt *kafkaschema.ClientOption) {
+			opt.URL = server.URL
+		})
+		avro := &kafkaschema.Schema{Schema: `"string"`}
+		_, e := client.Register(ctx, "another-subject", &kafkaschema.Schema{Schema: `"long"`})
+		g.Expect(e).To(Succeed(), "register should not fail")
+		_, e = client.Register(ctx, testSubject, avro)
+		g.Expect(e).To(Succeed(), "register should not fail")
+
+		found, e := client.Lookup(ctx, testSubject, avro)
+		g.Expect(e).To(Succeed(), "lookup should not fail")
+		g.Expect(found.ID).To(Equal(2), "schema ID should be correct")
+		g.Expect(found.SchemaType()).To(Equal(kafkaschema.SchemaTypeAvro), "schema type should be Avro by default")
+	}
+}
+
+func SubTestClientNotFound() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		server, _ := NewTestRegistryServer(t)
+		client := kafkaschema.NewClient(func(opt *kafkaschema.ClientOption) {
+			opt.URL = server.URL
+		})
+		_, e := client.LatestSchema(ctx, testSubject)
+		var regErr kafkaschema.RegistryError
+		g.Expect(errors.As(e, &regErr)).To(BeTrue(), "error should be RegistryError")
+		g.Expect(regErr.StatusCode).To(Equal(http.StatusNotFound), "status code should be correct")
+		g.Expect(regErr.ErrorCode).To(Equal(40401), "error code should be correct")
+
+		_, e = client.SchemaByID(ctx, 100)
+		g.Expect(errors.As(e, &regErr)).To(BeTrue(), "error should be RegistryError")
+		g.Expect(regErr.ErrorCode).To(Equal(40403), "error code should be correct")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MIME types of payloads encoded with schema registry wire format
+const (
+	MIMETypeAvro       = "application/vnd.schemaregistry.avro"
+	MIMETypeProtobuf   = "application/vnd.schemaregistry.protobuf"
+	MIMETypeJSONSchema = "application/vnd.schemaregistry.json"
+)
+
+// wire format: magic byte (0) + 4 bytes big-endian schema ID + payload
+const (
+	magicByte    = byte(0)
+	headerLength = 5
+)
+
+/**************************
+	Encoders
+ **************************/
+
+type EncoderOptions func(opt *EncoderOption)
+type EncoderOption struct {
+	// Schema the schema text used to encode payloads. If empty, the latest schema of the subject is used.
+	// Protobuf encoder doesn't need the schema text for encoding, but it's required for registration.
+	Schema string
+	// AutoRegister whether to register Schema under the subject. If false, Schema must be already registered.
+	AutoRegister bool
+}
+
+// WithSchema is an EncoderOptions that specify the schema text
+func WithSchema(schema string) EncoderOptions {
+	return func(opt *EncoderOption) {
+		opt.Schema = schema
+	}
+}
+
+// AutoRegister is an EncoderOptions that controls whether the schema is registered automatically. Default is true
+func AutoRegister(enabled bool) EncoderOptions {
+	return func(opt *EncoderOption) {
+		opt.AutoRegister = enabled
+	}
+}
+
+type marshalFunc func(schema *Schema, v interface{}) ([]byte, error)
+
+// schemaEncoder implements kafka.Encoder with schema registry wire format
+type schemaEncoder struct {
+	EncoderOption
+	client     Client
+	subject    string
+	schemaType SchemaType
+	mimeType   string
+	marshal    marshalFunc
+}
+
+// NewAvroEncoder returns a kafka.Encoder that encodes payloads with Avro binary encoding.
+// Payloads are converted using their JSON representation, so JSON tags are used as Avro field names.
+func NewAvroEncoder(client Client, subject string, opts ...EncoderOptions) kafka.Encoder {
+	return newSchemaEncoder(client, subject, SchemaTypeAvro, MIMETypeAvro, marshalAvro, opts)
+}
+
+// NewProtobufEncoder returns a kafka.Encoder that encodes payloads of proto.Message.
+func NewProtobufEncoder(client Client, subject string, opts ...EncoderOptions) kafka.Encoder {
+	return newSchemaEncoder(client, subject, SchemaTypeProtobuf, MIMETypeProtobuf, marshalProtobuf, opts)
+}
+
+// NewJSONSchemaEncoder returns a kafka.Encoder that encodes payloads as JSON.
+// Note: payloads are not validated against the schema.
+func NewJSONSchemaEncoder(client Client, subject string, opts ...EncoderOptions) kafka.Encoder {
+	return newSchemaEncoder(client, subject, SchemaTypeJSON, MIMETypeJSONSchema, marshalJSON, opts)
+}
+
+func newSchemaEncoder(client Client, subject string, typ SchemaType, mimeType string, fn marshalFunc, opts []EncoderOptions) *schemaEncoder {
+	enc := &schemaEncoder{
+		EncoderOption: EncoderOption{
+			AutoRegister: true,
+		},
+		client:     client,
+		subject:    subject,
+		schemaType: typ,
+		mimeType:   mimeType,
+		marshal:    fn,
+	}
+	for _, fn := range opts {
+		fn(&enc.EncoderOption)
+	}
+	return enc
+}
+
+func (enc *schemaEncoder) MIMEType() string {
+	return enc.mimeType
+}
+
+func (enc *schemaEncoder) Encode(v interface{}) ([]byte, error) {
+	schema, e := enc.resolveSchema(context.Background())
+	if e != nil {
+		return nil, kafka.ErrorSubTypeEncoding.WithCause(e, "unable to resolve schema of subject [%s]: %v", enc.subject, e)
+	}
+	payload, e := enc.marshal(schema, v)
+	if e != nil {
+		return nil, kafka.ErrorSubTypeEncoding.WithCause(e, "unable to encode as %s: %v", schema.SchemaType(), e)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, headerLength+len(payload)))
+	buf.WriteByte(magicByte)
+	_ = binary.Write(buf, binary.BigEndian, uint32(schema.ID))
+	buf.Write(payload)
+	return buf.Bytes(), nil
+}
+
+func (enc *schemaEncoder) resolveSchema(ctx context.Context) (*Schema, error) {
+	switch {
+	case len(enc.Schema) == 0:
+		return enc.client.LatestSchema(ctx, enc.subject)
+	case enc.AutoRegister:
+		schema := &Schema{Subject: enc.subject, Type: enc.schemaType, Schema: enc.Schema}
+		id, e := enc.client.Register(ctx, enc.subject, schema)
+		schema.ID = id
+		return schema, e
+	default:
+		return enc.client.Lookup(ctx, enc.subject, &Schema{Type: enc.schemaType, Schema: enc.Schema})
+	}
+}
+
+func marshalAvro(schema *Schema, v interface{}) ([]byte, error) {
+	t, e := parseAvroSchema(schema.Schema)
+	if e != nil {
+		return nil, e
+	}
+	return avroMarshal(t, v)
+}
+
+func marshalJSON(_ *Schema, v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func marshalProtobuf(_ *Schema, v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf payload must be proto.Message, but got %T", v)
+	}
+	data, e := proto.Marshal(msg)
+	if e != nil {
+		return nil, e
+	}
+	return append(protobufMessageIndexes(msg.ProtoReflect().Descriptor()), data...), nil
+}
+
+// protobufMessageIndexes encodes the path of the message type within its .proto file.
+// e.g. [1, 0] is the first nested message of the second message. [0] is encoded as a single 0 byte
+func protobufMessageIndexes(desc protoreflect.MessageDescriptor) []byte {
+	var indexes []int64
+	for d := protoreflect.Descriptor(desc); d != nil; d = d.Parent() {
+		if _, ok := d.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+		indexes = append([]int64{int64(d.Index())}, indexes...)
+	}
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return []byte{0}
+	}
+	buf := binary.AppendVarint(nil, int64(len(indexes)))
+	for _, i := range indexes {
+		buf = binary.AppendVarint(buf, i)
+	}
+	return buf
+}
+
+/**************************
+	Decoder
+ **************************/
+
+// Decoder implements kafka.Decoder. It decodes payloads with schema registry wire format, based on HeaderContentType
+// or the magic byte if the message doesn't have known MIME type (e.g. produced by non-go-lanai producers).
+type Decoder struct {
+	client Client
+}
+
+func NewDecoder(client Client) *Decoder {
+	return &Decoder{client: client}
+}
+
+func (d *Decoder) CanDecode(contentType string, data []byte) bool {
+	switch contentType {
+	case MIMETypeAvro, MIMETypeProtobuf, MIMETypeJSONSchema:
+		return true
+	default:
+		return len(data) > headerLength && data[0] == magicByte
+	}
+}
+
+func (d *Decoder) Decode(ctx context.Context, data []byte, v interface{}) error {
+	if len(data) < headerLength || data[0] != magicByte {
+		return kafka.ErrorSubTypeDecoding.WithMessage("payload is not in schema registry wire format")
+	}
+	id := int(binary.BigEndian.Uint32(data[1:headerLength]))
+	schema, e := d.client.SchemaByID(ctx, id)
+	if e != nil {
+		return kafka.ErrorSubTypeDecoding.WithCause(e, "unable to fetch schema of ID %d: %v", id, e)
+	}
+
+	payload := data[headerLength:]
+	switch schema.SchemaType() {
+	case SchemaTypeAvro:
+		var t *avroType
+		if t, e = parseAvroSchema(schema.Schema); e == nil {
+			e = avroUnmarshal(t, payload, v)
+		}
+	case SchemaTypeProtobuf:
+		e = unmarshalProtobuf(payload, v)
+	case SchemaTypeJSON:
+		e = json.Unmarshal(payload, v)
+	default:
+		e = fmt.Errorf("unsupported schema type [%s]", schema.Type)
+	}
+	if e != nil {
+		return kafka.ErrorSubTypeDecoding.WithCause(e, "unable to decode as %s: %v", schema.SchemaType(), e)
+	}
+	return nil
+}
+
+func unmarshalProtobuf(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf payload requires proto.Message, but got %T", v)
+	}
+	// skip message indexes
+	r := bytes.NewReader(data)
+	count, e := binary.ReadVarint(r)
+	for i := int64(0); e == nil && i < count; i++ {
+		_, e = binary.ReadVarint(r)
+	}
+	if e != nil {
+		return fmt.Errorf("invalid message indexes: %v", e)
+	}
+	return proto.Unmarshal(data[len(data)-r.Len():], msg)
+}
@@ -0,0 +1,215 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	kafkaschema "github.com/cisco-open/go-lanai/pkg/kafka/schema"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const testAvroSchema = `{
+  "type": "record",
+  "name": "Order",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "customer", "type": "string"},
+    {"name": "amount", "type": "double"},
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID"]}},
+    {"name": "tags", "type": {"type": "array", "items": "string"}},
+    {"name": "attrs", "type": {"type": "map", "values": "int"}},
+    {"name": "note", "type": ["null", "string"], "default": null},
+    {"name": "signature", "type": "bytes"},
+    {"name": "parent", "type": ["null", "Order"], "default": null},
+    {"name": "priority", "type": "int", "default": 5}
+  ]
+}`
+
+type TestOrder struct {
+	ID        int64          `json:"id"`
+	Customer  string         `json:"customer"`
+	Amount    float64        `json:"amount"`
+	Status    string         `json:"status"`
+	Tags      []string       `json:"tags"`
+	Attrs     map[string]int `json:"attrs"`
+	Note      *string        `json:"note"`
+	Signature []byte         `json:"signature"`
+	Parent    *TestOrder     `json:"parent"`
+	Priority  int            `json:"priority,omitempty"`
+}
+
+func NewTestOrder() *TestOrder {
+	note := "deliver fast"
+	return &TestOrder{
+		ID:        1234567890123,
+		Customer:  "alice",
+		Amount:    99.5,
+		Status:    "PAID",
+		Tags:      []string{"a", "b"},
+		Attrs:     map[string]int{"x": -1},
+		Note:      &note,
+		Signature: []byte{0, 1, 2},
+		Parent:    &TestOrder{ID: 1, Customer: "bob", Status: "NEW", Tags: []string{}, Attrs: map[string]int{}, Signature: []byte{}},
+	}
+}
+
+// DecodeWithDispatcher dispatch given encoded payload to a handler with given payload type, and returns the decoded payload
+func DecodeWithDispatcher[T any](ctx context.Context, registry kafkaschema.Client, contentType string, data []byte) (T, error) {
+	var decoded T
+	d := &kafka.Dispatcher{Decoders: []kafka.Decoder{kafkaschema.NewDecoder(registry)}}
+	_ = d.AddHandler(func(_ context.Context, payload T) error {
+		decoded = payload
+		return nil
+	})
+	headers := kafka.Headers{}
+	if len(contentType) != 0 {
+		headers[kafka.HeaderContentType] = contentType
+	}
+	e := d.Dispatch(&kafka.MessageContext{
+		Context: ctx,
+		Message: kafka.Message{Headers: headers, Payload: data},
+	})
+	return decoded, e
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestEncoding(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestAvroEncoding(), "TestAvroEncoding"),
+		test.GomegaSubTest(SubTestProtobufEncoding(), "TestProtobufEncoding"),
+		test.GomegaSubTest(SubTestJSONSchemaEncoding(), "TestJSONSchemaEncoding"),
+		test.GomegaSubTest(SubTestEncoderWithoutAutoRegister(), "TestEncoderWithoutAutoRegister"),
+		test.GomegaSubTest(SubTestDecodeWithoutContentType(), "TestDecodeWithoutContentType"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestAvroEncoding() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		registry := kafkaschema.NewInMemoryRegistry()
+		enc := kafkaschema.NewAvroEncoder(registry, testSubject, kafkaschema.WithSchema(testAvroSchema))
+		g.Expect(enc.MIMEType()).To(Equal(kafkaschema.MIMETypeAvro), "MIME type should be correct")
+
+		order := NewTestOrder()
+		data, e := enc.Encode(order)
+		g.Expect(e).To(Succeed(), "encode should not fail")
+		g.Expect(data[:5]).To(Equal([]byte{0, 0, 0, 0, 1}), "payload should have magic byte and schema ID")
+
+		decoded, e := DecodeWithDispatcher[*TestOrder](ctx, registry, enc.MIMEType(), data)
+		g.Expect(e).To(Succeed(), "decode should not fail")
+		order.Priority = 5
+		order.Parent.Priority = 5
+		g.Expect(decoded).To(Equal(order), "decoded payload should be correct")
+
+		generic, e := DecodeWithDispatcher[map[string]interface{}](ctx, registry, enc.MIMEType(), data)
+		g.Expect(e).To(Succeed(), "decode as map should not fail")
+		g.Expect(generic).To(HaveKeyWithValue("customer", "alice"), "decoded map should be correct")
+
+		_, e = enc.Encode(map[string]interface{}{"id": "not a number"})
+		g.Expect(e).To(HaveOccurred(), "encode invalid payload should fail")
+		g.Expect(errors.Is(e, kafka.ErrorSubTypeEncoding)).To(BeTrue(), "error should be encoding error")
+	}
+}
+
+func SubTestProtobufEncoding() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		registry := kafkaschema.NewInMemoryRegistry()
+		enc := kafkaschema.NewProtobufEncoder(registry, testSubject, kafkaschema.WithSchema(`syntax = "proto3";`))
+		data, e := enc.Encode(wrapperspb.String("hello"))
+		g.Expect(e).To(Succeed(), "encode should not fail")
+		// StringValue is the 8th message in wrappers.proto, message indexes [7] is encoded as zigzag varint [2, 14]
+		g.Expect(data[:7]).To(Equal([]byte{0, 0, 0, 0, 1, 2, 14}), "payload should have header and message indexes")
+
+		decoded, e := DecodeWithDispatcher[*wrapperspb.StringValue](ctx, registry, enc.MIMEType(), data)
+		g.Expect(e).To(Succeed(), "decode should not fail")
+		g.Expect(decoded.GetValue()).To(Equal("hello"), "decoded payload should be correct")
+
+		_, e = enc.Encode(NewTestOrder())
+		g.Expect(e).To(HaveOccurred(), "encode non-proto payload should fail")
+		_, e = DecodeWithDispatcher[*TestOrder](ctx, registry, enc.MIMEType(), data)
+		g.Expect(errors.Is(e, kafka.ErrorSubTypeDecoding)).To(BeTrue(), "decode into non-proto payload should fail")
+	}
+}
+
+func SubTestJSONSchemaEncoding() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		registry := kafkaschema.NewInMemoryRegistry()
+		enc := kafkaschema.NewJSONSchemaEncoder(registry, testSubject, kafkaschema.WithSchema(`{"type":"object"}`))
+		order := NewTestOrder()
+		data, e := enc.Encode(order)
+		g.Expect(e).To(Succeed(), "encode should not fail")
+		g.Expect(data[5]).To(Equal(byte('{')), "payload should be JSON after header")
+
+		decoded, e := DecodeWithDispatcher[TestOrder](ctx, registry, enc.MIMEType(), data)
+		g.Expect(e).To(Succeed(), "decode should not fail")
+		g.Expect(decoded).To(Equal(*order), "decoded payload should be correct")
+	}
+}
+
+func SubTestEncoderWithoutAutoRegister() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		registry := kafkaschema.NewInMemoryRegistry()
+		enc := kafkaschema.NewAvroEncoder(registry, testSubject, kafkaschema.WithSchema(`"string"`), kafkaschema.AutoRegister(false))
+		_, e := enc.Encode("value")
+		g.Expect(e).To(HaveOccurred(), "encode with unregistered schema should fail")
+
+		_, _ = registry.Register(ctx, "other-subject", &kafkaschema.Schema{Schema: `"long"`})
+		_, _ = registry.Register(ctx, testSubject, &kafkaschema.Schema{Schema: `"string"`})
+		data, e := enc.Encode("value")
+		g.Expect(e).To(Succeed(), "encode with registered schema should not fail")
+		g.Expect(data[:5]).To(Equal([]byte{0, 0, 0, 0, 2}), "payload should have correct schema ID")
+
+		// latest schema of the subject is used if schema is not specified
+		enc = kafkaschema.NewAvroEncoder(registry, "other-subject")
+		data, e = enc.Encode(42)
+		g.Expect(e).To(Succeed(), "encode with latest schema should not fail")
+		g.Expect(data).To(Equal([]byte{0, 0, 0, 0, 1, 84}), "payload should be correct")
+	}
+}
+
+func SubTestDecodeWithoutContentType() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		registry := kafkaschema.NewInMemoryRegistry()
+		enc := kafkaschema.NewAvroEncoder(registry, testSubject, kafkaschema.WithSchema(testAvroSchema))
+		data, e := enc.Encode(NewTestOrder())
+		g.Expect(e).To(Succeed(), "encode should not fail")
+
+		decoded, e := DecodeWithDispatcher[*TestOrder](ctx, registry, "", data)
+		g.Expect(e).To(Succeed(), "decode by magic byte should not fail")
+		g.Expect(decoded.Customer).To(Equal("alice"), "decoded payload should be correct")
+
+		_, e = DecodeWithDispatcher[*TestOrder](ctx, registry, "", []byte("not wire format"))
+		g.Expect(errors.Is(e, kafka.ErrorSubTypeDecoding)).To(BeTrue(), "unknown payload should fail to decode")
+	}
+}
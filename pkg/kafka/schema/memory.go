@@ -0,0 +1,173 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// InMemoryRegistry is an in-process schema registry. It implements Client, and also serves a subset of the schema
+// registry REST API as http.Handler, so it can be used with httptest.Server to test NewClient.
+// It's intended for testing, and doesn't check schema compatibility.
+type InMemoryRegistry struct {
+	mtx      sync.RWMutex
+	schemas  []*Schema
+	subjects map[string][]*Schema
+	muxOnce  sync.Once
+	mux      *http.ServeMux
+}
+
+func NewInMemoryRegistry() *InMemoryRegistry {
+	return &InMemoryRegistry{
+		subjects: make(map[string][]*Schema),
+	}
+}
+
+func (r *InMemoryRegistry) Register(_ context.Context, subject string, schema *Schema) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if found := r.find(subject, schema); found != nil {
+		return found.ID, nil
+	}
+	// same schema registered under different subjects shares the same ID
+	id := len(r.schemas) + 1
+	for _, s := range r.schemas {
+		if s.SchemaType() == schema.SchemaType() && s.Schema == schema.Schema {
+			id = s.ID
+			break
+		}
+	}
+	if id > len(r.schemas) {
+		r.schemas = append(r.schemas, &Schema{ID: id, Type: schema.Type, Schema: schema.Schema})
+	}
+	r.subjects[subject] = append(r.subjects[subject], &Schema{
+		ID:      id,
+		Subject: subject,
+		Version: len(r.subjects[subject]) + 1,
+		Type:    schema.Type,
+		Schema:  schema.Schema,
+	})
+	return id, nil
+}
+
+func (r *InMemoryRegistry) Lookup(_ context.Context, subject string, schema *Schema) (*Schema, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if found := r.find(subject, schema); found != nil {
+		return found, nil
+	}
+	if _, ok := r.subjects[subject]; !ok {
+		return nil, RegistryError{StatusCode: http.StatusNotFound, ErrorCode: 40401, Message: "Subject not found."}
+	}
+	return nil, RegistryError{StatusCode: http.StatusNotFound, ErrorCode: 40403, Message: "Schema not found."}
+}
+
+func (r *InMemoryRegistry) SchemaByID(_ context.Context, id int) (*Schema, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if id < 1 || id > len(r.schemas) {
+		return nil, RegistryError{StatusCode: http.StatusNotFound, ErrorCode: 40403, Message: "Schema not found."}
+	}
+	return r.schemas[id-1], nil
+}
+
+func (r *InMemoryRegistry) LatestSchema(_ context.Context, subject string) (*Schema, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	versions := r.subjects[subject]
+	if len(versions) == 0 {
+		return nil, RegistryError{StatusCode: http.StatusNotFound, ErrorCode: 40401, Message: "Subject not found."}
+	}
+	return versions[len(versions)-1], nil
+}
+
+func (r *InMemoryRegistry) find(subject string, schema *Schema) *Schema {
+	for _, s := range r.subjects[subject] {
+		if s.SchemaType() == schema.SchemaType() && s.Schema == schema.Schema {
+			return s
+		}
+	}
+	return nil
+}
+
+/**************************
+	http.Handler
+ **************************/
+
+func (r *InMemoryRegistry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	r.muxOnce.Do(r.initMux)
+	r.mux.ServeHTTP(rw, req)
+}
+
+func (r *InMemoryRegistry) initMux() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /subjects/{subject}/versions", func(rw http.ResponseWriter, req *http.Request) {
+		var schema Schema
+		if !r.decodeRequest(rw, req, &schema) {
+			return
+		}
+		id, e := r.Register(req.Context(), req.PathValue("subject"), &schema)
+		r.writeResponse(rw, map[string]int{"id": id}, e)
+	})
+	mux.HandleFunc("POST /subjects/{subject}", func(rw http.ResponseWriter, req *http.Request) {
+		var schema Schema
+		if !r.decodeRequest(rw, req, &schema) {
+			return
+		}
+		found, e := r.Lookup(req.Context(), req.PathValue("subject"), &schema)
+		r.writeResponse(rw, found, e)
+	})
+	mux.HandleFunc("GET /schemas/ids/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		id, e := strconv.Atoi(req.PathValue("id"))
+		if e != nil {
+			r.writeResponse(rw, nil, RegistryError{StatusCode: http.StatusNotFound, ErrorCode: 40403, Message: "Schema not found."})
+			return
+		}
+		found, e := r.SchemaByID(req.Context(), id)
+		if found != nil {
+			// schema registry doesn't return ID in this endpoint
+			found = &Schema{Type: found.Type, Schema: found.Schema}
+		}
+		r.writeResponse(rw, found, e)
+	})
+	mux.HandleFunc("GET /subjects/{subject}/versions/latest", func(rw http.ResponseWriter, req *http.Request) {
+		found, e := r.LatestSchema(req.Context(), req.PathValue("subject"))
+		r.writeResponse(rw, found, e)
+	})
+	r.mux = mux
+}
+
+func (r *InMemoryRegistry) decodeRequest(rw http.ResponseWriter, req *http.Request, v interface{}) bool {
+	if e := json.NewDecoder(req.Body).Decode(v); e != nil {
+		r.writeResponse(rw, nil, RegistryError{StatusCode: http.StatusUnprocessableEntity, ErrorCode: 42201, Message: e.Error()})
+		return false
+	}
+	return true
+}
+
+func (r *InMemoryRegistry) writeResponse(rw http.ResponseWriter, v interface{}, err error) {
+	rw.Header().Set("Content-Type", mimeTypeRegistry)
+	if regErr, ok := err.(RegistryError); ok {
+		rw.WriteHeader(regErr.StatusCode)
+		v = regErr
+	}
+	_ = json.NewEncoder(rw).Encode(v)
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema
+
+import (
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	"go.uber.org/fx"
+	"net/http"
+	"time"
+)
+
+var Module = &bootstrap.Module{
+	Name:       "kafka-schema-registry",
+	Precedence: bootstrap.KafkaPrecedence,
+	Options: []fx.Option{
+		fx.Provide(BindSchemaRegistryProperties, provideClient),
+		fx.Provide(fx.Annotated{
+			Group:  kafka.FxGroup,
+			Target: provideDecoder,
+		}),
+	},
+	Modules: []*bootstrap.Module{kafka.Module},
+}
+
+func Use() {
+	bootstrap.Register(Module)
+}
+
+/**************************
+	Provider
+***************************/
+
+func provideClient(props SchemaRegistryProperties) Client {
+	return NewClient(func(opt *ClientOption) {
+		opt.URL = props.URL
+		opt.Username = props.Username
+		opt.Password = props.Password
+		opt.HttpClient = &http.Client{Timeout: time.Duration(props.Timeout)}
+		opt.LatestTTL = time.Duration(props.LatestTTL)
+	})
+}
+
+func provideDecoder(client Client) kafka.Decoder {
+	return NewDecoder(client)
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaschema
+
+import (
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	PropertiesPrefix = "kafka.schema-registry"
+)
+
+type SchemaRegistryProperties struct {
+	// URL base URL of the schema registry, e.g. "http://localhost:8081"
+	URL string `json:"url"`
+	// Username and Password for HTTP basic auth. Basic auth is not used if Username is empty
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Timeout of HTTP requests to schema registry
+	Timeout utils.Duration `json:"timeout"`
+	// LatestTTL see ClientOption.LatestTTL
+	LatestTTL utils.Duration `json:"latest-ttl"`
+}
+
+func NewSchemaRegistryProperties() *SchemaRegistryProperties {
+	return &SchemaRegistryProperties{
+		URL:       "http://localhost:8081",
+		Timeout:   utils.Duration(10 * time.Second),
+		LatestTTL: utils.Duration(time.Minute),
+	}
+}
+
+func BindSchemaRegistryProperties(ctx *bootstrap.ApplicationContext) SchemaRegistryProperties {
+	props := NewSchemaRegistryProperties()
+	if err := ctx.Config().Bind(props, PropertiesPrefix); err != nil {
+		panic(errors.Wrap(err, "failed to bind SchemaRegistryProperties"))
+	}
+	return *props
+}
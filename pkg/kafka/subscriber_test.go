@@ -62,6 +62,7 @@ func TestSubscriber(t *testing.T) {
 		test.GomegaSubTest(SubTestSubscriberDispatchWithMetadata(&di), "DispatchWithMetadata"),
 		test.GomegaSubTest(SubTestSubscriberDispatchWithHeaders(&di), "DispatchWithHeaders"),
 		test.GomegaSubTest(SubTestSubscriberDispatchWithErrorResult(&di), "DispatchWithErrorResult"),
+		test.GomegaSubTest(SubTestSubscriberDispatchFromRetryTopic(&di), "DispatchFromRetryTopic"),
 	)
 }
 
@@ -208,6 +209,49 @@ func SubTestSubscriberDispatchWithErrorResult(di *TestSubscriberDI) test.GomegaS
 	}
 }
 
+func SubTestSubscriberDispatchFromRetryTopic(di *TestSubscriberDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const topic = `test-pubsub-retry`
+		var e error
+		var v HandlerParams
+		retryTopic := kafka.RetryTopicName(topic, 1)
+		dlt := kafka.DeadLetterTopicName(topic)
+		testdata.MockExistingTopic(ctx, retryTopic, 0)
+		testdata.MockExistingTopic(ctx, dlt, 0)
+		subscriber := TryBindTestSubscriber(ctx, g, &di.TestBinderDI, topic,
+			kafka.RetryAttempts(2, 10*time.Millisecond, 1), kafka.DeadLetter(true))
+		g.Expect(di.Binder.ListTopics()).To(ContainElements(retryTopic, dlt), "retry topic and dead-letter topic should be bound")
+
+		// add handler
+		ch := make(chan HandlerParams, 1)
+		defer close(ch)
+		e = subscriber.AddHandler(func(ctx context.Context, raw *kafka.Message) error {
+			ch <- HandlerParams{Message: raw, Headers: raw.Headers}
+			return nil
+		})
+		g.Expect(e).To(Succeed(), "adding handler should not fail")
+
+		// retried message targeting other subscriber should be skipped
+		go testdata.MockSubscribedMessage(ctx, retryTopic, 0, 0, MakeMockedMessage(
+			WithValue("other"),
+			WithHeader(kafka.HeaderRetryTarget, "other-subscriber"),
+		))
+		_, e = WaitForHandlerInvocation(ctx, ch, 200*time.Millisecond)
+		g.Expect(e).To(HaveOccurred(), "handler should not be triggered by message targeting other subscriber")
+
+		// retried message should be dispatched to the same handler
+		go testdata.MockSubscribedMessage(ctx, retryTopic, 0, 1, MakeMockedMessage(
+			WithValue("hello"),
+			WithHeader(kafka.HeaderOriginalTopic, topic),
+			WithHeader(kafka.HeaderRetryAttempts, "2"),
+		))
+		v, e = WaitForHandlerInvocation(ctx, ch, 10*time.Second)
+		g.Expect(e).To(Succeed(), "handler should be triggered by message from retry topic")
+		g.Expect(v.Message.Payload).To(BeEquivalentTo("hello"), "payload should be correct")
+		AssertHeaders(g, v.Headers, kafka.HeaderOriginalTopic, topic, kafka.HeaderRetryAttempts, "2")
+	}
+}
+
 /*************************
 	Helpers
  *************************/
@@ -153,3 +153,105 @@ type MockedMessage struct {
 	Value   []byte
 	Headers map[string]string
 }
+
+func MockTopicOffsets(ctx context.Context, topic string, partition int32, oldest, newest int64) {
+	mock := CurrentMockedBroker(ctx)
+	updaters := map[string]MockResponseUpdateFunc{
+		"OffsetRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return mr.(*sarama.MockOffsetResponse).
+				SetOffset(topic, partition, sarama.OffsetOldest, oldest).
+				SetOffset(topic, partition, sarama.OffsetNewest, newest)
+		},
+	}
+	mock.UpdateMocks(updaters)
+}
+
+func MockTimestampOffset(ctx context.Context, topic string, partition int32, timestamp, offset int64) {
+	mock := CurrentMockedBroker(ctx)
+	updaters := map[string]MockResponseUpdateFunc{
+		"OffsetRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return mr.(*sarama.MockOffsetResponse).SetOffset(topic, partition, timestamp, offset)
+		},
+	}
+	mock.UpdateMocks(updaters)
+}
+
+func MockCommittedOffset(ctx context.Context, topic, group string, partition int32, offset int64) {
+	mock := CurrentMockedBroker(ctx)
+	t := mock.t
+	updaters := map[string]MockResponseUpdateFunc{
+		"DescribeAclsRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockListAclsResponse(t)
+		},
+		"FindCoordinatorRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return mr.(*sarama.MockFindCoordinatorResponse).
+				SetCoordinator(sarama.CoordinatorGroup, group, mock.MockBroker)
+		},
+		"OffsetFetchRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return mr.(*sarama.MockOffsetFetchResponse).SetOffset(group, topic, partition, offset, "", sarama.ErrNoError)
+		},
+		"OffsetCommitRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return mr.(*sarama.MockOffsetCommitResponse).SetError(group, topic, partition, sarama.ErrNoError)
+		},
+	}
+	mock.UpdateMocks(updaters)
+}
+
+func MockGroupDescription(ctx context.Context, group string, desc *sarama.GroupDescription) {
+	mock := CurrentMockedBroker(ctx)
+	t := mock.t
+	updaters := map[string]MockResponseUpdateFunc{
+		"DescribeAclsRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockListAclsResponse(t)
+		},
+		"DescribeGroupsRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockDescribeGroupsResponse(t).AddGroupDescription(group, desc)
+		},
+	}
+	mock.UpdateMocks(updaters)
+}
+
+// MockTransaction mocks transaction coordinator of given transactional IDs, as well as transactional requests.
+// Requests sent by transactional producers can be inspected via sarama.MockBroker's History
+func MockTransaction(ctx context.Context, txIDs ...string) {
+	mock := CurrentMockedBroker(ctx)
+	t := mock.t
+	updaters := map[string]MockResponseUpdateFunc{
+		"FindCoordinatorRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			resp := mr.(*sarama.MockFindCoordinatorResponse)
+			for _, id := range txIDs {
+				resp = resp.SetCoordinator(sarama.CoordinatorTransaction, id, mock.MockBroker)
+			}
+			return resp
+		},
+		"InitProducerIDRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockInitProducerIDResponse(t).SetProducerID(1000)
+		},
+		"AddOffsetsToTxnRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockWrapper(&sarama.AddOffsetsToTxnResponse{Err: sarama.ErrNoError})
+		},
+		"TxnOffsetCommitRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockWrapper(&sarama.TxnOffsetCommitResponse{})
+		},
+		"EndTxnRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockWrapper(&sarama.EndTxnResponse{Err: sarama.ErrNoError})
+		},
+	}
+	mock.UpdateMocks(updaters)
+}
+
+// MockTransactionalProduce mocks producing to given topic within a transaction
+func MockTransactionalProduce(ctx context.Context, topic string) {
+	mock := CurrentMockedBroker(ctx)
+	updaters := map[string]MockResponseUpdateFunc{
+		"AddPartitionsToTxnRequest": func(mr sarama.MockResponse) sarama.MockResponse {
+			return sarama.NewMockWrapper(&sarama.AddPartitionsToTxnResponse{
+				Errors: map[string][]*sarama.PartitionError{
+					topic: {{Partition: 0, Err: sarama.ErrNoError}},
+				},
+			})
+		},
+	}
+	mock.UpdateMocks(updaters)
+	MockProduce(ctx, topic, false)
+}
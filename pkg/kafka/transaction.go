@@ -0,0 +1,227 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/cisco-open/go-lanai/pkg/utils"
+	"sync"
+	"time"
+)
+
+type txContextKey struct{}
+
+// TransactionFromContext returns the ongoing Transaction carried by given context, if any
+func TransactionFromContext(ctx context.Context) (Transaction, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txContextKey{}).(Transaction)
+	return tx, ok
+}
+
+// saramaTransaction implements Transaction
+type saramaTransaction struct {
+	context.Context
+	id       string
+	producer sarama.SyncProducer
+}
+
+func (tx *saramaTransaction) Value(key any) any {
+	if _, ok := key.(txContextKey); ok {
+		return tx
+	}
+	return tx.Context.Value(key)
+}
+
+func (tx *saramaTransaction) TransactionalID() string {
+	return tx.id
+}
+
+func (tx *saramaTransaction) AddOffset(group string, topic string, partition int32, offset int64) error {
+	offsets := map[string][]*sarama.PartitionOffsetMetadata{
+		topic: {{Partition: partition, Offset: offset + 1}},
+	}
+	if e := tx.producer.AddOffsetsToTxn(offsets, group); e != nil {
+		return NewKafkaError(ErrorSubTypeCodeProducerGeneral, fmt.Sprintf("unable to add offset to transaction: %v", e), e)
+	}
+	return nil
+}
+
+// saramaTransactionManager maintains a fixed size pool of transactional sarama.SyncProducer.
+// Each pooled producer has a transactional ID of "<prefix><index>" and can run one transaction at a time.
+type saramaTransactionManager struct {
+	mtx       sync.Mutex
+	brokers   []string
+	config    sarama.Config
+	available chan string
+	producers map[string]sarama.SyncProducer
+	closed    bool
+}
+
+func newSaramaTransactionManager(addrs []string, config *bindingConfig, props *TransactionProperties) *saramaTransactionManager {
+	cfg := config.sarama // make a copy
+	cfg.Producer.Idempotent = true
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.Return.Errors = true
+	cfg.Producer.Partitioner = func(topic string) sarama.Partitioner {
+		return sarama.NewRandomPartitioner(topic)
+	}
+	cfg.Net.MaxOpenRequests = 1
+	if cfg.Producer.Retry.Max < 1 {
+		cfg.Producer.Retry.Max = 1
+	}
+	if props.Timeout > 0 {
+		cfg.Producer.Transaction.Timeout = time.Duration(props.Timeout)
+	}
+
+	prefix := props.IDPrefix
+	if len(prefix) == 0 {
+		// Note: without configured prefix, each instance need its own transactional IDs
+		prefix = fmt.Sprintf("%s-%s-", cfg.ClientID, utils.RandomString(8))
+	}
+	size := props.PoolSize
+	if size < 1 {
+		size = 1
+	}
+	m := &saramaTransactionManager{
+		brokers:   addrs,
+		config:    cfg,
+		available: make(chan string, size),
+		producers: make(map[string]sarama.SyncProducer),
+	}
+	for i := 0; i < size; i++ {
+		m.available <- fmt.Sprintf("%s%d", prefix, i)
+	}
+	return m
+}
+
+// execute run given TransactionFunc within a transaction. If given context already carries a Transaction,
+// the function joins the ongoing transaction.
+func (m *saramaTransactionManager) execute(ctx context.Context, fn TransactionFunc) (err error) {
+	if tx, ok := TransactionFromContext(ctx); ok {
+		return fn(tx)
+	}
+
+	tx, e := m.begin(ctx)
+	if e != nil {
+		return e
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			m.rollback(tx)
+			panic(r)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		m.rollback(tx)
+		return
+	}
+	return m.commit(tx)
+}
+
+func (m *saramaTransactionManager) begin(ctx context.Context) (*saramaTransaction, error) {
+	var id string
+	select {
+	case id = <-m.available:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p, e := m.producer(id)
+	if e == nil {
+		e = p.BeginTxn()
+	}
+	if e != nil {
+		m.release(id, p, true)
+		return nil, translateSaramaBindingError(e, "unable to begin transaction: %v", e)
+	}
+	return &saramaTransaction{
+		Context:  ctx,
+		id:       id,
+		producer: p,
+	}, nil
+}
+
+func (m *saramaTransactionManager) commit(tx *saramaTransaction) error {
+	if e := tx.producer.CommitTxn(); e != nil {
+		if tx.producer.TxnStatus()&sarama.ProducerTxnFlagAbortableError != 0 {
+			_ = tx.producer.AbortTxn()
+		}
+		m.release(tx.id, tx.producer, true)
+		return NewKafkaError(ErrorSubTypeCodeProducerGeneral, fmt.Sprintf("unable to commit transaction: %v", e), e)
+	}
+	m.release(tx.id, tx.producer, false)
+	return nil
+}
+
+func (m *saramaTransactionManager) rollback(tx *saramaTransaction) {
+	e := tx.producer.AbortTxn()
+	if e != nil {
+		logger.WithContext(tx.Context).Warnf("unable to abort transaction [%s]: %v", tx.id, e)
+	}
+	m.release(tx.id, tx.producer, e != nil)
+}
+
+// producer returns existing or creates new transactional producer of given transactional ID
+func (m *saramaTransactionManager) producer(id string) (sarama.SyncProducer, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.closed {
+		return nil, ErrorSubTypeIllegalProducerUsage.WithMessage("transaction manager is closed")
+	}
+	if p, ok := m.producers[id]; ok {
+		return p, nil
+	}
+	cfg := m.config // make a copy
+	cfg.Producer.Transaction.ID = id
+	p, e := sarama.NewSyncProducer(m.brokers, &cfg)
+	if e != nil {
+		return nil, e
+	}
+	m.producers[id] = p
+	return p, nil
+}
+
+// release return the transactional ID to the pool. The producer is discarded if it encountered fatal error.
+func (m *saramaTransactionManager) release(id string, p sarama.SyncProducer, discard bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if p != nil && (discard || p.TxnStatus()&sarama.ProducerTxnFlagFatalError != 0) {
+		_ = p.Close()
+		delete(m.producers, id)
+	}
+	m.available <- id
+}
+
+func (m *saramaTransactionManager) Close() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.closed = true
+	var err error
+	for id, p := range m.producers {
+		if e := p.Close(); e != nil {
+			err = NewKafkaError(ErrorCodeIllegalState, "error when closing transactional producer: %v", e)
+		}
+		delete(m.producers, id)
+	}
+	return err
+}
@@ -0,0 +1,285 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"github.com/IBM/sarama"
+	"github.com/cisco-open/go-lanai/pkg/kafka"
+	"github.com/cisco-open/go-lanai/pkg/kafka/testdata"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const (
+	txTopic    = `test-tx-consumer`
+	txOutTopic = `test-tx-output`
+	txGroup    = `test.tx.group`
+	txID       = `test-tx-0`
+)
+
+func ProvideTestTxConsumer(binder kafka.Binder, lc fx.Lifecycle) (kafka.GroupConsumer, *TestTxHandler, error) {
+	consumer, e := binder.Consume(txTopic, txGroup, kafka.ExactlyOnce())
+	if e != nil {
+		return nil, nil, e
+	}
+	handler := &TestTxHandler{
+		CH: make(chan TxHandlerParams, 2),
+	}
+	lc.Append(fx.StopHook(func(context.Context) { close(handler.CH) }))
+	return consumer, handler, consumer.AddHandler(handler.HandleFunc)
+}
+
+type TxHandlerParams struct {
+	HandlerParams
+	TxID string
+}
+
+// TestTxHandler forwards received payload to Producer if set, and fails the first "Failures" invocations
+type TestTxHandler struct {
+	mtx      sync.Mutex
+	CH       chan TxHandlerParams
+	Producer kafka.Producer
+	Failures int
+}
+
+func (h *TestTxHandler) SetProducer(p kafka.Producer) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.Producer = p
+}
+
+func (h *TestTxHandler) SetFailures(n int) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.Failures = n
+}
+
+func (h *TestTxHandler) HandleFunc(ctx context.Context, raw *kafka.Message, meta *kafka.MessageMetadata) error {
+	params := TxHandlerParams{HandlerParams: HandlerParams{Message: raw, Metadata: meta}}
+	if tx, ok := kafka.TransactionFromContext(ctx); ok {
+		params.TxID = tx.TransactionalID()
+	}
+	h.CH <- params
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.Producer != nil {
+		if e := h.Producer.Send(ctx, raw.Payload); e != nil {
+			return e
+		}
+	}
+	if h.Failures > 0 {
+		h.Failures--
+		return errors.New("oops")
+	}
+	return nil
+}
+
+/*************************
+	Tests
+ *************************/
+
+type TestTxDI struct {
+	fx.In
+	TestBinderDI
+	Consumer kafka.GroupConsumer
+	Handler  *TestTxHandler
+}
+
+func TestTransaction(t *testing.T) {
+	di := TestTxDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithTimeout(60*time.Second),
+		testdata.WithMockedBroker(),
+		apptest.WithModules(kafka.Module),
+		apptest.WithProperties(
+			"kafka.transaction.enabled: true",
+			"kafka.transaction.id-prefix: test-tx-",
+			"kafka.transaction.pool-size: 1",
+		),
+		apptest.WithFxOptions(
+			fx.Provide(ProvideTestTxConsumer),
+		),
+		apptest.WithDI(&di),
+		test.SubTestSetup(SubSetupStartBinder(&di.TestBinderDI)),
+		test.SubTestSetup(SubSetupMockTransaction()),
+		test.GomegaSubTest(SubTestTransactionCommit(&di), "TestCommit"),
+		test.GomegaSubTest(SubTestTransactionAbort(&di), "TestAbort"),
+		test.GomegaSubTest(SubTestTransactionPool(&di), "TestProducerPool"),
+		test.GomegaSubTest(SubTestExactlyOnceRedelivery(&di), "TestExactlyOnceRedelivery"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubSetupMockTransaction() test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		testdata.MockTransaction(ctx, txID)
+		return ctx, nil
+	}
+}
+
+func SubTestTransactionCommit(di *TestTxDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		var txIDs []string
+		e := di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+			current, ok := kafka.TransactionFromContext(tx)
+			g.Expect(ok).To(BeTrue(), "transaction should be available from its context")
+			g.Expect(current).To(BeIdenticalTo(tx), "transaction from context should be correct")
+			txIDs = append(txIDs, tx.TransactionalID())
+			// nested transaction should join the ongoing one
+			return di.Binder.Transaction(tx, func(nested kafka.Transaction) error {
+				txIDs = append(txIDs, nested.TransactionalID())
+				g.Expect(nested).To(BeIdenticalTo(tx), "nested transaction should join the ongoing one")
+				return nil
+			})
+		})
+		g.Expect(e).To(Succeed(), "transaction should be committed")
+		g.Expect(txIDs).To(Equal([]string{txID, txID}), "transactional ID should be correct")
+		_, ok := kafka.TransactionFromContext(ctx)
+		g.Expect(ok).To(BeFalse(), "transaction should not leak into outer context")
+	}
+}
+
+func SubTestTransactionAbort(di *TestTxDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		expected := errors.New("oops")
+		e := di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+			return expected
+		})
+		g.Expect(e).To(MatchError(expected), "transaction should be aborted with the function's error")
+
+		// transactional ID should be returned to the pool after abort
+		e = di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+			g.Expect(tx.TransactionalID()).To(Equal(txID), "transactional ID should be re-used")
+			return nil
+		})
+		g.Expect(e).To(Succeed(), "transaction after abort should be committed")
+	}
+}
+
+func SubTestTransactionPool(di *TestTxDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		// occupy the only transactional producer
+		started := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+				close(started)
+				<-release
+				return nil
+			})
+		}()
+		<-started
+
+		timeoutCtx, cancelFn := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancelFn()
+		e := di.Binder.Transaction(timeoutCtx, func(tx kafka.Transaction) error {
+			return nil
+		})
+		g.Expect(e).To(MatchError(context.DeadlineExceeded), "transaction should wait for available producer")
+
+		close(release)
+		g.Expect(<-done).To(Succeed(), "occupying transaction should be committed")
+		e = di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+			return nil
+		})
+		g.Expect(e).To(Succeed(), "transaction should succeed after producer is released")
+
+		// the pooled producer should be re-used
+		initReqs := FilterMockedRequests[*sarama.InitProducerIDRequest](ctx)
+		g.Expect(initReqs).To(HaveLen(1), "transactional producer should be created only once")
+		g.Expect(initReqs[0].TransactionalID).ToNot(BeNil(), "producer should be transactional")
+		g.Expect(*initReqs[0].TransactionalID).To(Equal(txID), "transactional ID should be correct")
+	}
+}
+
+func SubTestExactlyOnceRedelivery(di *TestTxDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		var v TxHandlerParams
+		testdata.MockExistingTopic(ctx, txTopic, 0)
+		testdata.MockGroup(ctx, txTopic, txGroup, 0)
+		testdata.MockCreateTopic(ctx, txOutTopic)
+		testdata.MockTransactionalProduce(ctx, txOutTopic)
+		producer, e := di.Binder.Produce(txOutTopic)
+		g.Expect(e).To(Succeed(), "bind producer should not fail")
+		di.Handler.SetProducer(producer)
+		di.Handler.SetFailures(1)
+
+		go testdata.MockGroupMessage(ctx, txTopic, txGroup, 0, 0, MakeMockedMessage(WithValue([]byte("binary"))))
+		v, e = WaitForHandlerInvocation(ctx, di.Handler.CH, 50*time.Second)
+		g.Expect(e).To(Succeed(), "handler should be triggered")
+		g.Expect(v.TxID).To(Equal(txID), "handler should be invoked within transaction")
+		AssertMetadata(g, v.Metadata, 0, 0, nil)
+
+		// failed message should be re-delivered after the transaction is aborted
+		v, e = WaitForHandlerInvocation(ctx, di.Handler.CH, 50*time.Second)
+		g.Expect(e).To(Succeed(), "handler should be re-triggered")
+		g.Expect(v.TxID).To(Equal(txID), "handler should be invoked within transaction")
+		AssertMetadata(g, v.Metadata, 0, 0, nil)
+
+		// first transaction should be aborted, the second one should be committed
+		g.Eventually(func() []*sarama.EndTxnRequest {
+			return FilterMockedRequests[*sarama.EndTxnRequest](ctx)
+		}).WithTimeout(5*time.Second).Should(HaveLen(2), "both transactions should be ended")
+		endReqs := FilterMockedRequests[*sarama.EndTxnRequest](ctx)
+		g.Expect(endReqs[0].TransactionResult).To(BeFalse(), "transaction of failed message should be aborted")
+		g.Expect(endReqs[1].TransactionResult).To(BeTrue(), "transaction of re-delivered message should be committed")
+		produceReqs := FilterMockedRequests[*sarama.ProduceRequest](ctx)
+		g.Expect(produceReqs).To(HaveLen(2), "message should be produced within both transactions")
+		for _, req := range produceReqs {
+			g.Expect(req.TransactionalID).ToNot(BeNil(), "message should be produced by transactional producer")
+			g.Expect(*req.TransactionalID).To(Equal(txID), "message should be produced by transactional producer")
+		}
+
+		// consumed offset should be committed within the transaction, only after success
+		offsetReqs := FilterMockedRequests[*sarama.TxnOffsetCommitRequest](ctx)
+		g.Expect(offsetReqs).To(HaveLen(1), "consumed offset should be committed once")
+		g.Expect(offsetReqs[0].GroupID).To(Equal(txGroup), "offset should be committed with correct group")
+		g.Expect(offsetReqs[0].Topics).To(HaveKeyWithValue(txTopic, ContainElement(
+			HaveField("Offset", BeEquivalentTo(1)),
+		)), "next offset should be committed")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func FilterMockedRequests[T any](ctx context.Context) (ret []T) {
+	for _, rr := range testdata.CurrentMockedBroker(ctx).History() {
+		if req, ok := rr.Request.(T); ok {
+			ret = append(ret, req)
+		}
+	}
+	return
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migration
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+// untouchableVersioner fails any access to the database
+type untouchableVersioner struct {
+	accessed bool
+}
+
+func (v *untouchableVersioner) CreateVersionTableIfNotExist(_ context.Context) error {
+	v.accessed = true
+	return nil
+}
+
+func (v *untouchableVersioner) GetAppliedMigrations(_ context.Context) ([]AppliedMigration, error) {
+	v.accessed = true
+	return nil, nil
+}
+
+func (v *untouchableVersioner) RecordAppliedMigration(_ context.Context, _ Version, _ string, _ bool, _ time.Time, _ time.Duration) error {
+	v.accessed = true
+	return nil
+}
+
+func (v *untouchableVersioner) RemoveMigration(_ context.Context, _ Version) error {
+	v.accessed = true
+	return nil
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestMigrateFlags(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestDryRunWithRepair(), "TestDryRunWithRepair"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestDryRunWithRepair() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		dryRunFlag, repairFlag = true, true
+		defer func() { dryRunFlag, repairFlag = false, false }()
+
+		v := &untouchableVersioner{}
+		e := Migrate(ctx, NewRegistrar(), v)
+		g.Expect(e).To(HaveOccurred(), "dry run combined with repair should fail")
+		g.Expect(v.accessed).To(BeFalse(), "database should not be accessed")
+	}
+}
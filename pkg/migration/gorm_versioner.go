@@ -28,6 +28,7 @@ type MigrationVersion struct {
 	ExecutionTime time.Duration
 	InstalledOn   time.Time
 	Success       bool
+	Checksum      string
 }
 
 func (v MigrationVersion) GetVersion() Version {
@@ -46,6 +47,19 @@ func (v MigrationVersion) GetInstalledOn() time.Time {
 	return v.InstalledOn
 }
 
+func (v MigrationVersion) GetChecksum() string {
+	return v.Checksum
+}
+
+// MigrationUndo is the record of a rolled back migration step
+type MigrationUndo struct {
+	ID            uint    `gorm:"primaryKey"`
+	Version       Version `gorm:"index"`
+	Description   string
+	ExecutionTime time.Duration
+	RolledBackOn  time.Time
+	Success       bool
+}
 
 type GormVersioner struct {
 	db          *gorm.DB
@@ -61,9 +75,14 @@ func (v *GormVersioner) CreateVersionTableIfNotExist(ctx context.Context) error
 	return v.db.WithContext(ctx).AutoMigrate(&MigrationVersion{})
 }
 
+// GetAppliedMigrations returns empty result if version table doesn't exist
 func (v *GormVersioner) GetAppliedMigrations(ctx context.Context) ([]AppliedMigration, error) {
+	db := v.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&MigrationVersion{}) {
+		return []AppliedMigration{}, nil
+	}
 	versions := []MigrationVersion{}
-	result := v.db.WithContext(ctx).Find(&versions)
+	result := db.Find(&versions)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -76,13 +95,52 @@ func (v *GormVersioner) GetAppliedMigrations(ctx context.Context) ([]AppliedMigr
 }
 
 func (v *GormVersioner) RecordAppliedMigration(ctx context.Context, version Version, description string, success bool, installedOn time.Time, executionTime time.Duration) error {
+	return v.RecordAppliedMigrationWithChecksum(ctx, version, description, "", success, installedOn, executionTime)
+}
+
+func (v *GormVersioner) RecordAppliedMigrationWithChecksum(ctx context.Context, version Version, description string, checksum string, success bool, installedOn time.Time, executionTime time.Duration) error {
 	applied := &MigrationVersion{
 		Version:       version,
 		Description:   description,
+		Checksum:      checksum,
 		Success:       success,
 		InstalledOn:   installedOn,
 		ExecutionTime: executionTime,
 	}
 	result := v.db.WithContext(ctx).Save(applied)
 	return result.Error
-}
\ No newline at end of file
+}
+
+// RecordRolledBackMigration records the undo entry. On success, the applied version is removed, so it can be re-applied.
+// Otherwise, the applied version is marked as failed.
+// Note: undo table is created on demand, because rollback is rarely used.
+func (v *GormVersioner) RecordRolledBackMigration(ctx context.Context, version Version, description string, success bool, rolledBackOn time.Time, executionTime time.Duration) error {
+	db := v.db.WithContext(ctx)
+	if e := db.AutoMigrate(&MigrationUndo{}); e != nil {
+		return e
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		undo := &MigrationUndo{
+			Version:       version,
+			Description:   description,
+			Success:       success,
+			RolledBackOn:  rolledBackOn,
+			ExecutionTime: executionTime,
+		}
+		if e := tx.Create(undo).Error; e != nil {
+			return e
+		}
+		if success {
+			return tx.Delete(&MigrationVersion{Version: version}).Error
+		}
+		return tx.Model(&MigrationVersion{Version: version}).Update("Success", false).Error
+	})
+}
+
+func (v *GormVersioner) RemoveMigration(ctx context.Context, version Version) error {
+	return v.db.WithContext(ctx).Delete(&MigrationVersion{Version: version}).Error
+}
+
+func (v *GormVersioner) UpdateChecksum(ctx context.Context, version Version, checksum string) error {
+	return v.db.WithContext(ctx).Model(&MigrationVersion{Version: version}).Update("Checksum", checksum).Error
+}
@@ -42,6 +42,10 @@ type Migration struct {
 	Version     Version
 	Description string
 	Func		MigrationFunc
+	// RollbackFunc reverses Func. Optional, but required for rolling back to any version prior to this step
+	RollbackFunc MigrationFunc
+	// Checksum of the migration file, used to detect changes of applied steps. Empty if the step is not file based
+	Checksum    string
 	Tags        utils.StringSet
 }
 
@@ -70,7 +74,7 @@ func (m *Migration) WithTag(tags...string) *Migration {
 }
 
 func (m *Migration) WithFile(fs fs.FS, filePath string, db *gorm.DB) *Migration {
-	m.Func = migrationFuncFromTextFile(fs, filePath, db)
+	m.Func, m.Checksum = migrationFuncFromTextFile(fs, filePath, db)
 	return m
 }
 
@@ -79,6 +83,16 @@ func (m *Migration) WithFunc(f MigrationFunc) *Migration {
 	return m
 }
 
+func (m *Migration) WithRollbackFile(fs fs.FS, filePath string, db *gorm.DB) *Migration {
+	m.RollbackFunc, _ = migrationFuncFromTextFile(fs, filePath, db)
+	return m
+}
+
+func (m *Migration) WithRollbackFunc(f MigrationFunc) *Migration {
+	m.RollbackFunc = f
+	return m
+}
+
 func (m *Migration) WithDesc(d string) *Migration {
 	m.Description = d
 	return m
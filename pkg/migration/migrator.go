@@ -22,21 +22,105 @@ import (
     "fmt"
     "github.com/cisco-open/go-lanai/pkg/utils"
     "sort"
+    "strings"
     "time"
 )
 
+// PlannedStep is a migration step that is pending to be applied or rolled back
+type PlannedStep struct {
+	*Migration
+	Rollback bool
+}
+
+func (s PlannedStep) String() string {
+	if s.Rollback {
+		return fmt.Sprintf("rollback %s: %s", s.Version.String(), s.Description)
+	}
+	return fmt.Sprintf("apply %s: %s", s.Version.String(), s.Description)
+}
+
+// Migrate executes registered migration steps that are not applied yet.
+// Its behavior can be changed by command line flags:
+// - "target": migrates up or down to the target version, see MigrateTo
+// - "repair": repairs the migration records instead of migrating, see Repair
+// - "dry_run": logs pending steps without executing them, see Plan. It cannot be combined with "repair"
 func Migrate(ctx context.Context, r *Registrar, v Versioner) error {
+	// dry run is executed without migration lock and must not change the database
+	if dryRunFlag && repairFlag {
+		return fmt.Errorf(`"dry_run" cannot be combined with "repair"`)
+	}
+	var target Version
+	if targetFlag != "" {
+		var err error
+		if target, err = fromString(targetFlag); err != nil {
+			return fmt.Errorf("invalid target version [%s]: %v", targetFlag, err)
+		}
+	}
+
+	switch {
+	case repairFlag:
+		return Repair(ctx, r, v)
+	case dryRunFlag:
+		steps, err := Plan(ctx, r, v, target)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Dry run: %d pending migration steps", len(steps))
+		for _, s := range steps {
+			logger.Infof("Pending migration step: %v", s)
+		}
+		return nil
+	default:
+		return MigrateTo(ctx, r, v, target)
+	}
+}
+
+// MigrateTo migrates up or down to the target version. nil target means the latest version.
+// If any applied step is newer than the target version, applied steps are reversed in descending order until the target
+// version is reached. Rollback would not start if any of those steps has no RollbackFunc.
+// Otherwise, steps that are not applied yet are executed up to the target version.
+func MigrateTo(ctx context.Context, r *Registrar, v Versioner, target Version) error {
 	err := v.CreateVersionTableIfNotExist(ctx)
 	if err != nil {
 		return err
 	}
 
+	steps, err := Plan(ctx, r, v, target)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range steps {
+		// stop before next step if the context is cancelled, e.g. migration lock is lost
+		if ctx.Err() != nil {
+			return fmt.Errorf("migration stopped before %v: %v", s, context.Cause(ctx))
+		}
+		//TODO: should the migration func and recording the version be put in one transaction?
+		if s.Rollback {
+			// Plan makes sure v supports rollback
+			err = rollbackStep(ctx, s.Migration, v.(RollbackVersioner))
+		} else {
+			err = applyStep(ctx, s.Migration, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plan returns pending steps that MigrateTo would execute with the same target version, without executing them.
+// An error is returned if the migration cannot proceed, e.g. a failed step is recorded or an applied file-based step
+// is changed (checksum mismatch).
+// Plan has no side effect, the version table is not created. Versioner.GetAppliedMigrations is expected to return
+// empty result if the version table doesn't exist yet.
+func Plan(ctx context.Context, r *Registrar, v Versioner, target Version) ([]PlannedStep, error) {
 	//sort registered migration steps
 	sort.SliceStable(r.migrationSteps, func(i, j int) bool {return r.migrationSteps[i].Version.Lt(r.migrationSteps[j].Version)})
 
 	appliedMigrations, err := v.GetAppliedMigrations(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	//sort applied migration steps
@@ -44,10 +128,21 @@ func Migrate(ctx context.Context, r *Registrar, v Versioner) error {
 
 	for _, a := range appliedMigrations {
 		if !a.IsSuccess() {
-			return errors.New(fmt.Sprintf("stopping migration because there is a failed migration step: %s", a.GetVersion().String()))
+			return nil, errors.New(fmt.Sprintf("stopping migration because there is a failed migration step: %s", a.GetVersion().String()))
 		}
 	}
 
+	if err := validateChecksums(r, appliedMigrations); err != nil {
+		return nil, err
+	}
+
+	if target != nil && len(appliedMigrations) > 0 && target.Lt(appliedMigrations[len(appliedMigrations)-1].GetVersion()) {
+		if _, ok := v.(RollbackVersioner); !ok {
+			return nil, fmt.Errorf("cannot rollback to version %v because rollback is not supported by versioner %T", target, v)
+		}
+		return planRollback(r, appliedMigrations, target)
+	}
+
 	var shouldExecuteMigration func(*Migration) bool
 
 	if allowOutOfOrderFlag {
@@ -68,32 +163,153 @@ func Migrate(ctx context.Context, r *Registrar, v Versioner) error {
 		}
 	}
 
+	var steps []PlannedStep
 	for _, s := range r.migrationSteps {
+		if target != nil && target.Lt(s.Version) {
+			break
+		}
 		if filterFlag != "" && !s.Tags.Has(filterFlag) {
 			continue
 		}
-		//TODO: should the migration func and recording the version be put in one transaction?
 		if shouldExecuteMigration(s) {
-			logger.Infof("Executing migration step %s: %s", s.Version.String(), s.Description)
-			startTime := time.Now()
-			migrationErr := s.Func(ctx) //TODO: manual rollback function?
-			finishTime := time.Now()
-			duration := finishTime.Sub(startTime)
-			if migrationErr != nil {
-				err = v.RecordAppliedMigration(ctx, s.Version, s.Description, false, finishTime, duration)
-				if err != nil {
-					logger.Errorf("error recording failed migration version due to %v", err)
-				}
-				err = errors.New(fmt.Sprintf("migration stopped at step %v because of error: %v", s.Version, migrationErr))
-				logger.Errorf("%v", err)
+			steps = append(steps, PlannedStep{Migration: s})
+		}
+	}
+	return steps, nil
+}
+
+// Repair removes records of failed migration steps, so they can be executed again,
+// and realigns checksums of applied steps with registered steps.
+// Note: a step failed during rollback is also recorded as failed, and its record is removed as well. Such step is then
+// considered not applied and would be executed again by next migration. Make sure the partially rolled back changes
+// are cleaned up manually before repairing.
+// The Versioner is required to implement RepairVersioner. Checksums are realigned only if it implements ChecksumVersioner.
+func Repair(ctx context.Context, r *Registrar, v Versioner) error {
+	rv, ok := v.(RepairVersioner)
+	if !ok {
+		return fmt.Errorf("repair is not supported by versioner %T", v)
+	}
+	cv, _ := v.(ChecksumVersioner)
+
+	err := v.CreateVersionTableIfNotExist(ctx)
+	if err != nil {
+		return err
+	}
+
+	appliedMigrations, err := v.GetAppliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	registered := registeredSteps(r)
+	for _, a := range appliedMigrations {
+		ver := a.GetVersion()
+		if !a.IsSuccess() {
+			logger.Infof("Repair: removing failed migration step %s", ver.String())
+			if err := rv.RemoveMigration(ctx, ver); err != nil {
+				return err
+			}
+			continue
+		}
+		if s, ok := registered[ver.String()]; ok && cv != nil && s.Checksum != "" && s.Checksum != checksumOf(a) {
+			logger.Infof("Repair: updating checksum of migration step %s", ver.String())
+			if err := cv.UpdateChecksum(ctx, ver, s.Checksum); err != nil {
 				return err
-			} else {
-				err = v.RecordAppliedMigration(ctx, s.Version, s.Description, true, finishTime, duration)
-				if err != nil {
-					return err
-				}
 			}
 		}
 	}
 	return nil
 }
+
+func applyStep(ctx context.Context, s *Migration, v Versioner) error {
+	logger.Infof("Executing migration step %s: %s", s.Version.String(), s.Description)
+	startTime := time.Now()
+	migrationErr := s.Func(ctx)
+	finishTime := time.Now()
+	duration := finishTime.Sub(startTime)
+	if migrationErr != nil {
+		err := recordAppliedMigration(ctx, v, s, false, finishTime, duration)
+		if err != nil {
+			logger.Errorf("error recording failed migration version due to %v", err)
+		}
+		err = errors.New(fmt.Sprintf("migration stopped at step %v because of error: %v", s.Version, migrationErr))
+		logger.Errorf("%v", err)
+		return err
+	}
+	return recordAppliedMigration(ctx, v, s, true, finishTime, duration)
+}
+
+func recordAppliedMigration(ctx context.Context, v Versioner, s *Migration, success bool, installedOn time.Time, executionTime time.Duration) error {
+	if cv, ok := v.(ChecksumVersioner); ok {
+		return cv.RecordAppliedMigrationWithChecksum(ctx, s.Version, s.Description, s.Checksum, success, installedOn, executionTime)
+	}
+	return v.RecordAppliedMigration(ctx, s.Version, s.Description, success, installedOn, executionTime)
+}
+
+func rollbackStep(ctx context.Context, s *Migration, v RollbackVersioner) error {
+	logger.Infof("Rolling back migration step %s: %s", s.Version.String(), s.Description)
+	startTime := time.Now()
+	rollbackErr := s.RollbackFunc(ctx)
+	finishTime := time.Now()
+	duration := finishTime.Sub(startTime)
+	if rollbackErr != nil {
+		if err := v.RecordRolledBackMigration(ctx, s.Version, s.Description, false, finishTime, duration); err != nil {
+			logger.Errorf("error recording failed rollback due to %v", err)
+		}
+		err := fmt.Errorf("rollback stopped at step %v because of error: %v", s.Version, rollbackErr)
+		logger.Errorf("%v", err)
+		return err
+	}
+	return v.RecordRolledBackMigration(ctx, s.Version, s.Description, true, finishTime, duration)
+}
+
+// planRollback plans reversing applied migration steps that are newer than target version, in descending order.
+// "applied" should be sorted in ascending order
+func planRollback(r *Registrar, applied []AppliedMigration, target Version) ([]PlannedStep, error) {
+	registered := registeredSteps(r)
+
+	// make sure all steps can be rolled back before start
+	var steps []PlannedStep
+	var irreversible []string
+	for i := len(applied) - 1; i >= 0 && target.Lt(applied[i].GetVersion()); i-- {
+		ver := applied[i].GetVersion().String()
+		if s, ok := registered[ver]; !ok || s.RollbackFunc == nil {
+			irreversible = append(irreversible, ver)
+		} else {
+			steps = append(steps, PlannedStep{Migration: s, Rollback: true})
+		}
+	}
+	if len(irreversible) != 0 {
+		return nil, fmt.Errorf("cannot rollback to version %v because following steps have no rollback: %s", target, strings.Join(irreversible, ", "))
+	}
+	return steps, nil
+}
+
+// validateChecksums makes sure applied file-based steps are not changed.
+// Steps without recorded checksum (e.g. applied before checksum is supported) are not validated.
+func validateChecksums(r *Registrar, applied []AppliedMigration) error {
+	registered := registeredSteps(r)
+	var mismatched []string
+	for _, a := range applied {
+		s, ok := registered[a.GetVersion().String()]
+		if !ok || s.Checksum == "" || checksumOf(a) == "" {
+			continue
+		}
+		if s.Checksum != checksumOf(a) {
+			mismatched = append(mismatched, a.GetVersion().String())
+		}
+	}
+	if len(mismatched) != 0 {
+		return fmt.Errorf("checksum mismatch of applied migration steps: %s. Hint: use 'repair' flag if the changes are intended",
+			strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+func registeredSteps(r *Registrar) map[string]*Migration {
+	registered := map[string]*Migration{}
+	for _, s := range r.migrationSteps {
+		registered[s.Version.String()] = s
+	}
+	return registered
+}
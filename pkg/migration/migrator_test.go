@@ -72,6 +72,7 @@ func TestMigrate(t *testing.T) {
 		test.SubTestSetup(SetupDropMigrationTable(&di.DI)),
 		test.GomegaSubTest(SubTestMigrateSuccess(&di), "TestMigrateSuccess"),
 		test.GomegaSubTest(SubTestMigrateFailAndResume(&di), "TestMigrateFailAndResume"),
+		test.GomegaSubTest(SubTestPlanWithoutSideEffect(&di), "TestPlanWithoutSideEffect"),
 	)
 }
 
@@ -174,6 +175,21 @@ func SubTestMigrateFailAndResume(di *TestMigrateDI) test.GomegaSubTestFunc {
 	}
 }
 
+func SubTestPlanWithoutSideEffect(di *TestMigrateDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		reg := migration.NewRegistrar()
+		ver := migration.NewGormVersioner(di.DB)
+		reg.AddMigrations(
+			migration.WithVersion("1.0.0").Dot(1).WithTag(migration.TagPreUpgrade).
+				WithDesc("Step 1 - Create table from SQL file").WithFile(TestStepsFS, "testdata/test.sql", di.DB),
+		)
+		steps, e := migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(Succeed(), "plan should not fail without version table")
+		g.Expect(steps).To(HaveLen(1), "all steps should be pending")
+		g.Expect(di.DB.Migrator().HasTable(&migration.MigrationVersion{})).To(BeFalse(), "plan should not create version table")
+	}
+}
+
 /*************************
 	Helpers
  *************************/
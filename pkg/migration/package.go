@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/cisco-open/go-lanai/pkg/bootstrap"
 	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
 	"github.com/cisco-open/go-lanai/pkg/log"
 	"github.com/cisco-open/go-lanai/pkg/utils/order"
 	"go.uber.org/fx"
@@ -32,10 +33,17 @@ const (
 	TagPostUpgrade = "post_upgrade"
 )
 
+const (
+	migrationLockKeyFormat = "migration/%s"
+)
+
 var logger = log.New("Migration")
 
 var filterFlag string
 var allowOutOfOrderFlag bool
+var targetFlag string
+var repairFlag bool
+var dryRunFlag bool
 
 var Module = &bootstrap.Module{
 	Name:       "migration",
@@ -50,6 +58,9 @@ var Module = &bootstrap.Module{
 func Use() {
 	bootstrap.AddStringFlag(&filterFlag, "filter", "", fmt.Sprintf("filter the migration steps by tag value. supports %s or %s", TagPreUpgrade, TagPostUpgrade))
 	bootstrap.AddBoolFlag(&allowOutOfOrderFlag, "allow_out_of_order", false, fmt.Sprintf("allow migration steps to execute out of order"))
+	bootstrap.AddBoolFlag(&repairFlag, "repair", false, "remove failed migration steps (including failed rollbacks) and realign checksums of applied steps, instead of migrating")
+	bootstrap.AddBoolFlag(&dryRunFlag, "dry_run", false, "list pending migration steps without executing them or changing the database. cannot be combined with repair")
+	bootstrap.AddStringFlag(&targetFlag, "target", "", "migrate up or down to the target version, e.g. 1.0.0.1. applied steps newer than the target version are rolled back")
 	bootstrap.Register(Module)
 	// Note: migration CliRunner is provided in Module
 	bootstrap.EnableCliRunnerMode()
@@ -64,14 +75,21 @@ func provideMigrationRunner() fx.Annotated {
 
 type migrationRunnerIn struct {
 	fx.In
+	AppCtx     *bootstrap.ApplicationContext
 	R          *Registrar
 	V          Versioner
 	DB         *gorm.DB
 	DbCreators []data.DbCreator `group:"gorm_config"`
+	// dsync.SyncManager is used to prevent concurrent migrations. Optional
+	dsync.OptionalSyncManagerDI
 }
 
 func newMigrationRunner(di migrationRunnerIn) bootstrap.CliRunner {
 	return func(ctx context.Context) error {
+		// dry run has no side effect, neither database nor lock is needed
+		if dryRunFlag {
+			return Migrate(ctx, di.R, di.V)
+		}
 		if len(di.DbCreators) > 0 {
 			order.SortStable(di.DbCreators, order.OrderedFirstCompare)
 			dbCreator := di.DbCreators[0]
@@ -79,6 +97,17 @@ func newMigrationRunner(di migrationRunnerIn) bootstrap.CliRunner {
 				return err
 			}
 		}
-		return Migrate(ctx, di.R, di.V)
+		lock, e := di.OptionalLock(fmt.Sprintf(migrationLockKeyFormat, di.AppCtx.Name()))
+		if e != nil {
+			return e
+		}
+		if lock == nil {
+			logger.Warnf("dsync.SyncManager is not available, concurrent migrations are not prevented")
+			return Migrate(ctx, di.R, di.V)
+		}
+		// no further step is executed if the lock is lost
+		return dsync.RunWithLock(ctx, lock, func(ctx context.Context) error {
+			return Migrate(ctx, di.R, di.V)
+		})
 	}
 }
@@ -0,0 +1,140 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migration_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/migration"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+func newFileStep(version string, content string) *migration.Migration {
+	fs := fstest.MapFS{
+		"step.sql": &fstest.MapFile{Data: []byte(content)},
+	}
+	return migration.WithVersion(version).WithDesc("Step "+version).WithFile(fs, "step.sql", nil)
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestPlanAndRepair(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestPlan(), "TestPlan"),
+		test.GomegaSubTest(SubTestChecksumValidation(), "TestChecksumValidation"),
+		test.GomegaSubTest(SubTestRepair(), "TestRepair"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestPlan() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		rec := &stepRecorder{}
+		reg := migration.NewRegistrar()
+		reg.AddMigrations(rec.step("1.0.0.1", true, nil), rec.step("1.0.0.2", true, nil), rec.step("1.0.0.3", true, nil))
+		ver := newMemVersioner()
+
+		steps, e := migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(Succeed(), "plan should not fail")
+		g.Expect(steps).To(HaveLen(3), "all steps should be pending")
+		g.Expect(rec.executed).To(BeEmpty(), "no step should be executed")
+		g.Expect(ver.applied).To(BeEmpty(), "no step should be recorded")
+
+		e = migration.MigrateTo(ctx, reg, ver, migration.Version{1, 0, 0, 2})
+		g.Expect(e).To(Succeed(), "migration should not fail")
+		steps, e = migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(Succeed(), "plan should not fail")
+		g.Expect(steps).To(HaveLen(1), "remaining steps should be pending")
+		g.Expect(steps[0].String()).To(Equal("apply 1.0.0.3: Step 1.0.0.3"), "pending step should be correct")
+
+		steps, e = migration.Plan(ctx, reg, ver, migration.Version{1})
+		g.Expect(e).To(Succeed(), "plan should not fail")
+		g.Expect(steps).To(HaveLen(2), "applied steps should be pending for rollback")
+		g.Expect(steps[0].String()).To(Equal("rollback 1.0.0.2: Step 1.0.0.2"), "rollback should be in descending order")
+		g.Expect(steps[1].String()).To(Equal("rollback 1.0.0.1: Step 1.0.0.1"), "rollback should be in descending order")
+	}
+}
+
+func SubTestChecksumValidation() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ver := newMemVersioner()
+		reg := migration.NewRegistrar()
+		reg.AddMigrations(newFileStep("1.0.0.1", "SELECT 1;\n"))
+		step, e := migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(Succeed(), "plan should not fail")
+		g.Expect(step).To(HaveLen(1), "step should be pending")
+		g.Expect(step[0].Checksum).ToNot(BeEmpty(), "file based step should have checksum")
+		e = ver.RecordAppliedMigrationWithChecksum(ctx, step[0].Version, step[0].Description, step[0].Checksum, true, time.Now(), 0)
+		g.Expect(e).To(Succeed(), "recording should not fail")
+
+		// same content with different line endings
+		reg = migration.NewRegistrar()
+		reg.AddMigrations(newFileStep("1.0.0.1", "SELECT 1;\r\n"))
+		_, e = migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(Succeed(), "unchanged step should pass validation")
+
+		// changed
+		reg = migration.NewRegistrar()
+		reg.AddMigrations(newFileStep("1.0.0.1", "SELECT 2;"))
+		_, e = migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(HaveOccurred(), "changed step should fail validation")
+		g.Expect(e.Error()).To(ContainSubstring("1.0.0.1"), "error should mention changed step")
+
+		// legacy record without checksum
+		applied := ver.applied["1.0.0.1"]
+		applied.Checksum = ""
+		ver.applied["1.0.0.1"] = applied
+		_, e = migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(Succeed(), "step without recorded checksum should not be validated")
+	}
+}
+
+func SubTestRepair() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		ver := newMemVersioner()
+		reg := migration.NewRegistrar()
+		changed := newFileStep("1.0.0.1", "SELECT 2;")
+		reg.AddMigrations(changed, newFileStep("1.0.0.2", "SELECT 3;"))
+		_ = ver.RecordAppliedMigrationWithChecksum(ctx, migration.Version{1, 0, 0, 1}, "Step 1.0.0.1", "outdated", true, time.Now(), 0)
+		_ = ver.RecordAppliedMigrationWithChecksum(ctx, migration.Version{1, 0, 0, 2}, "Step 1.0.0.2", "", false, time.Now(), 0)
+
+		_, e := migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(HaveOccurred(), "plan should fail before repair")
+
+		e = migration.Repair(ctx, reg, ver)
+		g.Expect(e).To(Succeed(), "repair should not fail")
+		g.Expect(ver.appliedVersions()).To(Equal([]string{"1.0.0.1"}), "failed step should be removed")
+		g.Expect(ver.applied["1.0.0.1"].Checksum).To(Equal(changed.Checksum), "checksum should be realigned")
+
+		steps, e := migration.Plan(ctx, reg, ver, nil)
+		g.Expect(e).To(Succeed(), "plan should not fail after repair")
+		g.Expect(steps).To(HaveLen(1), "failed step should be pending again")
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/migration"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"sort"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+// memVersioner is an in-memory migration.Versioner
+type memVersioner struct {
+	applied map[string]migration.MigrationVersion
+	undo    []migration.MigrationUndo
+}
+
+func newMemVersioner() *memVersioner {
+	return &memVersioner{applied: map[string]migration.MigrationVersion{}}
+}
+
+func (v *memVersioner) CreateVersionTableIfNotExist(_ context.Context) error {
+	return nil
+}
+
+func (v *memVersioner) GetAppliedMigrations(_ context.Context) ([]migration.AppliedMigration, error) {
+	ret := make([]migration.AppliedMigration, 0, len(v.applied))
+	for _, ver := range v.applied {
+		ret = append(ret, ver)
+	}
+	return ret, nil
+}
+
+func (v *memVersioner) RecordAppliedMigration(ctx context.Context, version migration.Version, description string, success bool, installedOn time.Time, executionTime time.Duration) error {
+	return v.RecordAppliedMigrationWithChecksum(ctx, version, description, "", success, installedOn, executionTime)
+}
+
+func (v *memVersioner) RecordAppliedMigrationWithChecksum(_ context.Context, version migration.Version, description string, checksum string, success bool, installedOn time.Time, executionTime time.Duration) error {
+	v.applied[version.String()] = migration.MigrationVersion{
+		Version:       version,
+		Description:   description,
+		Checksum:      checksum,
+		ExecutionTime: executionTime,
+		InstalledOn:   installedOn,
+		Success:       success,
+	}
+	return nil
+}
+
+func (v *memVersioner) RecordRolledBackMigration(_ context.Context, version migration.Version, description string, success bool, rolledBackOn time.Time, executionTime time.Duration) error {
+	v.undo = append(v.undo, migration.MigrationUndo{
+		Version:       version,
+		Description:   description,
+		ExecutionTime: executionTime,
+		RolledBackOn:  rolledBackOn,
+		Success:       success,
+	})
+	if success {
+		delete(v.applied, version.String())
+	} else {
+		applied := v.applied[version.String()]
+		applied.Success = false
+		v.applied[version.String()] = applied
+	}
+	return nil
+}
+
+func (v *memVersioner) RemoveMigration(_ context.Context, version migration.Version) error {
+	delete(v.applied, version.String())
+	return nil
+}
+
+func (v *memVersioner) UpdateChecksum(_ context.Context, version migration.Version, checksum string) error {
+	applied := v.applied[version.String()]
+	applied.Checksum = checksum
+	v.applied[version.String()] = applied
+	return nil
+}
+
+func (v *memVersioner) appliedVersions() []string {
+	ret := make([]string, 0, len(v.applied))
+	for k := range v.applied {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// legacyVersioner implements migration.Versioner only, without any optional interfaces
+type legacyVersioner struct {
+	mem *memVersioner
+}
+
+func (v legacyVersioner) CreateVersionTableIfNotExist(ctx context.Context) error {
+	return v.mem.CreateVersionTableIfNotExist(ctx)
+}
+
+func (v legacyVersioner) GetAppliedMigrations(ctx context.Context) ([]migration.AppliedMigration, error) {
+	return v.mem.GetAppliedMigrations(ctx)
+}
+
+func (v legacyVersioner) RecordAppliedMigration(ctx context.Context, version migration.Version, description string, success bool, installedOn time.Time, executionTime time.Duration) error {
+	return v.mem.RecordAppliedMigration(ctx, version, description, success, installedOn, executionTime)
+}
+
+// stepRecorder records executed and rolled back steps in order
+type stepRecorder struct {
+	executed []string
+}
+
+func (r *stepRecorder) step(version string, reversible bool, rollbackErr error) *migration.Migration {
+	m := migration.WithVersion(version).WithDesc("Step " + version).
+		WithFunc(func(ctx context.Context) error {
+			r.executed = append(r.executed, "+"+version)
+			return nil
+		})
+	if reversible {
+		m.WithRollbackFunc(func(ctx context.Context) error {
+			r.executed = append(r.executed, "-"+version)
+			return rollbackErr
+		})
+	}
+	return m
+}
+
+/*************************
+	Tests
+ *************************/
+
+func TestMigrateToTarget(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestMigrateUpToTarget(), "TestMigrateUpToTarget"),
+		test.GomegaSubTest(SubTestRollbackToTarget(), "TestRollbackToTarget"),
+		test.GomegaSubTest(SubTestRollbackIrreversible(), "TestRollbackIrreversible"),
+		test.GomegaSubTest(SubTestRollbackFailure(), "TestRollbackFailure"),
+		test.GomegaSubTest(SubTestLegacyVersioner(), "TestLegacyVersioner"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestMigrateUpToTarget() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		rec := &stepRecorder{}
+		reg := migration.NewRegistrar()
+		reg.AddMigrations(rec.step("1.0.0.3", true, nil), rec.step("1.0.0.1", true, nil), rec.step("1.0.0.2", true, nil))
+		ver := newMemVersioner()
+
+		e := migration.MigrateTo(ctx, reg, ver, migration.Version{1, 0, 0, 2})
+		g.Expect(e).To(Succeed(), "migration should not fail")
+		g.Expect(rec.executed).To(Equal([]string{"+1.0.0.1", "+1.0.0.2"}), "steps up to target should be executed")
+		g.Expect(ver.appliedVersions()).To(Equal([]string{"1.0.0.1", "1.0.0.2"}), "applied versions should be correct")
+
+		e = migration.Migrate(ctx, reg, ver)
+		g.Expect(e).To(Succeed(), "migration should not fail")
+		g.Expect(rec.executed).To(Equal([]string{"+1.0.0.1", "+1.0.0.2", "+1.0.0.3"}), "remaining steps should be executed")
+	}
+}
+
+func SubTestRollbackToTarget() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		rec := &stepRecorder{}
+		reg := migration.NewRegistrar()
+		reg.AddMigrations(rec.step("1.0.0.1", false, nil), rec.step("1.0.0.2", true, nil), rec.step("1.0.0.3", true, nil))
+		ver := newMemVersioner()
+		e := migration.Migrate(ctx, reg, ver)
+		g.Expect(e).To(Succeed(), "migration should not fail")
+
+		rec.executed = nil
+		e = migration.MigrateTo(ctx, reg, ver, migration.Version{1, 0, 0, 1})
+		g.Expect(e).To(Succeed(), "rollback should not fail")
+		g.Expect(rec.executed).To(Equal([]string{"-1.0.0.3", "-1.0.0.2"}), "steps should be rolled back in descending order")
+		g.Expect(ver.appliedVersions()).To(Equal([]string{"1.0.0.1"}), "rolled back versions should be removed")
+		g.Expect(ver.undo).To(HaveLen(2), "undo entries should be recorded")
+		for _, undo := range ver.undo {
+			g.Expect(undo.Success).To(BeTrue(), "undo entries should be successful")
+			g.Expect(undo.RolledBackOn).ToNot(BeZero(), "undo entries should have time")
+		}
+
+		// re-apply
+		rec.executed = nil
+		e = migration.Migrate(ctx, reg, ver)
+		g.Expect(e).To(Succeed(), "migration should not fail")
+		g.Expect(rec.executed).To(Equal([]string{"+1.0.0.2", "+1.0.0.3"}), "rolled back steps should be re-applied")
+	}
+}
+
+func SubTestRollbackIrreversible() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		rec := &stepRecorder{}
+		reg := migration.NewRegistrar()
+		reg.AddMigrations(rec.step("1.0.0.1", true, nil), rec.step("1.0.0.2", false, nil), rec.step("1.0.0.3", true, nil))
+		ver := newMemVersioner()
+		e := migration.Migrate(ctx, reg, ver)
+		g.Expect(e).To(Succeed(), "migration should not fail")
+
+		rec.executed = nil
+		e = migration.MigrateTo(ctx, reg, ver, migration.Version{1, 0, 0, 1})
+		g.Expect(e).To(HaveOccurred(), "rollback should fail")
+		g.Expect(e.Error()).To(ContainSubstring("1.0.0.2"), "error should mention irreversible step")
+		g.Expect(rec.executed).To(BeEmpty(), "no step should be rolled back")
+		g.Expect(ver.appliedVersions()).To(HaveLen(3), "applied versions should not change")
+		g.Expect(ver.undo).To(BeEmpty(), "no undo entries should be recorded")
+	}
+}
+
+func SubTestRollbackFailure() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		rec := &stepRecorder{}
+		reg := migration.NewRegistrar()
+		reg.AddMigrations(rec.step("1.0.0.1", true, nil), rec.step("1.0.0.2", true, errors.New("oops")), rec.step("1.0.0.3", true, nil))
+		ver := newMemVersioner()
+		e := migration.Migrate(ctx, reg, ver)
+		g.Expect(e).To(Succeed(), "migration should not fail")
+
+		rec.executed = nil
+		e = migration.MigrateTo(ctx, reg, ver, migration.Version{1, 0, 0, 1})
+		g.Expect(e).To(HaveOccurred(), "rollback should fail")
+		g.Expect(rec.executed).To(Equal([]string{"-1.0.0.3", "-1.0.0.2"}), "rollback should stop at failed step")
+		g.Expect(ver.appliedVersions()).To(Equal([]string{"1.0.0.1", "1.0.0.2"}), "failed step should remain")
+		g.Expect(ver.applied["1.0.0.2"].Success).To(BeFalse(), "failed step should be marked as failed")
+
+		e = migration.Migrate(ctx, reg, ver)
+		g.Expect(e).To(HaveOccurred(), "migration should not continue after failed rollback")
+	}
+}
+
+func SubTestLegacyVersioner() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		rec := &stepRecorder{}
+		reg := migration.NewRegistrar()
+		reg.AddMigrations(rec.step("1.0.0.1", true, nil), rec.step("1.0.0.2", true, nil))
+		ver := legacyVersioner{mem: newMemVersioner()}
+		e := migration.Migrate(ctx, reg, ver)
+		g.Expect(e).To(Succeed(), "migration should not fail with legacy versioner")
+		g.Expect(rec.executed).To(Equal([]string{"+1.0.0.1", "+1.0.0.2"}), "steps should be executed")
+		g.Expect(ver.mem.appliedVersions()).To(Equal([]string{"1.0.0.1", "1.0.0.2"}), "applied versions should be correct")
+
+		rec.executed = nil
+		e = migration.MigrateTo(ctx, reg, ver, migration.Version{1, 0, 0, 1})
+		g.Expect(e).To(HaveOccurred(), "rollback should fail with legacy versioner")
+		g.Expect(rec.executed).To(BeEmpty(), "no step should be rolled back")
+		g.Expect(ver.mem.appliedVersions()).To(HaveLen(2), "applied versions should not change")
+
+		e = migration.Repair(ctx, reg, ver)
+		g.Expect(e).To(HaveOccurred(), "repair should fail with legacy versioner")
+	}
+}
@@ -18,6 +18,8 @@ package migration
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
@@ -26,7 +28,8 @@ import (
 	"strings"
 )
 
-func migrationFuncFromTextFile(fs fs.FS, filePath string, db *gorm.DB) (MigrationFunc){
+// migrationFuncFromTextFile returns MigrationFunc executing SQL statements in given file, and checksum of the file
+func migrationFuncFromTextFile(fs fs.FS, filePath string, db *gorm.DB) (MigrationFunc, string) {
 	file, err := fs.Open(filePath)
 	if err != nil {
 		panic(errors.New(fmt.Sprintf("%s does not exist or is not a file", filePath)))
@@ -51,5 +54,12 @@ func migrationFuncFromTextFile(fs fs.FS, filePath string, db *gorm.DB) (Migratio
 			}
 		}
 		return nil
-	}
-}
\ No newline at end of file
+	}, checksum(sql)
+}
+
+// checksum returns hex encoded SHA-256 of given content, line endings are normalized
+func checksum(content []byte) string {
+	normalized := strings.ReplaceAll(string(content), "\r\n", "\n")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
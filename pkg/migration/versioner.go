@@ -34,7 +34,43 @@ type Versioner interface {
 	RecordAppliedMigration(ctx context.Context, version Version, description string, success bool, installedOn time.Time, executionTime time.Duration) error
 }
 
+// ChecksumAppliedMigration is an AppliedMigration with checksum.
+// Checksums of applied steps are validated only if AppliedMigration implements this interface.
+type ChecksumAppliedMigration interface {
+	AppliedMigration
+	// GetChecksum returns checksum of the applied step, empty if the step is not file based
+	GetChecksum() string
+}
 
+// ChecksumVersioner is an optional interface of Versioner to record checksums of file based steps.
+// Checksums are not recorded if Versioner doesn't implement this interface.
+type ChecksumVersioner interface {
+	Versioner
+	// RecordAppliedMigrationWithChecksum is same as RecordAppliedMigration, with checksum of the step
+	RecordAppliedMigrationWithChecksum(ctx context.Context, version Version, description string, checksum string, success bool, installedOn time.Time, executionTime time.Duration) error
+	// UpdateChecksum updates checksum of an applied step
+	UpdateChecksum(ctx context.Context, version Version, checksum string) error
+}
 
+// RollbackVersioner is an optional interface of Versioner, required to roll back applied steps.
+type RollbackVersioner interface {
+	Versioner
+	// RecordRolledBackMigration records the undo of a previously applied migration step.
+	// When success, the step should no longer be returned by GetAppliedMigrations.
+	// Otherwise, the step should be returned as failed, so no further migration would happen until it's fixed manually.
+	RecordRolledBackMigration(ctx context.Context, version Version, description string, success bool, rolledBackOn time.Time, executionTime time.Duration) error
+}
 
+// RepairVersioner is an optional interface of Versioner, required to repair migration records.
+type RepairVersioner interface {
+	Versioner
+	// RemoveMigration removes the record of given version, typically used to clear failed steps
+	RemoveMigration(ctx context.Context, version Version) error
+}
 
+func checksumOf(a AppliedMigration) string {
+	if ca, ok := a.(ChecksumAppliedMigration); ok {
+		return ca.GetChecksum()
+	}
+	return ""
+}
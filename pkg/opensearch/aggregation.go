@@ -0,0 +1,438 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrAggregationNotFound = errors.New("aggregation not found")
+)
+
+// Aggregation is a typed aggregation of the OpenSearch aggregation DSL, see https://opensearch.org/docs/latest/aggregations/
+// Aggregations are added to SearchBody by name, and their results can be found in Aggregations of the same name.
+type Aggregation interface {
+	json.Marshaler
+	// Source returns the aggregation in its generic JSON form
+	Source() map[string]interface{}
+}
+
+// subAggregations is embedded by bucket aggregation builders
+type subAggregations map[string]Aggregation
+
+func (s *subAggregations) add(name string, agg Aggregation) {
+	if *s == nil {
+		*s = subAggregations{}
+	}
+	(*s)[name] = agg
+}
+
+func (s subAggregations) putInto(source map[string]interface{}) map[string]interface{} {
+	if len(s) != 0 {
+		source["aggs"] = aggregationSources(s)
+	}
+	return source
+}
+
+/*************************
+	Bucket Aggregations
+ *************************/
+
+// TermsAggregation groups documents by unique values of the field. See Aggregations.Terms for results
+func TermsAggregation(field string) *TermsAggregationBuilder {
+	return &TermsAggregationBuilder{field: field}
+}
+
+type TermsAggregationBuilder struct {
+	subAggregations
+	field string
+	size  *int
+	order []map[string]interface{}
+}
+
+func (a *TermsAggregationBuilder) Size(size int) *TermsAggregationBuilder {
+	a.size = &size
+	return a
+}
+
+// Order sorts buckets by the key, e.g. "_count", "_key" or name of a metric sub-aggregation. order is either "asc" or "desc"
+func (a *TermsAggregationBuilder) Order(key string, order string) *TermsAggregationBuilder {
+	a.order = append(a.order, map[string]interface{}{key: order})
+	return a
+}
+
+func (a *TermsAggregationBuilder) SubAggregation(name string, agg Aggregation) *TermsAggregationBuilder {
+	a.add(name, agg)
+	return a
+}
+
+func (a *TermsAggregationBuilder) Source() map[string]interface{} {
+	params := map[string]interface{}{"field": a.field}
+	if a.size != nil {
+		params["size"] = *a.size
+	}
+	if len(a.order) != 0 {
+		params["order"] = a.order
+	}
+	return a.putInto(map[string]interface{}{"terms": params})
+}
+
+func (a *TermsAggregationBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Source())
+}
+
+// DateHistogramAggregation groups documents by date intervals of the field. See Aggregations.DateHistogram for results
+func DateHistogramAggregation(field string) *DateHistogramAggregationBuilder {
+	return &DateHistogramAggregationBuilder{field: field}
+}
+
+type DateHistogramAggregationBuilder struct {
+	subAggregations
+	field            string
+	calendarInterval string
+	fixedInterval    string
+	format           string
+	timeZone         string
+	minDocCount      *int
+}
+
+// CalendarInterval is a calendar-aware interval, e.g. "day", "month" or "1y"
+func (a *DateHistogramAggregationBuilder) CalendarInterval(interval string) *DateHistogramAggregationBuilder {
+	a.calendarInterval = interval
+	return a
+}
+
+// FixedInterval is a fixed length interval, e.g. "30m" or "12h"
+func (a *DateHistogramAggregationBuilder) FixedInterval(interval string) *DateHistogramAggregationBuilder {
+	a.fixedInterval = interval
+	return a
+}
+
+func (a *DateHistogramAggregationBuilder) Format(format string) *DateHistogramAggregationBuilder {
+	a.format = format
+	return a
+}
+
+func (a *DateHistogramAggregationBuilder) TimeZone(tz string) *DateHistogramAggregationBuilder {
+	a.timeZone = tz
+	return a
+}
+
+func (a *DateHistogramAggregationBuilder) MinDocCount(count int) *DateHistogramAggregationBuilder {
+	a.minDocCount = &count
+	return a
+}
+
+func (a *DateHistogramAggregationBuilder) SubAggregation(name string, agg Aggregation) *DateHistogramAggregationBuilder {
+	a.add(name, agg)
+	return a
+}
+
+func (a *DateHistogramAggregationBuilder) Source() map[string]interface{} {
+	params := map[string]interface{}{"field": a.field}
+	putIfNotEmpty(params, "calendar_interval", a.calendarInterval)
+	putIfNotEmpty(params, "fixed_interval", a.fixedInterval)
+	putIfNotEmpty(params, "format", a.format)
+	putIfNotEmpty(params, "time_zone", a.timeZone)
+	if a.minDocCount != nil {
+		params["min_doc_count"] = *a.minDocCount
+	}
+	return a.putInto(map[string]interface{}{"date_histogram": params})
+}
+
+func (a *DateHistogramAggregationBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Source())
+}
+
+// CompositeSource is a named value source of CompositeAggregation
+type CompositeSource struct {
+	Name   string
+	Source map[string]interface{}
+}
+
+// CompositeTermsSource uses unique values of the field as the named part of the composite key
+func CompositeTermsSource(name string, field string) CompositeSource {
+	return CompositeSource{
+		Name:   name,
+		Source: map[string]interface{}{"terms": map[string]interface{}{"field": field}},
+	}
+}
+
+// CompositeDateHistogramSource uses calendar intervals of the date field as the named part of the composite key
+func CompositeDateHistogramSource(name string, field string, calendarInterval string) CompositeSource {
+	return CompositeSource{
+		Name: name,
+		Source: map[string]interface{}{"date_histogram": map[string]interface{}{
+			"field":             field,
+			"calendar_interval": calendarInterval,
+		}},
+	}
+}
+
+// CompositeAggregation groups documents by combinations of values from multiple sources, and supports paging through
+// all buckets using After. See Aggregations.Composite for results
+func CompositeAggregation(sources ...CompositeSource) *CompositeAggregationBuilder {
+	return &CompositeAggregationBuilder{sources: sources}
+}
+
+type CompositeAggregationBuilder struct {
+	subAggregations
+	sources []CompositeSource
+	size    *int
+	after   map[string]interface{}
+}
+
+func (a *CompositeAggregationBuilder) Size(size int) *CompositeAggregationBuilder {
+	a.size = &size
+	return a
+}
+
+// After fetches buckets after the key, typically CompositeAggregationResult.AfterKey of previous page
+func (a *CompositeAggregationBuilder) After(key map[string]interface{}) *CompositeAggregationBuilder {
+	a.after = key
+	return a
+}
+
+func (a *CompositeAggregationBuilder) SubAggregation(name string, agg Aggregation) *CompositeAggregationBuilder {
+	a.add(name, agg)
+	return a
+}
+
+func (a *CompositeAggregationBuilder) Source() map[string]interface{} {
+	sources := make([]map[string]interface{}, len(a.sources))
+	for i, s := range a.sources {
+		sources[i] = map[string]interface{}{s.Name: s.Source}
+	}
+	params := map[string]interface{}{"sources": sources}
+	if a.size != nil {
+		params["size"] = *a.size
+	}
+	if len(a.after) != 0 {
+		params["after"] = a.after
+	}
+	return a.putInto(map[string]interface{}{"composite": params})
+}
+
+func (a *CompositeAggregationBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Source())
+}
+
+/*************************
+	Metric Aggregations
+ *************************/
+
+// AvgAggregation See Aggregations.Metric for results
+func AvgAggregation(field string) Aggregation {
+	return metricAggregation{kind: "avg", field: field}
+}
+
+// SumAggregation See Aggregations.Metric for results
+func SumAggregation(field string) Aggregation {
+	return metricAggregation{kind: "sum", field: field}
+}
+
+// MinAggregation See Aggregations.Metric for results
+func MinAggregation(field string) Aggregation {
+	return metricAggregation{kind: "min", field: field}
+}
+
+// MaxAggregation See Aggregations.Metric for results
+func MaxAggregation(field string) Aggregation {
+	return metricAggregation{kind: "max", field: field}
+}
+
+// ValueCountAggregation See Aggregations.Metric for results
+func ValueCountAggregation(field string) Aggregation {
+	return metricAggregation{kind: "value_count", field: field}
+}
+
+// CardinalityAggregation counts approximate distinct values. See Aggregations.Metric for results
+func CardinalityAggregation(field string) Aggregation {
+	return metricAggregation{kind: "cardinality", field: field}
+}
+
+// StatsAggregation See Aggregations.Stats for results
+func StatsAggregation(field string) Aggregation {
+	return metricAggregation{kind: "stats", field: field}
+}
+
+type metricAggregation struct {
+	kind  string
+	field string
+}
+
+func (a metricAggregation) Source() map[string]interface{} {
+	return map[string]interface{}{a.kind: map[string]interface{}{"field": a.field}}
+}
+
+func (a metricAggregation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Source())
+}
+
+/*************************
+	Results
+ *************************/
+
+// Aggregations are aggregation results of a search, by aggregation names.
+// Typed results can be extracted with corresponding functions, e.g. Terms, DateHistogram, Metric.
+type Aggregations map[string]json.RawMessage
+
+// Terms returns result of TermsAggregation with the name
+func (a Aggregations) Terms(name string) (*BucketAggregationResult, error) {
+	return unmarshalAggregation[BucketAggregationResult](a, name)
+}
+
+// DateHistogram returns result of DateHistogramAggregation with the name. Bucket keys are epoch milliseconds.
+func (a Aggregations) DateHistogram(name string) (*BucketAggregationResult, error) {
+	return unmarshalAggregation[BucketAggregationResult](a, name)
+}
+
+// Composite returns result of CompositeAggregation with the name
+func (a Aggregations) Composite(name string) (*CompositeAggregationResult, error) {
+	return unmarshalAggregation[CompositeAggregationResult](a, name)
+}
+
+// Metric returns result of single value metric aggregation with the name, e.g. AvgAggregation, CardinalityAggregation
+func (a Aggregations) Metric(name string) (*MetricAggregationResult, error) {
+	return unmarshalAggregation[MetricAggregationResult](a, name)
+}
+
+// Stats returns result of StatsAggregation with the name
+func (a Aggregations) Stats(name string) (*StatsAggregationResult, error) {
+	return unmarshalAggregation[StatsAggregationResult](a, name)
+}
+
+type BucketAggregationResult struct {
+	DocCountErrorUpperBound int      `json:"doc_count_error_upper_bound"`
+	SumOtherDocCount        int      `json:"sum_other_doc_count"`
+	Buckets                 []Bucket `json:"buckets"`
+}
+
+// Bucket is a bucket of TermsAggregation or DateHistogramAggregation.
+// Key is a string or a number, depending on the field type. Results of sub-aggregations are in Aggregations
+type Bucket struct {
+	Key          interface{}
+	KeyAsString  string
+	DocCount     int
+	Aggregations Aggregations
+}
+
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	var known struct {
+		Key         interface{} `json:"key"`
+		KeyAsString string      `json:"key_as_string"`
+		DocCount    int         `json:"doc_count"`
+	}
+	aggs, e := unmarshalBucket(data, &known, "key", "key_as_string", "doc_count")
+	if e != nil {
+		return e
+	}
+	*b = Bucket{
+		Key:          known.Key,
+		KeyAsString:  known.KeyAsString,
+		DocCount:     known.DocCount,
+		Aggregations: aggs,
+	}
+	return nil
+}
+
+type CompositeAggregationResult struct {
+	// AfterKey is the key to fetch next page of buckets, see CompositeAggregationBuilder.After
+	AfterKey map[string]interface{} `json:"after_key"`
+	Buckets  []CompositeBucket      `json:"buckets"`
+}
+
+// CompositeBucket is a bucket of CompositeAggregation. Key contains values of all sources by their names
+type CompositeBucket struct {
+	Key          map[string]interface{}
+	DocCount     int
+	Aggregations Aggregations
+}
+
+func (b *CompositeBucket) UnmarshalJSON(data []byte) error {
+	var known struct {
+		Key      map[string]interface{} `json:"key"`
+		DocCount int                    `json:"doc_count"`
+	}
+	aggs, e := unmarshalBucket(data, &known, "key", "doc_count")
+	if e != nil {
+		return e
+	}
+	*b = CompositeBucket{
+		Key:          known.Key,
+		DocCount:     known.DocCount,
+		Aggregations: aggs,
+	}
+	return nil
+}
+
+// MetricAggregationResult is result of single value metric aggregation. Value is nil if no document has the field.
+type MetricAggregationResult struct {
+	Value         *float64 `json:"value"`
+	ValueAsString string   `json:"value_as_string,omitempty"`
+}
+
+// StatsAggregationResult is result of StatsAggregation. Min, Max and Avg are nil if no document has the field.
+type StatsAggregationResult struct {
+	Count int      `json:"count"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Avg   *float64 `json:"avg"`
+	Sum   float64  `json:"sum"`
+}
+
+/*************************
+	helpers
+ *************************/
+
+func aggregationSources[M ~map[string]Aggregation](aggs M) map[string]interface{} {
+	sources := make(map[string]interface{}, len(aggs))
+	for name, agg := range aggs {
+		sources[name] = agg.Source()
+	}
+	return sources
+}
+
+func unmarshalAggregation[R any](aggs Aggregations, name string) (*R, error) {
+	raw, ok := aggs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAggregationNotFound, name)
+	}
+	var result R
+	if e := json.Unmarshal(raw, &result); e != nil {
+		return nil, fmt.Errorf("unable to parse aggregation [%s]: %w", name, e)
+	}
+	return &result, nil
+}
+
+// unmarshalBucket unmarshal known fields of a bucket into dest, and returns other fields as results of sub-aggregations
+func unmarshalBucket(data []byte, dest interface{}, knownFields ...string) (Aggregations, error) {
+	if e := json.Unmarshal(data, dest); e != nil {
+		return nil, e
+	}
+	var aggs Aggregations
+	if e := json.Unmarshal(data, &aggs); e != nil {
+		return nil, e
+	}
+	for _, k := range knownFields {
+		delete(aggs, k)
+	}
+	return aggs, nil
+}
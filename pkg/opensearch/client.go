@@ -49,7 +49,23 @@ type Request interface {
 		opensearchapi.IndicesDeleteAliasRequest |
 		opensearchapi.IndicesPutIndexTemplateRequest |
 		opensearchapi.IndicesDeleteIndexTemplateRequest |
-		opensearchapi.PingRequest
+		opensearchapi.PingRequest |
+		opensearchapi.GetRequest |
+		opensearchapi.MgetRequest |
+		opensearchapi.UpdateRequest |
+		opensearchapi.DeleteRequest |
+		opensearchapi.DeleteByQueryRequest |
+		opensearchapi.UpdateByQueryRequest |
+		opensearchapi.CountRequest |
+		opensearchapi.ScrollRequest |
+		opensearchapi.ClearScrollRequest |
+		PointInTimeCreateRequest |
+		PointInTimeDeleteRequest |
+		opensearchapi.IndicesExistsRequest |
+		opensearchapi.IndicesGetAliasRequest |
+		opensearchapi.IndicesUpdateAliasesRequest |
+		opensearchapi.ReindexRequest |
+		opensearchapi.TasksGetRequest
 }
 
 type OpenClient interface {
@@ -65,6 +81,22 @@ type OpenClient interface {
 	IndicesPutIndexTemplate(ctx context.Context, name string, body io.Reader, o ...Option[opensearchapi.IndicesPutIndexTemplateRequest]) (*opensearchapi.Response, error)
 	IndicesDeleteIndexTemplate(ctx context.Context, name string, o ...Option[opensearchapi.IndicesDeleteIndexTemplateRequest]) (*opensearchapi.Response, error)
 	Ping(ctx context.Context, o ...Option[opensearchapi.PingRequest]) (*opensearchapi.Response, error)
+	Get(ctx context.Context, index string, id string, o ...Option[opensearchapi.GetRequest]) (*opensearchapi.Response, error)
+	MGet(ctx context.Context, body io.Reader, o ...Option[opensearchapi.MgetRequest]) (*opensearchapi.Response, error)
+	Update(ctx context.Context, index string, id string, body io.Reader, o ...Option[opensearchapi.UpdateRequest]) (*opensearchapi.Response, error)
+	Delete(ctx context.Context, index string, id string, o ...Option[opensearchapi.DeleteRequest]) (*opensearchapi.Response, error)
+	DeleteByQuery(ctx context.Context, index []string, body io.Reader, o ...Option[opensearchapi.DeleteByQueryRequest]) (*opensearchapi.Response, error)
+	UpdateByQuery(ctx context.Context, index []string, body io.Reader, o ...Option[opensearchapi.UpdateByQueryRequest]) (*opensearchapi.Response, error)
+	Count(ctx context.Context, o ...Option[opensearchapi.CountRequest]) (*opensearchapi.Response, error)
+	Scroll(ctx context.Context, o ...Option[opensearchapi.ScrollRequest]) (*opensearchapi.Response, error)
+	ClearScroll(ctx context.Context, o ...Option[opensearchapi.ClearScrollRequest]) (*opensearchapi.Response, error)
+	PointInTimeCreate(ctx context.Context, index []string, o ...Option[PointInTimeCreateRequest]) (*opensearchapi.Response, error)
+	PointInTimeDelete(ctx context.Context, pitID []string, o ...Option[PointInTimeDeleteRequest]) (*opensearchapi.Response, error)
+	IndicesExists(ctx context.Context, index []string, o ...Option[opensearchapi.IndicesExistsRequest]) (*opensearchapi.Response, error)
+	IndicesGetAlias(ctx context.Context, name []string, o ...Option[opensearchapi.IndicesGetAliasRequest]) (*opensearchapi.Response, error)
+	IndicesUpdateAliases(ctx context.Context, body io.Reader, o ...Option[opensearchapi.IndicesUpdateAliasesRequest]) (*opensearchapi.Response, error)
+	Reindex(ctx context.Context, body io.Reader, o ...Option[opensearchapi.ReindexRequest]) (*opensearchapi.Response, error)
+	TasksGet(ctx context.Context, taskID string, o ...Option[opensearchapi.TasksGetRequest]) (*opensearchapi.Response, error)
 	AddBeforeHook(hook BeforeHook)
 	AddAfterHook(hook AfterHook)
 	RemoveBeforeHook(hook BeforeHook)
@@ -160,6 +192,22 @@ const (
 	CmdIndicesDeleteIndexTemplate
 	CmdPing
 	CmdBulk
+	CmdGet
+	CmdMGet
+	CmdUpdate
+	CmdDelete
+	CmdDeleteByQuery
+	CmdUpdateByQuery
+	CmdCount
+	CmdScroll
+	CmdClearScroll
+	CmdPointInTimeCreate
+	CmdPointInTimeDelete
+	CmdIndicesExists
+	CmdIndicesGetAlias
+	CmdIndicesUpdateAliases
+	CmdReindex
+	CmdTasksGet
 )
 
 var CmdToString = map[CommandType]string{
@@ -175,6 +223,22 @@ var CmdToString = map[CommandType]string{
 	CmdIndicesDeleteIndexTemplate: "indices delete index template",
 	CmdPing:                       "ping",
 	CmdBulk:                       "bulk",
+	CmdGet:                        "get",
+	CmdMGet:                       "mget",
+	CmdUpdate:                     "update",
+	CmdDelete:                     "delete",
+	CmdDeleteByQuery:              "delete by query",
+	CmdUpdateByQuery:              "update by query",
+	CmdCount:                      "count",
+	CmdScroll:                     "scroll",
+	CmdClearScroll:                "clear scroll",
+	CmdPointInTimeCreate:          "point in time create",
+	CmdPointInTimeDelete:          "point in time delete",
+	CmdIndicesExists:              "indices exists",
+	CmdIndicesGetAlias:            "indices get alias",
+	CmdIndicesUpdateAliases:       "indices update aliases",
+	CmdReindex:                    "reindex",
+	CmdTasksGet:                   "tasks get",
 }
 
 // String will return the command in string format. If the command is not found
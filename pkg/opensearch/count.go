@@ -0,0 +1,83 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"net/http"
+)
+
+// CountResponse modeled after https://opensearch.org/docs/latest/api-reference/count/#response-body-fields
+type CountResponse struct {
+	Count int `json:"count"`
+}
+
+func (c *RepoImpl[T]) Count(ctx context.Context, body interface{}, o ...Option[opensearchapi.CountRequest]) (int, error) {
+	if body != nil {
+		var buffer bytes.Buffer
+		err := json.NewEncoder(&buffer).Encode(body)
+		if err != nil {
+			return 0, fmt.Errorf("unable to encode query: %w", err)
+		}
+		o = append(o, Count.WithBody(&buffer))
+	}
+	resp, err := c.client.Count(ctx, o...)
+	if err != nil {
+		return 0, err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		if resp.StatusCode == http.StatusNotFound {
+			return 0, fmt.Errorf("%w", ErrIndexNotFound)
+		}
+		return 0, fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+	countResp, err := UnmarshalResponse[CountResponse](resp)
+	if err != nil {
+		return 0, err
+	}
+	return countResp.Count, nil
+}
+
+func (c *OpenClientImpl) Count(ctx context.Context, o ...Option[opensearchapi.CountRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.CountRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdCount, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, Count.WithContext(ctx))
+	resp, err := c.client.API.Count(options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdCount, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type countExt struct {
+	opensearchapi.Count
+}
+
+var Count = countExt{}
@@ -0,0 +1,77 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"net/http"
+)
+
+func (c *RepoImpl[T]) Delete(ctx context.Context, index string, id string, o ...Option[opensearchapi.DeleteRequest]) error {
+	resp, err := c.client.Delete(ctx, index, id, o...)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		switch resp.StatusCode {
+		case http.StatusConflict:
+			return fmt.Errorf("%w: error status code: %d", ErrVersionConflict, resp.StatusCode)
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s", ErrDocumentNotFound, id)
+		default:
+			return fmt.Errorf("error status code: %d", resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+func (c *OpenClientImpl) Delete(ctx context.Context, index string, id string, o ...Option[opensearchapi.DeleteRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.DeleteRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdDelete, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, Delete.WithContext(ctx))
+	resp, err := c.client.API.Delete(index, id, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdDelete, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type deleteExt struct {
+	opensearchapi.Delete
+}
+
+var Delete = deleteExt{}
+
+// WithSeqNoPrimaryTerm only performs the deletion if the document still has given sequence number and primary term
+// (see Document). Otherwise, the deletion is rejected with ErrVersionConflict.
+func (s deleteExt) WithSeqNoPrimaryTerm(seqNo, primaryTerm int) func(request *opensearchapi.DeleteRequest) {
+	return func(request *opensearchapi.DeleteRequest) {
+		request.IfSeqNo = &seqNo
+		request.IfPrimaryTerm = &primaryTerm
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+	"net/http"
+)
+
+// ByQueryResponse is the response of Repo.DeleteByQuery and Repo.UpdateByQuery,
+// modeled after https://opensearch.org/docs/latest/api-reference/document-apis/delete-by-query/#response-body-fields
+type ByQueryResponse struct {
+	Took             int               `json:"took"`
+	TimedOut         bool              `json:"timed_out"`
+	Total            int               `json:"total"`
+	Updated          int               `json:"updated"`
+	Deleted          int               `json:"deleted"`
+	Batches          int               `json:"batches"`
+	VersionConflicts int               `json:"version_conflicts"`
+	Noops            int               `json:"noops"`
+	Failures         []json.RawMessage `json:"failures"`
+}
+
+func (c *RepoImpl[T]) DeleteByQuery(ctx context.Context, index []string, body interface{}, o ...Option[opensearchapi.DeleteByQueryRequest]) (*ByQueryResponse, error) {
+	var buffer bytes.Buffer
+	err := json.NewEncoder(&buffer).Encode(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode query: %w", err)
+	}
+	resp, err := c.client.DeleteByQuery(ctx, index, &buffer, o...)
+	if err != nil {
+		return nil, err
+	}
+	if err := byQueryError(ctx, resp); err != nil {
+		return nil, err
+	}
+	return UnmarshalResponse[ByQueryResponse](resp)
+}
+
+func (c *OpenClientImpl) DeleteByQuery(ctx context.Context, index []string, body io.Reader, o ...Option[opensearchapi.DeleteByQueryRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.DeleteByQueryRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdDeleteByQuery, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, DeleteByQuery.WithContext(ctx))
+	resp, err := c.client.API.DeleteByQuery(index, body, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdDeleteByQuery, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type deleteByQueryExt struct {
+	opensearchapi.DeleteByQuery
+}
+
+var DeleteByQuery = deleteByQueryExt{}
+
+// byQueryError translates error response of "_delete_by_query" and "_update_by_query".
+// Note: by default, the operation aborts on version conflicts. Use "WithConflicts("proceed")" to count them instead.
+func byQueryError(ctx context.Context, resp *opensearchapi.Response) error {
+	if !resp.IsError() {
+		return nil
+	}
+	logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+	switch resp.StatusCode {
+	case http.StatusConflict:
+		return fmt.Errorf("%w: error status code: %d", ErrVersionConflict, resp.StatusCode)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w", ErrIndexNotFound)
+	default:
+		return fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+}
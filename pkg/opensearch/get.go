@@ -0,0 +1,87 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"net/http"
+)
+
+var (
+	ErrDocumentNotFound = errors.New("document not found")
+)
+
+// Document is a single document with its metadata,
+// modeled after https://opensearch.org/docs/latest/api-reference/document-apis/get-documents/#response-body-fields
+//
+// SeqNo and PrimaryTerm can be used for optimistic concurrency control, see updateExt.WithSeqNoPrimaryTerm
+type Document[T any] struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     int    `json:"_version"`
+	SeqNo       int    `json:"_seq_no"`
+	PrimaryTerm int    `json:"_primary_term"`
+	Found       bool   `json:"found"`
+	Source      T      `json:"_source"`
+}
+
+func (c *RepoImpl[T]) Get(ctx context.Context, index string, id string, o ...Option[opensearchapi.GetRequest]) (*Document[T], error) {
+	resp, err := c.client.Get(ctx, index, id, o...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		if resp.StatusCode != http.StatusNotFound {
+			return nil, fmt.Errorf("error status code: %d", resp.StatusCode)
+		}
+		// 404 is also returned when the index doesn't exist. In such case, "found" is not in the response
+		if doc, e := UnmarshalResponse[Document[T]](resp); e == nil && doc.ID == id {
+			return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, id)
+		}
+		return nil, fmt.Errorf("%w", ErrIndexNotFound)
+	}
+	return UnmarshalResponse[Document[T]](resp)
+}
+
+func (c *OpenClientImpl) Get(ctx context.Context, index string, id string, o ...Option[opensearchapi.GetRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.GetRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdGet, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, Get.WithContext(ctx))
+	resp, err := c.client.API.Get(index, id, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdGet, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type getExt struct {
+	opensearchapi.Get
+}
+
+var Get = getExt{}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"github.com/opensearch-project/opensearch-go/opensearchapi"
 	"io"
+	"net/http"
 )
 
 func (c *RepoImpl[T]) Index(ctx context.Context, index string, document T, o ...Option[opensearchapi.IndexRequest]) error {
@@ -37,6 +38,9 @@ func (c *RepoImpl[T]) Index(ctx context.Context, index string, document T, o ...
 	}
 	if resp.IsError() {
 		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		if resp.StatusCode == http.StatusConflict {
+			return fmt.Errorf("%w: error status code: %d", ErrVersionConflict, resp.StatusCode)
+		}
 		return fmt.Errorf("error status code: %d", resp.StatusCode)
 	}
 	return nil
@@ -72,3 +76,17 @@ type indexExt struct {
 }
 
 var Index = indexExt{}
+
+// WithFencingToken uses given fencing token (see dsync.Lock) as the document's external version, so that
+// OpenSearch rejects the write if the document was written with a greater token.
+// Rejected writes result in ErrVersionConflict. Document ID is required (see opensearchapi.Index WithDocumentID).
+// e.g.
+//		token, _ := dsync.FencingTokenFromContext(ctx)
+//		repo.Index(ctx, index, doc, Index.WithDocumentID(id), Index.WithFencingToken(token))
+func (s indexExt) WithFencingToken(token uint64) func(request *opensearchapi.IndexRequest) {
+	return func(request *opensearchapi.IndexRequest) {
+		version := int(token)
+		request.Version = &version
+		request.VersionType = "external_gte"
+	}
+}
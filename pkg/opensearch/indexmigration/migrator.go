@@ -0,0 +1,267 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package indexmigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/opensearch"
+	"sort"
+	"time"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+)
+
+type MigratorOptions func(opt *MigratorOption)
+type MigratorOption struct {
+	// PollInterval is the interval of polling reindex tasks
+	PollInterval time.Duration
+	// Lock guards the whole migration run against concurrent migrations. Optional
+	Lock dsync.Lock
+}
+
+// WithPollInterval sets the interval of polling reindex tasks
+func WithPollInterval(interval time.Duration) MigratorOptions {
+	return func(opt *MigratorOption) {
+		opt.PollInterval = interval
+	}
+}
+
+// WithLock guards the whole migration run with the distributed lock
+func WithLock(lock dsync.Lock) MigratorOptions {
+	return func(opt *MigratorOption) {
+		opt.Lock = lock
+	}
+}
+
+// Migrator applies registered index versions that are not applied yet.
+type Migrator struct {
+	registrar    *Registrar
+	versioner    Versioner
+	repo         opensearch.Repo[map[string]interface{}]
+	pollInterval time.Duration
+	lock         dsync.Lock
+}
+
+func NewMigrator(client opensearch.OpenClient, r *Registrar, v Versioner, opts ...MigratorOptions) *Migrator {
+	opt := MigratorOption{
+		PollInterval: defaultPollInterval,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	return &Migrator{
+		registrar:    r,
+		versioner:    v,
+		repo:         opensearch.NewRepo(&map[string]interface{}{}, client),
+		pollInterval: opt.PollInterval,
+		lock:         opt.Lock,
+	}
+}
+
+// Migrate applies pending index versions of each logical index in ascending order. For each version:
+// 1. the versioned index is created with declared mappings and settings
+// 2. documents are reindexed from the index of previous applied version, if any, and the reindex task is polled until completion
+// 3. read and write aliases are swapped to the new index atomically
+// 4. the index of previous version is deleted, if requested
+// 5. the version is recorded in VersionIndex
+//
+// Note: documents written to the previous index during reindex are not copied. Writes should be paused during migration,
+// or be replayable by the application.
+// If any version failed, it's recorded as failed and no further migration would happen until the record is removed.
+// The index created by the failed version is deleted, unless aliases are already swapped to it.
+//
+// When a lock is set via WithLock, no further version is applied once the lock is lost.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	if m.lock != nil {
+		return dsync.RunWithLock(ctx, m.lock, m.migrateAll)
+	}
+	return m.migrateAll(ctx)
+}
+
+func (m *Migrator) migrateAll(ctx context.Context) error {
+	if e := m.versioner.CreateVersionIndexIfNotExist(ctx); e != nil {
+		return e
+	}
+	applied, e := m.versioner.GetAppliedVersions(ctx)
+	if e != nil {
+		return e
+	}
+	// latest applied version of each logical index
+	latest := map[string]*AppliedVersion{}
+	for _, a := range applied {
+		if !a.Success {
+			return fmt.Errorf("stopping index migration because there is a failed step: %s v%d", a.Name, a.Version)
+		}
+		if prev, ok := latest[a.Name]; !ok || prev.Version < a.Version {
+			latest[a.Name] = a
+		}
+	}
+
+	sort.SliceStable(m.registrar.versions, func(i, j int) bool {
+		vi, vj := m.registrar.versions[i], m.registrar.versions[j]
+		return vi.Name < vj.Name || vi.Name == vj.Name && vi.Version < vj.Version
+	})
+	for _, v := range m.registrar.versions {
+		prev := latest[v.Name]
+		if prev != nil && prev.Version >= v.Version {
+			continue
+		}
+		// stop before next version if the context is cancelled, e.g. migration lock is lost
+		if ctx.Err() != nil {
+			return fmt.Errorf("index migration stopped before %s: %v", v, context.Cause(ctx))
+		}
+		if e := m.applyVersion(ctx, v, prev); e != nil {
+			return e
+		}
+		latest[v.Name] = &AppliedVersion{Name: v.Name, Version: v.Version, Index: v.IndexName(), Success: true}
+	}
+	return nil
+}
+
+func (m *Migrator) applyVersion(ctx context.Context, v *IndexVersion, prev *AppliedVersion) error {
+	logger.WithContext(ctx).Infof("Migrating index %s", v)
+	startTime := time.Now()
+	migrationErr := m.migrate(ctx, v, prev)
+	finishTime := time.Now()
+	record := &AppliedVersion{
+		Name:          v.Name,
+		Version:       v.Version,
+		Index:         v.IndexName(),
+		Description:   v.Description,
+		Success:       migrationErr == nil,
+		InstalledOn:   finishTime,
+		ExecutionTime: finishTime.Sub(startTime).Milliseconds(),
+	}
+	if migrationErr != nil {
+		if e := m.versioner.RecordAppliedVersion(ctx, record); e != nil {
+			logger.WithContext(ctx).Errorf("error recording failed index migration due to %v", e)
+		}
+		e := fmt.Errorf("index migration stopped at %s because of error: %v", v, migrationErr)
+		logger.WithContext(ctx).Errorf("%v", e)
+		return e
+	}
+	return m.versioner.RecordAppliedVersion(ctx, record)
+}
+
+func (m *Migrator) migrate(ctx context.Context, v *IndexVersion, prev *AppliedVersion) error {
+	body := map[string]interface{}{}
+	if len(v.Mappings) != 0 {
+		body["mappings"] = v.Mappings
+	}
+	if len(v.Settings) != 0 {
+		body["settings"] = v.Settings
+	}
+	if e := m.repo.IndicesCreate(ctx, v.IndexName(), body); e != nil {
+		return fmt.Errorf("unable to create index [%s]: %v", v.IndexName(), e)
+	}
+	if e := m.switchIndex(ctx, v, prev); e != nil {
+		m.deleteIndex(ctx, v.IndexName())
+		return e
+	}
+
+	// aliases already point to the new index, failing to delete the old index doesn't fail the version
+	if prev != nil && v.DeleteOld {
+		if e := m.repo.IndicesDelete(ctx, []string{prev.Index}); e != nil {
+			logger.WithContext(ctx).Warnf("unable to delete old index [%s], it should be deleted manually: %v", prev.Index, e)
+		}
+	}
+	return nil
+}
+
+// switchIndex reindexes documents from previous index if any, and swaps aliases to the index of given version
+func (m *Migrator) switchIndex(ctx context.Context, v *IndexVersion, prev *AppliedVersion) error {
+	actions := []opensearch.AliasAction{
+		opensearch.AddAlias(v.IndexName(), v.ReadAlias()),
+		opensearch.AddAlias(v.IndexName(), v.WriteAlias(), true),
+	}
+	if prev != nil {
+		if e := m.reindex(ctx, v, prev.Index); e != nil {
+			return e
+		}
+		actions = append([]opensearch.AliasAction{
+			opensearch.RemoveAlias(prev.Index, v.ReadAlias()),
+			opensearch.RemoveAlias(prev.Index, v.WriteAlias()),
+		}, actions...)
+	}
+	if e := m.repo.IndicesUpdateAliases(ctx, actions); e != nil {
+		return fmt.Errorf("unable to swap aliases to index [%s]: %v", v.IndexName(), e)
+	}
+	return nil
+}
+
+// deleteIndex deletes the index created by a failed version, so the version can be re-applied once the failed record
+// is removed. The deletion is not cancelled together with the migration context.
+func (m *Migrator) deleteIndex(ctx context.Context, index string) {
+	logger.WithContext(ctx).Infof("Deleting index [%s] of failed migration", index)
+	if e := m.repo.IndicesDelete(context.WithoutCancel(ctx), []string{index}); e != nil {
+		logger.WithContext(ctx).Warnf("unable to delete index [%s] of failed migration: %v", index, e)
+	}
+}
+
+// reindex copies documents from the old index, and polls the reindex task until it's completed
+func (m *Migrator) reindex(ctx context.Context, v *IndexVersion, oldIndex string) error {
+	body := map[string]interface{}{
+		"source": map[string]interface{}{"index": oldIndex},
+		"dest":   map[string]interface{}{"index": v.IndexName()},
+	}
+	if v.ReindexScript != nil {
+		body["script"] = v.ReindexScript
+	}
+	resp, e := m.repo.Reindex(ctx, body, opensearch.Reindex.WithWaitForCompletion(false))
+	if e != nil {
+		return fmt.Errorf("unable to reindex from [%s] to [%s]: %v", oldIndex, v.IndexName(), e)
+	}
+	logger.WithContext(ctx).Infof("Reindexing from [%s] to [%s] with task [%s]", oldIndex, v.IndexName(), resp.Task)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(m.pollInterval):
+		}
+		task, e := m.repo.TasksGet(ctx, resp.Task)
+		if e != nil {
+			return fmt.Errorf("unable to get reindex task [%s]: %v", resp.Task, e)
+		}
+		status := task.Task.Status
+		if !task.Completed {
+			logger.WithContext(ctx).Debugf("Reindex task [%s] in progress: %d/%d", resp.Task, status.Created+status.Updated, status.Total)
+			continue
+		}
+		return reindexTaskError(task)
+	}
+}
+
+// reindexTaskError returns error if the completed reindex task failed
+func reindexTaskError(task *opensearch.TaskResponse) error {
+	if len(task.Error) != 0 {
+		return fmt.Errorf("reindex task failed: %s", task.Error)
+	}
+	var result opensearch.ReindexResponse
+	if e := json.Unmarshal(task.Response, &result); e != nil {
+		return fmt.Errorf("unable to parse reindex result: %v", e)
+	}
+	if len(result.Failures) != 0 {
+		return fmt.Errorf("reindex task failed with %d failures: %s", len(result.Failures), result.Failures[0])
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package indexmigration_test
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/opensearch"
+	"github.com/cisco-open/go-lanai/pkg/opensearch/indexmigration"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/opensearchtest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"testing"
+	"time"
+)
+
+/*************************
+	Setup Test
+ *************************/
+
+const (
+	testIndexName = "migrate_test"
+)
+
+type Ticket struct {
+	Title    string
+	Status   string
+	Priority int `json:",omitempty"`
+}
+
+func IndexVersionV1() *indexmigration.IndexVersion {
+	return indexmigration.WithIndex(testIndexName, 1).
+		WithDesc("initial version").
+		WithSettings(map[string]interface{}{"number_of_shards": 1}).
+		WithMappings(map[string]interface{}{
+			"properties": map[string]interface{}{
+				"Title":  map[string]interface{}{"type": "text"},
+				"Status": map[string]interface{}{"type": "keyword"},
+			},
+		})
+}
+
+func IndexVersionV2() *indexmigration.IndexVersion {
+	return indexmigration.WithIndex(testIndexName, 2).
+		WithDesc("add priority").
+		WithSettings(map[string]interface{}{"number_of_shards": 1}).
+		WithMappings(map[string]interface{}{
+			"properties": map[string]interface{}{
+				"Title":    map[string]interface{}{"type": "text"},
+				"Status":   map[string]interface{}{"type": "keyword"},
+				"Priority": map[string]interface{}{"type": "integer"},
+			},
+		}).
+		WithReindexScript(&opensearch.Script{Source: "ctx._source.Priority = 1", Lang: "painless"}).
+		WithDeleteOld()
+}
+
+// IndexVersionV3 fails with invalid reindex script
+func IndexVersionV3() *indexmigration.IndexVersion {
+	return indexmigration.WithIndex(testIndexName, 3).
+		WithDesc("invalid script").
+		WithSettings(map[string]interface{}{"number_of_shards": 1}).
+		WithReindexScript(&opensearch.Script{Source: "ctx._source.Priority = ", Lang: "painless"})
+}
+
+func CleanupIndices(ctx context.Context, di *TestMigrationDI) error {
+	repo := opensearch.NewRepo(&Ticket{}, di.Client)
+	return repo.IndicesDelete(ctx, []string{
+		indexmigration.VersionIndex,
+		indexmigration.IndexName(testIndexName, 1),
+		indexmigration.IndexName(testIndexName, 2),
+		indexmigration.IndexName(testIndexName, 3),
+	}, opensearch.IndicesDelete.WithIgnoreUnavailable(true))
+}
+
+/*************************
+	Tests
+ *************************/
+
+//func TestMain(m *testing.M) {
+//	suitetest.RunTests(m,
+//		dbtest.EnableDBRecordMode(),
+//	)
+//}
+
+type TestMigrationDI struct {
+	fx.In
+	Client opensearch.OpenClient
+}
+
+func TestIndexMigration(t *testing.T) {
+	di := &TestMigrationDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		opensearchtest.WithOpenSearchPlayback(
+			opensearchtest.SetRecordDelay(time.Millisecond*1500),
+			opensearchtest.FuzzyJsonPaths("$.installed_on", "$.execution_time"),
+		),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithModules(opensearch.Module),
+		apptest.WithDI(di),
+		test.GomegaSubTest(SubTestInitialVersion(di), "TestInitialVersion"),
+		test.GomegaSubTest(SubTestReindexAndSwapAliases(di), "TestReindexAndSwapAliases"),
+		test.GomegaSubTest(SubTestNoPendingVersions(di), "TestNoPendingVersions"),
+		test.GomegaSubTest(SubTestFailedVersion(di), "TestFailedVersion"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestInitialVersion(di *TestMigrationDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		g.Expect(CleanupIndices(ctx, di)).To(Succeed(), "cleanup should not fail")
+		reg := indexmigration.NewRegistrar()
+		reg.AddIndexVersions(IndexVersionV1())
+		versioner := indexmigration.NewVersioner(di.Client)
+		err := indexmigration.NewMigrator(di.Client, reg, versioner).Migrate(ctx)
+		g.Expect(err).To(Succeed(), "migration should not fail")
+
+		applied, err := versioner.GetAppliedVersions(ctx)
+		g.Expect(err).To(Succeed(), "getting applied versions should not fail")
+		g.Expect(applied).To(HaveLen(1), "applied versions should have correct count")
+		g.Expect(applied[0].Version).To(Equal(1), "applied version should be correct")
+		g.Expect(applied[0].Success).To(BeTrue(), "applied version should be successful")
+
+		// write via write alias
+		repo := opensearch.NewRepo(&Ticket{}, di.Client)
+		v1 := IndexVersionV1()
+		for _, ticket := range []Ticket{{Title: "first", Status: "open"}, {Title: "second", Status: "closed"}} {
+			err = repo.Index(ctx, v1.WriteAlias(), ticket, opensearch.Index.WithRefresh("true"))
+			g.Expect(err).To(Succeed(), "indexing via write alias should not fail")
+		}
+	}
+}
+
+func SubTestReindexAndSwapAliases(di *TestMigrationDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		reg := indexmigration.NewRegistrar()
+		reg.AddIndexVersions(IndexVersionV2(), IndexVersionV1())
+		versioner := indexmigration.NewVersioner(di.Client)
+		// deletion of old index is reported as failed, which should not fail the version
+		var deleteAttempts int
+		hook := opensearch.AfterHookBase{
+			Identifier: "fail-indices-delete",
+			F: func(ctx context.Context, after opensearch.AfterContext) context.Context {
+				if after.CommandType() == opensearch.CmdIndicesDelete {
+					deleteAttempts++
+					*after.Err = errors.New("simulated failure")
+				}
+				return ctx
+			},
+		}
+		di.Client.AddAfterHook(hook)
+		defer di.Client.RemoveAfterHook(hook)
+		migrator := indexmigration.NewMigrator(di.Client, reg, versioner, indexmigration.WithPollInterval(10*time.Millisecond))
+		err := migrator.Migrate(ctx)
+		g.Expect(err).To(Succeed(), "migration should not fail even if old index cannot be deleted")
+		g.Expect(deleteAttempts).To(Equal(1), "old index should be deleted")
+
+		// documents are reindexed with script
+		repo := opensearch.NewRepo(&Ticket{}, di.Client)
+		v2 := IndexVersionV2()
+		var tickets []Ticket
+		total, err := repo.Search(ctx, &tickets, opensearch.NewSearchBody().Query(opensearch.MatchAllQuery()),
+			opensearch.Search.WithIndex(v2.ReadAlias()))
+		g.Expect(err).To(Succeed(), "searching via read alias should not fail")
+		g.Expect(total).To(Equal(2), "all documents should be reindexed")
+		for _, ticket := range tickets {
+			g.Expect(ticket.Priority).To(Equal(1), "documents should be transformed by reindex script")
+		}
+
+		// aliases are swapped, and old index is deleted
+		aliases, err := repo.IndicesGetAlias(ctx, []string{v2.ReadAlias(), v2.WriteAlias()})
+		g.Expect(err).To(Succeed(), "getting aliases should not fail")
+		g.Expect(aliases).To(HaveLen(1), "aliases should point to a single index")
+		g.Expect(aliases).To(HaveKey(HavePrefix(v2.IndexName())), "aliases should point to new index")
+		exists, err := repo.IndicesExists(ctx, []string{indexmigration.IndexName(testIndexName, 1)})
+		g.Expect(err).To(Succeed(), "checking old index should not fail")
+		g.Expect(exists).To(BeFalse(), "old index should be deleted")
+
+		applied, err := versioner.GetAppliedVersions(ctx)
+		g.Expect(err).To(Succeed(), "getting applied versions should not fail")
+		g.Expect(applied).To(HaveLen(2), "applied versions should have correct count")
+		g.Expect(applied).To(HaveEach(HaveField("Success", BeTrue())), "applied versions should be successful")
+	}
+}
+
+func SubTestNoPendingVersions(di *TestMigrationDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		reg := indexmigration.NewRegistrar()
+		reg.AddIndexVersions(IndexVersionV1(), IndexVersionV2())
+		err := indexmigration.NewMigrator(di.Client, reg, indexmigration.NewVersioner(di.Client)).Migrate(ctx)
+		g.Expect(err).To(Succeed(), "migration without pending versions should not fail")
+	}
+}
+
+func SubTestFailedVersion(di *TestMigrationDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		reg := indexmigration.NewRegistrar()
+		reg.AddIndexVersions(IndexVersionV1(), IndexVersionV2(), IndexVersionV3())
+		versioner := indexmigration.NewVersioner(di.Client)
+		var deleted int
+		hook := opensearch.AfterHookBase{
+			Identifier: "count-indices-delete",
+			F: func(ctx context.Context, after opensearch.AfterContext) context.Context {
+				if after.CommandType() == opensearch.CmdIndicesDelete && *after.Err == nil && !after.Resp.IsError() {
+					deleted++
+				}
+				return ctx
+			},
+		}
+		di.Client.AddAfterHook(hook)
+		defer di.Client.RemoveAfterHook(hook)
+		err := indexmigration.NewMigrator(di.Client, reg, versioner).Migrate(ctx)
+		g.Expect(err).To(HaveOccurred(), "migration with invalid reindex script should fail")
+		g.Expect(deleted).To(Equal(1), "index of failed version should be deleted")
+
+		// created index is deleted, aliases are not changed
+		repo := opensearch.NewRepo(&Ticket{}, di.Client)
+		v3 := IndexVersionV3()
+		exists, err := repo.IndicesExists(ctx, []string{v3.IndexName()})
+		g.Expect(err).To(Succeed(), "checking index of failed version should not fail")
+		g.Expect(exists).To(BeFalse(), "index of failed version should be deleted")
+		aliases, err := repo.IndicesGetAlias(ctx, []string{v3.ReadAlias(), v3.WriteAlias()})
+		g.Expect(err).To(Succeed(), "getting aliases should not fail")
+		g.Expect(aliases).To(HaveKey(HavePrefix(IndexVersionV2().IndexName())), "aliases should still point to previous index")
+
+		applied, err := versioner.GetAppliedVersions(ctx)
+		g.Expect(err).To(Succeed(), "getting applied versions should not fail")
+		g.Expect(applied).To(ContainElement(HaveField("Success", BeFalse())), "failed version should be recorded")
+	}
+}
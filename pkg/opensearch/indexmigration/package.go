@@ -0,0 +1,85 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package indexmigration provides zero-downtime migrations of OpenSearch index mappings and settings,
+// using versioned indices, reindex and alias swapping. See Migrator
+package indexmigration
+
+import (
+	"context"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/bootstrap"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/pkg/log"
+	"github.com/cisco-open/go-lanai/pkg/opensearch"
+	"go.uber.org/fx"
+)
+
+const (
+	migrationLockKeyFormat = "index-migration/%s"
+)
+
+var logger = log.New("Search.Migration")
+
+var Module = &bootstrap.Module{
+	Name:       "opensearch migration",
+	Precedence: bootstrap.CommandLineRunnerPrecedence,
+	Options: []fx.Option{
+		fx.Provide(NewRegistrar),
+		fx.Provide(NewVersioner),
+		fx.Provide(provideMigrationRunner()),
+	},
+}
+
+// Use enables index migration as a CLI runner. opensearch.Module is required.
+// It can be used together with "migration" package in the same migration app.
+func Use() {
+	bootstrap.Register(Module)
+	bootstrap.EnableCliRunnerMode()
+}
+
+func provideMigrationRunner() fx.Annotated {
+	return fx.Annotated{
+		Group:  bootstrap.FxCliRunnerGroup,
+		Target: newMigrationRunner,
+	}
+}
+
+type migrationRunnerIn struct {
+	fx.In
+	AppCtx *bootstrap.ApplicationContext
+	R      *Registrar
+	V      Versioner
+	Client opensearch.OpenClient
+	// dsync.SyncManager is used to prevent concurrent migrations. Optional
+	dsync.OptionalSyncManagerDI
+}
+
+func newMigrationRunner(di migrationRunnerIn) bootstrap.CliRunner {
+	return func(ctx context.Context) error {
+		var opts []MigratorOptions
+		lock, e := di.OptionalLock(fmt.Sprintf(migrationLockKeyFormat, di.AppCtx.Name()))
+		switch {
+		case e != nil:
+			return e
+		case lock == nil:
+			logger.Warnf("dsync.SyncManager is not available, concurrent index migrations are not prevented")
+		default:
+			opts = append(opts, WithLock(lock))
+		}
+		return NewMigrator(di.Client, di.R, di.V, opts...).Migrate(ctx)
+	}
+}
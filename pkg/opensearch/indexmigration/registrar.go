@@ -0,0 +1,115 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package indexmigration
+
+import (
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/opensearch"
+)
+
+const (
+	// WriteAliasSuffix is appended to the index name to form the write alias
+	WriteAliasSuffix = "_write"
+)
+
+type Registrar struct {
+	versions []*IndexVersion
+}
+
+func NewRegistrar() *Registrar {
+	return &Registrar{}
+}
+
+func (r *Registrar) AddIndexVersions(v ...*IndexVersion) {
+	r.versions = append(r.versions, v...)
+}
+
+// IndexVersion declares a version of a logical index.
+// Each version is stored in its own index named "<name>_v<version>", and is accessed via aliases:
+// - reads use the alias "<name>"
+// - writes use the alias "<name>_write"
+// When a new version is applied, documents are reindexed from the index of previous version, and both aliases are
+// swapped to the new index atomically.
+type IndexVersion struct {
+	Name        string
+	Version     int
+	Description string
+	// Mappings of the new index, see https://opensearch.org/docs/latest/field-types/
+	Mappings map[string]interface{}
+	// Settings of the new index, see https://opensearch.org/docs/latest/api-reference/index-apis/create-index/#index-settings
+	Settings map[string]interface{}
+	// ReindexScript optionally transforms documents when they are copied from the index of previous version
+	ReindexScript *opensearch.Script
+	// DeleteOld deletes the index of previous version after aliases are swapped
+	DeleteOld bool
+}
+
+func WithIndex(name string, version int) *IndexVersion {
+	return &IndexVersion{
+		Name:    name,
+		Version: version,
+	}
+}
+
+func (v *IndexVersion) WithDesc(d string) *IndexVersion {
+	v.Description = d
+	return v
+}
+
+func (v *IndexVersion) WithMappings(mappings map[string]interface{}) *IndexVersion {
+	v.Mappings = mappings
+	return v
+}
+
+func (v *IndexVersion) WithSettings(settings map[string]interface{}) *IndexVersion {
+	v.Settings = settings
+	return v
+}
+
+func (v *IndexVersion) WithReindexScript(script *opensearch.Script) *IndexVersion {
+	v.ReindexScript = script
+	return v
+}
+
+func (v *IndexVersion) WithDeleteOld() *IndexVersion {
+	v.DeleteOld = true
+	return v
+}
+
+// IndexName is the name of the versioned index
+func (v *IndexVersion) IndexName() string {
+	return IndexName(v.Name, v.Version)
+}
+
+// ReadAlias is the alias for searching documents
+func (v *IndexVersion) ReadAlias() string {
+	return v.Name
+}
+
+// WriteAlias is the alias for indexing documents
+func (v *IndexVersion) WriteAlias() string {
+	return v.Name + WriteAliasSuffix
+}
+
+func (v *IndexVersion) String() string {
+	return fmt.Sprintf("%s v%d", v.Name, v.Version)
+}
+
+// IndexName returns the name of the versioned index of given logical index name and version
+func IndexName(name string, version int) string {
+	return fmt.Sprintf("%s_v%d", name, version)
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package indexmigration
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/opensearch"
+	"time"
+)
+
+const (
+	// VersionIndex is the system index that tracks applied index versions
+	VersionIndex = "index_migration_versions"
+	// maxAppliedVersions is the max number of applied versions that can be tracked
+	maxAppliedVersions = 10000
+)
+
+// AppliedVersion is the record of an applied IndexVersion
+type AppliedVersion struct {
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	Index       string    `json:"index"`
+	Description string    `json:"description"`
+	Success     bool      `json:"success"`
+	InstalledOn time.Time `json:"installed_on"`
+	// ExecutionTime in milliseconds
+	ExecutionTime int64 `json:"execution_time"`
+}
+
+type Versioner interface {
+	CreateVersionIndexIfNotExist(ctx context.Context) error
+	GetAppliedVersions(ctx context.Context) ([]*AppliedVersion, error)
+	RecordAppliedVersion(ctx context.Context, v *AppliedVersion) error
+}
+
+// OpenSearchVersioner implements Versioner, applied versions are stored in VersionIndex
+type OpenSearchVersioner struct {
+	repo opensearch.Repo[AppliedVersion]
+}
+
+func NewVersioner(client opensearch.OpenClient) Versioner {
+	return &OpenSearchVersioner{
+		repo: opensearch.NewRepo(&AppliedVersion{}, client),
+	}
+}
+
+func (v *OpenSearchVersioner) CreateVersionIndexIfNotExist(ctx context.Context) error {
+	exists, e := v.repo.IndicesExists(ctx, []string{VersionIndex})
+	if e != nil || exists {
+		return e
+	}
+	return v.repo.IndicesCreate(ctx, VersionIndex, map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"name":           map[string]interface{}{"type": "keyword"},
+				"version":        map[string]interface{}{"type": "integer"},
+				"index":          map[string]interface{}{"type": "keyword"},
+				"description":    map[string]interface{}{"type": "text"},
+				"success":        map[string]interface{}{"type": "boolean"},
+				"installed_on":   map[string]interface{}{"type": "date"},
+				"execution_time": map[string]interface{}{"type": "long"},
+			},
+		},
+	})
+}
+
+func (v *OpenSearchVersioner) GetAppliedVersions(ctx context.Context) ([]*AppliedVersion, error) {
+	body := opensearch.NewSearchBody().Query(opensearch.MatchAllQuery()).Size(maxAppliedVersions)
+	var records []AppliedVersion
+	if _, e := v.repo.Search(ctx, &records, body, opensearch.Search.WithIndex(VersionIndex)); e != nil {
+		return nil, e
+	}
+	applied := make([]*AppliedVersion, len(records))
+	for i := range records {
+		applied[i] = &records[i]
+	}
+	return applied, nil
+}
+
+// RecordAppliedVersion creates or overwrites the record of the index version
+func (v *OpenSearchVersioner) RecordAppliedVersion(ctx context.Context, applied *AppliedVersion) error {
+	return v.repo.Index(ctx, VersionIndex, *applied,
+		opensearch.Index.WithDocumentID(IndexName(applied.Name, applied.Version)),
+		opensearch.Index.WithRefresh("true"),
+	)
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"net/http"
+)
+
+func (c *RepoImpl[T]) IndicesExists(ctx context.Context, index []string, o ...Option[opensearchapi.IndicesExistsRequest]) (bool, error) {
+	resp, err := c.client.IndicesExists(ctx, index, o...)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.IsError():
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		return false, fmt.Errorf("error status code: %d", resp.StatusCode)
+	default:
+		return true, nil
+	}
+}
+
+func (c *OpenClientImpl) IndicesExists(ctx context.Context, index []string, o ...Option[opensearchapi.IndicesExistsRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.IndicesExistsRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdIndicesExists, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, IndicesExists.WithContext(ctx))
+	resp, err := c.client.API.Indices.Exists(index, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdIndicesExists, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type indicesExistsExt struct {
+	opensearchapi.IndicesExists
+}
+
+var IndicesExists = indicesExistsExt{}
@@ -0,0 +1,86 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+	"net/http"
+)
+
+// IndicesGetAlias returns names of the aliases, grouped by the indices they point to.
+// An empty map is returned if none of the aliases exists.
+func (c *RepoImpl[T]) IndicesGetAlias(ctx context.Context, name []string, o ...Option[opensearchapi.IndicesGetAliasRequest]) (map[string][]string, error) {
+	resp, err := c.client.IndicesGetAlias(ctx, name, o...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return map[string][]string{}, nil
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		return nil, fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var details map[string]struct {
+		Aliases map[string]interface{} `json:"aliases"`
+	}
+	if err := json.Unmarshal(respBody, &details); err != nil {
+		return nil, err
+	}
+	aliases := make(map[string][]string, len(details))
+	for index, detail := range details {
+		for alias := range detail.Aliases {
+			aliases[index] = append(aliases[index], alias)
+		}
+	}
+	return aliases, nil
+}
+
+func (c *OpenClientImpl) IndicesGetAlias(ctx context.Context, name []string, o ...Option[opensearchapi.IndicesGetAliasRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.IndicesGetAliasRequest), len(o)+1)
+	options[0] = IndicesGetAlias.WithName(name...)
+	for i, v := range o {
+		options[i+1] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdIndicesGetAlias, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, IndicesGetAlias.WithContext(ctx))
+	resp, err := c.client.API.Indices.GetAlias(options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdIndicesGetAlias, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type indicesGetAliasExt struct {
+	opensearchapi.IndicesGetAlias
+}
+
+var IndicesGetAlias = indicesGetAliasExt{}
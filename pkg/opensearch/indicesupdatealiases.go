@@ -0,0 +1,103 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+)
+
+// AliasAction is one of the actions of Repo.IndicesUpdateAliases. Exactly one of Add, Remove and RemoveIndex should be set.
+// See AddAlias, RemoveAlias and RemoveIndex
+//
+// [Format]: https://opensearch.org/docs/latest/api-reference/alias/#request-body
+type AliasAction struct {
+	Add         *AliasActionTarget `json:"add,omitempty"`
+	Remove      *AliasActionTarget `json:"remove,omitempty"`
+	RemoveIndex *AliasActionTarget `json:"remove_index,omitempty"`
+}
+
+type AliasActionTarget struct {
+	Index        string `json:"index,omitempty"`
+	Alias        string `json:"alias,omitempty"`
+	IsWriteIndex *bool  `json:"is_write_index,omitempty"`
+}
+
+// AddAlias adds the alias to the index. When isWriteIndex is set, writes via the alias go to the index.
+func AddAlias(index string, alias string, isWriteIndex ...bool) AliasAction {
+	target := &AliasActionTarget{Index: index, Alias: alias}
+	if len(isWriteIndex) != 0 {
+		target.IsWriteIndex = &isWriteIndex[0]
+	}
+	return AliasAction{Add: target}
+}
+
+// RemoveAlias removes the alias from the index
+func RemoveAlias(index string, alias string) AliasAction {
+	return AliasAction{Remove: &AliasActionTarget{Index: index, Alias: alias}}
+}
+
+// RemoveIndex deletes the index
+func RemoveIndex(index string) AliasAction {
+	return AliasAction{RemoveIndex: &AliasActionTarget{Index: index}}
+}
+
+func (c *RepoImpl[T]) IndicesUpdateAliases(ctx context.Context, actions []AliasAction, o ...Option[opensearchapi.IndicesUpdateAliasesRequest]) error {
+	var buffer bytes.Buffer
+	err := json.NewEncoder(&buffer).Encode(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return fmt.Errorf("unable to encode alias actions: %w", err)
+	}
+	resp, err := c.client.IndicesUpdateAliases(ctx, &buffer, o...)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		return fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *OpenClientImpl) IndicesUpdateAliases(ctx context.Context, body io.Reader, o ...Option[opensearchapi.IndicesUpdateAliasesRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.IndicesUpdateAliasesRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdIndicesUpdateAliases, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, IndicesUpdateAliases.WithContext(ctx))
+	resp, err := c.client.API.Indices.UpdateAliases(body, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdIndicesUpdateAliases, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type indicesUpdateAliasesExt struct {
+	opensearchapi.IndicesUpdateAliases
+}
+
+var IndicesUpdateAliases = indicesUpdateAliasesExt{}
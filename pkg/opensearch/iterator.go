@@ -0,0 +1,352 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// IteratorMode defines how Repo.SearchIterator walks through search results
+type IteratorMode int
+
+const (
+	// IteratorModeAuto uses IteratorModePointInTime for sorted queries, and IteratorModeScroll for unsorted queries
+	// or when Point in Time is not available (e.g. OpenSearch before 2.4)
+	IteratorModeAuto IteratorMode = iota
+	// IteratorModePointInTime pages through results using Point in Time and "search_after". The query must be sorted,
+	// and the sort should include a unique tiebreaker field.
+	IteratorModePointInTime
+	// IteratorModeScroll pages through results using scroll context
+	IteratorModeScroll
+)
+
+func (m IteratorMode) String() string {
+	switch m {
+	case IteratorModePointInTime:
+		return "point in time"
+	case IteratorModeScroll:
+		return "scroll"
+	default:
+		return "auto"
+	}
+}
+
+const (
+	defaultIteratorPageSize  = 1000
+	defaultIteratorKeepAlive = time.Minute
+)
+
+type IteratorOptions func(opt *IteratorOption)
+type IteratorOption struct {
+	Mode IteratorMode
+	// PageSize is the number of documents fetched per request
+	PageSize int
+	// KeepAlive is how long the Point in Time or scroll context is kept between requests
+	KeepAlive time.Duration
+}
+
+func IterateWithMode(mode IteratorMode) IteratorOptions {
+	return func(opt *IteratorOption) {
+		opt.Mode = mode
+	}
+}
+
+func IterateWithPageSize(size int) IteratorOptions {
+	return func(opt *IteratorOption) {
+		opt.PageSize = size
+	}
+}
+
+func IterateWithKeepAlive(keepAlive time.Duration) IteratorOptions {
+	return func(opt *IteratorOption) {
+		opt.KeepAlive = keepAlive
+	}
+}
+
+// IteratorProgress is the progress of Repo.SearchIterator.
+// Hooks can get it from their context via IteratorProgressFromContext when invoked by the iterator.
+type IteratorProgress struct {
+	Mode IteratorMode
+	// Page is the current page (starting from 1)
+	Page int
+	// Fetched is number of documents fetched before current page
+	Fetched int
+	// Total is total hits of the query, available after first page is fetched
+	Total int
+}
+
+type ckIteratorProgress struct{}
+
+// IteratorProgressFromContext returns the progress of the iterator that issued current request, if any
+func IteratorProgressFromContext(ctx context.Context) (IteratorProgress, bool) {
+	if p, ok := ctx.Value(ckIteratorProgress{}).(*IteratorProgress); ok {
+		return *p, true
+	}
+	return IteratorProgress{}, false
+}
+
+func (c *RepoImpl[T]) SearchIterator(ctx context.Context, index []string, body interface{}, opts ...IteratorOptions) iter.Seq2[T, error] {
+	opt := IteratorOption{
+		Mode:      IteratorModeAuto,
+		PageSize:  defaultIteratorPageSize,
+		KeepAlive: defaultIteratorKeepAlive,
+	}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	if opt.PageSize <= 0 {
+		opt.PageSize = defaultIteratorPageSize
+	}
+	if opt.KeepAlive <= 0 {
+		opt.KeepAlive = defaultIteratorKeepAlive
+	}
+	return func(yield func(T, error) bool) {
+		query, e := iteratorQuery(body, opt.PageSize)
+		if e != nil {
+			var zero T
+			yield(zero, e)
+			return
+		}
+		it := searchIterator[T]{
+			client:   c.client,
+			index:    index,
+			query:    query,
+			option:   opt,
+			progress: &IteratorProgress{},
+		}
+		it.iterate(context.WithValue(ctx, ckIteratorProgress{}, it.progress), yield)
+	}
+}
+
+/*************************
+	Iterator
+ *************************/
+
+type searchIterator[T any] struct {
+	client   OpenClient
+	index    []string
+	query    map[string]interface{}
+	option   IteratorOption
+	progress *IteratorProgress
+}
+
+func (it *searchIterator[T]) iterate(ctx context.Context, yield func(T, error) bool) {
+	var zero T
+	mode := it.option.Mode
+	_, sorted := it.query["sort"]
+	switch {
+	case mode == IteratorModeAuto && !sorted:
+		mode = IteratorModeScroll
+	case mode == IteratorModePointInTime && !sorted:
+		yield(zero, fmt.Errorf("iterating with point in time requires sorted query"))
+		return
+	}
+
+	if mode != IteratorModeScroll {
+		pitID, e := it.createPointInTime(ctx)
+		switch {
+		case e == nil:
+			it.progress.Mode = IteratorModePointInTime
+			it.iteratePointInTime(ctx, pitID, yield)
+			return
+		case mode == IteratorModeAuto:
+			logger.WithContext(ctx).Debugf("point in time is not available, iterating with scroll: %v", e)
+		default:
+			yield(zero, e)
+			return
+		}
+	}
+	it.progress.Mode = IteratorModeScroll
+	it.iterateScroll(ctx, yield)
+}
+
+func (it *searchIterator[T]) iteratePointInTime(ctx context.Context, pitID string, yield func(T, error) bool) {
+	var zero T
+	defer func() { it.deletePointInTime(ctx, pitID) }()
+	for {
+		it.query["pit"] = map[string]interface{}{
+			"id":         pitID,
+			"keep_alive": formatDuration(it.option.KeepAlive),
+		}
+		var buffer bytes.Buffer
+		if e := json.NewEncoder(&buffer).Encode(it.query); e != nil {
+			yield(zero, fmt.Errorf("unable to encode query: %w", e))
+			return
+		}
+		// Note: search with point in time should not specify indices
+		it.nextPage()
+		resp, e := it.client.Search(ctx, Search.WithBody(&buffer))
+		page, e := it.parsePage(ctx, resp, e)
+		if e != nil {
+			yield(zero, e)
+			return
+		}
+		if page.PitID != "" {
+			pitID = page.PitID
+		}
+		if !it.yieldPage(page, yield) {
+			return
+		}
+		it.query["search_after"] = page.Hits.Hits[len(page.Hits.Hits)-1].Sort
+	}
+}
+
+func (it *searchIterator[T]) iterateScroll(ctx context.Context, yield func(T, error) bool) {
+	var zero T
+	var buffer bytes.Buffer
+	if e := json.NewEncoder(&buffer).Encode(it.query); e != nil {
+		yield(zero, fmt.Errorf("unable to encode query: %w", e))
+		return
+	}
+	it.nextPage()
+	resp, e := it.client.Search(ctx,
+		Search.WithIndex(it.index...),
+		Search.WithBody(&buffer),
+		Search.WithScroll(it.option.KeepAlive),
+	)
+	page, e := it.parsePage(ctx, resp, e)
+	if e != nil {
+		yield(zero, e)
+		return
+	}
+	scrollID := page.ScrollID
+	defer func() { it.clearScroll(ctx, scrollID) }()
+	for it.yieldPage(page, yield) {
+		scrollBody, e := json.Marshal(map[string]interface{}{
+			"scroll_id": scrollID,
+			"scroll":    formatDuration(it.option.KeepAlive),
+		})
+		if e != nil {
+			yield(zero, e)
+			return
+		}
+		it.nextPage()
+		resp, e = it.client.Scroll(ctx, Scroll.WithBody(bytes.NewReader(scrollBody)))
+		if page, e = it.parsePage(ctx, resp, e); e != nil {
+			yield(zero, e)
+			return
+		}
+		if page.ScrollID != "" {
+			scrollID = page.ScrollID
+		}
+	}
+}
+
+// yieldPage yields all documents of the page, returns true if next page should be fetched
+func (it *searchIterator[T]) yieldPage(page *SearchResponse[T], yield func(T, error) bool) bool {
+	if it.progress.Page == 1 {
+		it.progress.Total = page.Hits.Total.Value
+	}
+	for _, hit := range page.Hits.Hits {
+		if !yield(hit.Source, nil) {
+			return false
+		}
+	}
+	it.progress.Fetched += len(page.Hits.Hits)
+	return len(page.Hits.Hits) != 0 && len(page.Hits.Hits) >= it.option.PageSize
+}
+
+func (it *searchIterator[T]) nextPage() {
+	it.progress.Page++
+}
+
+func (it *searchIterator[T]) parsePage(ctx context.Context, resp *opensearchapi.Response, err error) (*SearchResponse[T], error) {
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w", ErrIndexNotFound)
+		}
+		return nil, fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+	return UnmarshalResponse[SearchResponse[T]](resp)
+}
+
+func (it *searchIterator[T]) createPointInTime(ctx context.Context) (string, error) {
+	resp, e := it.client.PointInTimeCreate(ctx, it.index, PointInTimeCreate.WithKeepAlive(it.option.KeepAlive))
+	if e != nil {
+		return "", e
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		return "", fmt.Errorf("unable to create point in time: error status code: %d", resp.StatusCode)
+	}
+	pit, e := UnmarshalResponse[PointInTimeCreateResponse](resp)
+	if e != nil {
+		return "", e
+	}
+	return pit.PitID, nil
+}
+
+// deletePointInTime deletes the point in time, even if the iteration is stopped because the context is cancelled
+func (it *searchIterator[T]) deletePointInTime(ctx context.Context, pitID string) {
+	ctx = context.WithoutCancel(ctx)
+	resp, e := it.client.PointInTimeDelete(ctx, []string{pitID})
+	if e == nil && resp.IsError() {
+		e = fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+	if e != nil {
+		logger.WithContext(ctx).Warnf("unable to delete point in time: %v", e)
+	}
+}
+
+// clearScroll clears the scroll context, even if the iteration is stopped because the context is cancelled
+func (it *searchIterator[T]) clearScroll(ctx context.Context, scrollID string) {
+	if scrollID == "" {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	body, _ := json.Marshal(map[string]interface{}{"scroll_id": []string{scrollID}})
+	resp, e := it.client.ClearScroll(ctx, ClearScroll.WithBody(bytes.NewReader(body)))
+	if e == nil && resp.IsError() {
+		e = fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+	if e != nil {
+		logger.WithContext(ctx).Warnf("unable to clear scroll: %v", e)
+	}
+}
+
+// iteratorQuery converts given query body to a map, so paging parameters can be set
+func iteratorQuery(body interface{}, pageSize int) (map[string]interface{}, error) {
+	query := map[string]interface{}{}
+	if body != nil {
+		data, e := json.Marshal(body)
+		if e != nil {
+			return nil, fmt.Errorf("unable to encode query: %w", e)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if e := decoder.Decode(&query); e != nil {
+			return nil, fmt.Errorf("query should be a JSON object: %w", e)
+		}
+	}
+	if _, ok := query["from"]; ok {
+		return nil, errors.New(`"from" is not supported by search iterator`)
+	}
+	query["size"] = pageSize
+	return query, nil
+}
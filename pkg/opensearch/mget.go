@@ -0,0 +1,82 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+	"net/http"
+)
+
+// MGetResponse modeled after https://opensearch.org/docs/latest/api-reference/document-apis/multi-get/#response-body-fields
+type MGetResponse[T any] struct {
+	Docs []Document[T] `json:"docs"`
+}
+
+func (c *RepoImpl[T]) MGet(ctx context.Context, index string, ids []string, o ...Option[opensearchapi.MgetRequest]) ([]Document[T], error) {
+	var buffer bytes.Buffer
+	err := json.NewEncoder(&buffer).Encode(map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode ids: %w", err)
+	}
+	o = append(o, MGet.WithIndex(index))
+	resp, err := c.client.MGet(ctx, &buffer, o...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w", ErrIndexNotFound)
+		}
+		return nil, fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+	mgetResp, err := UnmarshalResponse[MGetResponse[T]](resp)
+	if err != nil {
+		return nil, err
+	}
+	return mgetResp.Docs, nil
+}
+
+func (c *OpenClientImpl) MGet(ctx context.Context, body io.Reader, o ...Option[opensearchapi.MgetRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.MgetRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdMGet, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, MGet.WithContext(ctx))
+	resp, err := c.client.API.Mget(body, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdMGet, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type mgetExt struct {
+	opensearchapi.Mget
+}
+
+var MGet = mgetExt{}
@@ -92,6 +92,10 @@ func TestOpenSearch(t *testing.T) {
         test.GomegaSubTest(SubTestTemplateAndAlias(di), "SubTestTemplateAndAlias"),
         test.GomegaSubTest(SubTestNewBulkIndexer(di), "SubTestNewBulkIndexer"),
         test.GomegaSubTest(SubTestHealth(di), "TestHealth"),
+        test.GomegaSubTest(SubTestDocumentCRUD(di), "SubTestDocumentCRUD"),
+        test.GomegaSubTest(SubTestSearchIterator(di), "SubTestSearchIterator"),
+        test.GomegaSubTest(SubTestAggregations(di), "SubTestAggregations"),
+        test.GomegaSubTest(SubTestFencingToken(di), "SubTestFencingToken"),
     )
 }
 
@@ -728,3 +732,283 @@ func SubTestHealth(di *opensearchDI) test.GomegaSubTestFunc {
        g.Expect(h.Status()).To(Equal(health.StatusUp))
     }
 }
+
+func SubTestDocumentCRUD(di *opensearchDI) test.GomegaSubTestFunc {
+    return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+        const index = "auditlog"
+        const docId = "crud-1"
+        testEvent := testdata.GenericAuditEvent{
+            Client_ID: "CRUD",
+            SubType:   "SYNCHRONIZED",
+            Time:      time.Date(2019, 10, 15, 0, 0, 0, 0, time.UTC),
+        }
+        err := di.FakeService.Repo.Index(ctx, index, testEvent,
+            opensearch.Index.WithDocumentID(docId),
+            opensearch.Index.WithRefresh("true"),
+        )
+        g.Expect(err).To(Succeed(), "index should not fail")
+
+        // Get & MGet
+        doc, err := di.FakeService.Repo.Get(ctx, index, docId)
+        g.Expect(err).To(Succeed(), "get should not fail")
+        g.Expect(doc.Found).To(BeTrue(), "document should be found")
+        g.Expect(doc.Source.Client_ID).To(Equal(testEvent.Client_ID), "document should be correct")
+
+        docs, err := di.FakeService.Repo.MGet(ctx, index, []string{docId, "non-existing"})
+        g.Expect(err).To(Succeed(), "mget should not fail")
+        g.Expect(docs).To(HaveLen(2), "mget should return all requested documents")
+        g.Expect(docs[0].Found).To(BeTrue(), "existing document should be found")
+        g.Expect(docs[0].Source.Client_ID).To(Equal(testEvent.Client_ID), "existing document should be correct")
+        g.Expect(docs[1].Found).To(BeFalse(), "non-existing document should not be found")
+
+        // Update with optimistic concurrency control
+        updated, err := di.FakeService.Repo.Update(ctx, index, docId,
+            opensearch.PartialUpdate(map[string]interface{}{"Severity": "HIGH"}),
+            opensearch.Update.WithSeqNoPrimaryTerm(doc.SeqNo, doc.PrimaryTerm),
+            opensearch.Update.WithRefresh("true"),
+        )
+        g.Expect(err).To(Succeed(), "partial update should not fail")
+        g.Expect(updated.Result).To(Equal("updated"), "partial update should have correct result")
+        g.Expect(updated.SeqNo).To(BeNumerically(">", doc.SeqNo), "partial update should increase seq_no")
+
+        _, err = di.FakeService.Repo.Update(ctx, index, docId,
+            opensearch.PartialUpdate(map[string]interface{}{"Severity": "LOW"}),
+            opensearch.Update.WithSeqNoPrimaryTerm(doc.SeqNo, doc.PrimaryTerm),
+        )
+        g.Expect(err).To(MatchError(opensearch.ErrVersionConflict), "stale update should fail with version conflict")
+
+        updated, err = di.FakeService.Repo.Update(ctx, index, docId,
+            opensearch.ScriptedUpdate(opensearch.Script{
+                Source: "ctx._source.Keywords = params.keywords",
+                Params: map[string]interface{}{"keywords": "scripted"},
+            }),
+            opensearch.Update.WithRefresh("true"),
+        )
+        g.Expect(err).To(Succeed(), "scripted update should not fail")
+        doc, err = di.FakeService.Repo.Get(ctx, index, docId)
+        g.Expect(err).To(Succeed(), "get should not fail")
+        g.Expect(doc.SeqNo).To(Equal(updated.SeqNo), "document should have latest seq_no")
+        g.Expect(doc.Source.Severity).To(Equal("HIGH"), "document should be partially updated")
+        g.Expect(doc.Source.Keywords).To(Equal("scripted"), "document should be updated by script")
+
+        // Count, UpdateByQuery & DeleteByQuery
+        query := map[string]interface{}{
+            "query": map[string]interface{}{
+                "match": map[string]interface{}{
+                    "SubType": "W",
+                },
+            },
+        }
+        total, err := di.FakeService.Repo.Count(ctx, nil, opensearch.Count.WithIndex(index))
+        g.Expect(err).To(Succeed(), "count should not fail")
+        g.Expect(total).To(Equal(11), "count should be correct")
+        count, err := di.FakeService.Repo.Count(ctx, query, opensearch.Count.WithIndex(index))
+        g.Expect(err).To(Succeed(), "count with query should not fail")
+
+        byQuery, err := di.FakeService.Repo.UpdateByQuery(ctx, []string{index}, map[string]interface{}{
+            "query":  query["query"],
+            "script": opensearch.Script{Source: "ctx._source.Severity = 'LOW'"},
+        }, opensearch.UpdateByQuery.WithRefresh(true))
+        g.Expect(err).To(Succeed(), "update by query should not fail")
+        g.Expect(byQuery.Updated).To(Equal(count), "update by query should update all matching documents")
+
+        byQuery, err = di.FakeService.Repo.DeleteByQuery(ctx, []string{index}, query, opensearch.DeleteByQuery.WithRefresh(true))
+        g.Expect(err).To(Succeed(), "delete by query should not fail")
+        g.Expect(byQuery.Deleted).To(Equal(count), "delete by query should delete all matching documents")
+
+        // Delete
+        err = di.FakeService.Repo.Delete(ctx, index, docId,
+            opensearch.Delete.WithSeqNoPrimaryTerm(doc.SeqNo, doc.PrimaryTerm),
+        )
+        g.Expect(err).To(Succeed(), "delete should not fail")
+        _, err = di.FakeService.Repo.Get(ctx, index, docId)
+        g.Expect(err).To(MatchError(opensearch.ErrDocumentNotFound), "get deleted document should fail")
+        err = di.FakeService.Repo.Delete(ctx, index, docId)
+        g.Expect(err).To(MatchError(opensearch.ErrDocumentNotFound), "delete non-existing document should fail")
+    }
+}
+
+func SubTestFencingToken(di *opensearchDI) test.GomegaSubTestFunc {
+    return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+        const index = "auditlog"
+        const docId = "fenced-1"
+        testEvent := testdata.GenericAuditEvent{
+            Client_ID: "FENCED",
+            SubType:   "SYNCHRONIZED",
+            Time:      time.Date(2019, 10, 15, 0, 0, 0, 0, time.UTC),
+        }
+        err := di.FakeService.Repo.Index(ctx, index, testEvent,
+            opensearch.Index.WithDocumentID(docId),
+            opensearch.Index.WithFencingToken(5),
+        )
+        g.Expect(err).To(Succeed(), "index with fencing token should not fail")
+
+        testEvent.Severity = "HIGH"
+        err = di.FakeService.Repo.Index(ctx, index, testEvent,
+            opensearch.Index.WithDocumentID(docId),
+            opensearch.Index.WithFencingToken(7),
+        )
+        g.Expect(err).To(Succeed(), "index with newer fencing token should not fail")
+
+        err = di.FakeService.Repo.Index(ctx, index, testEvent,
+            opensearch.Index.WithDocumentID(docId),
+            opensearch.Index.WithFencingToken(7),
+        )
+        g.Expect(err).To(Succeed(), "index with same fencing token should not fail")
+
+        testEvent.Severity = "LOW"
+        err = di.FakeService.Repo.Index(ctx, index, testEvent,
+            opensearch.Index.WithDocumentID(docId),
+            opensearch.Index.WithFencingToken(6),
+        )
+        g.Expect(err).To(MatchError(opensearch.ErrVersionConflict), "index with stale fencing token should fail with version conflict")
+
+        doc, err := di.FakeService.Repo.Get(ctx, index, docId)
+        g.Expect(err).To(Succeed(), "get should not fail")
+        g.Expect(doc.Version).To(BeEquivalentTo(7), "document version should be the latest fencing token")
+        g.Expect(doc.Source.Severity).To(Equal("HIGH"), "document should not be overwritten by stale write")
+
+        err = di.FakeService.Repo.Delete(ctx, index, docId)
+        g.Expect(err).To(Succeed(), "delete should not fail")
+    }
+}
+
+func SubTestSearchIterator(di *opensearchDI) test.GomegaSubTestFunc {
+    return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+        var progresses []opensearch.IteratorProgress
+        progressHook := opensearch.BeforeHookBase{
+            Identifier: "iterator progress",
+            F: func(ctx context.Context, before opensearch.BeforeContext) context.Context {
+                if p, ok := opensearch.IteratorProgressFromContext(ctx); ok {
+                    progresses = append(progresses, p)
+                }
+                return ctx
+            },
+        }
+        di.FakeService.Repo.AddBeforeHook(progressHook)
+        defer di.FakeService.Repo.RemoveBeforeHook(progressHook)
+
+        // point in time with search_after
+        sortedQuery := map[string]interface{}{
+            "query": map[string]interface{}{"match_all": map[string]interface{}{}},
+            "sort":  []map[string]interface{}{{"Time": "asc"}},
+        }
+        var events []testdata.GenericAuditEvent
+        for event, err := range di.FakeService.Repo.SearchIterator(ctx, []string{"auditlog"}, sortedQuery, opensearch.IterateWithPageSize(4)) {
+            g.Expect(err).To(Succeed(), "iterating should not fail")
+            events = append(events, event)
+        }
+        g.Expect(events).To(HaveLen(10), "all documents should be iterated")
+        for i := 1; i < len(events); i++ {
+            g.Expect(events[i].Time).To(BeTemporally(">", events[i-1].Time), "documents should be sorted")
+        }
+        // create PIT, 3 pages, delete PIT
+        g.Expect(progresses).To(HaveLen(5), "hooks should be invoked with progress")
+        g.Expect(progresses[3].Mode).To(Equal(opensearch.IteratorModePointInTime), "progress should have correct mode")
+        g.Expect(progresses[3].Page).To(Equal(3), "progress should have correct page")
+        g.Expect(progresses[3].Fetched).To(Equal(8), "progress should have correct fetched count")
+        g.Expect(progresses[3].Total).To(Equal(10), "progress should have correct total")
+
+        // stop early, PIT should be deleted
+        progresses = nil
+        count := 0
+        for _, err := range di.FakeService.Repo.SearchIterator(ctx, []string{"auditlog"}, sortedQuery, opensearch.IterateWithPageSize(4)) {
+            g.Expect(err).To(Succeed(), "iterating should not fail")
+            if count++; count == 5 {
+                break
+            }
+        }
+        // create PIT, 2 pages, delete PIT
+        g.Expect(progresses).To(HaveLen(4), "point in time should be deleted")
+
+        // unsorted query falls back to scroll
+        progresses = nil
+        events = nil
+        unsortedQuery := map[string]interface{}{
+            "query": map[string]interface{}{"match_all": map[string]interface{}{}},
+        }
+        for event, err := range di.FakeService.Repo.SearchIterator(ctx, []string{"auditlog"}, unsortedQuery, opensearch.IterateWithPageSize(4)) {
+            g.Expect(err).To(Succeed(), "iterating should not fail")
+            events = append(events, event)
+        }
+        g.Expect(events).To(HaveLen(10), "all documents should be iterated")
+        // search, 2 scrolls, clear scroll
+        g.Expect(progresses).To(HaveLen(4), "scroll should be cleared")
+        g.Expect(progresses[0].Mode).To(Equal(opensearch.IteratorModeScroll), "progress should have correct mode")
+    }
+}
+
+func SubTestAggregations(di *opensearchDI) test.GomegaSubTestFunc {
+    return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+        body := opensearch.NewSearchBody().
+            Query(opensearch.BoolQuery().Filter(opensearch.TermQuery("Type.keyword", "DEVICE"))).
+            Aggregation("by_subtype", opensearch.TermsAggregation("SubType.keyword").
+                Size(10).
+                SubAggregation("latest", opensearch.MaxAggregation("Time"))).
+            Aggregation("per_year", opensearch.DateHistogramAggregation("Time").
+                CalendarInterval("year").
+                Format("yyyy")).
+            Aggregation("composite", opensearch.CompositeAggregation(
+                opensearch.CompositeTermsSource("subtype", "SubType.keyword"),
+                opensearch.CompositeDateHistogramSource("year", "Time", "year"),
+            ).Size(2)).
+            Aggregation("tenants", opensearch.CardinalityAggregation("Tenant_ID.keyword")).
+            Aggregation("time_bucket", opensearch.StatsAggregation("Time_Bucket")).
+            Sort("Time", "asc").
+            Size(3)
+
+        var events []testdata.GenericAuditEvent
+        total, aggs, err := di.FakeService.Repo.SearchWithAggregations(ctx, &events, body, opensearch.Search.WithIndex("auditlog"))
+        g.Expect(err).To(Succeed(), "search with aggregations should not fail")
+        g.Expect(total).To(Equal(7), "total hits should be correct")
+        g.Expect(events).To(HaveLen(3), "hits should be returned alongside aggregations")
+
+        terms, err := aggs.Terms("by_subtype")
+        g.Expect(err).To(Succeed(), "terms aggregation should be available")
+        g.Expect(terms.Buckets).To(HaveLen(3), "terms aggregation should have correct buckets")
+        g.Expect(terms.Buckets[0].Key).To(Equal("W"), "terms bucket should have correct key")
+        g.Expect(terms.Buckets[0].DocCount).To(Equal(4), "terms bucket should have correct count")
+        latest, err := terms.Buckets[0].Aggregations.Metric("latest")
+        g.Expect(err).To(Succeed(), "sub-aggregation should be available")
+        g.Expect(latest.ValueAsString).To(Equal("2021-11-30T00:00:00.000Z"), "sub-aggregation should have correct value")
+
+        histogram, err := aggs.DateHistogram("per_year")
+        g.Expect(err).To(Succeed(), "date histogram aggregation should be available")
+        g.Expect(histogram.Buckets).To(HaveLen(2), "date histogram should have correct buckets")
+        g.Expect(histogram.Buckets[0].KeyAsString).To(Equal("2020"), "date histogram bucket should have correct key")
+        g.Expect(histogram.Buckets[0].DocCount).To(Equal(4), "date histogram bucket should have correct count")
+
+        composite, err := aggs.Composite("composite")
+        g.Expect(err).To(Succeed(), "composite aggregation should be available")
+        g.Expect(composite.Buckets).To(HaveLen(2), "composite aggregation should have correct buckets")
+        g.Expect(composite.Buckets[1].Key).To(HaveKeyWithValue("subtype", "SYNCHRONIZED"), "composite bucket should have correct key")
+        g.Expect(composite.AfterKey).To(Equal(composite.Buckets[1].Key), "composite aggregation should have correct after key")
+
+        tenants, err := aggs.Metric("tenants")
+        g.Expect(err).To(Succeed(), "metric aggregation should be available")
+        g.Expect(tenants.Value).To(HaveValue(BeNumerically("==", 7)), "metric aggregation should have correct value")
+
+        stats, err := aggs.Stats("time_bucket")
+        g.Expect(err).To(Succeed(), "stats aggregation should be available")
+        g.Expect(stats.Count).To(Equal(7), "stats aggregation should have correct count")
+
+        _, err = aggs.Terms("non_existing")
+        g.Expect(err).To(MatchError(opensearch.ErrAggregationNotFound), "missing aggregation should return error")
+
+        // search template with typed source
+        tmpl := opensearch.SearchTemplateBody{
+            Source: opensearch.NewSearchBody().
+                Query(opensearch.TermQuery("Type.keyword", "{{type}}")).
+                Aggregation("by_subtype", opensearch.TermsAggregation("SubType.keyword")).
+                Size(0),
+            Params: map[string]interface{}{"type": "DEVICE"},
+        }
+        total, aggs, err = di.FakeService.Repo.SearchTemplateWithAggregations(ctx, &events, tmpl, opensearch.SearchTemplate.WithIndex("auditlog"))
+        g.Expect(err).To(Succeed(), "search template with aggregations should not fail")
+        g.Expect(total).To(Equal(7), "total hits should be correct")
+        g.Expect(events).To(BeEmpty(), "no hits should be returned with size 0")
+        terms, err = aggs.Terms("by_subtype")
+        g.Expect(err).To(Succeed(), "terms aggregation should be available")
+        g.Expect(terms.Buckets).To(HaveLen(3), "terms aggregation should have correct buckets")
+    }
+}
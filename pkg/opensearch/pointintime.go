@@ -0,0 +1,229 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PointInTimeCreateRequest creates a Point in Time (PIT) of given indices.
+// opensearchapi doesn't support PIT APIs (available since OpenSearch 2.4), so this request is provided here.
+//
+// [Ref]: https://opensearch.org/docs/latest/search-plugins/searching-data/point-in-time-api/#create-a-pit
+type PointInTimeCreateRequest struct {
+	Index []string
+
+	KeepAlive               time.Duration
+	Preference              string
+	Routing                 []string
+	ExpandWildcards         string
+	AllowPartialPitCreation *bool
+
+	Header http.Header
+}
+
+// Do executes the request and returns response or error.
+func (r PointInTimeCreateRequest) Do(ctx context.Context, transport opensearchapi.Transport) (*opensearchapi.Response, error) {
+	params := make(map[string]string)
+	if r.KeepAlive != 0 {
+		params["keep_alive"] = formatDuration(r.KeepAlive)
+	}
+	if r.Preference != "" {
+		params["preference"] = r.Preference
+	}
+	if len(r.Routing) > 0 {
+		params["routing"] = strings.Join(r.Routing, ",")
+	}
+	if r.ExpandWildcards != "" {
+		params["expand_wildcards"] = r.ExpandWildcards
+	}
+	if r.AllowPartialPitCreation != nil {
+		params["allow_partial_pit_creation"] = strconv.FormatBool(*r.AllowPartialPitCreation)
+	}
+	path := "/" + strings.Join(r.Index, ",") + "/_search/point_in_time"
+	return perform(ctx, transport, http.MethodPost, path, params, nil, r.Header)
+}
+
+// PointInTimeCreateResponse modeled after https://opensearch.org/docs/latest/search-plugins/searching-data/point-in-time-api/#response-fields
+type PointInTimeCreateResponse struct {
+	PitID        string `json:"pit_id"`
+	CreationTime int64  `json:"creation_time"`
+}
+
+// PointInTimeDeleteRequest deletes Points in Time (PIT).
+//
+// [Ref]: https://opensearch.org/docs/latest/search-plugins/searching-data/point-in-time-api/#delete-pits
+type PointInTimeDeleteRequest struct {
+	PitID []string
+
+	Header http.Header
+}
+
+// Do executes the request and returns response or error.
+func (r PointInTimeDeleteRequest) Do(ctx context.Context, transport opensearchapi.Transport) (*opensearchapi.Response, error) {
+	body, e := json.Marshal(map[string]interface{}{"pit_id": r.PitID})
+	if e != nil {
+		return nil, e
+	}
+	return perform(ctx, transport, http.MethodDelete, "/_search/point_in_time", nil, bytes.NewReader(body), r.Header)
+}
+
+func (c *OpenClientImpl) PointInTimeCreate(ctx context.Context, index []string, o ...Option[PointInTimeCreateRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *PointInTimeCreateRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdPointInTimeCreate, Options: &options})
+	}
+
+	req := PointInTimeCreateRequest{Index: index}
+	for _, fn := range options {
+		fn(&req)
+	}
+	resp, err := req.Do(ctx, c.client)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdPointInTimeCreate, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+func (c *OpenClientImpl) PointInTimeDelete(ctx context.Context, pitID []string, o ...Option[PointInTimeDeleteRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *PointInTimeDeleteRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdPointInTimeDelete, Options: &options})
+	}
+
+	req := PointInTimeDeleteRequest{PitID: pitID}
+	for _, fn := range options {
+		fn(&req)
+	}
+	resp, err := req.Do(ctx, c.client)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdPointInTimeDelete, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type pointInTimeCreateExt struct{}
+
+var PointInTimeCreate = pointInTimeCreateExt{}
+
+func (s pointInTimeCreateExt) WithKeepAlive(v time.Duration) func(request *PointInTimeCreateRequest) {
+	return func(request *PointInTimeCreateRequest) {
+		request.KeepAlive = v
+	}
+}
+
+func (s pointInTimeCreateExt) WithPreference(v string) func(request *PointInTimeCreateRequest) {
+	return func(request *PointInTimeCreateRequest) {
+		request.Preference = v
+	}
+}
+
+func (s pointInTimeCreateExt) WithRouting(v ...string) func(request *PointInTimeCreateRequest) {
+	return func(request *PointInTimeCreateRequest) {
+		request.Routing = v
+	}
+}
+
+func (s pointInTimeCreateExt) WithHeader(h map[string]string) func(request *PointInTimeCreateRequest) {
+	return func(request *PointInTimeCreateRequest) {
+		if request.Header == nil {
+			request.Header = make(http.Header)
+		}
+		for k, v := range h {
+			request.Header.Add(k, v)
+		}
+	}
+}
+
+type pointInTimeDeleteExt struct{}
+
+var PointInTimeDelete = pointInTimeDeleteExt{}
+
+func (s pointInTimeDeleteExt) WithHeader(h map[string]string) func(request *PointInTimeDeleteRequest) {
+	return func(request *PointInTimeDeleteRequest) {
+		if request.Header == nil {
+			request.Header = make(http.Header)
+		}
+		for k, v := range h {
+			request.Header.Add(k, v)
+		}
+	}
+}
+
+/*************************
+	helpers
+ *************************/
+
+// perform executes a request that is not supported by opensearchapi, in the same way as opensearchapi does
+func perform(ctx context.Context, transport opensearchapi.Transport, method, path string, params map[string]string, body io.Reader, header http.Header) (*opensearchapi.Response, error) {
+	req, e := http.NewRequest(method, path, body)
+	if e != nil {
+		return nil, e
+	}
+	if len(params) > 0 {
+		q := req.URL.Query()
+		for k, v := range params {
+			q.Set(k, v)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, vv := range header {
+		for _, v := range vv {
+			req.Header.Add(k, v)
+		}
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	res, e := transport.Perform(req)
+	if e != nil {
+		return nil, e
+	}
+	return &opensearchapi.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}, nil
+}
+
+// formatDuration formats duration in the same way as opensearchapi
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return strconv.FormatInt(int64(d), 10) + "nanos"
+	}
+	return strconv.FormatInt(int64(d)/int64(time.Millisecond), 10) + "ms"
+}
@@ -0,0 +1,410 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"encoding/json"
+)
+
+// Query is a typed clause of the OpenSearch query DSL, see https://opensearch.org/docs/latest/query-dsl/
+//
+// Queries are marshalled into their JSON form, so they can be used with SearchBody, or as the "query" of any request body.
+// e.g.
+//
+//	q := opensearch.BoolQuery().
+//		Must(opensearch.MatchQuery("SubType", "SYNCHRONIZED")).
+//		Filter(opensearch.RangeQuery("Time").Gte("now-1d"))
+type Query interface {
+	json.Marshaler
+	// Source returns the query clause in its generic JSON form
+	Source() map[string]interface{}
+}
+
+// RawQuery is a Query in its generic JSON form, for clauses that are not supported by the builders
+type RawQuery map[string]interface{}
+
+func (q RawQuery) Source() map[string]interface{} {
+	return q
+}
+
+func (q RawQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}(q))
+}
+
+/*************************
+	Full Text & Term
+ *************************/
+
+// MatchAllQuery matches all documents
+func MatchAllQuery() Query {
+	return RawQuery{"match_all": map[string]interface{}{}}
+}
+
+// ExistsQuery matches documents that have a value of the field
+func ExistsQuery(field string) Query {
+	return RawQuery{"exists": map[string]interface{}{"field": field}}
+}
+
+// TermQuery matches documents that contain the exact term in the field
+func TermQuery(field string, value interface{}) Query {
+	return RawQuery{"term": map[string]interface{}{field: value}}
+}
+
+// TermsQuery matches documents that contain any of the exact terms in the field
+func TermsQuery(field string, values ...interface{}) Query {
+	return RawQuery{"terms": map[string]interface{}{field: values}}
+}
+
+// MatchQuery performs full-text search of the field
+func MatchQuery(field string, text interface{}) *MatchQueryBuilder {
+	return &MatchQueryBuilder{field: field, text: text}
+}
+
+type MatchQueryBuilder struct {
+	field     string
+	text      interface{}
+	operator  string
+	fuzziness string
+}
+
+// Operator is either "or" (default) or "and"
+func (q *MatchQueryBuilder) Operator(operator string) *MatchQueryBuilder {
+	q.operator = operator
+	return q
+}
+
+func (q *MatchQueryBuilder) Fuzziness(fuzziness string) *MatchQueryBuilder {
+	q.fuzziness = fuzziness
+	return q
+}
+
+func (q *MatchQueryBuilder) Source() map[string]interface{} {
+	if q.operator == "" && q.fuzziness == "" {
+		return map[string]interface{}{"match": map[string]interface{}{q.field: q.text}}
+	}
+	params := map[string]interface{}{"query": q.text}
+	putIfNotEmpty(params, "operator", q.operator)
+	putIfNotEmpty(params, "fuzziness", q.fuzziness)
+	return map[string]interface{}{"match": map[string]interface{}{q.field: params}}
+}
+
+func (q *MatchQueryBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Source())
+}
+
+/*************************
+	Range
+ *************************/
+
+// RangeQuery matches documents whose field value is in the range
+func RangeQuery(field string) *RangeQueryBuilder {
+	return &RangeQueryBuilder{field: field, params: map[string]interface{}{}}
+}
+
+type RangeQueryBuilder struct {
+	field  string
+	params map[string]interface{}
+}
+
+func (q *RangeQueryBuilder) Gt(v interface{}) *RangeQueryBuilder {
+	q.params["gt"] = v
+	return q
+}
+
+func (q *RangeQueryBuilder) Gte(v interface{}) *RangeQueryBuilder {
+	q.params["gte"] = v
+	return q
+}
+
+func (q *RangeQueryBuilder) Lt(v interface{}) *RangeQueryBuilder {
+	q.params["lt"] = v
+	return q
+}
+
+func (q *RangeQueryBuilder) Lte(v interface{}) *RangeQueryBuilder {
+	q.params["lte"] = v
+	return q
+}
+
+// Format is the date format of the range values, only applicable to date fields
+func (q *RangeQueryBuilder) Format(format string) *RangeQueryBuilder {
+	q.params["format"] = format
+	return q
+}
+
+func (q *RangeQueryBuilder) TimeZone(tz string) *RangeQueryBuilder {
+	q.params["time_zone"] = tz
+	return q
+}
+
+func (q *RangeQueryBuilder) Source() map[string]interface{} {
+	return map[string]interface{}{"range": map[string]interface{}{q.field: q.params}}
+}
+
+func (q *RangeQueryBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Source())
+}
+
+/*************************
+	Compound
+ *************************/
+
+// BoolQuery combines multiple queries
+func BoolQuery() *BoolQueryBuilder {
+	return &BoolQueryBuilder{}
+}
+
+type BoolQueryBuilder struct {
+	must               []Query
+	filter             []Query
+	should             []Query
+	mustNot            []Query
+	minimumShouldMatch interface{}
+}
+
+func (q *BoolQueryBuilder) Must(queries ...Query) *BoolQueryBuilder {
+	q.must = append(q.must, queries...)
+	return q
+}
+
+func (q *BoolQueryBuilder) Filter(queries ...Query) *BoolQueryBuilder {
+	q.filter = append(q.filter, queries...)
+	return q
+}
+
+func (q *BoolQueryBuilder) Should(queries ...Query) *BoolQueryBuilder {
+	q.should = append(q.should, queries...)
+	return q
+}
+
+func (q *BoolQueryBuilder) MustNot(queries ...Query) *BoolQueryBuilder {
+	q.mustNot = append(q.mustNot, queries...)
+	return q
+}
+
+// MinimumShouldMatch is either a number or a percentage string (e.g. "50%")
+func (q *BoolQueryBuilder) MinimumShouldMatch(v interface{}) *BoolQueryBuilder {
+	q.minimumShouldMatch = v
+	return q
+}
+
+func (q *BoolQueryBuilder) Source() map[string]interface{} {
+	params := map[string]interface{}{}
+	putQueries(params, "must", q.must)
+	putQueries(params, "filter", q.filter)
+	putQueries(params, "should", q.should)
+	putQueries(params, "must_not", q.mustNot)
+	if q.minimumShouldMatch != nil {
+		params["minimum_should_match"] = q.minimumShouldMatch
+	}
+	return map[string]interface{}{"bool": params}
+}
+
+func (q *BoolQueryBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Source())
+}
+
+// NestedQuery queries nested objects at the path
+func NestedQuery(path string, query Query) *NestedQueryBuilder {
+	return &NestedQueryBuilder{path: path, query: query}
+}
+
+type NestedQueryBuilder struct {
+	path      string
+	query     Query
+	scoreMode string
+}
+
+// ScoreMode is one of "avg" (default), "max", "min", "sum" or "none"
+func (q *NestedQueryBuilder) ScoreMode(mode string) *NestedQueryBuilder {
+	q.scoreMode = mode
+	return q
+}
+
+func (q *NestedQueryBuilder) Source() map[string]interface{} {
+	params := map[string]interface{}{
+		"path":  q.path,
+		"query": q.query.Source(),
+	}
+	putIfNotEmpty(params, "score_mode", q.scoreMode)
+	return map[string]interface{}{"nested": params}
+}
+
+func (q *NestedQueryBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Source())
+}
+
+/*************************
+	k-NN
+ *************************/
+
+// KnnQuery finds k nearest neighbors of the vector in a "knn_vector" field.
+// See https://opensearch.org/docs/latest/search-plugins/knn/approximate-knn/
+func KnnQuery(field string, vector []float32, k int) *KnnQueryBuilder {
+	return &KnnQueryBuilder{field: field, vector: vector, k: k}
+}
+
+type KnnQueryBuilder struct {
+	field  string
+	vector []float32
+	k      int
+	filter Query
+}
+
+// Filter applies the filter during the k-NN search
+func (q *KnnQueryBuilder) Filter(filter Query) *KnnQueryBuilder {
+	q.filter = filter
+	return q
+}
+
+func (q *KnnQueryBuilder) Source() map[string]interface{} {
+	params := map[string]interface{}{
+		"vector": q.vector,
+		"k":      q.k,
+	}
+	if q.filter != nil {
+		params["filter"] = q.filter.Source()
+	}
+	return map[string]interface{}{"knn": map[string]interface{}{q.field: params}}
+}
+
+func (q *KnnQueryBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Source())
+}
+
+/*************************
+	Search Body
+ *************************/
+
+// NewSearchBody returns a typed Search request body, which can be used as "body" of Repo.Search, Repo.SearchIterator, etc.,
+// or as "source" of SearchTemplateBody
+//
+// [Format]: https://opensearch.org/docs/latest/api-reference/search/#request-body
+func NewSearchBody() *SearchBody {
+	return &SearchBody{}
+}
+
+type SearchBody struct {
+	query          Query
+	aggregations   map[string]Aggregation
+	sort           []map[string]interface{}
+	size           *int
+	from           *int
+	source         []string
+	trackTotalHits *bool
+}
+
+func (b *SearchBody) Query(query Query) *SearchBody {
+	b.query = query
+	return b
+}
+
+func (b *SearchBody) Aggregation(name string, agg Aggregation) *SearchBody {
+	if b.aggregations == nil {
+		b.aggregations = map[string]Aggregation{}
+	}
+	b.aggregations[name] = agg
+	return b
+}
+
+// Sort adds a sort field. order is either "asc" or "desc"
+func (b *SearchBody) Sort(field string, order string) *SearchBody {
+	b.sort = append(b.sort, map[string]interface{}{field: map[string]interface{}{"order": order}})
+	return b
+}
+
+func (b *SearchBody) Size(size int) *SearchBody {
+	b.size = &size
+	return b
+}
+
+func (b *SearchBody) From(from int) *SearchBody {
+	b.from = &from
+	return b
+}
+
+// SourceIncludes limits the fields of "_source" in hits
+func (b *SearchBody) SourceIncludes(fields ...string) *SearchBody {
+	b.source = fields
+	return b
+}
+
+func (b *SearchBody) TrackTotalHits(track bool) *SearchBody {
+	b.trackTotalHits = &track
+	return b
+}
+
+func (b *SearchBody) Source() map[string]interface{} {
+	body := map[string]interface{}{}
+	if b.query != nil {
+		body["query"] = b.query.Source()
+	}
+	if len(b.aggregations) != 0 {
+		body["aggs"] = aggregationSources(b.aggregations)
+	}
+	if len(b.sort) != 0 {
+		body["sort"] = b.sort
+	}
+	if b.size != nil {
+		body["size"] = *b.size
+	}
+	if b.from != nil {
+		body["from"] = *b.from
+	}
+	if len(b.source) != 0 {
+		body["_source"] = b.source
+	}
+	if b.trackTotalHits != nil {
+		body["track_total_hits"] = *b.trackTotalHits
+	}
+	return body
+}
+
+func (b *SearchBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Source())
+}
+
+// SearchTemplateBody is a typed Search Template request body, used as "body" of Repo.SearchTemplate.
+// Either ID of a stored template or Source should be set. Source can be a *SearchBody with "{{param}}" placeholders.
+//
+// [Format]: https://opensearch.org/docs/latest/api-reference/search-template/
+type SearchTemplateBody struct {
+	ID     string                 `json:"id,omitempty"`
+	Source interface{}            `json:"source,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+/*************************
+	helpers
+ *************************/
+
+func putQueries(params map[string]interface{}, key string, queries []Query) {
+	if len(queries) == 0 {
+		return
+	}
+	sources := make([]map[string]interface{}, len(queries))
+	for i := range queries {
+		sources[i] = queries[i].Source()
+	}
+	params[key] = sources
+}
+
+func putIfNotEmpty(params map[string]interface{}, key string, value string) {
+	if value != "" {
+		params[key] = value
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch_test
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/opensearch"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/opensearchtest"
+	"github.com/onsi/gomega"
+	"testing"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	test.RunTest(context.Background(), t,
+		test.GomegaSubTest(SubTestBoolQuery(), "TestBoolQuery"),
+		test.GomegaSubTest(SubTestNestedAndKnnQuery(), "TestNestedAndKnnQuery"),
+		test.GomegaSubTest(SubTestAggregationBuilder(), "TestAggregationBuilder"),
+		test.GomegaSubTest(SubTestSearchTemplateBody(), "TestSearchTemplateBody"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SubTestBoolQuery() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		body := opensearch.NewSearchBody().
+			Query(opensearch.BoolQuery().
+				Must(opensearch.MatchQuery("Description", "device sync").Operator("and")).
+				Filter(
+					opensearch.TermQuery("Type.keyword", "DEVICE"),
+					opensearch.RangeQuery("Time").Gte("now-1d").Lt("now"),
+				).
+				Should(opensearch.TermsQuery("SubType.keyword", "W", "SYNCHRONIZED")).
+				MustNot(opensearch.ExistsQuery("Orig_User")).
+				MinimumShouldMatch(1)).
+			Sort("Time", "desc").
+			From(10).
+			Size(5)
+		g.Expect(body).To(opensearchtest.MatchBody(`{
+			"query": {"bool": {
+				"must": [{"match": {"Description": {"query": "device sync", "operator": "and"}}}],
+				"filter": [
+					{"term": {"Type.keyword": "DEVICE"}},
+					{"range": {"Time": {"gte": "now-1d", "lt": "now"}}}
+				],
+				"should": [{"terms": {"SubType.keyword": ["W", "SYNCHRONIZED"]}}],
+				"must_not": [{"exists": {"field": "Orig_User"}}],
+				"minimum_should_match": 1
+			}},
+			"sort": [{"Time": {"order": "desc"}}],
+			"from": 10,
+			"size": 5
+		}`), "bool query should be correct")
+
+		g.Expect(opensearch.MatchQuery("Type", "DEVICE")).
+			To(opensearchtest.MatchBody(`{"match": {"Type": "DEVICE"}}`), "simple match query should use short form")
+		g.Expect(opensearch.NewSearchBody().Query(opensearch.RangeQuery("Time").Gte("now-1d"))).
+			To(opensearchtest.MatchBody(`{"query": {"range": {"Time": {"gte": "2024-01-01"}}}}`, "$.query.range.Time"),
+				"fuzzy JSON paths should be ignored")
+		g.Expect(opensearch.MatchAllQuery()).
+			NotTo(opensearchtest.MatchBody(map[string]interface{}{"match_none": map[string]interface{}{}}), "different queries should not match")
+	}
+}
+
+func SubTestNestedAndKnnQuery() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		nested := opensearch.NestedQuery("Details", opensearch.TermQuery("Details.Key", "value")).ScoreMode("max")
+		g.Expect(nested).To(opensearchtest.MatchBody(`{
+			"nested": {"path": "Details", "query": {"term": {"Details.Key": "value"}}, "score_mode": "max"}
+		}`), "nested query should be correct")
+
+		knn := opensearch.KnnQuery("Embedding", []float32{0.5, 1, 1.5}, 3).Filter(opensearch.TermQuery("Type.keyword", "DEVICE"))
+		g.Expect(knn).To(opensearchtest.MatchBody(`{
+			"knn": {"Embedding": {"vector": [0.5, 1, 1.5], "k": 3, "filter": {"term": {"Type.keyword": "DEVICE"}}}}
+		}`), "knn query should be correct")
+	}
+}
+
+func SubTestAggregationBuilder() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		body := opensearch.NewSearchBody().
+			Aggregation("by_type", opensearch.TermsAggregation("Type.keyword").
+				Size(5).
+				Order("_count", "desc").
+				SubAggregation("avg_bucket", opensearch.AvgAggregation("Time_Bucket"))).
+			Aggregation("daily", opensearch.DateHistogramAggregation("Time").
+				FixedInterval("1d").
+				TimeZone("UTC").
+				MinDocCount(1)).
+			Aggregation("pages", opensearch.CompositeAggregation(
+				opensearch.CompositeTermsSource("type", "Type.keyword"),
+				opensearch.CompositeDateHistogramSource("month", "Time", "month"),
+			).Size(10).After(map[string]interface{}{"type": "DP", "month": 1577836800000})).
+			Size(0)
+		g.Expect(body).To(opensearchtest.MatchBody(`{
+			"aggs": {
+				"by_type": {
+					"terms": {"field": "Type.keyword", "size": 5, "order": [{"_count": "desc"}]},
+					"aggs": {"avg_bucket": {"avg": {"field": "Time_Bucket"}}}
+				},
+				"daily": {"date_histogram": {"field": "Time", "fixed_interval": "1d", "time_zone": "UTC", "min_doc_count": 1}},
+				"pages": {"composite": {
+					"sources": [
+						{"type": {"terms": {"field": "Type.keyword"}}},
+						{"month": {"date_histogram": {"field": "Time", "calendar_interval": "month"}}}
+					],
+					"size": 10,
+					"after": {"type": "DP", "month": 1577836800000}
+				}}
+			},
+			"size": 0
+		}`), "aggregations should be correct")
+	}
+}
+
+func SubTestSearchTemplateBody() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		body := opensearch.SearchTemplateBody{
+			Source: opensearch.NewSearchBody().Query(opensearch.MatchQuery("Type", "{{type}}")),
+			Params: map[string]interface{}{"type": "DP"},
+		}
+		g.Expect(body).To(opensearchtest.MatchBody(`{
+			"source": {"query": {"match": {"Type": "{{type}}"}}},
+			"params": {"type": "DP"}
+		}`), "search template body should be correct")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+)
+
+// ReindexResponse is the response of Repo.Reindex.
+// When Reindex.WithWaitForCompletion(false) is used, only Task is populated, and the result can be polled with Repo.TasksGet.
+// Otherwise, the result is available directly, modeled after https://opensearch.org/docs/latest/im-plugin/reindex-data/
+type ReindexResponse struct {
+	// Task is the ID of the background task, only available when not waiting for completion
+	Task string `json:"task,omitempty"`
+	ByQueryResponse
+	Created int `json:"created"`
+}
+
+func (c *RepoImpl[T]) Reindex(ctx context.Context, body interface{}, o ...Option[opensearchapi.ReindexRequest]) (*ReindexResponse, error) {
+	var buffer bytes.Buffer
+	err := json.NewEncoder(&buffer).Encode(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode reindex body: %w", err)
+	}
+	resp, err := c.client.Reindex(ctx, &buffer, o...)
+	if err != nil {
+		return nil, err
+	}
+	if err := byQueryError(ctx, resp); err != nil {
+		return nil, err
+	}
+	return UnmarshalResponse[ReindexResponse](resp)
+}
+
+func (c *OpenClientImpl) Reindex(ctx context.Context, body io.Reader, o ...Option[opensearchapi.ReindexRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.ReindexRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdReindex, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, Reindex.WithContext(ctx))
+	resp, err := c.client.API.Reindex(body, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdReindex, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type reindexExt struct {
+	opensearchapi.Reindex
+}
+
+var Reindex = reindexExt{}
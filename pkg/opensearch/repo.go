@@ -20,6 +20,7 @@ import (
 	"context"
 	"github.com/opensearch-project/opensearch-go/opensearchapi"
 	"github.com/opensearch-project/opensearch-go/opensearchutil"
+	"iter"
 )
 
 // NewRepo will return a OpenSearch repository for any model type T
@@ -46,6 +47,30 @@ type Repo[T any] interface {
 	// [Format]: https://opensearch.org/docs/latest/opensearch/rest-api/search/#request-body
 	SearchTemplate(ctx context.Context, dest *[]T, body interface{}, o ...Option[opensearchapi.SearchTemplateRequest]) (int, error)
 
+	// SearchWithAggregations is same as Search, and additionally returns results of aggregations in the body.
+	//
+	// The body argument is typically a SearchBody with aggregations, see SearchBody.Aggregation.
+	// Typed aggregation results can be extracted from the returned Aggregations, e.g. Aggregations.Terms.
+	SearchWithAggregations(ctx context.Context, dest *[]T, body interface{}, o ...Option[opensearchapi.SearchRequest]) (int, Aggregations, error)
+
+	// SearchTemplateWithAggregations is same as SearchTemplate, and additionally returns results of aggregations
+	// in the rendered template.
+	//
+	// The body argument is typically a SearchTemplateBody.
+	SearchTemplateWithAggregations(ctx context.Context, dest *[]T, body interface{}, o ...Option[opensearchapi.SearchTemplateRequest]) (int, Aggregations, error)
+
+	// SearchIterator will return an iterator over all documents matching the query, fetched page by page.
+	//
+	// The index argument defines the indices to search.
+	// The body argument should follow the Search request body [Format], without "from".
+	// "size" is replaced by page size, see IterateWithPageSize.
+	// By default, sorted queries are paged with Point in Time and "search_after", and unsorted queries with scroll,
+	// see IteratorMode. The Point in Time or scroll context is released when the iteration finishes or stops early.
+	// Hooks can report progress using IteratorProgressFromContext.
+	//
+	// [Format]: https://opensearch.org/docs/latest/opensearch/rest-api/search/#request-body
+	SearchIterator(ctx context.Context, index []string, body interface{}, opts ...IteratorOptions) iter.Seq2[T, error]
+
 	// Index will create a new Document in the index that is defined.
 	//
 	// The index argument defines the index name that the document should be stored in.
@@ -109,9 +134,87 @@ type Repo[T any] interface {
 	// The name argument defines the name of the template to delete
 	IndicesDeleteIndexTemplate(ctx context.Context, name string, o ...Option[opensearchapi.IndicesDeleteIndexTemplateRequest]) error
 
+	// IndicesExists checks if all the indices or aliases exist
+	IndicesExists(ctx context.Context, index []string, o ...Option[opensearchapi.IndicesExistsRequest]) (bool, error)
+
+	// IndicesGetAlias returns the aliases with given names, grouped by indices they point to.
+	//
+	// An empty map is returned if none of the aliases exists.
+	IndicesGetAlias(ctx context.Context, name []string, o ...Option[opensearchapi.IndicesGetAliasRequest]) (map[string][]string, error)
+
+	// IndicesUpdateAliases will perform multiple alias actions atomically, e.g. moving an alias from one index to another.
+	//
+	// See AddAlias, RemoveAlias and RemoveIndex.
+	//
+	// [Format]: https://opensearch.org/docs/latest/api-reference/alias/#request-body
+	IndicesUpdateAliases(ctx context.Context, actions []AliasAction, o ...Option[opensearchapi.IndicesUpdateAliasesRequest]) error
+
+	// Reindex will copy documents from one index to another.
+	//
+	// The body argument should follow the Reindex request body [Format].
+	// Use Reindex.WithWaitForCompletion(false) to run it as a background task, and poll the result with TasksGet.
+	//
+	// [Format]: https://opensearch.org/docs/latest/im-plugin/reindex-data/
+	Reindex(ctx context.Context, body interface{}, o ...Option[opensearchapi.ReindexRequest]) (*ReindexResponse, error)
+
+	// TasksGet will return the status of a background task, e.g. the task of Reindex.
+	//
+	// ErrTaskNotFound is returned if the task doesn't exist.
+	TasksGet(ctx context.Context, taskID string, o ...Option[opensearchapi.TasksGetRequest]) (*TaskResponse, error)
+
 	// Ping will ping the OpenSearch cluster. If no error is returned, then the ping was successful
 	Ping(ctx context.Context, o ...Option[opensearchapi.PingRequest]) error
 
+	// Get will return a single document by its ID, together with its metadata.
+	//
+	// ErrDocumentNotFound is returned if the document doesn't exist.
+	// The returned Document's SeqNo and PrimaryTerm can be used for optimistic concurrency control of Update and Delete.
+	//
+	// [Format]: https://opensearch.org/docs/latest/api-reference/document-apis/get-documents/
+	Get(ctx context.Context, index string, id string, o ...Option[opensearchapi.GetRequest]) (*Document[T], error)
+
+	// MGet will return multiple documents by their IDs.
+	//
+	// The returned documents are in the same order of the ids argument. Documents that don't exist have Found set to false.
+	//
+	// [Format]: https://opensearch.org/docs/latest/api-reference/document-apis/multi-get/
+	MGet(ctx context.Context, index string, ids []string, o ...Option[opensearchapi.MgetRequest]) ([]Document[T], error)
+
+	// Update will partially update a document or update it with a script.
+	//
+	// The body argument should follow the Update request body [Format], see UpdateBody, PartialUpdate and ScriptedUpdate.
+	// Use Update.WithSeqNoPrimaryTerm for optimistic concurrency control. ErrVersionConflict is returned on conflicts.
+	//
+	// [Format]: https://opensearch.org/docs/latest/api-reference/document-apis/update-document/#request-body
+	Update(ctx context.Context, index string, id string, body interface{}, o ...Option[opensearchapi.UpdateRequest]) (*DocumentResponse, error)
+
+	// Delete will delete a document by its ID.
+	//
+	// ErrDocumentNotFound is returned if the document doesn't exist.
+	// Use Delete.WithSeqNoPrimaryTerm for optimistic concurrency control. ErrVersionConflict is returned on conflicts.
+	Delete(ctx context.Context, index string, id string, o ...Option[opensearchapi.DeleteRequest]) error
+
+	// DeleteByQuery will delete all documents matching the query.
+	//
+	// The body argument should follow the Delete by query request body [Format].
+	//
+	// [Format]: https://opensearch.org/docs/latest/api-reference/document-apis/delete-by-query/#request-body
+	DeleteByQuery(ctx context.Context, index []string, body interface{}, o ...Option[opensearchapi.DeleteByQueryRequest]) (*ByQueryResponse, error)
+
+	// UpdateByQuery will update all documents matching the query, typically with a Script.
+	//
+	// The body argument should follow the Update by query request body [Format].
+	//
+	// [Format]: https://opensearch.org/docs/latest/api-reference/document-apis/update-by-query/#request-body
+	UpdateByQuery(ctx context.Context, index []string, body interface{}, o ...Option[opensearchapi.UpdateByQueryRequest]) (*ByQueryResponse, error)
+
+	// Count will return the number of documents matching the query.
+	//
+	// The body argument should follow the Count request body [Format]. nil body counts all documents.
+	//
+	// [Format]: https://opensearch.org/docs/latest/api-reference/count/
+	Count(ctx context.Context, body interface{}, o ...Option[opensearchapi.CountRequest]) (int, error)
+
 	AddBeforeHook(hook BeforeHook)
 	AddAfterHook(hook AfterHook)
 	RemoveBeforeHook(hook BeforeHook)
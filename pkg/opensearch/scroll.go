@@ -0,0 +1,72 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"context"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+func (c *OpenClientImpl) Scroll(ctx context.Context, o ...Option[opensearchapi.ScrollRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.ScrollRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdScroll, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, Scroll.WithContext(ctx))
+	resp, err := c.client.API.Scroll(options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdScroll, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+func (c *OpenClientImpl) ClearScroll(ctx context.Context, o ...Option[opensearchapi.ClearScrollRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.ClearScrollRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdClearScroll, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, ClearScroll.WithContext(ctx))
+	resp, err := c.client.API.ClearScroll(options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdClearScroll, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type scrollExt struct {
+	opensearchapi.Scroll
+}
+
+var Scroll = scrollExt{}
+
+type clearScrollExt struct {
+	opensearchapi.ClearScroll
+}
+
+var ClearScroll = clearScrollExt{}
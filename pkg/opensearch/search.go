@@ -29,13 +29,16 @@ import (
 )
 
 var (
-	ErrIndexNotFound = errors.New("index not found")
+	ErrIndexNotFound   = errors.New("index not found")
+	ErrVersionConflict = errors.New("version conflict")
 )
 
 // SearchResponse modeled after https://opensearch.org/docs/latest/opensearch/rest-api/search/#response-body
 type SearchResponse[T any] struct {
-	Took     int  `json:"took"`
-	TimedOut bool `json:"timed_out"`
+	Took     int    `json:"took"`
+	TimedOut bool   `json:"timed_out"`
+	ScrollID string `json:"_scroll_id,omitempty"`
+	PitID    string `json:"pit_id,omitempty"`
 	Shards   struct {
 		Total      int `json:"total"`
 		Successful int `json:"successful"`
@@ -52,44 +55,58 @@ type SearchResponse[T any] struct {
 			ID     string  `json:"_id"`
 			Score  float64 `json:"_score"`
 			Source T       `json:"_source"`
+			// Sort values of the hit, only available when the search is sorted. Used as "search_after" of next page
+			Sort []json.RawMessage `json:"sort,omitempty"`
 		} `json:"hits"`
 	} `json:"hits"`
+	// Aggregations are results of aggregations in the search body, by names. See SearchBody.Aggregation
+	Aggregations Aggregations `json:"aggregations,omitempty"`
 }
 
 func (c *RepoImpl[T]) Search(ctx context.Context, dest *[]T, body interface{}, o ...Option[opensearchapi.SearchRequest]) (hits int, err error) {
+	hits, _, err = c.SearchWithAggregations(ctx, dest, body, o...)
+	return
+}
+
+func (c *RepoImpl[T]) SearchWithAggregations(ctx context.Context, dest *[]T, body interface{}, o ...Option[opensearchapi.SearchRequest]) (hits int, aggs Aggregations, err error) {
 	var buffer bytes.Buffer
 	err = json.NewEncoder(&buffer).Encode(body)
 	if err != nil {
-		return 0, fmt.Errorf("unable to encode mapping: %w", err)
+		return 0, nil, fmt.Errorf("unable to encode mapping: %w", err)
 	}
 	o = append(o, Search.WithBody(&buffer))
 	resp, err := c.client.Search(ctx, o...)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 	if resp != nil && resp.IsError() {
 		logger.WithContext(ctx).Errorf("error response: %s", resp.String())
 		if resp.StatusCode == http.StatusNotFound {
-			return 0, fmt.Errorf("%w", ErrIndexNotFound)
+			return 0, nil, fmt.Errorf("%w", ErrIndexNotFound)
 		} else {
-			return 0, fmt.Errorf("error status code: %d", resp.StatusCode)
+			return 0, nil, fmt.Errorf("error status code: %d", resp.StatusCode)
 		}
 	}
+	return unmarshalSearchResponse(resp, dest)
+}
+
+// unmarshalSearchResponse extracts hits into dest, and returns total hits and aggregation results
+func unmarshalSearchResponse[T any](resp *opensearchapi.Response, dest *[]T) (int, Aggregations, error) {
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 	var searchResp SearchResponse[T]
 	err = json.Unmarshal(respBody, &searchResp)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 	retModel := make([]T, len(searchResp.Hits.Hits))
 	for i, hits := range searchResp.Hits.Hits {
 		retModel[i] = hits.Source
 	}
 	*dest = retModel
-	return searchResp.Hits.Total.Value, nil
+	return searchResp.Hits.Total.Value, searchResp.Aggregations, nil
 }
 
 func (c *OpenClientImpl) Search(ctx context.Context, o ...Option[opensearchapi.SearchRequest]) (*opensearchapi.Response, error) {
@@ -27,34 +27,25 @@ import (
 )
 
 func (c *RepoImpl[T]) SearchTemplate(ctx context.Context, dest *[]T, body interface{}, o ...Option[opensearchapi.SearchTemplateRequest]) (hits int, err error) {
+	hits, _, err = c.SearchTemplateWithAggregations(ctx, dest, body, o...)
+	return
+}
+
+func (c *RepoImpl[T]) SearchTemplateWithAggregations(ctx context.Context, dest *[]T, body interface{}, o ...Option[opensearchapi.SearchTemplateRequest]) (hits int, aggs Aggregations, err error) {
 	var buffer bytes.Buffer
 	err = json.NewEncoder(&buffer).Encode(body)
 	if err != nil {
-		return 0, fmt.Errorf("unable to encode mapping: %w", err)
+		return 0, nil, fmt.Errorf("unable to encode mapping: %w", err)
 	}
 	resp, err := c.client.SearchTemplate(ctx, &buffer, o...)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 	if resp != nil && resp.IsError() {
 		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
-		return 0, fmt.Errorf("error status code: %d", resp.StatusCode)
-	}
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, err
-	}
-	var searchResp SearchResponse[T]
-	err = json.Unmarshal(respBody, &searchResp)
-	if err != nil {
-		return 0, err
-	}
-	retModel := make([]T, len(searchResp.Hits.Hits))
-	for i, hits := range searchResp.Hits.Hits {
-		retModel[i] = hits.Source
+		return 0, nil, fmt.Errorf("error status code: %d", resp.StatusCode)
 	}
-	*dest = retModel
-	return searchResp.Hits.Total.Value, nil
+	return unmarshalSearchResponse(resp, dest)
 }
 
 func (c *OpenClientImpl) SearchTemplate(ctx context.Context, body io.Reader, o ...Option[opensearchapi.SearchTemplateRequest]) (*opensearchapi.Response, error) {
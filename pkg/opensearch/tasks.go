@@ -0,0 +1,97 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"net/http"
+)
+
+var (
+	ErrTaskNotFound = errors.New("task not found")
+)
+
+// TaskResponse is the response of Repo.TasksGet, modeled after https://opensearch.org/docs/latest/api-reference/tasks/
+type TaskResponse struct {
+	Completed bool `json:"completed"`
+	Task      struct {
+		Node        string     `json:"node"`
+		ID          int        `json:"id"`
+		Action      string     `json:"action"`
+		Description string     `json:"description"`
+		Status      TaskStatus `json:"status"`
+	} `json:"task"`
+	// Response is the result of a completed task, e.g. ReindexResponse
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error is the cause of a failed task
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+// TaskStatus is the progress of reindex, update by query or delete by query tasks
+type TaskStatus struct {
+	Total            int `json:"total"`
+	Created          int `json:"created"`
+	Updated          int `json:"updated"`
+	Deleted          int `json:"deleted"`
+	Batches          int `json:"batches"`
+	VersionConflicts int `json:"version_conflicts"`
+	Noops            int `json:"noops"`
+}
+
+func (c *RepoImpl[T]) TasksGet(ctx context.Context, taskID string, o ...Option[opensearchapi.TasksGetRequest]) (*TaskResponse, error) {
+	resp, err := c.client.TasksGet(ctx, taskID, o...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+		}
+		return nil, fmt.Errorf("error status code: %d", resp.StatusCode)
+	}
+	return UnmarshalResponse[TaskResponse](resp)
+}
+
+func (c *OpenClientImpl) TasksGet(ctx context.Context, taskID string, o ...Option[opensearchapi.TasksGetRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.TasksGetRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdTasksGet, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, TasksGet.WithContext(ctx))
+	resp, err := c.client.API.Tasks.Get(taskID, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdTasksGet, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type tasksGetExt struct {
+	opensearchapi.TasksGet
+}
+
+var TasksGet = tasksGetExt{}
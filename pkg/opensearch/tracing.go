@@ -37,6 +37,12 @@ func (t *Tracer) Before(ctx context.Context, before BeforeContext) context.Conte
 		tracing.SpanKind(ext.SpanKindRPCClientEnum),
 		tracing.SpanTag("command", before.CommandType()),
 	}
+	if progress, ok := IteratorProgressFromContext(ctx); ok {
+		opts = append(opts,
+			tracing.SpanTag("iterator.mode", progress.Mode.String()),
+			tracing.SpanTag("iterator.page", progress.Page),
+		)
+	}
 	ctx = tracing.WithTracer(t.tracer).
 		WithOpName("opensearch " + before.CommandType().String()).
 		WithOptions(opts...).
@@ -59,7 +65,7 @@ func (t *Tracer) After(ctx context.Context, afterContext AfterContext) context.C
 			tracing.SpanTag("error", afterContext.Err),
 		)
 	} else {
-		if afterContext.CommandType() == CmdSearch {
+		if afterContext.CommandType() == CmdSearch || afterContext.CommandType() == CmdScroll {
 			resp, err := UnmarshalResponse[SearchResponse[any]](afterContext.Resp)
 			if err != nil {
 				logger.Errorf("unable to unmarshal error: %v", err)
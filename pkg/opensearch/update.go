@@ -0,0 +1,123 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+	"net/http"
+)
+
+// UpdateBody is the request body of Repo.Update. Either Doc (partial update) or Script (scripted update) should be set.
+//
+// [Format]: https://opensearch.org/docs/latest/api-reference/document-apis/update-document/#request-body
+type UpdateBody struct {
+	Doc         interface{} `json:"doc,omitempty"`
+	DocAsUpsert bool        `json:"doc_as_upsert,omitempty"`
+	Script      *Script     `json:"script,omitempty"`
+	Upsert      interface{} `json:"upsert,omitempty"`
+}
+
+// Script is a script used by scripted updates, e.g. Repo.Update and Repo.UpdateByQuery
+type Script struct {
+	Source string                 `json:"source"`
+	Lang   string                 `json:"lang,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// PartialUpdate returns an UpdateBody that merges given partial document into the existing document
+func PartialUpdate(doc interface{}) UpdateBody {
+	return UpdateBody{Doc: doc}
+}
+
+// ScriptedUpdate returns an UpdateBody that updates the existing document using given script
+func ScriptedUpdate(script Script) UpdateBody {
+	return UpdateBody{Script: &script}
+}
+
+// DocumentResponse is the response of single document write operations,
+// modeled after https://opensearch.org/docs/latest/api-reference/document-apis/update-document/#response-body-fields
+type DocumentResponse struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     int    `json:"_version"`
+	Result      string `json:"result"`
+	SeqNo       int    `json:"_seq_no"`
+	PrimaryTerm int    `json:"_primary_term"`
+}
+
+func (c *RepoImpl[T]) Update(ctx context.Context, index string, id string, body interface{}, o ...Option[opensearchapi.UpdateRequest]) (*DocumentResponse, error) {
+	var buffer bytes.Buffer
+	err := json.NewEncoder(&buffer).Encode(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode update body: %w", err)
+	}
+	resp, err := c.client.Update(ctx, index, id, &buffer, o...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		logger.WithContext(ctx).Debugf("error response: %s", resp.String())
+		switch resp.StatusCode {
+		case http.StatusConflict:
+			return nil, fmt.Errorf("%w: error status code: %d", ErrVersionConflict, resp.StatusCode)
+		case http.StatusNotFound:
+			return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, id)
+		default:
+			return nil, fmt.Errorf("error status code: %d", resp.StatusCode)
+		}
+	}
+	return UnmarshalResponse[DocumentResponse](resp)
+}
+
+func (c *OpenClientImpl) Update(ctx context.Context, index string, id string, body io.Reader, o ...Option[opensearchapi.UpdateRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.UpdateRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdUpdate, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, Update.WithContext(ctx))
+	resp, err := c.client.API.Update(index, id, body, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdUpdate, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type updateExt struct {
+	opensearchapi.Update
+}
+
+var Update = updateExt{}
+
+// WithSeqNoPrimaryTerm only performs the update if the document still has given sequence number and primary term
+// (see Document). Otherwise, the update is rejected with ErrVersionConflict.
+func (s updateExt) WithSeqNoPrimaryTerm(seqNo, primaryTerm int) func(request *opensearchapi.UpdateRequest) {
+	return func(request *opensearchapi.UpdateRequest) {
+		request.IfSeqNo = &seqNo
+		request.IfPrimaryTerm = &primaryTerm
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"io"
+)
+
+func (c *RepoImpl[T]) UpdateByQuery(ctx context.Context, index []string, body interface{}, o ...Option[opensearchapi.UpdateByQueryRequest]) (*ByQueryResponse, error) {
+	var buffer bytes.Buffer
+	err := json.NewEncoder(&buffer).Encode(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode query: %w", err)
+	}
+	resp, err := c.client.UpdateByQuery(ctx, index, &buffer, o...)
+	if err != nil {
+		return nil, err
+	}
+	if err := byQueryError(ctx, resp); err != nil {
+		return nil, err
+	}
+	return UnmarshalResponse[ByQueryResponse](resp)
+}
+
+func (c *OpenClientImpl) UpdateByQuery(ctx context.Context, index []string, body io.Reader, o ...Option[opensearchapi.UpdateByQueryRequest]) (*opensearchapi.Response, error) {
+	options := make([]func(request *opensearchapi.UpdateByQueryRequest), len(o))
+	for i, v := range o {
+		options[i] = v
+	}
+	for _, hook := range c.beforeHook {
+		ctx = hook.Before(ctx, BeforeContext{cmd: CmdUpdateByQuery, Options: &options})
+	}
+
+	//nolint:makezero
+	options = append(options, UpdateByQuery.WithContext(ctx), UpdateByQuery.WithBody(body))
+	resp, err := c.client.API.UpdateByQuery(index, options...)
+
+	for _, hook := range c.afterHook {
+		ctx = hook.After(ctx, AfterContext{cmd: CmdUpdateByQuery, Options: &options, Resp: resp, Err: &err})
+	}
+	return resp, err
+}
+
+type updateByQueryExt struct {
+	opensearchapi.UpdateByQuery
+}
+
+var UpdateByQuery = updateByQueryExt{}
@@ -0,0 +1,125 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"sync"
+	"time"
+)
+
+/**************************
+	Cluster Options
+ **************************/
+
+// LeaderOnly option to execute the task only if current replica holds the leadership lock (dsync.LeadershipLock).
+// Replicas that are not the leader skip the execution, and the skip is reported to TaskHook as ErrSkipped.
+// The execution context is cancelled if leadership is lost during execution, and carries the lock's fencing token.
+// See dsync.FencingTokenFromContext
+// Note: dsync.Module is required
+func LeaderOnly() TaskOptions {
+	return func(opt *TaskOption) error {
+		opt.guards = append(opt.guards, leaderOnlyGuard)
+		return nil
+	}
+}
+
+// WithLock option to execute the task only if the distributed lock with given key is acquired (dsync.Lock.TryLock).
+// Replicas failed to acquire the lock skip the execution, and the skip is reported to TaskHook as ErrSkipped.
+// The lock is released after each execution.
+//
+// "ttl" limits how long a single execution may hold the lock: the execution context is cancelled after "ttl".
+// Non-positive "ttl" means no limit. In any case, the execution context is cancelled if the lock is lost.
+// The execution context also carries the lock's fencing token. See dsync.FencingTokenFromContext
+// Note: dsync.Module is required
+func WithLock(key string, ttl time.Duration) TaskOptions {
+	return func(opt *TaskOption) error {
+		if key == "" {
+			return fmt.Errorf("WithLock requires non-empty lock key")
+		}
+		opt.guards = append(opt.guards, lockGuard(key, ttl))
+		return nil
+	}
+}
+
+/**************************
+	Helpers
+ **************************/
+
+func leaderOnlyGuard(ctx context.Context, _ string) (context.Context, context.CancelFunc, error) {
+	lock := dsync.LeadershipLock()
+	if e := lock.TryLock(ctx); e != nil {
+		return ctx, nil, fmt.Errorf("%w: not leader: %w", ErrSkipped, e)
+	}
+	execCtx, cancel := cancelOnLost(ctx, lock)
+	return execCtx, cancel, nil
+}
+
+func lockGuard(key string, ttl time.Duration) taskGuard {
+	// dsync.Lock with same key is shared within the process.
+	// We use a local mutex to prevent overlapping executions of same task from sharing the acquired lock.
+	var localMtx sync.Mutex
+	return func(ctx context.Context, _ string) (context.Context, context.CancelFunc, error) {
+		if !localMtx.TryLock() {
+			return ctx, nil, fmt.Errorf("%w: lock [%s] is held by another execution", ErrSkipped, key)
+		}
+		lock := dsync.LockWithKey(key)
+		if e := lock.TryLock(ctx); e != nil {
+			_ = lock.Release()
+			localMtx.Unlock()
+			return ctx, nil, fmt.Errorf("%w: unable to acquire lock [%s]: %w", ErrSkipped, key, e)
+		}
+
+		execCtx, cancel := cancelOnLost(ctx, lock)
+		if ttl > 0 {
+			var cancelTimeout context.CancelFunc
+			execCtx, cancelTimeout = context.WithTimeout(execCtx, ttl)
+			cancelLost := cancel
+			cancel = func() {
+				cancelTimeout()
+				cancelLost()
+			}
+		}
+		return execCtx, func() {
+			cancel()
+			if e := lock.Release(); e != nil {
+				logger.WithContext(ctx).Warnf("Unable to release lock [%s]: %v", key, e)
+			}
+			localMtx.Unlock()
+		}, nil
+	}
+}
+
+// cancelOnLost returns a context that is cancelled when given lock is lost.
+// The returned context also carries the lock's fencing token, if available
+func cancelOnLost(ctx context.Context, lock dsync.Lock) (context.Context, context.CancelFunc) {
+	if token := lock.FencingToken(); token != 0 {
+		ctx = dsync.ContextWithFencingToken(ctx, token)
+	}
+	execCtx, cancel := context.WithCancel(ctx)
+	lost := lock.Lost()
+	go func() {
+		select {
+		case <-lost:
+			cancel()
+		case <-execCtx.Done():
+		}
+	}()
+	return execCtx, cancel
+}
@@ -0,0 +1,322 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/dsync"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*************************
+	Test Setup
+ *************************/
+
+type TestSyncManagerOut struct {
+	fx.Out
+	Manager     dsync.SyncManager `group:"dsync"`
+	TestManager *TestSyncManager
+}
+
+func ProvideTestSyncManager() TestSyncManagerOut {
+	m := &TestSyncManager{locks: map[string]*TestLock{}}
+	return TestSyncManagerOut{
+		Manager:     m,
+		TestManager: m,
+	}
+}
+
+/*************************
+	Tests
+ *************************/
+
+type TestClusterDI struct {
+	fx.In
+	SyncManager *TestSyncManager
+}
+
+func TestClusterAwareTasks(t *testing.T) {
+	di := TestClusterDI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		apptest.WithTimeout(1000*TestTimeUnit),
+		apptest.WithModules(dsync.Module),
+		apptest.WithFxOptions(
+			fx.Provide(ProvideTestSyncManager),
+		),
+		apptest.WithDI(&di),
+		test.GomegaSubTest(SubTestLeaderOnly(&di, true), "TestAsLeader"),
+		test.GomegaSubTest(SubTestLeaderOnly(&di, false), "TestAsNonLeader"),
+		test.GomegaSubTest(SubTestWithLock(&di, true), "TestLockAvailable"),
+		test.GomegaSubTest(SubTestWithLock(&di, false), "TestLockUnavailable"),
+		test.GomegaSubTest(SubTestWithLockTTL(&di), "TestLockTTL"),
+		test.GomegaSubTest(SubTestWithLockFencingToken(&di), "TestLockFencingToken"),
+	)
+}
+
+/*************************
+	Sub-Test Cases
+ *************************/
+
+func SubTestLeaderOnly(di *TestClusterDI, isLeader bool) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		di.SyncManager.Deny(!isLeader, func(key string) bool { return strings.HasSuffix(key, "/leadership") })
+		hook := &RecordingTaskHook{}
+		tf, execCh := TimingNotifyingTask(TestTimeUnit, nil)
+		defer close(execCh)
+
+		canceller, e := Repeat(tf, AtRate(5*TestTimeUnit), LeaderOnly(), TaskHooks(hook), Name("test-leader-only"))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+
+		AssertGuardedExecution(ctx, g, canceller, execCh, hook, isLeader)
+	}
+}
+
+func SubTestWithLock(di *TestClusterDI, available bool) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "test-task-lock"
+		di.SyncManager.Deny(!available, func(key string) bool { return key == lockKey })
+		hook := &RecordingTaskHook{}
+		tf, execCh := TimingNotifyingTask(TestTimeUnit, nil)
+		defer close(execCh)
+
+		canceller, e := Repeat(tf, AtRate(5*TestTimeUnit), WithLock(lockKey, 0), TaskHooks(hook), Name("test-with-lock"))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+
+		AssertGuardedExecution(ctx, g, canceller, execCh, hook, available)
+		g.Eventually(func() bool { return di.SyncManager.locks[lockKey].Held() }, 10*TestTimeUnit).
+			To(BeFalse(), "lock should be released after execution")
+	}
+}
+
+func SubTestWithLockTTL(di *TestClusterDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "test-task-lock-ttl"
+		di.SyncManager.Deny(false, nil)
+		errCh := make(chan error, 1)
+		tf := func(ctx context.Context) error {
+			<-ctx.Done()
+			errCh <- ctx.Err()
+			return nil
+		}
+
+		canceller, e := RunOnce(tf, WithLock(lockKey, 5*TestTimeUnit), Name("test-lock-ttl"))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+
+		select {
+		case e := <-errCh:
+			g.Expect(e).To(MatchError(context.DeadlineExceeded), "execution should be cancelled after TTL")
+		case <-ctx.Done():
+			t.Errorf("execution should be cancelled before test timeout")
+		}
+
+		// invalid option
+		_, e = RunOnce(tf, WithLock("", time.Second))
+		g.Expect(e).To(HaveOccurred(), "WithLock with empty key should fail")
+	}
+}
+
+func SubTestWithLockFencingToken(di *TestClusterDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const lockKey = "test-task-lock-fencing"
+		di.SyncManager.Deny(false, nil)
+		tokenCh := make(chan uint64, 2)
+		tf := func(ctx context.Context) error {
+			token, _ := dsync.FencingTokenFromContext(ctx)
+			tokenCh <- token
+			return nil
+		}
+
+		for i := 0; i < 2; i++ {
+			canceller, e := RunOnce(tf, WithLock(lockKey, 0), Name("test-lock-fencing"))
+			g.Expect(e).To(Succeed(), "new task shouldn't return error")
+			<-canceller.Cancelled()
+		}
+		close(tokenCh)
+		var tokens []uint64
+		for token := range tokenCh {
+			tokens = append(tokens, token)
+		}
+		g.Expect(tokens).To(HaveLen(2), "task should be executed")
+		g.Expect(tokens[0]).To(BeNumerically(">", 0), "execution context should carry fencing token")
+		g.Expect(tokens[1]).To(BeNumerically(">", tokens[0]), "fencing token should increase")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func AssertGuardedExecution(ctx context.Context, g *gomega.WithT, canceller TaskCanceller, execCh chan time.Time, hook *RecordingTaskHook, expectExec bool) {
+	if expectExec {
+		i, e := WaitTask(ctx, canceller, 2, execCh, nil)
+		g.Expect(e).To(Succeed(), "task shouldn't finished with error")
+		g.Expect(i).To(Equal(2), "task should be triggered")
+		g.Expect(hook.Errors()).To(HaveEach(Succeed()), "hooks should not see any error")
+		return
+	}
+
+	g.Eventually(hook.Errors, 20*TestTimeUnit).Should(HaveLen(2), "hooks should be invoked")
+	g.Expect(hook.Errors()).To(HaveEach(MatchError(ErrSkipped)), "hooks should see skipped error")
+	g.Expect(execCh).To(BeEmpty(), "task should not be triggered")
+	g.Expect(canceller.(*task).skipped).To(BeNumerically(">=", 2), "skipped executions should be recorded")
+	select {
+	case <-canceller.Cancelled():
+		g.Expect(true).To(BeFalse(), "task should not be cancelled by skipped executions")
+	default:
+	}
+}
+
+type RecordingTaskHook struct {
+	mtx  sync.Mutex
+	errs []error
+}
+
+func (h *RecordingTaskHook) BeforeTrigger(ctx context.Context, _ string) context.Context {
+	return ctx
+}
+
+func (h *RecordingTaskHook) AfterTrigger(_ context.Context, _ string, err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.errs = append(h.errs, err)
+}
+
+func (h *RecordingTaskHook) Errors() []error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return append([]error{}, h.errs...)
+}
+
+// TestSyncManager is a dsync.SyncManager that deny lock acquisition for keys matching configured predicate
+type TestSyncManager struct {
+	mtx   sync.Mutex
+	locks map[string]*TestLock
+	deny  func(key string) bool
+	token uint64
+}
+
+func (m *TestSyncManager) Deny(deny bool, matcher func(key string) bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.deny = nil
+	if deny {
+		m.deny = matcher
+	}
+}
+
+func (m *TestSyncManager) denied(key string) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.deny != nil && m.deny(key)
+}
+
+func (m *TestSyncManager) Lock(key string, _ ...dsync.LockOptions) (dsync.Lock, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if l, ok := m.locks[key]; ok {
+		return l, nil
+	}
+	l := &TestLock{key: key, manager: m}
+	m.locks[key] = l
+	return l, nil
+}
+
+func (m *TestSyncManager) nextToken() uint64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.token++
+	return m.token
+}
+
+type TestLock struct {
+	mtx     sync.Mutex
+	key     string
+	manager *TestSyncManager
+	lost    chan struct{}
+	token   uint64
+}
+
+func (l *TestLock) Key() string {
+	return l.key
+}
+
+func (l *TestLock) Lock(ctx context.Context) error {
+	for {
+		if e := l.TryLock(ctx); e == nil {
+			return nil
+		}
+		select {
+		case <-time.After(TestTimeUnit):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *TestLock) TryLock(_ context.Context) error {
+	if l.manager.denied(l.key) {
+		return dsync.ErrLockUnavailable
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.lost == nil {
+		l.lost = make(chan struct{})
+		l.token = l.manager.nextToken()
+	}
+	return nil
+}
+
+func (l *TestLock) Release() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.lost != nil {
+		close(l.lost)
+		l.lost = nil
+		l.token = 0
+	}
+	return nil
+}
+
+func (l *TestLock) Lost() <-chan struct{} {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.lost
+}
+
+func (l *TestLock) FencingToken() uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.token
+}
+
+func (l *TestLock) Held() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.lost != nil
+}
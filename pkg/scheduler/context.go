@@ -18,6 +18,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -30,6 +31,11 @@ const (
 
 type Mode int
 
+// ErrSkipped is reported to TaskHook.AfterTrigger (wrapped) when an execution is skipped by one of the task's guards,
+// e.g. current replica is not the leader or the distributed lock is held by another replica.
+// Skipped executions are not considered as failures, therefore CancelOnError doesn't apply to them.
+var ErrSkipped = errors.New("task execution skipped")
+
 type TaskFunc func(ctx context.Context) error
 
 type TaskCanceller interface {
@@ -46,6 +52,10 @@ type TaskOption struct {
 	cancelOnError bool
 	nextFunc      nextFunc
 	hooks         []TaskHook
+	guards        []taskGuard
+	cronExpr      string
+	jobStore      JobStore
+	misfire       MisfirePolicy
 }
 
 type TaskHook interface {
@@ -56,5 +66,9 @@ type TaskHook interface {
 	AfterTrigger(ctx context.Context, id string, err error)
 }
 
+// taskGuard is invoked before each execution, after all TaskHook.BeforeTrigger.
+// Non-nil error would skip current execution. The returned context.CancelFunc (if not nil) is invoked after the execution.
+type taskGuard func(ctx context.Context, id string) (context.Context, context.CancelFunc, error)
+
 // nextFunc is used for ModeDynamic. It's currently unexported and only used for cron impl
 type nextFunc func(time.Time) time.Time
@@ -39,6 +39,7 @@ func withCronExpression(expr string) TaskOptions {
 		if e != nil {
 			return e
 		}
+		opt.cronExpr = expr
 		return dynamicNext(nextFn)(opt)
 	}
 }
@@ -0,0 +1,105 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gormscheduler
+// Provides scheduler.JobStore backed by relational database via GORM (pkg/data).
+//
+// The store doesn't create its table automatically. Use GormJobStore.CreateTableIfNotExist as a migration step
+// (see pkg/migration), e.g.
+//
+//	func registerMigrations(r *migration.Registrar, db *gorm.DB) {
+//		r.AddMigrations(
+//			migration.WithVersion("1.0.0.1").WithTag(migration.TagPreUpgrade).
+//				WithDesc("create scheduled task history table").
+//				WithFunc(gormscheduler.NewGormJobStore(db).CreateTableIfNotExist),
+//		)
+//	}
+package gormscheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/cisco-open/go-lanai/pkg/data"
+	"github.com/cisco-open/go-lanai/pkg/scheduler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// TaskHistory is the GORM model of scheduler.TaskRecord
+type TaskHistory struct {
+	Name           string `gorm:"primaryKey;type:text"`
+	LastStartedAt  time.Time
+	LastFinishedAt time.Time
+	LastOutcome    string
+	LastError      string
+}
+
+func (TaskHistory) TableName() string {
+	return "scheduled_task_history"
+}
+
+// GormJobStore implements scheduler.JobStore
+type GormJobStore struct {
+	db *gorm.DB
+}
+
+func NewGormJobStore(db *gorm.DB) *GormJobStore {
+	return &GormJobStore{
+		db: db,
+	}
+}
+
+// CreateTableIfNotExist creates or updates table "scheduled_task_history". It's compatible with migration.MigrationFunc
+func (s *GormJobStore) CreateTableIfNotExist(ctx context.Context) error {
+	return s.db.WithContext(ctx).AutoMigrate(&TaskHistory{})
+}
+
+func (s *GormJobStore) Load(ctx context.Context, name string) (*scheduler.TaskRecord, error) {
+	var model TaskHistory
+	if e := s.db.WithContext(ctx).Where("name = ?", name).Take(&model).Error; e != nil {
+		if errors.Is(e, gorm.ErrRecordNotFound) || errors.Is(e, data.ErrorRecordNotFound) {
+			return nil, nil
+		}
+		return nil, e
+	}
+	return &scheduler.TaskRecord{
+		Name:           model.Name,
+		LastStartedAt:  model.LastStartedAt,
+		LastFinishedAt: model.LastFinishedAt,
+		LastOutcome:    scheduler.Outcome(model.LastOutcome),
+		LastError:      model.LastError,
+	}, nil
+}
+
+func (s *GormJobStore) Save(ctx context.Context, record *scheduler.TaskRecord) error {
+	model := TaskHistory{
+		Name:           record.Name,
+		LastStartedAt:  record.LastStartedAt,
+		LastFinishedAt: record.LastFinishedAt,
+		LastOutcome:    string(record.LastOutcome),
+		LastError:      record.LastError,
+	}
+	return s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&model).Error
+}
+
+// Delete removes the TaskRecord of given task name, e.g. when the task is decommissioned.
+// Deleting non-existing record is not an error.
+func (s *GormJobStore) Delete(ctx context.Context, name string) error {
+	return s.db.WithContext(ctx).Where("name = ?", name).Delete(&TaskHistory{}).Error
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gormscheduler
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/pkg/scheduler"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/cisco-open/go-lanai/test/apptest"
+	"github.com/cisco-open/go-lanai/test/dbtest"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+/*************************
+	Test
+ *************************/
+
+//func TestMain(m *testing.M) {
+//	suitetest.RunTests(m,
+//		dbtest.EnableDBRecordMode(),
+//	)
+//}
+
+func TestGormJobStore(t *testing.T) {
+	di := &dbtest.DI{}
+	test.RunTest(context.Background(), t,
+		apptest.Bootstrap(),
+		dbtest.WithDBPlayback("testdb"),
+		apptest.WithTimeout(time.Minute),
+		apptest.WithDI(di),
+		test.SubTestSetup(SetupTestPrepareTable(di)),
+		test.GomegaSubTest(SubTestSaveAndLoad(di), "TestSaveAndLoad"),
+		test.GomegaSubTest(SubTestSaveOverwrite(di), "TestSaveOverwrite"),
+		test.GomegaSubTest(SubTestLoadNonExisting(di), "TestLoadNonExisting"),
+		test.GomegaSubTest(SubTestDelete(di), "TestDelete"),
+	)
+}
+
+/*************************
+	Sub Tests
+ *************************/
+
+func SetupTestPrepareTable(di *dbtest.DI) test.SetupFunc {
+	return func(ctx context.Context, t *testing.T) (context.Context, error) {
+		g := gomega.NewWithT(t)
+		store := NewGormJobStore(di.DB)
+		g.Expect(store.CreateTableIfNotExist(ctx)).To(Succeed(), "create table should not fail")
+		rs := di.DB.Exec(`TRUNCATE TABLE "scheduled_task_history"`)
+		g.Expect(rs.Error).To(Succeed(), "truncate table should not fail")
+		return ctx, nil
+	}
+}
+
+func SubTestSaveAndLoad(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		store := NewGormJobStore(di.DB)
+		expected := scheduler.TaskRecord{
+			Name:           "test-save",
+			LastStartedAt:  time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC),
+			LastFinishedAt: time.Date(2023, 6, 1, 10, 0, 5, 0, time.UTC),
+			LastOutcome:    scheduler.OutcomeFailure,
+			LastError:      "oops",
+		}
+		g.Expect(store.Save(ctx, &expected)).To(Succeed(), "save should not fail")
+		assertRecord(ctx, g, store, &expected)
+	}
+}
+
+func SubTestSaveOverwrite(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		store := NewGormJobStore(di.DB)
+		record := scheduler.TaskRecord{
+			Name:           "test-overwrite",
+			LastStartedAt:  time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC),
+			LastFinishedAt: time.Date(2023, 6, 1, 10, 0, 5, 0, time.UTC),
+			LastOutcome:    scheduler.OutcomeFailure,
+			LastError:      "oops",
+		}
+		g.Expect(store.Save(ctx, &record)).To(Succeed(), "save should not fail")
+
+		record = scheduler.TaskRecord{
+			Name:           "test-overwrite",
+			LastStartedAt:  time.Date(2023, 6, 1, 11, 0, 0, 0, time.UTC),
+			LastFinishedAt: time.Date(2023, 6, 1, 11, 0, 1, 0, time.UTC),
+			LastOutcome:    scheduler.OutcomeSuccess,
+		}
+		g.Expect(store.Save(ctx, &record)).To(Succeed(), "save existing record should not fail")
+		assertRecord(ctx, g, store, &record)
+
+		var count int64
+		rs := di.DB.WithContext(ctx).Model(&TaskHistory{}).Where("name = ?", record.Name).Count(&count)
+		g.Expect(rs.Error).To(Succeed(), "count should not fail")
+		g.Expect(count).To(BeEquivalentTo(1), "save existing record should not create new row")
+	}
+}
+
+func SubTestLoadNonExisting(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		store := NewGormJobStore(di.DB)
+		record, e := store.Load(ctx, "test-non-existing")
+		g.Expect(e).To(Succeed(), "load non-existing record should not fail")
+		g.Expect(record).To(BeNil(), "load non-existing record should return nil")
+	}
+}
+
+func SubTestDelete(di *dbtest.DI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		store := NewGormJobStore(di.DB)
+		record := scheduler.TaskRecord{
+			Name:           "test-delete",
+			LastStartedAt:  time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC),
+			LastFinishedAt: time.Date(2023, 6, 1, 10, 0, 5, 0, time.UTC),
+			LastOutcome:    scheduler.OutcomeSuccess,
+		}
+		g.Expect(store.Save(ctx, &record)).To(Succeed(), "save should not fail")
+		assertRecord(ctx, g, store, &record)
+
+		g.Expect(store.Delete(ctx, record.Name)).To(Succeed(), "delete should not fail")
+		loaded, e := store.Load(ctx, record.Name)
+		g.Expect(e).To(Succeed(), "load deleted record should not fail")
+		g.Expect(loaded).To(BeNil(), "load deleted record should return nil")
+
+		g.Expect(store.Delete(ctx, record.Name)).To(Succeed(), "delete non-existing record should not fail")
+	}
+}
+
+/*************************
+	Helpers
+ *************************/
+
+func assertRecord(ctx context.Context, g *gomega.WithT, store scheduler.JobStore, expected *scheduler.TaskRecord) {
+	record, e := store.Load(ctx, expected.Name)
+	g.Expect(e).To(Succeed(), "load should not fail")
+	g.Expect(record).ToNot(BeNil(), "load should return record")
+	g.Expect(record.Name).To(Equal(expected.Name), "record should have correct name")
+	g.Expect(record.LastStartedAt).To(BeTemporally("==", expected.LastStartedAt), "record should have correct start time")
+	g.Expect(record.LastFinishedAt).To(BeTemporally("==", expected.LastFinishedAt), "record should have correct finish time")
+	g.Expect(record.LastOutcome).To(Equal(expected.LastOutcome), "record should have correct outcome")
+	g.Expect(record.LastError).To(Equal(expected.LastError), "record should have correct error")
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Outcome of a task execution
+type Outcome string
+
+const (
+	// MisfireSkip ignores any runs missed during downtime
+	MisfireSkip MisfirePolicy = iota
+	// MisfireFireOnce fires the task once at startup if any run was missed during downtime
+	MisfireFireOnce
+	// MisfireFireAll fires the task at startup as many times as the runs missed during downtime
+	MisfireFireAll
+)
+
+// MisfirePolicy controls how runs missed during downtime are handled when the task is scheduled
+type MisfirePolicy int
+
+// maxMisfires caps the number of missed runs calculated at startup, to guard against tasks with very short interval
+const maxMisfires = 1000
+
+// TaskRecord is the execution history of a named task
+type TaskRecord struct {
+	Name           string
+	LastStartedAt  time.Time
+	LastFinishedAt time.Time
+	LastOutcome    Outcome
+	LastError      string
+}
+
+// JobStore persists TaskRecord of named tasks.
+// Implementations should be goroutine-safe
+type JobStore interface {
+	// Load returns the TaskRecord of given task name, or nil if the task never finished before
+	Load(ctx context.Context, name string) (*TaskRecord, error)
+	// Save creates or overwrites the TaskRecord of the task
+	Save(ctx context.Context, record *TaskRecord) error
+}
+
+/**************************
+	Options
+ **************************/
+
+// WithJobStore option to record the task's last execution in given JobStore.
+// Name option is required, because records are keyed by task's name.
+// Skipped executions (see ErrSkipped) are not recorded.
+func WithJobStore(store JobStore) TaskOptions {
+	return func(opt *TaskOption) error {
+		opt.jobStore = store
+		return nil
+	}
+}
+
+// Misfire option to set the MisfirePolicy for runs missed during downtime. Default is MisfireSkip.
+// Missed runs are calculated based on the last record in JobStore, therefore WithJobStore is required.
+// Note: catch-up runs are executed sequentially before the regular schedule starts, and are subject to other
+// options such as LeaderOnly, WithLock, etc.
+func Misfire(policy MisfirePolicy) TaskOptions {
+	return func(opt *TaskOption) error {
+		opt.misfire = policy
+		return nil
+	}
+}
+
+/**************************
+	In-Memory Store
+ **************************/
+
+// InMemoryJobStore implements JobStore and keeps records within current process.
+type InMemoryJobStore struct {
+	mtx     sync.RWMutex
+	records map[string]TaskRecord
+}
+
+func NewInMemoryJobStore() *InMemoryJobStore {
+	return &InMemoryJobStore{
+		records: map[string]TaskRecord{},
+	}
+}
+
+func (s *InMemoryJobStore) Load(_ context.Context, name string) (*TaskRecord, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if r, ok := s.records[name]; ok {
+		return &r, nil
+	}
+	return nil, nil
+}
+
+func (s *InMemoryJobStore) Save(_ context.Context, record *TaskRecord) error {
+	if record == nil || record.Name == "" {
+		return fmt.Errorf("task record without name cannot be saved")
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.records[record.Name] = *record
+	return nil
+}
+
+/**************************
+	Hook
+ **************************/
+
+type ckJobStartTime struct{}
+
+// jobStoreHook implements TaskHook and records each execution into JobStore
+type jobStoreHook struct {
+	name  string
+	store JobStore
+}
+
+func (h *jobStoreHook) BeforeTrigger(ctx context.Context, _ string) context.Context {
+	return context.WithValue(ctx, ckJobStartTime{}, time.Now())
+}
+
+func (h *jobStoreHook) AfterTrigger(ctx context.Context, id string, err error) {
+	if errors.Is(err, ErrSkipped) {
+		return
+	}
+	record := TaskRecord{
+		Name:           h.name,
+		LastFinishedAt: time.Now(),
+		LastOutcome:    OutcomeSuccess,
+	}
+	record.LastStartedAt, _ = ctx.Value(ckJobStartTime{}).(time.Time)
+	if err != nil {
+		record.LastOutcome = OutcomeFailure
+		record.LastError = err.Error()
+	}
+	if e := h.store.Save(ctx, &record); e != nil {
+		logger.WithContext(ctx).Warnf("Task [%s] failed to save execution record: %v", id, e)
+	}
+}
+
+/**************************
+	Helpers
+ **************************/
+
+// countMisfires calculates how many runs were missed between the last recorded start time and now.
+func countMisfires(opt *TaskOption, last time.Time, now time.Time) (count int) {
+	var next nextFunc
+	switch opt.mode {
+	case ModeDynamic:
+		next = opt.nextFunc
+	case ModeFixedRate, ModeFixedDelay:
+		next = func(t time.Time) time.Time { return t.Add(opt.interval) }
+	default:
+		return 0
+	}
+	for t := next(last); t.Before(now) && count < maxMisfires; t = next(t) {
+		count++
+	}
+	return
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+/************************
+	Tests
+ ************************/
+
+func TestJobStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1000*TestTimeUnit)
+	defer cancel()
+	test.RunTest(ctx, t,
+		test.GomegaSubTest(SubTestRecordSuccess(), "TestRecordSuccess"),
+		test.GomegaSubTest(SubTestRecordFailure(), "TestRecordFailure"),
+		test.GomegaSubTest(SubTestMisfire(MisfireSkip, 0), "TestMisfireSkip"),
+		test.GomegaSubTest(SubTestMisfire(MisfireFireOnce, 1), "TestMisfireFireOnce"),
+		test.GomegaSubTest(SubTestMisfire(MisfireFireAll, 3), "TestMisfireFireAll"),
+		test.GomegaSubTest(SubTestCronMisfire(), "TestCronMisfire"),
+		test.GomegaSubTest(SubTestJobStoreErrors(), "TestJobStoreErrors"),
+	)
+}
+
+/************************
+	Sub Tests
+ ************************/
+
+func SubTestRecordSuccess() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const name = "test-record-success"
+		store := NewInMemoryJobStore()
+		tf, execCh := TimingNotifyingTask(TestTimeUnit, nil)
+		defer close(execCh)
+
+		before := time.Now()
+		canceller, e := RunOnce(tf, Name(name), WithJobStore(store))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+
+		i, e := WaitTask(ctx, canceller, 1, execCh, nil)
+		g.Expect(e).To(Succeed(), "task shouldn't finished with error")
+		g.Expect(i).To(Equal(1), "task should be triggered")
+
+		record := EventuallyRecord(ctx, g, store, name)
+		g.Expect(record.LastOutcome).To(Equal(OutcomeSuccess), "record should have correct outcome")
+		g.Expect(record.LastError).To(BeEmpty(), "record should have no error")
+		g.Expect(record.LastStartedAt).To(BeTemporally(">=", before), "record should have correct start time")
+		g.Expect(record.LastFinishedAt).To(BeTemporally(">=", record.LastStartedAt), "record should have correct end time")
+	}
+}
+
+func SubTestRecordFailure() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const name = "test-record-failure"
+		store := NewInMemoryJobStore()
+		tf, execCh := TimingNotifyingTask(TestTimeUnit, TaskErrorAfterN(0))
+		defer close(execCh)
+
+		canceller, e := RunOnce(tf, Name(name), WithJobStore(store))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+
+		_, _ = WaitTask(ctx, canceller, 1, execCh, nil)
+		record := EventuallyRecord(ctx, g, store, name)
+		g.Expect(record.LastOutcome).To(Equal(OutcomeFailure), "record should have correct outcome")
+		g.Expect(record.LastError).To(Equal(MockedErr.Error()), "record should have correct error")
+	}
+}
+
+func SubTestMisfire(policy MisfirePolicy, expectedRuns int) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const name = "test-misfire"
+		interval := 20 * TestTimeUnit
+		store := NewInMemoryJobStore()
+		_ = store.Save(ctx, &TaskRecord{
+			Name:          name,
+			LastStartedAt: time.Now().Add(-3*interval - interval/2),
+			LastOutcome:   OutcomeSuccess,
+		})
+		tf, execCh := TimingNotifyingTask(0, nil)
+		defer close(execCh)
+
+		// regular schedule starts way later than the test duration
+		canceller, e := Repeat(tf, StartAfter(time.Hour), AtRate(interval), Name(name), WithJobStore(store), Misfire(policy))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*TestTimeUnit)
+		defer cancel()
+		i, e := WaitTask(timeoutCtx, canceller, expectedRuns+1, execCh, nil)
+		g.Expect(e).To(Equal(context.DeadlineExceeded), "task should not be triggered more than expected")
+		g.Expect(i).To(Equal(expectedRuns), "missed runs should be caught up correctly")
+	}
+}
+
+func SubTestCronMisfire() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		const name = "test-cron-misfire"
+		store := NewInMemoryJobStore()
+		_ = store.Save(ctx, &TaskRecord{
+			Name:          name,
+			LastStartedAt: time.Now().Add(-48 * time.Hour),
+			LastOutcome:   OutcomeSuccess,
+		})
+		tf, execCh := TimingNotifyingTask(0, nil)
+		defer close(execCh)
+
+		// daily at midnight
+		canceller, e := Cron("0 0 0 * * *", tf, Name(name), WithJobStore(store), Misfire(MisfireFireOnce))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*TestTimeUnit)
+		defer cancel()
+		i, _ := WaitTask(timeoutCtx, canceller, 2, execCh, nil)
+		g.Expect(i).To(Equal(1), "missed cron runs should be fired once")
+	}
+}
+
+func SubTestJobStoreErrors() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		tf, execCh := TimingNotifyingTask(0, nil)
+		defer close(execCh)
+
+		_, e := RunOnce(tf, WithJobStore(NewInMemoryJobStore()))
+		g.Expect(e).To(HaveOccurred(), "job store without name should fail")
+
+		_, e = Repeat(tf, AtRate(time.Hour), Name("test"), Misfire(MisfireFireAll))
+		g.Expect(e).To(HaveOccurred(), "misfire policy without job store should fail")
+
+		e = NewInMemoryJobStore().Save(ctx, &TaskRecord{})
+		g.Expect(e).To(HaveOccurred(), "saving record without name should fail")
+
+		r, e := NewInMemoryJobStore().Load(ctx, "non-existing")
+		g.Expect(e).To(Succeed(), "loading non-existing record should not fail")
+		g.Expect(r).To(BeNil(), "loading non-existing record should return nil")
+	}
+}
+
+/************************
+	Helpers
+ ************************/
+
+func EventuallyRecord(ctx context.Context, g *gomega.WithT, store JobStore, name string) *TaskRecord {
+	var record *TaskRecord
+	g.Eventually(func() *TaskRecord {
+		record, _ = store.Load(ctx, name)
+		return record
+	}, 10*TestTimeUnit).ShouldNot(BeNil(), "record should be saved")
+	return record
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var registry = &taskRegistry{
+	tasks: map[string]*task{},
+}
+
+// ManagedTask is a scheduled task that is still active (not cancelled or finished).
+// All tasks scheduled via Repeat, RunOnce and Cron are ManagedTask and can be found via Tasks or FindTask
+type ManagedTask interface {
+	TaskCanceller
+	// Info returns a snapshot of current task status
+	Info() TaskInfo
+	// Pause skips any scheduled executions until Resume is called. Skipped executions are reported as ErrSkipped.
+	Pause()
+	// Resume resumes a paused task
+	Resume()
+	// Trigger executes the task immediately regardless if it's paused.
+	// Other options such as LeaderOnly, WithLock still apply
+	Trigger() error
+}
+
+// TaskInfo is a snapshot of ManagedTask's status
+type TaskInfo struct {
+	ID             string
+	Name           string
+	Mode           string
+	Interval       string
+	Cron           string
+	Paused         bool
+	NextFireTime   time.Time
+	RunCount       int
+	SkipCount      int
+	LastStartedAt  time.Time
+	LastFinishedAt time.Time
+	LastOutcome    Outcome
+	LastError      string
+}
+
+// Tasks returns all active ManagedTask, sorted by ID. Cancelled tasks are excluded
+func Tasks() []ManagedTask {
+	return registry.list()
+}
+
+// FindTask returns active ManagedTask with given ID. If not found, the first task with given name is returned.
+// nil is returned if no task matches
+func FindTask(idOrName string) ManagedTask {
+	return registry.find(idOrName)
+}
+
+/**************************
+	ManagedTask Impl
+ **************************/
+
+func (m Mode) String() string {
+	switch m {
+	case ModeFixedRate:
+		return "fixed-rate"
+	case ModeFixedDelay:
+		return "fixed-delay"
+	case ModeRunOnce:
+		return "run-once"
+	case ModeDynamic:
+		return "dynamic"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// Info implements ManagedTask
+func (t *task) Info() TaskInfo {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	info := TaskInfo{
+		ID:             t.id,
+		Name:           t.option.name,
+		Mode:           t.option.mode.String(),
+		Paused:         t.paused,
+		NextFireTime:   t.next,
+		RunCount:       t.stats.runs,
+		SkipCount:      t.skipped,
+		LastStartedAt:  t.stats.lastStart,
+		LastFinishedAt: t.stats.lastFinish,
+	}
+	switch {
+	case t.option.cronExpr != "":
+		info.Mode = "cron"
+		info.Cron = t.option.cronExpr
+	case t.option.mode == ModeFixedRate || t.option.mode == ModeFixedDelay:
+		info.Interval = t.option.interval.String()
+	}
+	if t.stats.runs != 0 {
+		info.LastOutcome = OutcomeSuccess
+		if t.stats.lastErr != nil {
+			info.LastOutcome = OutcomeFailure
+			info.LastError = t.stats.lastErr.Error()
+		}
+	}
+	return info
+}
+
+// Pause implements ManagedTask
+func (t *task) Pause() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.paused = true
+}
+
+// Resume implements ManagedTask
+func (t *task) Resume() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.paused = false
+}
+
+// Trigger implements ManagedTask
+func (t *task) Trigger() error {
+	if e := t.ctx.Err(); e != nil {
+		return fmt.Errorf("task [%s] is no longer active: %w", t.id, e)
+	}
+	t.execTask(t.ctx, false, true)
+	return nil
+}
+
+/**************************
+	Registry
+ **************************/
+
+type taskRegistry struct {
+	mtx   sync.RWMutex
+	tasks map[string]*task
+}
+
+func (r *taskRegistry) add(t *task) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.tasks[t.id] = t
+}
+
+func (r *taskRegistry) remove(t *task) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.tasks, t.id)
+}
+
+func (r *taskRegistry) list() []ManagedTask {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	tasks := make([]ManagedTask, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		if t.ctx.Err() == nil {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].(*task).id < tasks[j].(*task).id
+	})
+	return tasks
+}
+
+func (r *taskRegistry) find(idOrName string) ManagedTask {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if t, ok := r.tasks[idOrName]; ok && t.ctx.Err() == nil {
+		return t
+	}
+	var found *task
+	for _, t := range r.tasks {
+		if t.option.name == idOrName && t.ctx.Err() == nil && (found == nil || t.id < found.id) {
+			found = t
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	return found
+}
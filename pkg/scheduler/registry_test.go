@@ -0,0 +1,133 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"github.com/cisco-open/go-lanai/test"
+	"github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+/************************
+	Tests
+ ************************/
+
+func TestTaskRegistry(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1000*TestTimeUnit)
+	defer cancel()
+	test.RunTest(ctx, t,
+		test.GomegaSubTest(SubTestRegistryListing(), "TestListing"),
+		test.GomegaSubTest(SubTestPauseAndResume(), "TestPauseAndResume"),
+		test.GomegaSubTest(SubTestManualTrigger(), "TestManualTrigger"),
+	)
+}
+
+/************************
+	Sub Tests
+ ************************/
+
+func SubTestRegistryListing() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		tf, execCh := TimingNotifyingTask(0, nil)
+		defer close(execCh)
+
+		fixedRate, e := Repeat(tf, StartAfter(time.Hour), AtRate(time.Minute), Name("test-registry-rate"))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer fixedRate.Cancel()
+		cron, e := Cron("0 0 0 * * *", tf, Name("test-registry-cron"))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer cron.Cancel()
+
+		g.Expect(Tasks()).To(ContainElements(fixedRate, cron), "registry should contain scheduled tasks")
+		g.Expect(FindTask("test-registry-rate")).To(Equal(fixedRate), "task should be found by name")
+		g.Expect(FindTask(fixedRate.(ManagedTask).Info().ID)).To(Equal(fixedRate), "task should be found by ID")
+		g.Expect(FindTask("non-existing")).To(BeNil(), "non-existing task should not be found")
+
+		g.Eventually(func() time.Time { return cron.(ManagedTask).Info().NextFireTime }, 10*TestTimeUnit).
+			ShouldNot(BeZero(), "next fire time should be available")
+		info := cron.(ManagedTask).Info()
+		g.Expect(info.Mode).To(Equal("cron"), "info should have correct mode")
+		g.Expect(info.Cron).To(Equal("0 0 0 * * *"), "info should have correct cron expression")
+		g.Expect(info.NextFireTime).To(BeTemporally(">", time.Now()), "info should have correct next fire time")
+
+		info = fixedRate.(ManagedTask).Info()
+		g.Expect(info.Mode).To(Equal("fixed-rate"), "info should have correct mode")
+		g.Expect(info.Interval).To(Equal(time.Minute.String()), "info should have correct interval")
+		g.Expect(info.RunCount).To(BeZero(), "info should have correct run count")
+		g.Expect(info.LastOutcome).To(BeZero(), "info should have no outcome")
+
+		fixedRate.Cancel()
+		<-fixedRate.Cancelled()
+		g.Expect(Tasks()).ToNot(ContainElement(fixedRate), "cancelled task should be removed from registry")
+	}
+}
+
+func SubTestPauseAndResume() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		hook := &RecordingTaskHook{}
+		tf, execCh := TimingNotifyingTask(0, nil)
+		defer close(execCh)
+
+		canceller, e := Repeat(tf, AtRate(5*TestTimeUnit), TaskHooks(hook), Name("test-pause"))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+		managed := canceller.(ManagedTask)
+
+		managed.Pause()
+		g.Expect(managed.Info().Paused).To(BeTrue(), "task should be paused")
+		g.Eventually(hook.Errors, 20*TestTimeUnit).Should(HaveLen(2), "hooks should be invoked")
+		g.Expect(hook.Errors()).To(HaveEach(MatchError(ErrSkipped)), "paused executions should be skipped")
+		g.Expect(execCh).To(BeEmpty(), "task should not be triggered when paused")
+		g.Expect(managed.Info().SkipCount).To(BeNumerically(">=", 2), "skip count should be correct")
+
+		managed.Resume()
+		g.Expect(managed.Info().Paused).To(BeFalse(), "task should be resumed")
+		i, e := WaitTask(ctx, canceller, 1, execCh, nil)
+		g.Expect(e).To(Succeed(), "task shouldn't finished with error")
+		g.Expect(i).To(Equal(1), "task should be triggered after resumed")
+		g.Eventually(func() int { return managed.Info().RunCount }, 10*TestTimeUnit).
+			Should(BeNumerically(">=", 1), "run count should be correct")
+	}
+}
+
+func SubTestManualTrigger() test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		tf, execCh := TimingNotifyingTask(0, TaskErrorAfterN(0))
+		defer close(execCh)
+
+		canceller, e := Repeat(tf, StartAfter(time.Hour), AtRate(time.Hour), Name("test-trigger"))
+		g.Expect(e).To(Succeed(), "new task shouldn't return error")
+		defer canceller.Cancel()
+		managed := canceller.(ManagedTask)
+
+		managed.Pause()
+		g.Expect(managed.Trigger()).To(Succeed(), "trigger should not fail")
+		i, e := WaitTask(ctx, canceller, 1, execCh, nil)
+		g.Expect(e).To(Succeed(), "task shouldn't finished with error")
+		g.Expect(i).To(Equal(1), "task should be triggered even if paused")
+		g.Eventually(func() Outcome { return managed.Info().LastOutcome }, 10*TestTimeUnit).
+			Should(Equal(OutcomeFailure), "last outcome should be correct")
+		g.Expect(managed.Info().LastError).To(Equal(MockedErr.Error()), "last error should be correct")
+
+		canceller.Cancel()
+		<-canceller.Cancelled()
+		g.Expect(managed.Trigger()).ToNot(Succeed(), "trigger cancelled task should fail")
+	}
+}
@@ -18,21 +18,35 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"sync"
 	"time"
 )
 
-// task execute TaskFunc based on TaskOption. Also implements TaskCanceller
+// task execute TaskFunc based on TaskOption. Also implements TaskCanceller and ManagedTask
 type task struct {
 	mtx  sync.Mutex
 	id     string
 	task   TaskFunc
 	option TaskOption
+	ctx    context.Context
 	cancel context.CancelFunc
 	done chan error
 	err  error
+	skipped int
+	paused  bool
+	next    time.Time
+	stats   taskStats
+}
+
+// taskStats execution statistics of a task, skipped executions are not counted
+type taskStats struct {
+	runs       int
+	lastStart  time.Time
+	lastFinish time.Time
+	lastErr    error
 }
 
 func newTask(taskFunc TaskFunc, opts ...TaskOptions) (TaskCanceller, error) {
@@ -59,6 +73,14 @@ func newTask(taskFunc TaskFunc, opts ...TaskOptions) (TaskCanceller, error) {
 	switch {
 	case t.option.mode != ModeRunOnce && t.option.mode != ModeDynamic && t.option.interval <= 0:
 		return nil, fmt.Errorf("repeated task should have positive repeat interval")
+	case t.option.jobStore != nil && t.option.name == "":
+		return nil, fmt.Errorf("task with job store should have a name")
+	case t.option.misfire != MisfireSkip && t.option.jobStore == nil:
+		return nil, fmt.Errorf("misfire policy requires job store")
+	}
+
+	if t.option.jobStore != nil {
+		t.option.hooks = append(t.option.hooks, &jobStoreHook{name: t.option.name, store: t.option.jobStore})
 	}
 
 	// start and return
@@ -82,19 +104,26 @@ func (t *task) Cancelled() <-chan error {
 // start main loop
 func (t *task) start(ctx context.Context) {
 	taskCtx, fn := context.WithCancel(ctx)
+	t.ctx = taskCtx
 	t.cancel = fn
+	registry.add(t)
 	go t.loop(taskCtx)
 }
 
 // loop is the main loop for the task
 func (t *task) loop(ctx context.Context) {
 	defer func() {
+		registry.remove(t)
+		t.setNext(time.Time{})
 		t.mtx.Lock()
 		defer t.mtx.Unlock()
 		t.done <- t.err
 		close(t.done)
 	}()
 
+	// catch up runs missed during downtime
+	t.catchUp(ctx)
+
 	// first, figure out first fire time if set
 	var delay time.Duration
 	switch {
@@ -112,9 +141,10 @@ func (t *task) loop(ctx context.Context) {
 		}
 	}
 
+	t.setNext(time.Now().Add(delay))
 	select {
 	case <-time.After(delay):
-		t.execTask(ctx, t.option.mode != ModeFixedRate && t.option.mode != ModeDynamic)
+		t.execTask(ctx, t.option.mode != ModeFixedRate && t.option.mode != ModeDynamic, false)
 	case <-ctx.Done():
 		return
 	}
@@ -131,13 +161,41 @@ func (t *task) loop(ctx context.Context) {
 	}
 }
 
+// catchUp executes missed runs according to MisfirePolicy
+func (t *task) catchUp(ctx context.Context) {
+	if t.option.misfire == MisfireSkip || t.option.jobStore == nil {
+		return
+	}
+	record, e := t.option.jobStore.Load(ctx, t.option.name)
+	switch {
+	case e != nil:
+		logger.WithContext(ctx).Warnf("Task [%s] failed to load execution record: %v", t.id, e)
+		return
+	case record == nil || record.LastStartedAt.IsZero():
+		return
+	}
+
+	missed := countMisfires(&t.option, record.LastStartedAt, time.Now())
+	if missed > 0 && t.option.misfire == MisfireFireOnce {
+		missed = 1
+	}
+	if missed > 0 {
+		logger.WithContext(ctx).Infof("Task [%s] catching up %d missed run(s) since %v", t.id, missed, record.LastStartedAt)
+	}
+	for i := 0; i < missed && ctx.Err() == nil; i++ {
+		t.execTask(ctx, true, false)
+	}
+}
+
 func (t *task) fixedIntervalLoop(ctx context.Context) {
 	ticker := time.NewTicker(t.option.interval)
 	defer ticker.Stop()
+	t.setNext(time.Now().Add(t.option.interval))
 	for {
 		select {
-		case <-ticker.C:
-			t.execTask(ctx, false)
+		case now := <-ticker.C:
+			t.setNext(now.Add(t.option.interval))
+			t.execTask(ctx, false, false)
 		case <-ctx.Done():
 			return
 		}
@@ -146,11 +204,13 @@ func (t *task) fixedIntervalLoop(ctx context.Context) {
 
 func (t *task) fixedDelayLoop(ctx context.Context) {
 	timer := time.NewTimer(t.option.interval)
+	t.setNext(time.Now().Add(t.option.interval))
 	for {
 		select {
 		case <-timer.C:
-			t.execTask(ctx, true)
+			t.execTask(ctx, true, false)
 			timer.Reset(t.option.interval)
+			t.setNext(time.Now().Add(t.option.interval))
 		case <-ctx.Done():
 			timer.Stop()
 			return
@@ -161,12 +221,14 @@ func (t *task) fixedDelayLoop(ctx context.Context) {
 func (t *task) dynamicTriggerLoop(ctx context.Context) {
 	next := t.option.nextFunc(time.Now())
 	timer := time.NewTimer(time.Until(next))
+	t.setNext(next)
 	for {
 		select {
 		case now := <-timer.C:
-			t.execTask(ctx, false)
+			t.execTask(ctx, false, false)
 			next = t.option.nextFunc(now)
 			timer.Reset(time.Until(next))
+			t.setNext(next)
 		case <-ctx.Done():
 			timer.Stop()
 			return
@@ -174,26 +236,31 @@ func (t *task) dynamicTriggerLoop(ctx context.Context) {
 	}
 }
 
-func (t *task) execTask(ctx context.Context, wait bool) {
+// execTask executes the task. Scheduled executions (manual=false) are skipped when the task is paused
+func (t *task) execTask(ctx context.Context, wait bool, manual bool) {
 	errCh := make(chan error, 1)
 	go func() {
 		execCtx := ctx
 		var err error
+		var releases []context.CancelFunc
 		defer func() {
 			// try recover
 			if e := recover(); e != nil {
 				err = fmt.Errorf("%v", e)
 			}
 
+			// release guards in reverse order
+			for i := len(releases) - 1; i >= 0; i-- {
+				releases[i]()
+			}
+
 			// post-hook
 			for _, hook := range t.option.hooks {
 				hook.AfterTrigger(execCtx, t.id, err)
 			}
 
-			// handle error
-			if err != nil {
-				t.handleError(execCtx, err)
-			}
+			// handle result
+			t.handleResult(execCtx, err)
 
 			// notify and cleanup
 			errCh <- err
@@ -205,7 +272,25 @@ func (t *task) execTask(ctx context.Context, wait bool) {
 			execCtx = hook.BeforeTrigger(execCtx, t.id)
 		}
 
+		// paused
+		if !manual && t.isPaused() {
+			err = fmt.Errorf("%w: task is paused", ErrSkipped)
+			return
+		}
+
+		// guards
+		for _, guard := range t.option.guards {
+			var release context.CancelFunc
+			if execCtx, release, err = guard(execCtx, t.id); release != nil {
+				releases = append(releases, release)
+			}
+			if err != nil {
+				return
+			}
+		}
+
 		// run task
+		t.recordStart()
 		err = t.task(execCtx)
 	}()
 
@@ -218,9 +303,40 @@ func (t *task) execTask(ctx context.Context, wait bool) {
 	}
 }
 
-func (t *task) handleError(ctx context.Context, err error) {
+func (t *task) recordStart() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.stats.lastStart = time.Now()
+}
+
+func (t *task) setNext(next time.Time) {
 	t.mtx.Lock()
 	defer t.mtx.Unlock()
+	t.next = next
+}
+
+func (t *task) isPaused() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.paused
+}
+
+func (t *task) handleResult(ctx context.Context, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if errors.Is(err, ErrSkipped) {
+		t.skipped++
+		logger.WithContext(ctx).Debugf("Task [%s] skipped: %v", t.id, err)
+		return
+	}
+
+	t.stats.runs++
+	t.stats.lastFinish = time.Now()
+	t.stats.lastErr = err
+	if err == nil {
+		return
+	}
 
 	t.err = err
 	if t.option.cancelOnError {
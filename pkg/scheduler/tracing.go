@@ -18,6 +18,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"github.com/cisco-open/go-lanai/pkg/tracing"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -50,7 +51,10 @@ func (h *tracingTaskHook) BeforeTrigger(ctx context.Context, id string) context.
 
 func (h *tracingTaskHook) AfterTrigger(ctx context.Context, _ string, err error) {
 	op := tracing.WithTracer(h.tracer)
-	if err != nil {
+	switch {
+	case errors.Is(err, ErrSkipped):
+		op.WithOptions(tracing.SpanTag("skipped", err))
+	case err != nil:
 		op.WithOptions(tracing.SpanTag("err", err))
 	}
 	op.Finish(ctx)
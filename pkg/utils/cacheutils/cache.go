@@ -205,8 +205,8 @@ func (c *cache) Delete(k Key) {
 }
 
 func (c *cache) Reset() {
-	c.mtx.RLock()
-	defer c.mtx.RUnlock()
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	c.store = map[interface{}]*cEntry{}
 }
 
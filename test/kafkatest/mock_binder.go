@@ -109,6 +109,22 @@ func (b *MockedBinder) ListTopics() []string {
 	return topics.Values()
 }
 
+// Transaction implements kafka.Binder. Messages sent within the transaction are recorded only if given function
+// returns nil
+func (b *MockedBinder) Transaction(ctx context.Context, fn kafka.TransactionFunc) error {
+	if tx, ok := mockedTransactionFromContext(ctx); ok {
+		return fn(tx)
+	}
+	tx := &MockedTransaction{Context: ctx}
+	if e := fn(tx); e != nil {
+		return e
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.recordings = append(b.recordings, tx.records...)
+	return nil
+}
+
 func (b *MockedBinder) Reset() {
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
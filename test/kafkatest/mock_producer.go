@@ -37,11 +37,16 @@ func (p *MockedProducer) Topic() string {
 	return p.T
 }
 
-func (p *MockedProducer) Send(_ context.Context, message interface{}, _ ...kafka.MessageOptions) error {
-	p.Recorder.Record(&MessageRecord{
+func (p *MockedProducer) Send(ctx context.Context, message interface{}, _ ...kafka.MessageOptions) error {
+	record := &MessageRecord{
 		Topic: p.T,
 		Payload: message,
-	})
+	}
+	if tx, ok := mockedTransactionFromContext(ctx); ok {
+		tx.Record(record)
+		return nil
+	}
+	p.Recorder.Record(record)
 	return nil
 }
 
@@ -0,0 +1,82 @@
+// Copyright 2023 Cisco Systems, Inc. and its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkatest
+
+import (
+	"context"
+	"sync"
+)
+
+type mockedTxKey struct{}
+
+// MockedTransaction implements kafka.Transaction. Messages sent by MockedProducer within the transaction are recorded
+// only when the transaction is committed
+type MockedTransaction struct {
+	context.Context
+	mtx     sync.Mutex
+	records []*MessageRecord
+	offsets []MockedTxOffset
+}
+
+// MockedTxOffset is the consumed offset added to MockedTransaction
+type MockedTxOffset struct {
+	Group     string
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+func (tx *MockedTransaction) Value(key any) any {
+	if _, ok := key.(mockedTxKey); ok {
+		return tx
+	}
+	return tx.Context.Value(key)
+}
+
+func (tx *MockedTransaction) TransactionalID() string {
+	return "mocked"
+}
+
+func (tx *MockedTransaction) AddOffset(group string, topic string, partition int32, offset int64) error {
+	tx.mtx.Lock()
+	defer tx.mtx.Unlock()
+	tx.offsets = append(tx.offsets, MockedTxOffset{Group: group, Topic: topic, Partition: partition, Offset: offset})
+	return nil
+}
+
+// Offsets returns offsets added to the transaction
+func (tx *MockedTransaction) Offsets() []MockedTxOffset {
+	tx.mtx.Lock()
+	defer tx.mtx.Unlock()
+	ret := make([]MockedTxOffset, len(tx.offsets))
+	copy(ret, tx.offsets)
+	return ret
+}
+
+func (tx *MockedTransaction) Record(record *MessageRecord) {
+	tx.mtx.Lock()
+	defer tx.mtx.Unlock()
+	tx.records = append(tx.records, record)
+}
+
+func mockedTransactionFromContext(ctx context.Context) (*MockedTransaction, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(mockedTxKey{}).(*MockedTransaction)
+	return tx, ok
+}
@@ -129,6 +129,7 @@ func TestMockedBinder(t *testing.T) {
 		test.GomegaSubTest(SubTestProducerRecording(di), "ProducerRecording"),
 		test.GomegaSubTest(SubTestSubscriber(di), "Subscriber"),
 		test.GomegaSubTest(SubTestConsumer(di), "Consumer"),
+		test.GomegaSubTest(SubTestTransaction(di), "Transaction"),
 	)
 }
 
@@ -238,3 +239,30 @@ func assertRecordedMessages(_ *testing.T, g *gomega.WithT, actual []*MessageReco
 		g.Expect(msg.String).To(BeEquivalentTo(fmt.Sprintf("Message-%d", i)), "recorded message at [%d] should have correct String field", i)
 	}
 }
+
+func SubTestTransaction(di *testDI) test.GomegaSubTestFunc {
+	return func(ctx context.Context, t *testing.T, g *gomega.WithT) {
+		var e error
+		di.Recorder.Reset()
+		e = di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+			if e := di.Service.GenerateSomeMessages(tx, 3); e != nil {
+				return e
+			}
+			g.Expect(di.Recorder.Records(TestTopic)).To(BeEmpty(), "messages should not be recorded before commit")
+			return tx.AddOffset(TestGroup, TestTopic, 0, 1)
+		})
+		g.Expect(e).To(Succeed(), "transaction should not fail")
+		assertRecordedMessages(t, g, di.Recorder.Records(TestTopic), 3, true)
+
+		// aborted transaction
+		di.Recorder.Reset()
+		e = di.Binder.Transaction(ctx, func(tx kafka.Transaction) error {
+			if e := di.Service.GenerateSomeMessages(tx, 3); e != nil {
+				return e
+			}
+			return fmt.Errorf("oops")
+		})
+		g.Expect(e).To(HaveOccurred(), "transaction should fail")
+		assertRecordedMessages(t, g, di.Recorder.Records(TestTopic), 0, false)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/cisco-open/go-lanai/pkg/dsync"
 	"go.uber.org/fx"
 	"sync"
+	"sync/atomic"
 )
 
 type SimpleSyncManagerMock struct {}
@@ -40,10 +41,26 @@ func (m SimpleSyncManagerMock) Lock(key string, _ ...dsync.LockOptions) (dsync.L
 	return &AlwaysLockMock{key: key}, nil
 }
 
+func (m SimpleSyncManagerMock) Semaphore(key string, permits int, _ ...dsync.LockOptions) (dsync.Semaphore, error) {
+	return &AlwaysSemaphoreMock{AlwaysLockMock: AlwaysLockMock{key: key}, permits: permits}, nil
+}
+
+func (m SimpleSyncManagerMock) RWLock(key string, _ ...dsync.LockOptions) (dsync.RWLock, error) {
+	return &AlwaysRWLockMock{
+		key:   key,
+		read:  &AlwaysLockMock{key: key},
+		write: &AlwaysLockMock{key: key},
+	}, nil
+}
+
+// fencingTokens is shared among all AlwaysLockMock, to mimic monotonically increasing fencing tokens
+var fencingTokens uint64
+
 type AlwaysLockMock struct {
-	mtx sync.Mutex
-	key string
-	ch  chan struct{}
+	mtx   sync.Mutex
+	key   string
+	ch    chan struct{}
+	token uint64
 }
 
 func (l *AlwaysLockMock) Key() string {
@@ -55,6 +72,7 @@ func (l *AlwaysLockMock) Lock(_ context.Context) error {
 	defer l.mtx.Unlock()
 	if l.ch == nil {
 		l.ch = make(chan struct{}, 1)
+		l.token = atomic.AddUint64(&fencingTokens, 1)
 	}
 	return nil
 }
@@ -69,6 +87,7 @@ func (l *AlwaysLockMock) Release() error {
 	if l.ch != nil {
 		close(l.ch)
 		l.ch = nil
+		l.token = 0
 	}
 	return nil
 }
@@ -79,7 +98,35 @@ func (l *AlwaysLockMock) Lost() <-chan struct{} {
 	return l.ch
 }
 
+func (l *AlwaysLockMock) FencingToken() uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.token
+}
 
+type AlwaysSemaphoreMock struct {
+	AlwaysLockMock
+	permits int
+}
 
+func (s *AlwaysSemaphoreMock) Permits() int {
+	return s.permits
+}
+
+type AlwaysRWLockMock struct {
+	key   string
+	read  *AlwaysLockMock
+	write *AlwaysLockMock
+}
+
+func (l *AlwaysRWLockMock) Key() string {
+	return l.key
+}
 
+func (l *AlwaysRWLockMock) ReadLock() dsync.Lock {
+	return l.read
+}
 
+func (l *AlwaysRWLockMock) WriteLock() dsync.Lock {
+	return l.write
+}
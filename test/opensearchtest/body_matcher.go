@@ -17,8 +17,12 @@
 package opensearchtest
 
 import (
+    "encoding/json"
     "fmt"
     "github.com/cisco-open/go-lanai/test/ittest"
+    "github.com/onsi/gomega/format"
+    "github.com/onsi/gomega/types"
+    "io"
     "strings"
 )
 
@@ -56,4 +60,75 @@ func (m BulkJsonBodyMatcher) split(data []byte) [][]byte {
         }
     }
     return rs
-}
\ No newline at end of file
+}
+
+// MatchBody returns a gomega matcher that compares JSON request bodies semantically, e.g. typed query builders and
+// search bodies of opensearch package, instead of comparing raw JSON strings.
+// Both actual and expected value can be a json.Marshaler (e.g. opensearch.SearchBody), []byte, string, io.Reader or
+// any value that can be marshalled into JSON.
+// Parts of the body can be ignored with JSONPath notation, same as FuzzyJsonPaths
+//
+// e.g.
+//
+//	Expect(opensearch.NewSearchBody().Query(opensearch.TermQuery("SubType", "SYNCHRONIZED"))).
+//		To(MatchBody(`{"query":{"term":{"SubType":"SYNCHRONIZED"}}}`))
+func MatchBody(expected interface{}, fuzzyJsonPaths ...string) types.GomegaMatcher {
+    return &GomegaBodyMatcher{
+        expected: expected,
+        delegate: ittest.NewRecordJsonBodyMatcher(fuzzyJsonPaths...),
+    }
+}
+
+type GomegaBodyMatcher struct {
+    expected interface{}
+    delegate ittest.RecordBodyMatcher
+    err      error
+}
+
+func (m *GomegaBodyMatcher) Match(actual interface{}) (success bool, err error) {
+    actualJson, e := toJson(actual)
+    if e != nil {
+        return false, fmt.Errorf("unable to convert actual value to JSON: %v", e)
+    }
+    expectedJson, e := toJson(m.expected)
+    if e != nil {
+        return false, fmt.Errorf("unable to convert expected value to JSON: %v", e)
+    }
+    m.err = m.delegate.Matches(actualJson, expectedJson)
+    return m.err == nil, nil
+}
+
+func (m *GomegaBodyMatcher) FailureMessage(actual interface{}) (message string) {
+    desc := format.Message(asJsonString(actual), "to match JSON body", asJsonString(m.expected))
+    return fmt.Sprintf("%s\nResult:\n%v", desc, m.err)
+}
+
+func (m *GomegaBodyMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+    return format.Message(asJsonString(actual), "not to match JSON body", asJsonString(m.expected))
+}
+
+func toJson(v interface{}) ([]byte, error) {
+    switch body := v.(type) {
+    case []byte:
+        return body, nil
+    case string:
+        return []byte(body), nil
+    case io.Reader:
+        return io.ReadAll(body)
+    default:
+        return json.Marshal(body)
+    }
+}
+
+func asJsonString(v interface{}) string {
+    switch v.(type) {
+    case io.Reader:
+        // readers cannot be read twice
+        return fmt.Sprintf("%v", v)
+    }
+    data, e := toJson(v)
+    if e != nil {
+        return fmt.Sprintf("%v", v)
+    }
+    return strings.TrimSpace(string(data))
+}
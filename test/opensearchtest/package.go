@@ -17,7 +17,9 @@
 package opensearchtest
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"github.com/cisco-open/go-lanai/pkg/opensearch"
 	"github.com/cisco-open/go-lanai/pkg/utils/order"
 	"github.com/cisco-open/go-lanai/test"
@@ -28,6 +30,7 @@ import (
 	"github.com/opensearch-project/opensearch-go/opensearchutil"
 	"go.uber.org/fx"
 	"gopkg.in/dnaeon/go-vcr.v3/recorder"
+	"io"
 	"net/http"
 	"testing"
 	"time"
@@ -279,10 +282,157 @@ func (e *EditIndexForTestingHook) Before(ctx context.Context, before opensearch.
 			request.Index = indices
 		}
 		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.GetRequest):
+		f := func(request *opensearchapi.GetRequest) {
+			request.Index = request.Index + e.Suffix
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.MgetRequest):
+		f := func(request *opensearchapi.MgetRequest) {
+			request.Index = request.Index + e.Suffix
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.UpdateRequest):
+		f := func(request *opensearchapi.UpdateRequest) {
+			request.Index = request.Index + e.Suffix
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.DeleteRequest):
+		f := func(request *opensearchapi.DeleteRequest) {
+			request.Index = request.Index + e.Suffix
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.DeleteByQueryRequest):
+		f := func(request *opensearchapi.DeleteByQueryRequest) {
+			var indices []string
+			for _, index := range request.Index {
+				indices = append(indices, index+e.Suffix)
+			}
+			request.Index = indices
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.UpdateByQueryRequest):
+		f := func(request *opensearchapi.UpdateByQueryRequest) {
+			var indices []string
+			for _, index := range request.Index {
+				indices = append(indices, index+e.Suffix)
+			}
+			request.Index = indices
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearch.PointInTimeCreateRequest):
+		f := func(request *opensearch.PointInTimeCreateRequest) {
+			var indices []string
+			for _, index := range request.Index {
+				indices = append(indices, index+e.Suffix)
+			}
+			request.Index = indices
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.CountRequest):
+		f := func(request *opensearchapi.CountRequest) {
+			var indices []string
+			for _, index := range request.Index {
+				indices = append(indices, index+e.Suffix)
+			}
+			request.Index = indices
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.IndicesExistsRequest):
+		f := func(request *opensearchapi.IndicesExistsRequest) {
+			var indices []string
+			for _, index := range request.Index {
+				indices = append(indices, index+e.Suffix)
+			}
+			request.Index = indices
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.IndicesGetAliasRequest):
+		f := func(request *opensearchapi.IndicesGetAliasRequest) {
+			var indices, names []string
+			for _, index := range request.Index {
+				indices = append(indices, index+e.Suffix)
+			}
+			for _, name := range request.Name {
+				names = append(names, name+e.Suffix)
+			}
+			request.Index = indices
+			request.Name = names
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.ReindexRequest):
+		f := func(request *opensearchapi.ReindexRequest) {
+			request.Body = e.editBody(request.Body, func(body map[string]interface{}) {
+				for _, k := range []string{"source", "dest"} {
+					if v, ok := body[k].(map[string]interface{}); ok {
+						v["index"] = e.suffixed(v["index"])
+					}
+				}
+			})
+		}
+		*opt = append(*opt, f)
+	case *[]func(request *opensearchapi.IndicesUpdateAliasesRequest):
+		f := func(request *opensearchapi.IndicesUpdateAliasesRequest) {
+			request.Body = e.editBody(request.Body, func(body map[string]interface{}) {
+				actions, _ := body["actions"].([]interface{})
+				for _, action := range actions {
+					action, _ := action.(map[string]interface{})
+					for _, v := range action {
+						target, ok := v.(map[string]interface{})
+						if !ok {
+							continue
+						}
+						for _, k := range []string{"index", "alias"} {
+							if target[k] != nil {
+								target[k] = e.suffixed(target[k])
+							}
+						}
+					}
+				}
+			})
+		}
+		*opt = append(*opt, f)
 	}
 	return ctx
 }
 
+// editBody applies the edit function on JSON body. Body is unchanged if it's not a JSON object
+func (e *EditIndexForTestingHook) editBody(body io.Reader, editFn func(body map[string]interface{})) io.Reader {
+	if body == nil {
+		return nil
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return bytes.NewReader(data)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return bytes.NewReader(data)
+	}
+	editFn(parsed)
+	edited, err := json.Marshal(parsed)
+	if err != nil {
+		return bytes.NewReader(data)
+	}
+	return bytes.NewReader(edited)
+}
+
+// suffixed appends the suffix to index names in request body, which is either a string or a list of strings
+func (e *EditIndexForTestingHook) suffixed(index interface{}) interface{} {
+	switch v := index.(type) {
+	case string:
+		return v + e.Suffix
+	case []interface{}:
+		indices := make([]interface{}, len(v))
+		for i := range v {
+			indices[i] = e.suffixed(v[i])
+		}
+		return indices
+	default:
+		return index
+	}
+}
+
 func IndexEditHookProvider(group string) fx.Annotated {
 	return fx.Annotated{
 		Group:  group,